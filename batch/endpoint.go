@@ -0,0 +1,21 @@
+package batch
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeBatchEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.BatchRequest)
+		res, err := s.Batch(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotCreateCode, "The batch request cannot be processed."), nil
+		}
+		return http.MultiStatusResponse{Item: res}, nil
+	}
+	return endpoint
+}
@@ -0,0 +1,66 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/qreasio/restlr/model"
+)
+
+// responseRecorder is http.ResponseWriter that stores response of sub request in memory
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: http.Header{}}
+}
+
+// Header returns response headers
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+// Write stores response body, status is 200 if WriteHeader is not called before
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(b)
+}
+
+// WriteHeader stores the first status code
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+// subResponse returns recorded response as batch sub response, body that is not json is returned as json string
+func (rec *responseRecorder) subResponse() *model.BatchSubResponse {
+	res := &model.BatchSubResponse{Status: rec.status, Headers: map[string]string{}}
+	if res.Status == 0 {
+		res.Status = http.StatusOK
+	}
+
+	for key := range rec.header {
+		if key != "Content-Type" {
+			res.Headers[key] = rec.header.Get(key)
+		}
+	}
+
+	body := rec.body.Bytes()
+	switch {
+	case len(body) == 0:
+		res.Body = json.RawMessage("null")
+	case json.Valid(body):
+		res.Body = json.RawMessage(body)
+	default:
+		res.Body, _ = json.Marshal(string(body))
+	}
+
+	return res
+}
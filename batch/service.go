@@ -0,0 +1,260 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/qreasio/restlr/cache"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	log "github.com/sirupsen/logrus"
+)
+
+// allowedMethods are http methods of sub requests like WP Rest API batch endpoint allows
+var allowedMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+var (
+	// errSubRequestFailed is returned inside savepoint of sub request that fails so its changes are rolled back
+	errSubRequestFailed = errors.New("batch sub request failed")
+	// errValidationFailed is returned inside batch transaction to roll back all sub requests in require-all-validate mode
+	errValidationFailed = errors.New("batch validation failed")
+)
+
+// subRequestKey is context key that marks request as sub request of batch that is dispatched in process
+type subRequestKey struct{}
+
+// IsSubRequest returns true if the context is context of sub request that is dispatched in process by batch
+func IsSubRequest(ctx context.Context) bool {
+	_, ok := ctx.Value(subRequestKey{}).(bool)
+	return ok
+}
+
+// Service is interface for batch functions
+type Service interface {
+	Batch(ctx context.Context, req model.BatchRequest) (interface{}, error)
+}
+
+// service is struct that will implement Service interface, sub requests are dispatched to router in process
+type service struct {
+	router chi.Router
+	shared shared.Repository
+}
+
+// NewService is a simple helper function to create a service instance
+func NewService(router chi.Router, sharedRepo shared.Repository) Service {
+	return &service{
+		router: router,
+		shared: sharedRepo,
+	}
+}
+
+// validateRequest checks validation mode and method and path of sub requests like the schema of WP Rest API batch endpoint
+func validateRequest(req *model.BatchRequest) error {
+	if req.Validation == "" {
+		req.Validation = model.BatchValidationNormal
+	}
+	if req.Validation != model.BatchValidationNormal && req.Validation != model.BatchValidationRequireAll {
+		return model.NewParamError("validation", "validation is not one of normal and require-all-validate.")
+	}
+	if len(req.Requests) > model.BatchMaxRequests {
+		return model.NewParamError("requests", fmt.Sprintf("requests must contain at most %d items.", model.BatchMaxRequests))
+	}
+
+	for i, sub := range req.Requests {
+		if sub == nil {
+			return model.NewParamError("requests", fmt.Sprintf("requests[%d] is not of type object.", i))
+		}
+		sub.Method = strings.ToUpper(sub.Method)
+		if sub.Method == "" {
+			sub.Method = http.MethodPost
+		}
+		allowed := false
+		for _, method := range allowedMethods {
+			allowed = allowed || sub.Method == method
+		}
+		if !allowed {
+			return model.NewParamError("requests", fmt.Sprintf("requests[%d][method] is not one of POST, PUT, PATCH, and DELETE.", i))
+		}
+		if !strings.HasPrefix(sub.Path, "/") {
+			return model.NewParamError("requests", fmt.Sprintf("requests[%d][path] is not a valid path.", i))
+		}
+	}
+
+	return nil
+}
+
+// restRoot returns path of rest api root that sub request path is relative to, e.g. /wp-json for /wp-json/wp API path
+func restRoot(ctx context.Context) string {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	return path.Dir(config.APIPath)
+}
+
+// subRequestURL returns url of sub request under rest api root, body of DELETE request is sent as query string
+// because delete endpoints read their parameters from the url
+func subRequestURL(ctx context.Context, sub *model.BatchSubRequest) (*url.URL, []byte, error) {
+	u, err := url.Parse(restRoot(ctx) + sub.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body := []byte(sub.Body)
+	if sub.Method != http.MethodDelete || len(body) == 0 {
+		return u, body, nil
+	}
+
+	var params map[string]interface{}
+	if err = json.Unmarshal(body, &params); err != nil {
+		return u, nil, nil
+	}
+	query := u.Query()
+	for key, value := range params {
+		switch v := value.(type) {
+		case string:
+			query.Set(key, v)
+		case bool:
+			query.Set(key, strconv.FormatBool(v))
+		case float64:
+			query.Set(key, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u, nil, nil
+}
+
+// errorSubResponse returns api error response as batch sub response
+func errorSubResponse(res resthttp.APIResponse) *model.BatchSubResponse {
+	body, _ := json.Marshal(res)
+	return &model.BatchSubResponse{Body: body, Status: res.Data.Status, Headers: map[string]string{}}
+}
+
+// matchRoute returns error response if the sub request can't be handled by the router, it returns nil if the route exists
+func (s *service) matchRoute(ctx context.Context, sub *model.BatchSubRequest) *model.BatchSubResponse {
+	if strings.HasPrefix(sub.Path, "/batch/") {
+		return errorSubResponse(resthttp.NewErrorResponse(resthttp.RestBatchNotAllowedCode, resthttp.RestBatchNotAllowedMessage, http.StatusBadRequest))
+	}
+
+	u, _, err := subRequestURL(ctx, sub)
+	if err != nil || !s.router.Match(chi.NewRouteContext(), sub.Method, u.Path) {
+		return errorSubResponse(resthttp.NewRouteNotFoundResponse())
+	}
+
+	return nil
+}
+
+// dispatch serves sub request with the router in process, the sub request gets headers and remote address of the batch request
+// and the context of the batch, so it joins running transaction
+func (s *service) dispatch(ctx context.Context, req model.BatchRequest, sub *model.BatchSubRequest) *model.BatchSubResponse {
+	u, body, err := subRequestURL(ctx, sub)
+	if err != nil {
+		return errorSubResponse(resthttp.NewRouteNotFoundResponse())
+	}
+
+	// route context of the batch request is removed so the router routes sub request from the root
+	r, err := http.NewRequest(sub.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return errorSubResponse(resthttp.NewRouteNotFoundResponse())
+	}
+	r = r.WithContext(context.WithValue(context.WithValue(ctx, chi.RouteCtxKey, nil), subRequestKey{}, true))
+
+	for key, values := range req.Header {
+		if key != "Content-Type" && key != "Content-Length" {
+			r.Header[key] = values
+		}
+	}
+	if len(body) > 0 {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range sub.Headers {
+		r.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	r.RemoteAddr = req.RemoteAddr
+
+	rec := newResponseRecorder()
+	s.router.ServeHTTP(rec, r)
+	return rec.subResponse()
+}
+
+// Batch runs sub requests in order and returns their responses. If all sub requests target existing routes, they run in a single
+// transaction with savepoint for each sub request, so failed sub request doesn't leave partial changes. In require-all-validate mode
+// no sub request is applied if one of them fails, and only responses of the failed sub requests are returned
+func (s *service) Batch(ctx context.Context, req model.BatchRequest) (interface{}, error) {
+	if err := validateRequest(&req); err != nil {
+		return nil, err
+	}
+
+	// the batch changes only content of its sub requests, which invalidate the cache after the batch
+	cache.KeepCache(ctx)
+
+	responses := make([]*model.BatchSubResponse, len(req.Requests))
+	handled := true
+	for i, sub := range req.Requests {
+		responses[i] = s.matchRoute(ctx, sub)
+		handled = handled && responses[i] == nil
+	}
+	requireAll := req.Validation == model.BatchValidationRequireAll
+
+	if !handled {
+		if requireAll {
+			return &model.BatchResponse{Failed: "validation", Responses: responses}, nil
+		}
+		// sub request that is not handled by restlr can't join transaction, so each sub request runs on its own
+		for i, sub := range req.Requests {
+			if responses[i] == nil {
+				responses[i] = s.dispatch(ctx, req, sub)
+			}
+		}
+		return &model.BatchResponse{Responses: responses}, nil
+	}
+
+	err := s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		failed := false
+		for i, sub := range req.Requests {
+			err := s.shared.WithSavepoint(ctx, fmt.Sprintf("batch_%d", i), func(ctx context.Context) error {
+				responses[i] = s.dispatch(ctx, req, sub)
+				if responses[i].Status >= http.StatusBadRequest {
+					return errSubRequestFailed
+				}
+				return nil
+			})
+			if err == errSubRequestFailed {
+				failed = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if failed && requireAll {
+			return errValidationFailed
+		}
+		return nil
+	})
+
+	if err == errValidationFailed {
+		// successful sub requests are rolled back, so their responses are not returned
+		for i, res := range responses {
+			if res.Status < http.StatusBadRequest {
+				responses[i] = nil
+			}
+		}
+		return &model.BatchResponse{Failed: "validation", Responses: responses}, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": len(req.Requests),
+			"func":   "s.shared.WithTransaction",
+		}).Errorf("Failed to run batch: %s", err)
+		return nil, err
+	}
+
+	return &model.BatchResponse{Responses: responses}, nil
+}
@@ -0,0 +1,144 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockshared "github.com/qreasio/restlr/shared/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBatchTestService returns service that dispatches sub requests to test router under /wp-json/wp/v2,
+// names of savepoints that are rolled back are appended to rolledBack
+func newBatchTestService(t *testing.T, ctx context.Context, rolledBack *[]string) Service {
+	posts := chi.NewRouter()
+	posts.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "http://example.com/wp-json/wp/v2/posts/1")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"authorization": r.Header.Get("Authorization"), "content_type": r.Header.Get("Content-Type")})
+	})
+	posts.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":` + chi.URLParam(r, "id") + `,"force":"` + r.URL.Query().Get("force") + `"}`))
+	})
+	posts.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":"rest_invalid_param"}`))
+	})
+	router := chi.NewRouter()
+	router.Mount("/wp-json/wp/v2/posts", posts)
+
+	ctrl := gomock.NewController(t)
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().WithTransaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	sharedRepoMock.EXPECT().WithSavepoint(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+		err := fn(ctx)
+		if err != nil {
+			*rolledBack = append(*rolledBack, name)
+		}
+		return err
+	}).AnyTimes()
+
+	return NewService(router, sharedRepoMock)
+}
+
+func newBatchTestContext() context.Context {
+	return context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIPath: "/wp-json/wp"})
+}
+
+func TestService_BatchValidation(t *testing.T) {
+	ctx := newBatchTestContext()
+	var rolledBack []string
+	s := newBatchTestService(t, ctx, &rolledBack)
+
+	requests := make([]*model.BatchSubRequest, model.BatchMaxRequests+1)
+	for i := range requests {
+		requests[i] = &model.BatchSubRequest{Path: "/wp/v2/posts"}
+	}
+	_, err := s.Batch(ctx, model.BatchRequest{Requests: requests})
+	assert.Equal(t, model.NewParamError("requests", "requests must contain at most 25 items."), err)
+
+	_, err = s.Batch(ctx, model.BatchRequest{Validation: "all", Requests: requests[:1]})
+	assert.Equal(t, model.NewParamError("validation", "validation is not one of normal and require-all-validate."), err)
+
+	_, err = s.Batch(ctx, model.BatchRequest{Requests: []*model.BatchSubRequest{{Method: "get", Path: "/wp/v2/posts"}}})
+	assert.Equal(t, model.NewParamError("requests", "requests[0][method] is not one of POST, PUT, PATCH, and DELETE."), err)
+
+	_, err = s.Batch(ctx, model.BatchRequest{Requests: []*model.BatchSubRequest{{Path: "wp/v2/posts"}}})
+	assert.Equal(t, model.NewParamError("requests", "requests[0][path] is not a valid path."), err)
+}
+
+func TestService_Batch(t *testing.T) {
+	ctx := newBatchTestContext()
+	var rolledBack []string
+	s := newBatchTestService(t, ctx, &rolledBack)
+
+	res, err := s.Batch(ctx, model.BatchRequest{
+		Header: http.Header{"Authorization": {"Basic YWRtaW46c2VjcmV0"}, "Content-Type": {"application/json"}},
+		Requests: []*model.BatchSubRequest{
+			{Path: "/wp/v2/posts", Body: json.RawMessage(`{"title":"Hello"}`)},
+			{Method: "delete", Path: "/wp/v2/posts/7", Body: json.RawMessage(`{"force":true}`)},
+			{Method: "PUT", Path: "/wp/v2/posts/7"},
+		},
+	})
+	assert.Nil(t, err)
+	batchRes := res.(*model.BatchResponse)
+	assert.Equal(t, "", batchRes.Failed)
+	assert.Len(t, batchRes.Responses, 3)
+
+	assert.Equal(t, http.StatusCreated, batchRes.Responses[0].Status)
+	assert.Equal(t, map[string]string{"Location": "http://example.com/wp-json/wp/v2/posts/1"}, batchRes.Responses[0].Headers)
+	assert.JSONEq(t, `{"authorization":"Basic YWRtaW46c2VjcmV0","content_type":"application/json"}`, string(batchRes.Responses[0].Body))
+
+	assert.Equal(t, http.StatusOK, batchRes.Responses[1].Status)
+	assert.JSONEq(t, `{"id":7,"force":"true"}`, string(batchRes.Responses[1].Body))
+
+	assert.Equal(t, http.StatusBadRequest, batchRes.Responses[2].Status)
+	assert.Equal(t, []string{"batch_2"}, rolledBack)
+}
+
+func TestService_BatchRequireAllValidate(t *testing.T) {
+	ctx := newBatchTestContext()
+	var rolledBack []string
+	s := newBatchTestService(t, ctx, &rolledBack)
+
+	requests := []*model.BatchSubRequest{
+		{Path: "/wp/v2/posts"},
+		{Method: "PUT", Path: "/wp/v2/posts/7"},
+	}
+	res, err := s.Batch(ctx, model.BatchRequest{Validation: model.BatchValidationRequireAll, Requests: requests})
+	assert.Nil(t, err)
+	batchRes := res.(*model.BatchResponse)
+	assert.Equal(t, "validation", batchRes.Failed)
+	assert.Nil(t, batchRes.Responses[0])
+	assert.Equal(t, http.StatusBadRequest, batchRes.Responses[1].Status)
+
+	// unknown route fails the batch before any sub request runs
+	requests = []*model.BatchSubRequest{
+		{Path: "/wp/v2/posts"},
+		{Path: "/wp/v2/widgets"},
+		{Path: "/batch/v1"},
+	}
+	res, err = s.Batch(ctx, model.BatchRequest{Validation: model.BatchValidationRequireAll, Requests: requests})
+	assert.Nil(t, err)
+	batchRes = res.(*model.BatchResponse)
+	assert.Equal(t, "validation", batchRes.Failed)
+	assert.Nil(t, batchRes.Responses[0])
+	assert.Equal(t, http.StatusNotFound, batchRes.Responses[1].Status)
+	assert.Equal(t, http.StatusBadRequest, batchRes.Responses[2].Status)
+
+	// in normal mode the other sub requests still run
+	res, err = s.Batch(ctx, model.BatchRequest{Requests: requests})
+	assert.Nil(t, err)
+	batchRes = res.(*model.BatchResponse)
+	assert.Equal(t, "", batchRes.Failed)
+	assert.Equal(t, http.StatusCreated, batchRes.Responses[0].Status)
+	assert.Equal(t, http.StatusNotFound, batchRes.Responses[1].Status)
+}
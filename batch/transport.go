@@ -0,0 +1,46 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// MakeHTTPHandler returns http handler that makes a set of endpoints available on predefined paths
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	BatchHandler := kithttp.NewServer(
+		instrument("batch.Batch", makeBatchEndpoint(s)),
+		batchRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	// every sub request is checked by the middleware of its own route, so batch route doesn't require write access
+	r.Method(http.MethodPost, "/", BatchHandler)
+
+	return r
+}
+
+func batchRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest model.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&batchRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Header,
+			"func":   "json.Decoder.Decode",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, model.ErrInvalidParameter
+	}
+	batchRequest.Header = r.Header
+	batchRequest.RemoteAddr = r.RemoteAddr
+	return batchRequest, nil
+}
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache stores values in Store with keys that are prefixed by version of the database content and generation of writes,
+// so values that were loaded before content changes are never returned
+type Cache struct {
+	hits          uint64
+	misses        uint64
+	shared        uint64
+	invalidations uint64
+
+	store Store
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu         sync.RWMutex
+	version    string
+	generation uint64
+}
+
+// Stats is hit and miss counters and size of Cache
+type Stats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Shared        uint64 `json:"shared"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+	Size          int64  `json:"size"`
+}
+
+// New returns Cache that stores values in store for ttl
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Fetch decodes cached value of the key to v, which must be pointer. If the key is not cached, value returned by load is cached
+// and decoded to v. Concurrent fetches of the same key that is not cached run load only once, and error of load is not cached.
+// load runs with context that isn't canceled when ctx is canceled, so a caller that goes away doesn't fail the others
+func (c *Cache) Fetch(ctx context.Context, key string, v interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	data, err := c.Load(ctx, key, func(ctx context.Context) ([]byte, bool, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var buf bytes.Buffer
+		if err = gob.NewEncoder(&buf).Encode(value); err != nil {
+			log.WithFields(log.Fields{
+				"params": key,
+				"func":   "gob.Encoder.Encode",
+			}).Errorf("Failed to encode cache value: %s", err)
+			return nil, false, err
+		}
+		return buf.Bytes(), true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// Load returns cached data of the key, or data returned by load if the key is not cached. The data is cached if load returns
+// cacheable true, and concurrent loads of the same key run load only once
+func (c *Cache) Load(ctx context.Context, key string, load func(ctx context.Context) (data []byte, cacheable bool, err error)) ([]byte, error) {
+	data, _, err := c.load(ctx, key, load)
+	return data, err
+}
+
+// load is Load that also returns whether data is cached. The load is shared by concurrent callers, so it runs with context
+// that keeps values and deadline of ctx of the caller that starts it but isn't canceled with it. A caller whose ctx is done
+// before the load completes gets error of ctx while the load goes on for the others
+func (c *Cache) load(ctx context.Context, key string, load func(ctx context.Context) ([]byte, bool, error)) ([]byte, bool, error) {
+	key = c.key(key)
+	if data, ok := c.store.Get(key); ok {
+		atomic.AddUint64(&c.hits, 1)
+		return data, true, nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	var started int32
+	loaded := c.group.DoChan(key, func() (value interface{}, err error) {
+		atomic.StoreInt32(&started, 1)
+		loadCtx, cancel := detachedContext(ctx)
+		defer cancel()
+		// the load runs in its own goroutine, panic is returned as error so it doesn't crash the server
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("cache: load panicked: %v", p)
+				log.WithFields(log.Fields{
+					"params": key,
+					"func":   "load",
+				}).Errorf("Failed to load cache value: %s", err)
+			}
+		}()
+
+		data, cacheable, err := load(loadCtx)
+		if err == nil && cacheable {
+			c.store.Set(key, data, c.ttl)
+		}
+		return data, err
+	})
+
+	select {
+	case res := <-loaded:
+		if atomic.LoadInt32(&started) == 0 {
+			atomic.AddUint64(&c.shared, 1)
+		}
+		data, _ := res.Val.([]byte)
+		return data, false, res.Err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// Invalidate makes all cached values stale, it is called after content is changed by write request
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.generation++
+	c.mu.Unlock()
+	c.purge()
+}
+
+// SetVersion sets version of the database content, cached values of other versions are stale. The first version that
+// is set keeps values of the store, so values that are stored on disk are still used after restart if content is not changed
+func (c *Cache) SetVersion(version string) {
+	c.mu.Lock()
+	previous := c.version
+	c.version = version
+	c.mu.Unlock()
+
+	if previous != "" && previous != version {
+		c.purge()
+	}
+}
+
+// Stats returns counters of the cache and number and size of the stored values
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:          atomic.LoadUint64(&c.hits),
+		Misses:        atomic.LoadUint64(&c.misses),
+		Shared:        atomic.LoadUint64(&c.shared),
+		Invalidations: atomic.LoadUint64(&c.invalidations),
+		Entries:       c.store.Len(),
+		Size:          c.store.Size(),
+	}
+}
+
+// Key returns cache key of repository result of the name and arguments, it has table prefix of the request
+// so results of sites with different prefix don't collide
+func Key(ctx context.Context, name string, args ...interface{}) string {
+	apiConfig, _ := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	return fmt.Sprintf("%s%s:%v", apiConfig.TablePrefix, name, args)
+}
+
+// detachedContext returns context with the values and the deadline of ctx that isn't canceled when ctx is canceled
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+func (c *Cache) purge() {
+	atomic.AddUint64(&c.invalidations, 1)
+	c.store.Purge()
+}
+
+func (c *Cache) key(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version + ":" + strconv.FormatUint(c.generation, 10) + ":" + key
+}
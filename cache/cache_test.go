@@ -0,0 +1,314 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetch(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	loads := 0
+	load := func(context.Context) (interface{}, error) {
+		loads++
+		return &model.Option{OptionName: "sticky_posts", OptionValue: "a:0:{}"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		var option *model.Option
+		assert.NoError(t, c.Fetch(context.Background(), "option", &option, load))
+		assert.Equal(t, "a:0:{}", option.OptionValue)
+	}
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, uint64(1), c.Stats().Hits)
+	assert.Equal(t, uint64(1), c.Stats().Misses)
+
+	c.Invalidate()
+	var option *model.Option
+	assert.NoError(t, c.Fetch(context.Background(), "option", &option, load))
+	assert.Equal(t, 2, loads)
+}
+
+func TestFetchDoesNotCacheError(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	errLoad := errors.New("load failed")
+
+	var option *model.Option
+	err := c.Fetch(context.Background(), "option", &option, func(context.Context) (interface{}, error) { return nil, errLoad })
+	assert.Equal(t, errLoad, err)
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestFetchCollapsesConcurrentMisses(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	var loads int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var value string
+			err := c.Fetch(context.Background(), "key", &value, func(context.Context) (interface{}, error) {
+				atomic.AddInt32(&loads, 1)
+				<-release
+				return "value", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+		}()
+	}
+
+	// every caller misses because the load is blocked, then give them time to join the load
+	for atomic.LoadUint64(&c.misses) < 5 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads)
+	assert.Equal(t, uint64(4), c.Stats().Shared)
+}
+
+func TestFetchCanceledCaller(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return "value", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		var value string
+		firstErr <- c.Fetch(ctx, "key", &value, load)
+	}()
+	<-started
+
+	secondErr := make(chan error)
+	var second string
+	go func() {
+		secondErr <- c.Fetch(context.Background(), "key", &second, load)
+	}()
+	for atomic.LoadUint64(&c.misses) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the first caller goes away, the load that it started goes on for the second one
+	cancel()
+	assert.Equal(t, context.Canceled, <-firstErr)
+	close(release)
+	assert.NoError(t, <-secondErr)
+	assert.Equal(t, "value", second)
+	assert.Equal(t, 1, c.Stats().Entries)
+}
+
+func TestSetVersion(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	c.SetVersion("v1")
+	var value string
+	assert.NoError(t, c.Fetch(context.Background(), "key", &value, func(context.Context) (interface{}, error) { return "old", nil }))
+
+	c.SetVersion("v1")
+	assert.NoError(t, c.Fetch(context.Background(), "key", &value, func(context.Context) (interface{}, error) { return "new", nil }))
+	assert.Equal(t, "old", value)
+
+	c.SetVersion("v2")
+	assert.NoError(t, c.Fetch(context.Background(), "key", &value, func(context.Context) (interface{}, error) { return "new", nil }))
+	assert.Equal(t, "new", value)
+	assert.Equal(t, uint64(1), c.Stats().Invalidations)
+}
+
+func TestKeyHasTablePrefix(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: "wp_2_"})
+	assert.Equal(t, "wp_2_term:[3 category]", Key(ctx, "term", 3, "category"))
+}
+
+func TestMiddleware(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	calls := 0
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-WP-Total", "1")
+		w.Write([]byte(`[{"id":1}]`))
+	}))
+
+	serve := func(method string, target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/posts?page=1&per_page=5", nil)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	rec = serve(http.MethodGet, "/posts?per_page=5&page=1", nil)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "1", rec.Header().Get("X-WP-Total"))
+	assert.Equal(t, `[{"id":1}]`, rec.Body.String())
+	assert.Equal(t, 1, calls)
+
+	serve(http.MethodGet, "/posts?page=1&per_page=5", http.Header{"Authorization": {"Bearer key"}})
+	assert.Equal(t, 2, calls)
+
+	serve(http.MethodGet, "/missing", nil)
+	rec = serve(http.MethodGet, "/missing", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, 4, calls)
+
+	serve(http.MethodPost, "/posts", nil)
+	rec = serve(http.MethodGet, "/posts?page=1&per_page=5", nil)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 6, calls)
+}
+
+func TestMiddleware_CanceledClient(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	var calls int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		assert.NoError(t, r.Context().Err())
+		w.Write([]byte(`[{"id":1}]`))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := httptest.NewRecorder()
+	firstDone := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/posts", nil).WithContext(ctx))
+		close(firstDone)
+	}()
+	<-started
+
+	second := httptest.NewRecorder()
+	secondDone := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/posts", nil))
+		close(secondDone)
+	}()
+
+	// the first client goes away while the response is filled, the fill goes on for the others
+	cancel()
+	<-firstDone
+	assert.Equal(t, http.StatusServiceUnavailable, first.Code)
+	close(release)
+	<-secondDone
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, `[{"id":1}]`, second.Body.String())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestMiddleware_NotCacheable(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	calls := 0
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/slow":
+			<-r.Context().Done()
+		}
+		w.Write([]byte(`[]`))
+	}))
+
+	serve := func(ctx context.Context, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx))
+		return rec
+	}
+
+	serve(context.Background(), "/error")
+	rec := serve(context.Background(), "/error")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, calls)
+
+	// the fill keeps the deadline of the request, response of fill that times out isn't cached
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	serve(ctx, "/slow")
+	// the fill goes on after the request times out, waiting for the key joins it until it is completed
+	c.group.Do(c.key(ResponseKey(req)), func() (interface{}, error) { return nil, nil })
+	assert.Equal(t, 0, c.Stats().Entries)
+	assert.Equal(t, 3, calls)
+}
+
+func TestMiddleware_LoadError(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	calls := 0
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		panic("handler failed")
+	}))
+
+	// the handler runs only once, its failure is returned as error response
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"internal_server_error"`)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestMiddleware_KeepCache(t *testing.T) {
+	c := New(NewLRUStore(1<<20), 0)
+	var handler http.Handler
+	handler = c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/held":
+			KeepCache(r.Context())
+		case "/batch":
+			// sub requests run inside the batch request, the batch itself keeps the cache
+			KeepCache(r.Context())
+			for _, path := range r.URL.Query()["path"] {
+				sub := httptest.NewRequest(http.MethodPost, path, nil).WithContext(r.Context())
+				handler.ServeHTTP(httptest.NewRecorder(), sub)
+				assert.Equal(t, uint64(0), c.Stats().Invalidations)
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	serve := func(target string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, target, nil))
+	}
+
+	serve("/held")
+	serve("/batch?path=/held&path=/held")
+	assert.Equal(t, uint64(0), c.Stats().Invalidations)
+	serve("/batch?path=/held&path=/approved")
+	assert.Equal(t, uint64(1), c.Stats().Invalidations)
+	serve("/approved")
+	assert.Equal(t, uint64(2), c.Stats().Invalidations)
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// diskFileExt is extension of files of DiskStore, Purge only removes files with the extension
+const diskFileExt = ".cache"
+
+// DiskStore is Store that keeps every value in its own file in a directory, so values survive restart.
+// The file starts with expiry time in unix nano seconds followed by the value
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore returns DiskStore of the directory, the directory is created if it doesn't exist
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+// Get returns value of the key, expired file is removed
+func (s *DiskStore) Get(key string) ([]byte, bool) {
+	name := s.path(key)
+	data, err := ioutil.ReadFile(name)
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+
+	var expires time.Time
+	if nano := int64(binary.BigEndian.Uint64(data)); nano != 0 {
+		expires = time.Unix(0, nano)
+	}
+	if expired(expires, time.Now()) {
+		os.Remove(name)
+		return nil, false
+	}
+
+	return data[8:], true
+}
+
+// Set writes value of the key to temporary file that is renamed to the file of the key, so reader never sees partial value
+func (s *DiskStore) Set(key string, value []byte, ttl time.Duration) {
+	var nano int64
+	if expires := expiry(ttl); !expires.IsZero() {
+		nano = expires.UnixNano()
+	}
+
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(nano))
+	copy(data[8:], value)
+
+	f, err := ioutil.TempFile(s.dir, "tmp-")
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": s.dir,
+			"func":   "ioutil.TempFile",
+		}).Errorf("Failed to create cache file: %s", err)
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		log.WithFields(log.Fields{
+			"params": key,
+			"func":   "f.Write",
+		}).Errorf("Failed to write cache file: %s", err)
+	}
+}
+
+// Purge removes all cache files in the directory
+func (s *DiskStore) Purge() {
+	for _, name := range s.files() {
+		os.Remove(filepath.Join(s.dir, name))
+	}
+}
+
+// Len returns number of cache files in the directory
+func (s *DiskStore) Len() int {
+	return len(s.files())
+}
+
+// Size returns total size of cache files in the directory
+func (s *DiskStore) Size() int64 {
+	var size int64
+	for _, name := range s.files() {
+		if info, err := os.Stat(filepath.Join(s.dir, name)); err == nil {
+			size += info.Size() - 8
+		}
+	}
+	return size
+}
+
+func (s *DiskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+diskFileExt)
+}
+
+func (s *DiskStore) files() []string {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": s.dir,
+			"func":   "ioutil.ReadDir",
+		}).Errorf("Failed to read cache directory: %s", err)
+		return nil
+	}
+
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), diskFileExt) {
+			names = append(names, info.Name())
+		}
+	}
+	return names
+}
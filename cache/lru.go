@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUStore is in memory Store that evicts the least recently used values when total size of values exceeds its max size
+type LRUStore struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	items   map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUStore returns LRUStore that holds values up to maxSize bytes
+func NewLRUStore(maxSize int64) *LRUStore {
+	return &LRUStore{
+		maxSize: maxSize,
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Get returns value of the key and marks it as the most recently used
+func (s *LRUStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if expired(entry.expires, time.Now()) {
+		s.remove(elem)
+		return nil, false
+	}
+
+	s.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value of the key and evicts the least recently used values until the size fits, value that is larger than
+// max size is not stored
+func (s *LRUStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.remove(elem)
+	}
+	if int64(len(value)) > s.maxSize {
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&lruEntry{key: key, value: value, expires: expiry(ttl)})
+	s.size += int64(len(value))
+
+	for s.size > s.maxSize {
+		s.remove(s.order.Back())
+	}
+}
+
+// Purge removes all values
+func (s *LRUStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = map[string]*list.Element{}
+	s.order.Init()
+	s.size = 0
+}
+
+// Len returns number of stored values
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// Size returns total size of stored values in bytes
+func (s *LRUStore) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *LRUStore) remove(elem *list.Element) {
+	entry := s.order.Remove(elem).(*lruEntry)
+	delete(s.items, entry.key)
+	s.size -= int64(len(entry.value))
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"net/http"
+
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// responseKeyPrefix is prefix of keys of cached responses
+const responseKeyPrefix = "response:"
+
+// writeKey is context key of state of write request that is served by Middleware
+type writeKey struct{}
+
+// writeState records whether write request keeps the cache and whether write requests inside it changed content
+type writeState struct {
+	keep    bool
+	changed bool
+}
+
+// KeepCache marks write request of ctx as request that doesn't change cached content by itself, like comment that is held
+// for moderation, so Middleware doesn't invalidate the cache after it unless write requests inside it change content
+func KeepCache(ctx context.Context) {
+	if state, ok := ctx.Value(writeKey{}).(*writeState); ok {
+		state.keep = true
+	}
+}
+
+// cachedResponse is response of GET request that is stored in cache
+type cachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Middleware caches successful responses of anonymous GET requests by path and query string with sorted parameters, and
+// invalidates the cache after successful write requests that aren't marked by KeepCache. Concurrent requests of the same key
+// share one fill of the response, a request whose client goes away gets error response without canceling the fill of the others.
+// Response has header X-Cache HIT if it comes from cache and MISS otherwise.
+// Requests with Authorization header are never cached, because they may see content that is not public
+func (c *Cache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			parent, nested := r.Context().Value(writeKey{}).(*writeState)
+			state := &writeState{}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), writeKey{}, state)))
+			if sw.status >= http.StatusBadRequest || (state.keep && !state.changed) {
+				return
+			}
+			// change of write request inside another one, like sub request of batch, invalidates the cache after the outer
+			// request so it isn't refilled before transaction of the outer request is committed
+			if nested {
+				parent.changed = true
+			} else {
+				c.Invalidate()
+			}
+			return
+		case http.MethodGet:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the response is filled once for all concurrent requests of the key, the fill keeps the deadline of the request
+		// but isn't canceled when the client that starts it goes away
+		data, hit, err := c.load(r.Context(), ResponseKey(r), func(ctx context.Context) ([]byte, bool, error) {
+			fill := r.WithContext(ctx)
+			rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(rec, fill)
+
+			var buf bytes.Buffer
+			err := gob.NewEncoder(&buf).Encode(cachedResponse{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()})
+			cacheable := rec.status >= http.StatusOK && rec.status < http.StatusMultipleChoices && ctx.Err() == nil
+			return buf.Bytes(), cacheable, err
+		})
+
+		var res cachedResponse
+		if err == nil {
+			err = gob.NewDecoder(bytes.NewReader(data)).Decode(&res)
+		}
+		if err != nil {
+			// the handler already ran for the fill, so error is returned instead of running it again
+			response, ok := resthttp.NewContextErrorResponse(err)
+			if !ok {
+				log.WithFields(log.Fields{
+					"params": r.URL.String(),
+					"func":   "c.load",
+				}).Errorf("Failed to load cached response: %s", err)
+				response = resthttp.NewErrorResponse(resthttp.InternalServerErrorCode, resthttp.InternalServerErrorMessage, http.StatusInternalServerError)
+			}
+			resthttp.EncodeJSONResponse(r.Context(), w, response)
+			return
+		}
+
+		for name, values := range res.Header {
+			w.Header()[name] = values
+		}
+		if hit {
+			w.Header().Set("X-Cache", "HIT")
+		} else {
+			w.Header().Set("X-Cache", "MISS")
+		}
+		w.WriteHeader(res.Status)
+		w.Write(res.Body)
+	})
+}
+
+// ResponseKey returns cache key of response of the request, query parameters are sorted by name
+// so the same query in different order has the same key, it has table prefix of the request so sites don't collide
+func ResponseKey(r *http.Request) string {
+	apiConfig, _ := r.Context().Value(model.APIConfigKey).(model.APIConfig)
+	return responseKeyPrefix + apiConfig.TablePrefix + r.URL.Path + "?" + r.URL.Query().Encode()
+}
+
+// responseRecorder records response of the handler to be cached
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+// statusWriter records status code of response that is written to the wrapped ResponseWriter
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Store is interface of cache backend that stores encoded values by key, it must be safe for concurrent use
+type Store interface {
+	// Get returns value of the key, ok is false if the key doesn't exist or is expired
+	Get(key string) (value []byte, ok bool)
+	// Set stores value of the key that expires after ttl, value is not expired if ttl is zero
+	Set(key string, value []byte, ttl time.Duration)
+	// Purge removes all values
+	Purge()
+	// Len returns number of stored values
+	Len() int
+	// Size returns total size of stored values in bytes
+	Size() int64
+}
+
+// Backend names of CACHE_BACKEND
+const (
+	MemoryBackendName = "memory"
+	DiskBackendName   = "disk"
+)
+
+// ValidateBackendName returns error if the name isn't name of a cache backend
+func ValidateBackendName(name string) error {
+	switch name {
+	case MemoryBackendName, DiskBackendName:
+		return nil
+	}
+	return fmt.Errorf("unknown cache backend %q", name)
+}
+
+// expired returns true if expiry time is set and has passed
+func expired(expires time.Time, now time.Time) bool {
+	return !expires.IsZero() && now.After(expires)
+}
+
+// expiry returns expiry time of value that is set now with ttl, zero time means the value doesn't expire
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
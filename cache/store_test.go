@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewLRUStore(10)
+	s.Set("a", []byte("aaaa"), 0)
+	s.Set("b", []byte("bbbb"), 0)
+	_, ok := s.Get("a")
+	assert.True(t, ok)
+
+	s.Set("c", []byte("cccc"), 0)
+	_, ok = s.Get("b")
+	assert.False(t, ok, "b is the least recently used")
+	value, ok := s.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("aaaa"), value)
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, int64(8), s.Size())
+
+	s.Set("d", []byte("too large value"), 0)
+	_, ok = s.Get("d")
+	assert.False(t, ok)
+}
+
+func TestLRUStoreExpires(t *testing.T) {
+	s := NewLRUStore(10)
+	s.Set("a", []byte("a"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	_, ok := s.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestDiskStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restlr-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewDiskStore(dir)
+	assert.NoError(t, err)
+	s.Set("a", []byte("value"), time.Minute)
+	s.Set("b", []byte("expired"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	value, ok := s.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+	_, ok = s.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 1, s.Len())
+	assert.Equal(t, int64(5), s.Size())
+
+	s.Purge()
+	_, ok = s.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, s.Len())
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	resthttp "github.com/qreasio/restlr/http"
+)
+
+// MakeHTTPHandler returns http handler of cache stats, it requires write access
+func MakeHTTPHandler(c *Cache) http.Handler {
+	r := chi.NewRouter()
+
+	statsHandler := kithttp.NewServer(
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return c.Stats(), nil
+		},
+		kithttp.NopRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodGet, "/", statsHandler)
+
+	return r
+}
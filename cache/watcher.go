@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Watcher polls change markers of the database content and sets their digest as version of Cache, so content that is
+// changed outside of the API, like in WP admin, invalidates the cache
+type Watcher struct {
+	db    *dialect.DB
+	cache *Cache
+	sites func() []model.Tables
+}
+
+// NewWatcher returns Watcher of tables of the sites
+func NewWatcher(db *dialect.DB, c *Cache, sites ...model.Tables) *Watcher {
+	return NewSitesWatcher(db, c, func() []model.Tables { return sites })
+}
+
+// NewSitesWatcher returns Watcher of tables of the sites that sites returns on every poll, so sites that are added to
+// multisite network are watched from the next poll
+func NewSitesWatcher(db *dialect.DB, c *Cache, sites func() []model.Tables) *Watcher {
+	return &Watcher{
+		db:    db,
+		cache: c,
+		sites: sites,
+	}
+}
+
+// markers returns marker queries of tables of the sites, markers of tables that are shared by sites of multisite network
+// are queried once
+func (w *Watcher) markers() []string {
+	var markers []string
+	seen := map[string]bool{}
+	for _, tables := range w.sites() {
+		for _, marker := range Markers(w.db.Dialect, tables) {
+			if !seen[marker] {
+				seen[marker] = true
+				markers = append(markers, marker)
+			}
+		}
+	}
+	return markers
+}
+
+// Markers returns queries of change markers of content, posts are marked by the last modified time, number of posts and
+// comments, while terms, users and options that have no modified time are marked by checksum of their columns
+func Markers(d dialect.Dialect, tables model.Tables) []string {
+	return []string{
+		fmt.Sprintf(`SELECT MAX(post_modified_gmt), COUNT(*), SUM(comment_count) FROM %s`, tables.Posts),
+		fmt.Sprintf(`SELECT COUNT(*), SUM(%s) FROM %s t INNER JOIN %s tt ON tt.term_id = t.term_id`,
+			d.Checksum("t.term_id", "t.name", "t.slug", "tt.taxonomy", "tt.description", "tt.parent", "tt.count"), tables.Terms, tables.TermTaxonomy),
+		fmt.Sprintf(`SELECT COUNT(*), SUM(%s) FROM %s`, d.Checksum("ID", "user_nicename", "user_url", "display_name"), tables.Users),
+		fmt.Sprintf(`SELECT COUNT(*), SUM(%s) FROM %s WHERE meta_key = 'description'`, d.Checksum("user_id", "meta_key", "meta_value"), tables.UserMeta),
+		fmt.Sprintf(`SELECT COUNT(*), SUM(%s) FROM %s `+
+			`WHERE SUBSTR(option_name, 1, 11) <> '_transient_' AND SUBSTR(option_name, 1, 16) <> '_site_transient_'`,
+			d.Checksum("option_name", "option_value"), tables.Options),
+	}
+}
+
+// Poll runs marker queries and sets digest of their results as version of the cache
+func (w *Watcher) Poll(ctx context.Context) error {
+	digest := sha256.New()
+	for _, query := range w.markers() {
+		values, err := w.marker(ctx, query)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": query,
+				"func":   "w.marker",
+			}).Errorf("Failed to query change marker: %s", err)
+			return err
+		}
+		for _, value := range values {
+			fmt.Fprintf(digest, "%s|", value.String)
+		}
+		digest.Write([]byte{'\n'})
+	}
+
+	w.cache.SetVersion(hex.EncodeToString(digest.Sum(nil))[:16])
+	return nil
+}
+
+// Run polls change markers every interval until ctx is done
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Poll(ctx); err != nil {
+				log.WithFields(log.Fields{
+					"params": interval,
+					"func":   "w.Poll",
+				}).Errorf("Failed to poll cache version: %s", err)
+			}
+		}
+	}
+}
+
+// marker returns columns of the single row of the marker query
+func (w *Watcher) marker(ctx context.Context, query string) ([]sql.NullString, error) {
+	rows, err := w.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	if rows.Next() {
+		dest := make([]interface{}, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, rows.Err()
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSitesWatcher(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, prefix := range []string{"wp_", "wp_2_"} {
+		for _, query := range []string{
+			"CREATE TABLE " + prefix + "posts (ID INTEGER PRIMARY KEY, post_modified_gmt DATETIME, comment_count INTEGER)",
+			"CREATE TABLE " + prefix + "terms (term_id INTEGER PRIMARY KEY, name TEXT, slug TEXT)",
+			"CREATE TABLE " + prefix + "term_taxonomy (term_id INTEGER, taxonomy TEXT, description TEXT, parent INTEGER, count INTEGER)",
+			"CREATE TABLE " + prefix + "options (option_name TEXT, option_value TEXT)",
+		} {
+			if _, err = db.Exec(query); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, query := range []string{
+		"CREATE TABLE wp_users (ID INTEGER PRIMARY KEY, user_nicename TEXT, user_url TEXT, display_name TEXT)",
+		"CREATE TABLE wp_usermeta (user_id INTEGER, meta_key TEXT, meta_value TEXT)",
+	} {
+		if _, err = db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := New(NewLRUStore(1<<20), 0)
+	sites := []model.Tables{model.NewTables("wp_")}
+	w := NewSitesWatcher(db, c, func() []model.Tables { return sites })
+	assert.NoError(t, w.Poll(context.Background()))
+
+	// site that is added to the network is watched from the next poll
+	sites = append(sites, model.NewSiteTables("wp_", "wp_2_"))
+	assert.NoError(t, w.Poll(context.Background()))
+	version := c.version
+	assert.NoError(t, w.Poll(context.Background()))
+	assert.Equal(t, version, c.version)
+
+	if _, err = db.Exec("INSERT INTO wp_2_posts VALUES (1, '2020-01-01 00:00:00', 0)"); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, w.Poll(context.Background()))
+	assert.NotEqual(t, version, c.version)
+}
@@ -0,0 +1,32 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeCreateCommentEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.CreateCommentRequest)
+		res, err := s.CreateComment(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotCreateCode, "The comment cannot be created."), nil
+		}
+		return http.CreatedResponse{Item: res, Location: itemLocation(ctx, res)}, nil
+	}
+	return endpoint
+}
+
+// itemLocation returns url of created comment for Location header
+func itemLocation(ctx context.Context, item interface{}) string {
+	c, ok := item.(*model.Comment)
+	if !ok {
+		return ""
+	}
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	return fmt.Sprintf("%s/comments/%d", apiConfig.APIBaseURL, c.ID)
+}
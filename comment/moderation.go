@@ -0,0 +1,46 @@
+package comment
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/qreasio/restlr/model"
+)
+
+// linkRegexp matches anchor tag with href attribute, it is the pattern used by check_comment to count links in comment content
+var linkRegexp = regexp.MustCompile(`(?i)<a [^>]*href`)
+
+// countLinks returns number of links in comment content
+func countLinks(content string) int {
+	return len(linkRegexp.FindAllStringIndex(content, -1))
+}
+
+// matchesKeys checks whether any word or IP in the keys option, one per line, is part of the comment author, email, url,
+// content, IP or user agent. Keys are matched case insensitively inside words like WP does, so "press" matches "WordPress"
+func matchesKeys(keys string, comment *model.Comment) bool {
+	fields := []string{
+		comment.AuthorName,
+		comment.AuthorEmail,
+		comment.AuthorAvatarURL,
+		comment.Content.Rendered,
+		comment.AuthorIP,
+		comment.AuthorAgent,
+	}
+	for i, field := range fields {
+		fields[i] = strings.ToLower(field)
+	}
+
+	for _, key := range strings.Split(keys, "\n") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		for _, field := range fields {
+			if strings.Contains(field, key) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package comment
+
+import (
+	"testing"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountLinks(t *testing.T) {
+	assert.Equal(t, 0, countLinks("no links here, see example.com"))
+	assert.Equal(t, 1, countLinks(`<a href="http://example.com">link</a>`))
+	assert.Equal(t, 2, countLinks(`<A HREF="http://a.com">a</A> and <a class="x" href="http://b.com">b</a>`))
+}
+
+func TestMatchesKeys(t *testing.T) {
+	comment := &model.Comment{
+		AuthorName:      "John",
+		AuthorEmail:     "john@example.com",
+		AuthorAvatarURL: "http://john.example.com",
+		AuthorIP:        "10.0.0.1",
+		AuthorAgent:     "Mozilla/5.0",
+		Content:         &model.ContentRendered{Rendered: "I love WordPress"},
+	}
+
+	assert.False(t, matchesKeys("", comment))
+	assert.False(t, matchesKeys("\n  \n", comment))
+	assert.False(t, matchesKeys("casino\nviagra", comment))
+	assert.True(t, matchesKeys("casino\npress", comment))
+	assert.True(t, matchesKeys(" JOHN@EXAMPLE.COM ", comment))
+	assert.True(t, matchesKeys("10.0.0.1", comment))
+	assert.True(t, matchesKeys("mozilla", comment))
+}
@@ -0,0 +1,218 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/sqlbuilder"
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository is interface for functions to interact with database
+type Repository interface {
+	CommentByID(ctx context.Context, id uint64) (*model.Comment, error)
+	InsertComment(ctx context.Context, comment *model.Comment) (uint64, error)
+	IsDuplicateComment(ctx context.Context, comment *model.Comment) (bool, error)
+	LatestCommentDateGmt(ctx context.Context, authorIP string, authorEmail string, since time.Time) (time.Time, error)
+	HasApprovedComment(ctx context.Context, authorName string, authorEmail string) (bool, error)
+	UpdateCommentCount(ctx context.Context, postID uint64) error
+	LockPostComments(ctx context.Context, postID uint64) error
+}
+
+type repository struct {
+	db *dialect.DB
+}
+
+// NewRepository is function to create new repository struct instance that implements Repository interface
+func NewRepository(db *dialect.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// CommentByID retrieves post id and approved status of a comment from prefix+'_comments' table
+func (repo *repository) CommentByID(ctx context.Context, id uint64) (*model.Comment, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Comments
+
+	sqlQuery := `SELECT comment_ID, comment_post_ID, comment_approved FROM ` + tableName + ` WHERE comment_ID = ?`
+
+	comment := &model.Comment{PostID: new(uint64)}
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, id).Scan(&comment.ID, comment.PostID, &comment.Approved)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": id,
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to get comment by id: %s", err)
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// InsertComment inserts comment as new row of prefix+'_comments' table and returns the new comment ID.
+// Comment type is stored as empty string like WP does before 5.5, so it is returned by CommentsByPostIDs
+func (repo *repository) InsertComment(ctx context.Context, comment *model.Comment) (uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Comments
+
+	sqlQuery, args, err := sqlbuilder.New(`INSERT INTO `).Ident(tableName).
+		SQL(` (comment_post_ID, comment_author, comment_author_email, comment_author_url, `+
+			`comment_author_IP, comment_date, comment_date_gmt, comment_content, comment_karma, comment_approved, comment_agent, `+
+			`comment_type, comment_parent, user_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?, '', ?, ?)`, *comment.PostID, comment.AuthorName,
+			comment.AuthorEmail, comment.AuthorAvatarURL, comment.AuthorIP, model.FormatDate(comment.Date), model.FormatDate(comment.DateGmt),
+			comment.Content.Rendered, comment.Approved, comment.AuthorAgent, comment.Parent, comment.Author).
+		Build()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := repo.db.Dialect.Insert(ctx, shared.Conn(ctx, repo.db), sqlQuery, "comment_ID", args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": comment,
+			"func":   "Dialect.Insert",
+		}).Errorf("Failed to insert comment: %s", err)
+		return 0, err
+	}
+
+	comment.ID = uint64(id)
+	return comment.ID, nil
+}
+
+// IsDuplicateComment checks whether the same author has submitted the same comment content on the post like wp_allow_comment does
+func (repo *repository) IsDuplicateComment(ctx context.Context, comment *model.Comment) (bool, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Comments
+
+	sqlQuery := `SELECT comment_ID FROM ` + tableName + ` WHERE comment_post_ID = ? AND comment_parent = ? ` +
+		`AND comment_approved != ? AND comment_content = ? AND (comment_author = ?`
+	args := []interface{}{*comment.PostID, comment.Parent, model.CommentTrash, comment.Content.Rendered, comment.AuthorName}
+	if comment.AuthorEmail != "" {
+		sqlQuery += ` OR comment_author_email = ?`
+		args = append(args, comment.AuthorEmail)
+	}
+	sqlQuery += `) LIMIT 1`
+
+	var id uint64
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to check duplicate comment: %s", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// LatestCommentDateGmt returns gmt date of the latest comment since the given time from the same IP address or email,
+// it returns zero time if there is no comment
+func (repo *repository) LatestCommentDateGmt(ctx context.Context, authorIP string, authorEmail string, since time.Time) (time.Time, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Comments
+
+	sqlQuery := `SELECT comment_date_gmt FROM ` + tableName + ` WHERE comment_date_gmt >= ? AND (comment_author_IP = ?`
+	args := []interface{}{model.FormatDate(since), authorIP}
+	if authorEmail != "" {
+		sqlQuery += ` OR comment_author_email = ?`
+		args = append(args, authorEmail)
+	}
+	sqlQuery += `) ORDER BY comment_date_gmt DESC LIMIT 1`
+
+	var dateGmt time.Time
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, args...).Scan(&dateGmt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to get latest comment date: %s", err)
+		return time.Time{}, err
+	}
+
+	return dateGmt, nil
+}
+
+// HasApprovedComment checks whether comment author with the name and email has approved comment
+func (repo *repository) HasApprovedComment(ctx context.Context, authorName string, authorEmail string) (bool, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Comments
+
+	sqlQuery := `SELECT comment_ID FROM ` + tableName + ` WHERE comment_author = ? AND comment_author_email = ? AND comment_approved = ? LIMIT 1`
+
+	var id uint64
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, authorName, authorEmail, model.CommentApproved).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": []string{authorName, authorEmail},
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to check approved comment: %s", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpdateCommentCount updates comment_count of the post with number of its approved comments like wp_update_comment_count_now does
+func (repo *repository) UpdateCommentCount(ctx context.Context, postID uint64) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	postTableName := config.Tables().Posts
+	tableName := config.Tables().Comments
+
+	sqlQuery, args, err := sqlbuilder.New(`UPDATE `).Ident(postTableName).SQL(` SET comment_count = (SELECT COUNT(*) FROM `).
+		Ident(tableName).SQL(` WHERE comment_post_ID = ? AND comment_approved = ?) WHERE ID = ?`, postID, model.CommentApproved, postID).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	if _, err = shared.Conn(ctx, repo.db).ExecContext(ctx, sqlQuery, args...); err != nil {
+		log.WithFields(log.Fields{
+			"params": postID,
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to update comment count: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// LockPostComments locks row of the post in prefix+'_posts' table until the transaction of ctx ends, so concurrent comments
+// of the post are checked for duplicate and flood and inserted one at a time. The row is locked by writing its comment_count
+// unchanged, which locks it in every database
+func (repo *repository) LockPostComments(ctx context.Context, postID uint64) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+
+	sqlQuery, args, err := sqlbuilder.New(`UPDATE `).Ident(tableName).SQL(` SET comment_count = comment_count WHERE ID = ?`, postID).Build()
+	if err != nil {
+		return err
+	}
+
+	if _, err = shared.Conn(ctx, repo.db).ExecContext(ctx, sqlQuery, args...); err != nil {
+		log.WithFields(log.Fields{
+			"params": postID,
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to lock post of comment: %s", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,393 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/shared"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// floodInterval is minimum duration between two comments from the same IP address or email like wp_check_comment_flood
+	floodInterval = 15 * time.Second
+	// floodWindow is how far back previous comments are checked for flood
+	floodWindow = time.Hour
+
+	maxAuthorNameLength  = 245
+	maxAuthorEmailLength = 100
+	maxAuthorURLLength   = 200
+	maxContentLength     = 65525
+)
+
+// contentPolicy allows only the tags and attributes that WP allows in comments of anonymous visitors ($allowedtags of kses),
+// scripts, event handler attributes and unsafe urls are removed and links get rel="nofollow" like wp_rel_nofollow
+var contentPolicy = newContentPolicy()
+
+func newContentPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowStandardURLs()
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowAttrs("title").OnElements("abbr", "acronym")
+	p.AllowAttrs("cite").OnElements("blockquote", "q")
+	p.AllowAttrs("datetime").OnElements("del")
+	p.AllowElements("b", "cite", "code", "em", "i", "s", "strike", "strong")
+	return p
+}
+
+// Service is interface for comment functions
+type Service interface {
+	CreateComment(ctx context.Context, req model.CreateCommentRequest) (interface{}, error)
+}
+
+// service is struct that will implement Service interface and store related repositories
+type service struct {
+	comment Repository
+	post    post.Repository
+	shared  shared.Repository
+}
+
+// NewService is a simple helper function to create a service instance
+func NewService(commentRepo Repository, postRepo post.Repository, sharedRepo shared.Repository) Service {
+	return &service{
+		comment: commentRepo,
+		post:    postRepo,
+		shared:  sharedRepo,
+	}
+}
+
+// optionValue returns value of the first option that exists from the option names, it is used for options that are renamed
+// in newer WP version like 'disallowed_keys' that was 'blacklist_keys'
+func (s *service) optionValue(ctx context.Context, defaultValue string, optionNames ...string) (string, error) {
+	for _, optionName := range optionNames {
+		option, err := s.shared.LoadOption(ctx, optionName)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": optionName,
+				"func":   "s.shared.LoadOption",
+			}).Errorf("Failed to load option: %s", err)
+			return "", err
+		}
+		return option.OptionValue, nil
+	}
+	return defaultValue, nil
+}
+
+// isEnabled returns true if the option value is set, WP stores checked setting as "1"
+func isEnabled(value string) bool {
+	return value != "" && value != "0"
+}
+
+// commentsOpen checks whether the post accepts new comment like comments_open does,
+// comments on old post are closed when 'close_comments_for_old_posts' setting is enabled
+func (s *service) commentsOpen(ctx context.Context, record *model.PostRecord, now time.Time) (bool, error) {
+	if record.CommentStatus != "open" {
+		return false, nil
+	}
+	if record.Type != model.PostType {
+		return true, nil
+	}
+
+	closeOld, err := s.optionValue(ctx, "0", "close_comments_for_old_posts")
+	if err != nil || !isEnabled(closeOld) {
+		return err == nil, err
+	}
+	daysOld, err := s.optionValue(ctx, "14", "close_comments_days_old")
+	if err != nil {
+		return false, err
+	}
+	days, err := strconv.Atoi(daysOld)
+	if err != nil || days <= 0 {
+		return true, nil
+	}
+
+	return now.Sub(record.DateGmt) <= time.Duration(days)*24*time.Hour, nil
+}
+
+// validatePost checks the post of the comment exists, can be read and accepts comment like the permission check of
+// WP Rest API comments controller does for anonymous user
+func (s *service) validatePost(ctx context.Context, req model.CreateCommentRequest, now time.Time) (*model.PostRecord, error) {
+	if req.Post == nil || *req.Post == 0 {
+		return nil, model.ErrCommentInvalidPostID
+	}
+
+	record, err := s.post.PostRecordByID(ctx, *req.Post)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrCommentInvalidPostID
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": *req.Post,
+			"func":   "s.post.PostRecordByID",
+		}).Errorf("Failed to get post record by id: %s", err)
+		return nil, err
+	}
+
+	switch record.Status {
+	case model.DraftStatus:
+		return nil, model.ErrCommentDraftPost
+	case model.TrashStatus:
+		return nil, model.ErrCommentTrashPost
+	case model.PublishStatus:
+	default:
+		return nil, model.ErrCommentCannotReadPost
+	}
+	if record.Password != "" && (req.Password == nil || *req.Password != record.Password) {
+		return nil, model.ErrCommentCannotReadPost
+	}
+
+	open, err := s.commentsOpen(ctx, record, now)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, model.ErrCommentClosed
+	}
+
+	return record, nil
+}
+
+// validateAuthor checks comment author fields like WP Rest API and wp_handle_comment_submission do
+func (s *service) validateAuthor(ctx context.Context, comment *model.Comment) error {
+	requireNameEmail, err := s.optionValue(ctx, "1", "require_name_email")
+	if err != nil {
+		return err
+	}
+	if isEnabled(requireNameEmail) && (comment.AuthorName == "" || comment.AuthorEmail == "") {
+		return model.ErrCommentAuthorDataRequired
+	}
+
+	if comment.AuthorEmail != "" {
+		if _, err := mail.ParseAddress(comment.AuthorEmail); err != nil {
+			return model.NewParamError("author_email", "Invalid email address.")
+		}
+	}
+
+	authorURL, ok := sanitizeAuthorURL(comment.AuthorAvatarURL)
+	if !ok {
+		return model.NewParamError("author_url", "Invalid URL.")
+	}
+	comment.AuthorAvatarURL = authorURL
+
+	lengths := []struct {
+		param  string
+		value  string
+		length int
+	}{
+		{"author_name", comment.AuthorName, maxAuthorNameLength},
+		{"author_email", comment.AuthorEmail, maxAuthorEmailLength},
+		{"author_url", comment.AuthorAvatarURL, maxAuthorURLLength},
+		{"content", comment.Content.Rendered, maxContentLength},
+	}
+	for _, l := range lengths {
+		if len(l.value) > l.length {
+			return model.NewParamError(l.param, "Value is too long.")
+		}
+	}
+
+	return nil
+}
+
+// sanitizeAuthorURL returns author url with http scheme if it has none like esc_url_raw, ok is false for url that isn't
+// http or https url like javascript: and data: urls
+func sanitizeAuthorURL(value string) (string, bool) {
+	if value == "" {
+		return "", true
+	}
+	if !strings.Contains(value, ":") {
+		value = "http://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// approvedStatus returns comment_approved value of new comment from discussion settings like wp_allow_comment does
+func (s *service) approvedStatus(ctx context.Context, comment *model.Comment) (string, error) {
+	disallowedKeys, err := s.optionValue(ctx, "", "disallowed_keys", "blacklist_keys")
+	if err != nil {
+		return "", err
+	}
+	if matchesKeys(disallowedKeys, comment) {
+		return model.CommentSpam, nil
+	}
+
+	moderation, err := s.optionValue(ctx, "0", "comment_moderation")
+	if err != nil {
+		return "", err
+	}
+	if isEnabled(moderation) {
+		return model.CommentHold, nil
+	}
+
+	maxLinks, err := s.optionValue(ctx, "2", "comment_max_links")
+	if err != nil {
+		return "", err
+	}
+	if max, err := strconv.Atoi(maxLinks); err == nil && max > 0 && countLinks(comment.Content.Rendered) >= max {
+		return model.CommentHold, nil
+	}
+
+	moderationKeys, err := s.optionValue(ctx, "", "moderation_keys")
+	if err != nil {
+		return "", err
+	}
+	if matchesKeys(moderationKeys, comment) {
+		return model.CommentHold, nil
+	}
+
+	previouslyApproved, err := s.optionValue(ctx, "1", "comment_previously_approved", "comment_whitelist")
+	if err != nil {
+		return "", err
+	}
+	if isEnabled(previouslyApproved) {
+		if comment.AuthorName == "" || comment.AuthorEmail == "" {
+			return model.CommentHold, nil
+		}
+		approved, err := s.comment.HasApprovedComment(ctx, comment.AuthorName, comment.AuthorEmail)
+		if err != nil {
+			return "", err
+		}
+		if !approved {
+			return model.CommentHold, nil
+		}
+	}
+
+	return model.CommentApproved, nil
+}
+
+// CreateComment stores anonymous comment after checking the post accepts comment, duplicate and flood,
+// the comment is approved, held for moderation or marked as spam based on discussion settings in options table
+func (s *service) CreateComment(ctx context.Context, req model.CreateCommentRequest) (interface{}, error) {
+	registration, err := s.optionValue(ctx, "0", "comment_registration")
+	if err != nil {
+		return nil, err
+	}
+	if isEnabled(registration) {
+		return nil, model.ErrCommentLoginRequired
+	}
+
+	now := time.Now()
+	record, err := s.validatePost(ctx, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.Comment{
+		PostID:          &record.ID,
+		Parent:          req.Parent,
+		AuthorName:      strings.TrimSpace(req.AuthorName),
+		AuthorEmail:     strings.TrimSpace(req.AuthorEmail),
+		AuthorAvatarURL: strings.TrimSpace(req.AuthorURL),
+		AuthorIP:        req.AuthorIP,
+		AuthorAgent:     req.AuthorUserAgent,
+		Content:         &model.ContentRendered{Rendered: strings.TrimSpace(contentPolicy.Sanitize(req.Content))},
+		DateGmt:         now.UTC(),
+	}
+	if comment.Content.Rendered == "" {
+		return nil, model.ErrCommentContentInvalid
+	}
+	if err = s.validateAuthor(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	if comment.Parent != 0 {
+		parent, err := s.comment.CommentByID(ctx, comment.Parent)
+		if err == sql.ErrNoRows || (err == nil && *parent.PostID != record.ID) {
+			return nil, model.NewParamError("parent", "Invalid comment parent.")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	loc, err := post.SiteLocation(ctx, s.shared)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"func": "post.SiteLocation",
+		}).Errorf("Failed to get site location: %s", err)
+		return nil, err
+	}
+	comment.Date = now.In(loc)
+
+	// duplicate and flood checks run in the transaction of the insert with the post locked, so concurrent comments
+	// don't pass them together
+	err = s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.comment.LockPostComments(ctx, record.ID); err != nil {
+			return err
+		}
+
+		duplicate, err := s.comment.IsDuplicateComment(ctx, comment)
+		if err != nil {
+			return err
+		}
+		if duplicate {
+			return model.ErrCommentDuplicate
+		}
+
+		lastDate, err := s.comment.LatestCommentDateGmt(ctx, comment.AuthorIP, comment.AuthorEmail, comment.DateGmt.Add(-floodWindow))
+		if err != nil {
+			return err
+		}
+		if !lastDate.IsZero() && comment.DateGmt.Sub(lastDate) < floodInterval {
+			return model.ErrCommentFlood
+		}
+
+		if comment.Approved, err = s.approvedStatus(ctx, comment); err != nil {
+			return err
+		}
+		if _, err := s.comment.InsertComment(ctx, comment); err != nil {
+			return err
+		}
+		return s.comment.UpdateCommentCount(ctx, record.ID)
+	})
+	if err == model.ErrCommentDuplicate || err == model.ErrCommentFlood {
+		return nil, err
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": record.ID,
+			"func":   "s.shared.WithTransaction",
+		}).Errorf("Failed to save comment: %s", err)
+		return nil, err
+	}
+
+	// comment that isn't approved is not shown in any response, so cached responses stay valid
+	if comment.Approved != model.CommentApproved {
+		cache.KeepCache(ctx)
+	}
+
+	p, err := s.post.PostByID(ctx, record.ID, record.Type)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": record.ID,
+			"func":   "s.post.PostByID",
+		}).Errorf("Failed to get post by id: %s", err)
+		return nil, err
+	}
+
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	comments, err := model.CommentsAsEmbeddedComments(apiConfig.APIBaseURL, p.Link, []*model.Comment{comment})
+	if err != nil {
+		return nil, err
+	}
+	comments[0].Status = model.CommentStatus(comment.Approved)
+	// CommentsAsEmbeddedComments hides post id for embedded replies, but it is part of single comment response
+	comments[0].PostID = &record.ID
+
+	return comments[0], nil
+}
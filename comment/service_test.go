@@ -0,0 +1,313 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockcomment "github.com/qreasio/restlr/comment/mock"
+	"github.com/qreasio/restlr/model"
+	mockpost "github.com/qreasio/restlr/post/mock"
+	mockshared "github.com/qreasio/restlr/shared/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type commentTestMocks struct {
+	comment *mockcomment.MockRepository
+	post    *mockpost.MockRepository
+	shared  *mockshared.MockRepository
+}
+
+// newCommentTestService returns service with mocked repositories, options are returned from the map
+func newCommentTestService(t *testing.T, ctx context.Context, options map[string]string, record *model.PostRecord) (Service, commentTestMocks) {
+	ctrl := gomock.NewController(t)
+	mocks := commentTestMocks{
+		comment: mockcomment.NewMockRepository(ctrl),
+		post:    mockpost.NewMockRepository(ctrl),
+		shared:  mockshared.NewMockRepository(ctrl),
+	}
+
+	mocks.shared.EXPECT().LoadOption(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (*model.Option, error) {
+		if value, ok := options[name]; ok {
+			return &model.Option{OptionName: name, OptionValue: value}, nil
+		}
+		return nil, sql.ErrNoRows
+	}).AnyTimes()
+	mocks.shared.EXPECT().WithTransaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	mocks.comment.EXPECT().LockPostComments(ctx, record.ID).Return(nil).AnyTimes()
+	mocks.post.EXPECT().PostRecordByID(ctx, record.ID).Return(record, nil).AnyTimes()
+	mocks.post.EXPECT().PostRecordByID(ctx, gomock.Any()).Return(nil, sql.ErrNoRows).AnyTimes()
+	p := &model.Post{}
+	p.ID, p.Link = record.ID, "http://example.com/hello-world/"
+	mocks.post.EXPECT().PostByID(ctx, record.ID, record.Type).Return(p, nil).AnyTimes()
+
+	return NewService(mocks.comment, mocks.post, mocks.shared), mocks
+}
+
+func newCommentRequest(postID uint64) model.CreateCommentRequest {
+	return model.CreateCommentRequest{
+		Post:            &postID,
+		AuthorName:      "John",
+		AuthorEmail:     "john@example.com",
+		Content:         "Nice post",
+		AuthorIP:        "10.0.0.1",
+		AuthorUserAgent: "Mozilla/5.0",
+	}
+}
+
+func TestService_CreateComment(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+
+	s, mocks := newCommentTestService(t, ctx, map[string]string{"comment_previously_approved": "1"}, record)
+	mocks.comment.EXPECT().IsDuplicateComment(ctx, gomock.Any()).Return(false, nil)
+	mocks.comment.EXPECT().LatestCommentDateGmt(ctx, "10.0.0.1", "john@example.com", gomock.Any()).Return(time.Time{}, nil)
+	mocks.comment.EXPECT().HasApprovedComment(ctx, "John", "john@example.com").Return(true, nil)
+	mocks.comment.EXPECT().InsertComment(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, comment *model.Comment) (uint64, error) {
+		assert.Equal(t, model.CommentApproved, comment.Approved)
+		assert.Equal(t, "Mozilla/5.0", comment.AuthorAgent)
+		comment.ID = 5
+		return comment.ID, nil
+	})
+	mocks.comment.EXPECT().UpdateCommentCount(ctx, uint64(1)).Return(nil)
+
+	res, err := s.CreateComment(ctx, newCommentRequest(1))
+	assert.Nil(t, err)
+	comment := res.(*model.Comment)
+	assert.Equal(t, uint64(5), comment.ID)
+	assert.Equal(t, uint64(1), *comment.PostID)
+	assert.Equal(t, "approved", comment.Status)
+	assert.Equal(t, "http://example.com/hello-world/#comment-5", comment.Link)
+	assert.Equal(t, "http://example.com/wp-json/wp/v2/comments/5", comment.Links.SelfLink[0]["href"])
+}
+
+func TestService_CreateCommentSanitizedContent(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"script", `Nice<script>alert(1)</script> post`, "Nice post"},
+		{"event handler", `<b onclick="alert(1)">Nice</b> <img src="x" onerror="alert(1)">post`, "<b>Nice</b> post"},
+		{"javascript url", `<a href="javascript:alert(1)" onmouseover="alert(1)">Nice</a> post`, "Nice post"},
+		{"allowed tags", `<a href="http://a.com" title="A">Nice</a> <em>post</em> <p style="color:red">here</p>`,
+			`<a href="http://a.com" title="A" rel="nofollow">Nice</a> <em>post</em> here`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mocks := newCommentTestService(t, ctx, map[string]string{"comment_previously_approved": "0"}, record)
+			mocks.comment.EXPECT().IsDuplicateComment(ctx, gomock.Any()).Return(false, nil)
+			mocks.comment.EXPECT().LatestCommentDateGmt(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(time.Time{}, nil)
+			mocks.comment.EXPECT().InsertComment(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, comment *model.Comment) (uint64, error) {
+				assert.Equal(t, tt.expected, comment.Content.Rendered)
+				comment.ID = 5
+				return comment.ID, nil
+			})
+			mocks.comment.EXPECT().UpdateCommentCount(ctx, uint64(1)).Return(nil)
+
+			req := newCommentRequest(1)
+			req.Content = tt.content
+			_, err := s.CreateComment(ctx, req)
+			assert.Nil(t, err)
+		})
+	}
+
+	// comment that has only removed markup is empty
+	s, _ := newCommentTestService(t, ctx, map[string]string{}, record)
+	req := newCommentRequest(1)
+	req.Content = `<script>alert(1)</script>`
+	_, err := s.CreateComment(ctx, req)
+	assert.Equal(t, model.ErrCommentContentInvalid, err)
+}
+
+func TestService_CreateCommentApprovedStatus(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+
+	tests := []struct {
+		name             string
+		options          map[string]string
+		content          string
+		approvedBefore   bool
+		expectedApproved string
+	}{
+		{"moderation", map[string]string{"comment_moderation": "1", "comment_previously_approved": "0"}, "Nice post", true, model.CommentHold},
+		{"max links", map[string]string{"comment_max_links": "2", "comment_previously_approved": "0"},
+			`<a href="http://a.com">a</a> <a href="http://b.com">b</a>`, true, model.CommentHold},
+		{"links under max", map[string]string{"comment_max_links": "2", "comment_previously_approved": "0"},
+			`<a href="http://a.com">a</a>`, true, model.CommentApproved},
+		{"moderation keys", map[string]string{"moderation_keys": "casino", "comment_previously_approved": "0"}, "Best Casino", true, model.CommentHold},
+		{"disallowed keys", map[string]string{"disallowed_keys": "10.0.0.1", "comment_moderation": "1"}, "Nice post", true, model.CommentSpam},
+		{"blacklist keys fallback", map[string]string{"blacklist_keys": "john"}, "Nice post", true, model.CommentSpam},
+		{"not previously approved", map[string]string{}, "Nice post", false, model.CommentHold},
+		{"comment whitelist fallback", map[string]string{"comment_whitelist": "0"}, "Nice post", false, model.CommentApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mocks := newCommentTestService(t, ctx, tt.options, record)
+			mocks.comment.EXPECT().IsDuplicateComment(ctx, gomock.Any()).Return(false, nil)
+			mocks.comment.EXPECT().LatestCommentDateGmt(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(time.Time{}, nil)
+			mocks.comment.EXPECT().HasApprovedComment(ctx, gomock.Any(), gomock.Any()).Return(tt.approvedBefore, nil).AnyTimes()
+			mocks.comment.EXPECT().InsertComment(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, comment *model.Comment) (uint64, error) {
+				assert.Equal(t, tt.expectedApproved, comment.Approved)
+				comment.ID = 5
+				return comment.ID, nil
+			})
+			mocks.comment.EXPECT().UpdateCommentCount(ctx, uint64(1)).Return(nil)
+
+			req := newCommentRequest(1)
+			req.Content = tt.content
+			res, err := s.CreateComment(ctx, req)
+			assert.Nil(t, err)
+			assert.Equal(t, model.CommentStatus(tt.expectedApproved), res.(*model.Comment).Status)
+		})
+	}
+}
+
+func TestService_CreateCommentRejected(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	now := time.Now().UTC()
+	password := "secret"
+
+	tests := []struct {
+		name        string
+		options     map[string]string
+		record      *model.PostRecord
+		modify      func(req *model.CreateCommentRequest)
+		expectedErr error
+	}{
+		{"registration required", map[string]string{"comment_registration": "1"}, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open"},
+			nil, model.ErrCommentLoginRequired},
+		{"missing post", nil, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open"},
+			func(req *model.CreateCommentRequest) { req.Post = nil }, model.ErrCommentInvalidPostID},
+		{"unknown post", nil, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open"},
+			func(req *model.CreateCommentRequest) { id := uint64(2); req.Post = &id }, model.ErrCommentInvalidPostID},
+		{"draft post", nil, &model.PostRecord{ID: 1, Status: model.DraftStatus, CommentStatus: "open"}, nil, model.ErrCommentDraftPost},
+		{"trash post", nil, &model.PostRecord{ID: 1, Status: model.TrashStatus, CommentStatus: "open"}, nil, model.ErrCommentTrashPost},
+		{"private post", nil, &model.PostRecord{ID: 1, Status: model.PrivateStatus, CommentStatus: "open"}, nil, model.ErrCommentCannotReadPost},
+		{"protected post", nil, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open", Password: password}, nil, model.ErrCommentCannotReadPost},
+		{"closed", nil, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "closed"}, nil, model.ErrCommentClosed},
+		{"old post", map[string]string{"close_comments_for_old_posts": "1", "close_comments_days_old": "14"},
+			&model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: now.AddDate(0, 0, -15)}, nil, model.ErrCommentClosed},
+		{"empty content", nil, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open"},
+			func(req *model.CreateCommentRequest) { req.Content = "  " }, model.ErrCommentContentInvalid},
+		{"name and email required", nil, &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open"},
+			func(req *model.CreateCommentRequest) { req.AuthorEmail = "" }, model.ErrCommentAuthorDataRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newCommentTestService(t, ctx, tt.options, tt.record)
+			req := newCommentRequest(1)
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+			_, err := s.CreateComment(ctx, req)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+
+	// the correct password allows comment on protected post
+	record := &model.PostRecord{ID: 1, Status: model.PublishStatus, CommentStatus: "open", Password: password}
+	s, mocks := newCommentTestService(t, ctx, map[string]string{"comment_previously_approved": "0"}, record)
+	mocks.comment.EXPECT().IsDuplicateComment(ctx, gomock.Any()).Return(true, nil)
+	req := newCommentRequest(1)
+	req.Password = &password
+	_, err := s.CreateComment(ctx, req)
+	assert.Equal(t, model.ErrCommentDuplicate, err)
+}
+
+func TestService_CreateCommentFlood(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+
+	s, mocks := newCommentTestService(t, ctx, nil, record)
+	mocks.comment.EXPECT().IsDuplicateComment(ctx, gomock.Any()).Return(false, nil)
+	mocks.comment.EXPECT().LatestCommentDateGmt(ctx, "10.0.0.1", "john@example.com", gomock.Any()).Return(time.Now().UTC().Add(-5*time.Second), nil)
+
+	_, err := s.CreateComment(ctx, newCommentRequest(1))
+	assert.Equal(t, model.ErrCommentFlood, err)
+}
+
+func TestService_CreateCommentInvalidParent(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+	otherPostID := uint64(2)
+
+	s, mocks := newCommentTestService(t, ctx, nil, record)
+	mocks.comment.EXPECT().CommentByID(ctx, uint64(7)).Return(&model.Comment{ID: 7, PostID: &otherPostID}, nil)
+
+	req := newCommentRequest(1)
+	req.Parent = 7
+	_, err := s.CreateComment(ctx, req)
+	assert.Equal(t, model.NewParamError("parent", "Invalid comment parent."), err)
+}
+
+func TestService_CreateCommentAuthorURL(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+
+	for _, authorURL := range []string{"javascript:alert(1)", "data:text/html,<script>alert(1)</script>", "ftp://example.com", "http://"} {
+		s, _ := newCommentTestService(t, ctx, nil, record)
+		req := newCommentRequest(1)
+		req.AuthorURL = authorURL
+		_, err := s.CreateComment(ctx, req)
+		assert.Equal(t, model.NewParamError("author_url", "Invalid URL."), err, authorURL)
+	}
+
+	// url without scheme gets http scheme like esc_url_raw
+	s, mocks := newCommentTestService(t, ctx, map[string]string{"comment_previously_approved": "0"}, record)
+	mocks.comment.EXPECT().IsDuplicateComment(ctx, gomock.Any()).Return(false, nil)
+	mocks.comment.EXPECT().LatestCommentDateGmt(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(time.Time{}, nil)
+	mocks.comment.EXPECT().InsertComment(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, comment *model.Comment) (uint64, error) {
+		assert.Equal(t, "http://john.example.com/blog", comment.AuthorAvatarURL)
+		return 5, nil
+	})
+	mocks.comment.EXPECT().UpdateCommentCount(ctx, uint64(1)).Return(nil)
+	req := newCommentRequest(1)
+	req.AuthorURL = " john.example.com/blog "
+	_, err := s.CreateComment(ctx, req)
+	assert.Nil(t, err)
+}
+
+// txKey marks context of the mocked transaction
+type txKey struct{}
+
+func TestService_CreateCommentChecksInTransaction(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	txCtx := context.WithValue(ctx, txKey{}, true)
+	record := &model.PostRecord{ID: 1, Type: model.PostType, Status: model.PublishStatus, CommentStatus: "open", DateGmt: time.Now().UTC()}
+
+	ctrl := gomock.NewController(t)
+	commentRepoMock := mockcomment.NewMockRepository(ctrl)
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().LoadOption(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows).AnyTimes()
+	postRepoMock.EXPECT().PostRecordByID(ctx, record.ID).Return(record, nil)
+	sharedRepoMock.EXPECT().WithTransaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(txCtx)
+	})
+	// the post is locked before the checks of the comment, and the checks run in the transaction of the insert
+	gomock.InOrder(
+		commentRepoMock.EXPECT().LockPostComments(txCtx, record.ID).Return(nil),
+		commentRepoMock.EXPECT().IsDuplicateComment(txCtx, gomock.Any()).Return(false, nil),
+		commentRepoMock.EXPECT().LatestCommentDateGmt(txCtx, gomock.Any(), gomock.Any(), gomock.Any()).Return(time.Time{}, nil),
+		commentRepoMock.EXPECT().HasApprovedComment(txCtx, "John", "john@example.com").Return(false, nil),
+		commentRepoMock.EXPECT().InsertComment(txCtx, gomock.Any()).Return(uint64(5), nil),
+		commentRepoMock.EXPECT().UpdateCommentCount(txCtx, record.ID).Return(nil),
+	)
+	postRepoMock.EXPECT().PostByID(ctx, record.ID, record.Type).Return(&model.Post{}, nil)
+
+	s := NewService(commentRepoMock, postRepoMock, sharedRepoMock)
+	_, err := s.CreateComment(ctx, newCommentRequest(1))
+	assert.Nil(t, err)
+}
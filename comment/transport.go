@@ -0,0 +1,57 @@
+package comment
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxUserAgentLength is length of comment_agent column
+const maxUserAgentLength = 254
+
+// MakeHTTPHandler returns http handler that makes a set of endpoints available on predefined paths
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	CreateCommentHandler := kithttp.NewServer(
+		instrument("comment.CreateComment", makeCreateCommentEndpoint(s)),
+		createCommentRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	// comments can be submitted by anonymous readers, so the route doesn't require write access
+	r.Method(http.MethodPost, "/", CreateCommentHandler)
+
+	return r
+}
+
+func createCommentRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var commentRequest model.CreateCommentRequest
+	if err := resthttp.DecodeBody(r, &commentRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Header,
+			"func":   "resthttp.DecodeBody",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+
+	commentRequest.AuthorIP = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		commentRequest.AuthorIP = host
+	}
+	commentRequest.AuthorUserAgent = r.UserAgent()
+	if len(commentRequest.AuthorUserAgent) > maxUserAgentLength {
+		commentRequest.AuthorUserAgent = commentRequest.AuthorUserAgent[:maxUserAgentLength]
+	}
+
+	return commentRequest, nil
+}
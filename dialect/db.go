@@ -0,0 +1,57 @@
+package dialect
+
+import (
+	"database/sql"
+	"hash/crc32"
+
+	// drivers of the dialects, they are registered as mysql, sqlite3 and postgres
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLiteDriverName is name of SQLite driver that has CRC32 function of Checksum, sqlite3 databases are opened with it
+const SQLiteDriverName = "sqlite3_restlr"
+
+func init() {
+	sql.Register(SQLiteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("CRC32", func(value string) int64 {
+				return int64(crc32.ChecksumIEEE([]byte(value)))
+			}, true)
+		},
+	})
+}
+
+// DB is database of the API with dialect of its SQL. It is passed to repositories, so every database runs SQL of its own dialect
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// NewDB returns database of the dialect
+func NewDB(db *sql.DB, d Dialect) *DB {
+	return &DB{DB: db, Dialect: d}
+}
+
+// drivers are the imported drivers that open databases of the dialects
+var drivers = map[Dialect]string{
+	MySQL:    "mysql",
+	SQLite:   SQLiteDriverName,
+	Postgres: "postgres",
+}
+
+// Open opens database of the database/sql driver name like dburl returns it with dialect of the driver, the database
+// is opened with the imported driver of the dialect
+func Open(driver string, dsn string) (*DB, error) {
+	d, err := ForDriver(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(drivers[d], dsn)
+	if err != nil {
+		return nil, err
+	}
+	return NewDB(db, d), nil
+}
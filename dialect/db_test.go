@@ -0,0 +1,27 @@
+package dialect
+
+import (
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen_SQLiteChecksum(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	assert.Equal(t, SQLite, db.Dialect)
+
+	var checksum int64
+	err = db.QueryRow(`SELECT ` + db.Dialect.Checksum("a", "b") + ` FROM (SELECT 'tea' AS a, NULL AS b)`).Scan(&checksum)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(crc32.ChecksumIEEE([]byte("tea|"))), checksum)
+}
+
+func TestOpen_UnknownDriver(t *testing.T) {
+	_, err := Open("mssql", "")
+	assert.Error(t, err)
+}
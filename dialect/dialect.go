@@ -0,0 +1,269 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Names of dialects
+const (
+	MySQLName    = "mysql"
+	SQLiteName   = "sqlite"
+	PostgresName = "postgres"
+)
+
+// Dialect writes SQL that differs between databases, repositories write queries with ? placeholders and MySQL date
+// formats, and the dialect of the database turns them into SQL that the database runs
+type Dialect interface {
+	// Name returns name of the dialect
+	Name() string
+	// Rebind returns query with ? placeholders in placeholder style of the database
+	Rebind(query string) string
+	// Concat returns expression that concatenates the expressions
+	Concat(exprs ...string) string
+	// DateFormat returns expression that formats the datetime expression with MySQL DATE_FORMAT format, only %Y, %m, %d,
+	// %H, %i and %s are supported
+	DateFormat(expr string, format string) string
+	// Field returns expression of 1-based position of the expression value in n placeholders, it is 0 if the value isn't one of them
+	Field(expr string, n int) string
+	// CastText returns expression that converts the expression to text
+	CastText(expr string) string
+	// Checksum returns integer expression of checksum of the expressions, it is summed to detect changes of rows
+	Checksum(exprs ...string) string
+	// Like returns case insensitive LIKE operator
+	Like() string
+	// LikeEscape returns ESCAPE clause of LIKE pattern that has value escaped by EscapeLike
+	LikeEscape() string
+	// Insert runs insert statement and returns id of the new row in the id column
+	Insert(ctx context.Context, q Querier, query string, idColumn string, args ...interface{}) (int64, error)
+}
+
+// Querier runs statements and queries of Insert
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) Row
+}
+
+// Row is result of query that returns single row, like *sql.Row
+type Row interface {
+	Scan(dest ...interface{}) error
+}
+
+var (
+	// MySQL is dialect of MySQL and MariaDB
+	MySQL Dialect = mysql{}
+	// SQLite is dialect of SQLite
+	SQLite Dialect = sqlite{}
+	// Postgres is dialect of PostgreSQL
+	Postgres Dialect = postgres{}
+)
+
+// ForDriver returns dialect of the database/sql driver name like dburl returns it
+func ForDriver(driver string) (Dialect, error) {
+	switch driver {
+	case "mysql":
+		return MySQL, nil
+	case "sqlite3", "sqlite":
+		return SQLite, nil
+	case "postgres", "pgx":
+		return Postgres, nil
+	}
+	return nil, fmt.Errorf("database driver %q has no dialect, it must be mysql, sqlite3 or postgres", driver)
+}
+
+type mysql struct{}
+
+func (mysql) Name() string {
+	return MySQLName
+}
+
+func (mysql) Rebind(query string) string {
+	return query
+}
+
+func (mysql) Concat(exprs ...string) string {
+	return "CONCAT(" + strings.Join(exprs, ", ") + ")"
+}
+
+func (mysql) DateFormat(expr string, format string) string {
+	return "DATE_FORMAT(" + expr + ", '" + format + "')"
+}
+
+func (mysql) Field(expr string, n int) string {
+	return "FIELD(" + expr + ", " + placeholders(n) + ")"
+}
+
+func (mysql) CastText(expr string) string {
+	return "CAST(" + expr + " AS CHAR)"
+}
+
+func (mysql) Checksum(exprs ...string) string {
+	return "CRC32(CONCAT_WS('|', " + strings.Join(exprs, ", ") + "))"
+}
+
+func (mysql) Like() string {
+	return "LIKE"
+}
+
+func (mysql) LikeEscape() string {
+	return ` ESCAPE '\\'`
+}
+
+func (mysql) Insert(ctx context.Context, q Querier, query string, idColumn string, args ...interface{}) (int64, error) {
+	return lastInsertID(ctx, q, query, args...)
+}
+
+// sqlite is dialect of SQLite, checksum is CRC32 function that SQLite driver of Open registers
+type sqlite struct{}
+
+func (sqlite) Name() string {
+	return SQLiteName
+}
+
+func (sqlite) Rebind(query string) string {
+	return query
+}
+
+func (sqlite) Concat(exprs ...string) string {
+	return "(" + strings.Join(exprs, " || ") + ")"
+}
+
+func (sqlite) DateFormat(expr string, format string) string {
+	return "STRFTIME('" + strings.NewReplacer("%i", "%M", "%s", "%S").Replace(format) + "', " + expr + ")"
+}
+
+func (sqlite) Field(expr string, n int) string {
+	return caseField(expr, n)
+}
+
+func (sqlite) CastText(expr string) string {
+	return "CAST(" + expr + " AS TEXT)"
+}
+
+func (sqlite) Checksum(exprs ...string) string {
+	return "CRC32(" + strings.Join(coalesce(exprs), " || '|' || ") + ")"
+}
+
+func (sqlite) Like() string {
+	return "LIKE"
+}
+
+func (sqlite) LikeEscape() string {
+	return ` ESCAPE '\'`
+}
+
+func (sqlite) Insert(ctx context.Context, q Querier, query string, idColumn string, args ...interface{}) (int64, error) {
+	return lastInsertID(ctx, q, query, args...)
+}
+
+// postgres is dialect of PostgreSQL, it has numbered placeholders and returns id of inserted row with RETURNING
+// because its driver has no LastInsertId
+type postgres struct{}
+
+func (postgres) Name() string {
+	return PostgresName
+}
+
+func (postgres) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	var quote rune
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (postgres) Concat(exprs ...string) string {
+	return "(" + strings.Join(exprs, " || ") + ")"
+}
+
+func (postgres) DateFormat(expr string, format string) string {
+	return "TO_CHAR(" + expr + ", '" + strings.NewReplacer("%Y", "YYYY", "%m", "MM", "%d", "DD", "%H", "HH24", "%i", "MI", "%s", "SS").Replace(format) + "')"
+}
+
+func (postgres) Field(expr string, n int) string {
+	return caseField(expr, n)
+}
+
+func (postgres) CastText(expr string) string {
+	return "CAST(" + expr + " AS TEXT)"
+}
+
+func (postgres) Checksum(exprs ...string) string {
+	return "('x' || SUBSTR(MD5(CONCAT_WS('|', " + strings.Join(exprs, ", ") + ")), 1, 8))::BIT(32)::BIGINT"
+}
+
+func (postgres) Like() string {
+	return "ILIKE"
+}
+
+func (postgres) LikeEscape() string {
+	return ` ESCAPE '\'`
+}
+
+func (postgres) Insert(ctx context.Context, q Querier, query string, idColumn string, args ...interface{}) (int64, error) {
+	var id int64
+	err := q.QueryRowContext(ctx, query+" RETURNING "+idColumn, args...).Scan(&id)
+	return id, err
+}
+
+// likeEscaper escapes wildcards and escape character of LIKE pattern with backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike returns value that is matched literally by LIKE pattern with ESCAPE clause of LikeEscape, so '%' and '_'
+// of value from request aren't wildcards
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// lastInsertID runs insert statement and returns LastInsertId of its result
+func lastInsertID(ctx context.Context, q Querier, query string, args ...interface{}) (int64, error) {
+	res, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// caseField returns CASE expression that works like MySQL FIELD
+func caseField(expr string, n int) string {
+	var b strings.Builder
+	b.WriteString("CASE " + expr)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, " WHEN ? THEN %d", i)
+	}
+	b.WriteString(" ELSE 0 END")
+	return b.String()
+}
+
+// coalesce returns expressions that are empty text instead of NULL, so concatenation of them isn't NULL
+func coalesce(exprs []string) []string {
+	coalesced := make([]string, len(exprs))
+	for i, expr := range exprs {
+		coalesced[i] = "COALESCE(" + expr + ", '')"
+	}
+	return coalesced
+}
+
+// placeholders returns n comma separated placeholders
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return "?" + strings.Repeat(", ?", n-1)
+}
@@ -0,0 +1,89 @@
+package dialect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForDriver(t *testing.T) {
+	for driver, name := range map[string]string{"mysql": MySQLName, "sqlite3": SQLiteName, "postgres": PostgresName} {
+		d, err := ForDriver(driver)
+		assert.NoError(t, err)
+		assert.Equal(t, name, d.Name())
+	}
+
+	_, err := ForDriver("sqlserver")
+	assert.Error(t, err)
+}
+
+func TestPostgres_Rebind(t *testing.T) {
+	assert.Equal(t, "SELECT ID FROM wp_posts WHERE post_name = $1 AND ID IN ($2, $3)",
+		Postgres.Rebind("SELECT ID FROM wp_posts WHERE post_name = ? AND ID IN (?, ?)"))
+	assert.Equal(t, `SELECT '?', "a?b", 'it''s ?' FROM t WHERE a = $1`,
+		Postgres.Rebind(`SELECT '?', "a?b", 'it''s ?' FROM t WHERE a = ?`))
+	assert.Equal(t, "a = ?", MySQL.Rebind("a = ?"))
+	assert.Equal(t, "a = ?", SQLite.Rebind("a = ?"))
+}
+
+func FuzzPostgres_Rebind(f *testing.F) {
+	for _, query := range []string{"", "?", "a = ? AND b IN (?, ?)", "SELECT '?' FROM t WHERE a = ?", `"a?b" = ?`, "?? ?"} {
+		f.Add(query)
+	}
+
+	// every placeholder outside quotes is numbered
+	f.Fuzz(func(t *testing.T, query string) {
+		if strings.ContainsAny(query, `'"$`) {
+			return
+		}
+		rebound := Postgres.Rebind(query)
+		assert.NotContains(t, rebound, "?")
+		assert.Equal(t, strings.Count(query, "?"), strings.Count(rebound, "$"))
+	})
+}
+
+func TestDialect_Functions(t *testing.T) {
+	assert.Equal(t, "CONCAT(?, post_name)", MySQL.Concat("?", "post_name"))
+	assert.Equal(t, "(? || post_name)", SQLite.Concat("?", "post_name"))
+	assert.Equal(t, "(? || post_name)", Postgres.Concat("?", "post_name"))
+
+	assert.Equal(t, "DATE_FORMAT(post_date, '%Y-%m-%d %H:%i:%s')", MySQL.DateFormat("post_date", "%Y-%m-%d %H:%i:%s"))
+	assert.Equal(t, "STRFTIME('%Y-%m-%d %H:%M:%S', post_date)", SQLite.DateFormat("post_date", "%Y-%m-%d %H:%i:%s"))
+	assert.Equal(t, "TO_CHAR(post_date, 'YYYY-MM-DD HH24:MI:SS')", Postgres.DateFormat("post_date", "%Y-%m-%d %H:%i:%s"))
+
+	assert.Equal(t, "FIELD(ID, ?, ?)", MySQL.Field("ID", 2))
+	assert.Equal(t, "CASE ID WHEN ? THEN 1 WHEN ? THEN 2 ELSE 0 END", SQLite.Field("ID", 2))
+	assert.Equal(t, "CASE ID WHEN ? THEN 1 WHEN ? THEN 2 ELSE 0 END", Postgres.Field("ID", 2))
+
+	assert.Equal(t, "CRC32(CONCAT_WS('|', a, b))", MySQL.Checksum("a", "b"))
+	assert.Equal(t, "CRC32(COALESCE(a, '') || '|' || COALESCE(b, ''))", SQLite.Checksum("a", "b"))
+	assert.Contains(t, Postgres.Checksum("a", "b"), "MD5(CONCAT_WS('|', a, b))")
+
+	assert.Equal(t, "ILIKE", Postgres.Like())
+	assert.Equal(t, ` ESCAPE '\\'`, MySQL.LikeEscape())
+	assert.Equal(t, ` ESCAPE '\'`, SQLite.LikeEscape())
+	assert.Equal(t, ` ESCAPE '\'`, Postgres.LikeEscape())
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `100\% \_a\\b`, EscapeLike(`100% _a\b`))
+
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// escaped value is matched literally, so wildcards of the value don't match other text
+	text := "100 axb ab"
+	for value, found := range map[string]bool{"100": true, "axb": true, "100%": false, "a_b": false, `a\b`: false} {
+		var n int
+		err = db.QueryRow("SELECT COUNT(*) WHERE ? LIKE ?"+SQLite.LikeEscape(), text, "%"+EscapeLike(value)+"%").Scan(&n)
+		assert.NoError(t, err)
+		assert.Equal(t, found, n == 1, value)
+		err = db.QueryRow("SELECT COUNT(*) WHERE ? LIKE ?"+SQLite.LikeEscape(), value, "%"+EscapeLike(value)+"%").Scan(&n)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n, value)
+	}
+}
@@ -0,0 +1,41 @@
+package fulltext
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// LikeBackendName is name of backend that matches keyword with LIKE, it is the default backend
+	LikeBackendName = "like"
+	// MySQLBackendName is name of backend that matches keyword with MySQL FULLTEXT index
+	MySQLBackendName = "mysql"
+	// IndexBackendName is name of backend that matches keyword with inverted index in memory
+	IndexBackendName = "index"
+)
+
+// Backend is interface of search backend that matches posts with search keyword of list and search requests
+type Backend interface {
+	Match(ctx context.Context, search string) (*Match, error)
+}
+
+// Match stores sql condition that selects posts matching the keyword and sql expression of relevance score of the post,
+// posts table columns are used without table alias and higher score is more relevant
+type Match struct {
+	Condition     string
+	ConditionArgs []interface{}
+	Score         string
+	ScoreArgs     []interface{}
+}
+
+// matchAll is returned for keyword without any word to match, so every post matches with the same score
+var matchAll = &Match{Condition: "1=1", Score: "0"}
+
+// ValidateBackendName returns error if the name isn't name of a search backend
+func ValidateBackendName(name string) error {
+	switch name {
+	case LikeBackendName, MySQLBackendName, IndexBackendName:
+		return nil
+	}
+	return fmt.Errorf("unknown search backend %q", name)
+}
@@ -0,0 +1,257 @@
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// field is part of post that is indexed
+type field int
+
+const (
+	titleField field = iota
+	excerptField
+	contentField
+	fieldCount
+)
+
+// fieldWeights are weights of term frequency in title, excerpt and content, so match in title is more relevant
+var fieldWeights = [fieldCount]float64{3, 2, 1}
+
+const (
+	// bm25K1 is term frequency saturation of BM25 score
+	bm25K1 = 1.2
+	// bm25B is document length normalization of BM25 score
+	bm25B = 0.75
+)
+
+// posting stores positions of the term in every field of the post, positions are used to match phrase
+type posting struct {
+	positions [fieldCount][]int
+}
+
+// weightedFrequency returns term frequency of the posting weighted by its field
+func (p *posting) weightedFrequency() float64 {
+	frequency := 0.0
+	for f, positions := range p.positions {
+		frequency += fieldWeights[f] * float64(len(positions))
+	}
+	return frequency
+}
+
+// document stores terms of indexed post so its postings can be removed and its weighted length for score normalization
+type document struct {
+	terms  []string
+	length float64
+}
+
+// Result is post that matches query with its relevance score
+type Result struct {
+	ID    uint64
+	Score float64
+}
+
+// Index is inverted index of stemmed terms of post title, excerpt and content, it is safe for concurrent use
+type Index struct {
+	mu          sync.RWMutex
+	postings    map[string]map[uint64]*posting
+	docs        map[uint64]*document
+	totalLength float64
+}
+
+// NewIndex returns empty index
+func NewIndex() *Index {
+	return &Index{
+		postings: map[string]map[uint64]*posting{},
+		docs:     map[uint64]*document{},
+	}
+}
+
+// Len returns number of indexed posts
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Add indexes the post, content is html that is stripped before it is indexed and previous version of the post is replaced
+func (idx *Index) Add(id uint64, title string, excerpt string, content string) {
+	texts := [fieldCount]string{StripHTML(title), StripHTML(excerpt), StripHTML(content)}
+
+	postings := map[string]*posting{}
+	doc := &document{}
+	for f, text := range texts {
+		for position, word := range Tokenize(text) {
+			term := Stem(word)
+			p, ok := postings[term]
+			if !ok {
+				p = &posting{}
+				postings[term] = p
+				doc.terms = append(doc.terms, term)
+			}
+			p.positions[f] = append(p.positions[f], position)
+			doc.length += fieldWeights[f]
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+	for term, p := range postings {
+		if idx.postings[term] == nil {
+			idx.postings[term] = map[uint64]*posting{}
+		}
+		idx.postings[term][id] = p
+	}
+	idx.docs[id] = doc
+	idx.totalLength += doc.length
+}
+
+// Remove removes the post from index
+func (idx *Index) Remove(id uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+// remove removes the post from index, caller must hold write lock
+func (idx *Index) remove(id uint64) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, id)
+}
+
+// Search returns posts that contain every term and phrase of the query and none of its excluded terms,
+// ordered by BM25 score with weighted field frequency from the most relevant
+func (idx *Index) Search(q Query) []Result {
+	required := stems(q.Terms)
+	for _, phrase := range q.Phrases {
+		required = append(required, stems(phrase)...)
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	candidates := idx.candidates(required)
+	for _, term := range stems(q.Excluded) {
+		for id := range idx.postings[term] {
+			delete(candidates, id)
+		}
+	}
+
+	var results []Result
+	for id := range candidates {
+		if !idx.matchPhrases(id, q.Phrases) {
+			continue
+		}
+		results = append(results, Result{ID: id, Score: idx.score(id, required)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID > results[j].ID
+	})
+	return results
+}
+
+// candidates returns ids of posts that contain every term, caller must hold read lock
+func (idx *Index) candidates(terms []string) map[uint64]bool {
+	// start from the rarest term so the candidate set is as small as possible
+	sorted := append([]string{}, terms...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(idx.postings[sorted[i]]) < len(idx.postings[sorted[j]])
+	})
+
+	candidates := map[uint64]bool{}
+	for id := range idx.postings[sorted[0]] {
+		candidates[id] = true
+	}
+	for _, term := range sorted[1:] {
+		for id := range candidates {
+			if _, ok := idx.postings[term][id]; !ok {
+				delete(candidates, id)
+			}
+		}
+	}
+	return candidates
+}
+
+// matchPhrases returns true if every phrase is in the same field of the post with its words next to each other,
+// caller must hold read lock
+func (idx *Index) matchPhrases(id uint64, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		if !idx.matchPhrase(id, stems(phrase)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPhrase returns true if the stemmed words are next to each other in any field of the post
+func (idx *Index) matchPhrase(id uint64, terms []string) bool {
+	for f := field(0); f < fieldCount; f++ {
+		for _, start := range idx.postings[terms[0]][id].positions[f] {
+			matched := true
+			for i, term := range terms[1:] {
+				if !containsInt(idx.postings[term][id].positions[f], start+i+1) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// score returns BM25 score of the post for the terms, caller must hold read lock
+func (idx *Index) score(id uint64, terms []string) float64 {
+	total := float64(len(idx.docs))
+	averageLength := idx.totalLength / total
+	doc := idx.docs[id]
+
+	score := 0.0
+	for _, term := range terms {
+		p, ok := idx.postings[term][id]
+		if !ok {
+			continue
+		}
+		frequency := float64(len(idx.postings[term]))
+		idf := math.Log(1 + (total-frequency+0.5)/(frequency+0.5))
+		tf := p.weightedFrequency()
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*doc.length/averageLength))
+	}
+	return score
+}
+
+// stems returns stem of every word
+func stems(words []string) []string {
+	list := make([]string, len(words))
+	for i, word := range words {
+		list[i] = Stem(word)
+	}
+	return list
+}
+
+// containsInt returns true if the value is in the sorted list
+func containsInt(list []int, value int) bool {
+	i := sort.SearchInts(list, value)
+	return i < len(list) && list[i] == value
+}
@@ -0,0 +1,78 @@
+package fulltext
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+func resultIDs(results []Result) []uint64 {
+	ids := make([]uint64, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestStripHTML(t *testing.T) {
+	content := `<!-- wp:paragraph --><p>Fish&amp;chips<br/>recipe</p><!-- /wp:paragraph --><script>var x = "hidden";</script>[caption id="1"]Photo[/caption]`
+	assert.Equal(t, []string{"fish", "chips", "recipe", "photo"}, Tokenize(StripHTML(content)))
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery(`Running "Green Tea" e-mail -coffee "solo"`)
+	assert.Equal(t, []string{"running", "solo"}, q.Terms)
+	assert.Equal(t, [][]string{{"green", "tea"}, {"e", "mail"}}, q.Phrases)
+	assert.Equal(t, []string{"coffee"}, q.Excluded)
+	assert.True(t, ParseQuery(` - "" `).IsEmpty())
+	assert.Equal(t, `+running* +"green tea" -coffee`, BooleanQuery(ParseQuery(`running "green tea" -coffee`)))
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(1, "Morning routine", "", "<p>I drink green tea while running.</p>")
+	idx.Add(2, "Tea ceremony", "About green tea", "<p>Tea is green.</p>")
+	idx.Add(3, "Coffee", "Runners drink coffee", "<p>Tea green or coffee.</p>")
+	idx.Add(4, "Runs", "", "")
+
+	// match in title is more relevant than match in content
+	assert.Equal(t, []uint64{2, 1, 3}, resultIDs(idx.Search(ParseQuery("tea"))))
+	// every term must match and terms are stemmed
+	assert.Equal(t, []uint64{4, 1}, resultIDs(idx.Search(ParseQuery("run"))))
+	assert.Equal(t, []uint64{1}, resultIDs(idx.Search(ParseQuery("drinking tea running"))))
+	// words of phrase must be next to each other in the same field
+	assert.Equal(t, []uint64{2, 1}, resultIDs(idx.Search(ParseQuery(`"green tea"`))))
+	assert.Equal(t, []uint64{2, 1}, resultIDs(idx.Search(ParseQuery(`tea -coffee`))))
+	assert.Empty(t, idx.Search(ParseQuery("milk")))
+
+	// updated post replaces its previous version and removed post isn't matched
+	idx.Add(2, "Milk", "", "")
+	idx.Remove(1)
+	assert.Equal(t, []uint64{3}, resultIDs(idx.Search(ParseQuery("tea"))))
+	assert.Equal(t, []uint64{2}, resultIDs(idx.Search(ParseQuery("milk"))))
+	assert.Equal(t, 3, idx.Len())
+}
+
+func TestIndexBackend_Match(t *testing.T) {
+	ctx := context.Background()
+	b := NewIndexBackend(dialect.NewDB(nil, dialect.MySQL), "wp_")
+	b.Index().Add(7, "Tea", "", "")
+	b.Index().Add(9, "Morning", "", "tea")
+
+	match, err := b.Match(ctx, "tea")
+	assert.NoError(t, err)
+	assert.Equal(t, "ID IN (?, ?)", match.Condition)
+	assert.Equal(t, []interface{}{uint64(9), uint64(7)}, match.ConditionArgs)
+	assert.Equal(t, "FIELD(ID, ?, ?)", match.Score)
+	assert.Equal(t, []interface{}{uint64(9), uint64(7)}, match.ScoreArgs)
+
+	match, err = b.Match(ctx, "coffee")
+	assert.NoError(t, err)
+	assert.Equal(t, "1=0", match.Condition)
+
+	match, err = b.Match(ctx, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "1=1", match.Condition)
+}
@@ -0,0 +1,168 @@
+package fulltext
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/sqlbuilder"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxIndexMatches is maximum number of the most relevant posts that are matched by index backend,
+// their ids are bound as arguments of the sql condition
+const maxIndexMatches = 1000
+
+// unindexedStatuses are post statuses of posts that are removed from index
+var unindexedStatuses = []string{"trash", "auto-draft"}
+
+// IndexBackend matches keyword with inverted index in memory that is built from posts table,
+// it stays current by polling posts that are modified since the last refresh. Only posts table of the table prefix
+// is indexed, keywords of other sites of multisite network are matched by like backend
+type IndexBackend struct {
+	index       *Index
+	like        Backend
+	db          *dialect.DB
+	tablePrefix string
+
+	// mu serializes refreshes and guards lastModified
+	mu           sync.Mutex
+	lastModified string
+}
+
+// NewIndexBackend returns backend with empty index, Refresh must be called to build the index
+func NewIndexBackend(db *dialect.DB, tablePrefix string) *IndexBackend {
+	return &IndexBackend{
+		index:       NewIndex(),
+		like:        NewLikeBackend(db.Dialect),
+		db:          db,
+		tablePrefix: tablePrefix,
+	}
+}
+
+// Index returns inverted index of the backend
+func (b *IndexBackend) Index() *Index {
+	return b.index
+}
+
+// Refresh indexes posts whose post_modified_gmt is not older than the last indexed post, the first refresh indexes every post.
+// Posts modified in the same second as the last indexed post are indexed again so none of them is missed
+func (b *IndexBackend) Refresh(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sqlQuery := `SELECT ID, post_title, post_excerpt, post_content, post_status, ` + b.db.Dialect.CastText("post_modified_gmt") + ` ` +
+		`FROM ` + model.NewTables(b.tablePrefix).Posts + ` WHERE post_type NOT IN ('revision', 'nav_menu_item')`
+	var args []interface{}
+	if b.lastModified != "" {
+		sqlQuery += ` AND post_modified_gmt >= ?`
+		args = append(args, b.lastModified)
+	}
+	sqlQuery += ` ORDER BY post_modified_gmt`
+
+	q, err := b.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "db.QueryContext",
+		}).Errorf("Failed to query posts to index: %s", err)
+		return err
+	}
+	defer q.Close()
+
+	count := 0
+	for q.Next() {
+		var id uint64
+		var title, excerpt, content, status, modified string
+		if err = q.Scan(&id, &title, &excerpt, &content, &status, &modified); err != nil {
+			log.WithFields(log.Fields{
+				"params": args,
+				"func":   "q.Scan",
+			}).Errorf("Failed to scan post to index: %s", err)
+			return err
+		}
+
+		if inStrings(status, unindexedStatuses) {
+			b.index.Remove(id)
+		} else {
+			b.index.Add(id, title, excerpt, content)
+		}
+		b.lastModified = modified
+		count++
+	}
+	if err = q.Err(); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"params": b.lastModified,
+		"func":   "IndexBackend.Refresh",
+	}).Debugf("Indexed %d posts, index has %d posts", count, b.index.Len())
+	return nil
+}
+
+// Run refreshes the index every interval until the context is done, error of refresh is logged and retried on the next interval
+func (b *IndexBackend) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Refresh(ctx); err != nil {
+				log.WithFields(log.Fields{
+					"params": interval,
+					"func":   "b.Refresh",
+				}).Errorf("Failed to refresh search index: %s", err)
+			}
+		}
+	}
+}
+
+// Match returns condition that matches ids of the most relevant posts in index and score that is their rank,
+// field expression of the dialect returns position of the id in the list so the ids are listed from the least relevant
+func (b *IndexBackend) Match(ctx context.Context, search string) (*Match, error) {
+	if config, ok := ctx.Value(model.APIConfigKey).(model.APIConfig); ok && config.TablePrefix != b.tablePrefix {
+		return b.like.Match(ctx, search)
+	}
+
+	q := ParseQuery(search)
+	if q.IsEmpty() {
+		return matchAll, nil
+	}
+
+	results := b.index.Search(q)
+	if len(results) == 0 {
+		return &Match{Condition: "1=0", Score: "0"}, nil
+	}
+	if len(results) > maxIndexMatches {
+		results = results[:maxIndexMatches]
+	}
+
+	ids := make([]interface{}, len(results))
+	for i, result := range results {
+		ids[len(results)-1-i] = result.ID
+	}
+	placeholders := sqlbuilder.Placeholders(len(ids))
+
+	return &Match{
+		Condition:     "ID IN (" + placeholders + ")",
+		ConditionArgs: ids,
+		Score:         b.db.Dialect.Field("ID", len(ids)),
+		ScoreArgs:     ids,
+	}, nil
+}
+
+// inStrings returns true if the value is in the list
+func inStrings(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package fulltext
+
+import (
+	"context"
+
+	"github.com/qreasio/restlr/dialect"
+)
+
+// likeBackend matches whole keyword in title, excerpt and content with LIKE, it doesn't need index but scans every post
+type likeBackend struct {
+	dialect dialect.Dialect
+}
+
+// NewLikeBackend returns backend that matches keyword with LIKE of the dialect
+func NewLikeBackend(d dialect.Dialect) Backend {
+	return &likeBackend{dialect: d}
+}
+
+// Match returns condition that matches keyword in title, excerpt or content and score that ranks match in title
+// over match in excerpt over match in content
+func (b *likeBackend) Match(ctx context.Context, search string) (*Match, error) {
+	keyword := "%" + dialect.EscapeLike(search) + "%"
+	like, escape := b.dialect.Like(), b.dialect.LikeEscape()
+	return &Match{
+		Condition:     `(post_title ` + like + ` ?` + escape + `) OR (post_excerpt ` + like + ` ?` + escape + `) OR (post_content ` + like + ` ?` + escape + `)`,
+		ConditionArgs: []interface{}{keyword, keyword, keyword},
+		Score: `CASE WHEN post_title ` + like + ` ?` + escape + ` THEN 3 WHEN post_excerpt ` + like + ` ?` + escape + ` THEN 2 ` +
+			`WHEN post_content ` + like + ` ?` + escape + ` THEN 1 ELSE 0 END`,
+		ScoreArgs: []interface{}{keyword, keyword, keyword},
+	}, nil
+}
@@ -0,0 +1,49 @@
+package fulltext
+
+import (
+	"context"
+	"strings"
+)
+
+// mysqlBackend matches keyword with MATCH ... AGAINST in boolean mode, posts table needs FULLTEXT index on
+// (post_title, post_excerpt, post_content) for the condition and FULLTEXT index on each column for the score
+type mysqlBackend struct{}
+
+// NewMySQLBackend returns backend that matches keyword with MySQL FULLTEXT index
+func NewMySQLBackend() Backend {
+	return &mysqlBackend{}
+}
+
+// BooleanQuery returns query of MySQL boolean mode full text search, every term is required and matched as prefix
+// so it matches other forms of the word, phrase is required and excluded term must not match
+func BooleanQuery(q Query) string {
+	var parts []string
+	for _, term := range q.Terms {
+		parts = append(parts, "+"+term+"*")
+	}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `+"`+strings.Join(phrase, " ")+`"`)
+	}
+	for _, term := range q.Excluded {
+		parts = append(parts, "-"+term)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Match returns condition that matches boolean query with all columns and score that weighs
+// relevance of title over excerpt over content
+func (b *mysqlBackend) Match(ctx context.Context, search string) (*Match, error) {
+	q := ParseQuery(search)
+	if q.IsEmpty() {
+		return matchAll, nil
+	}
+
+	query := BooleanQuery(q)
+	return &Match{
+		Condition:     `MATCH(post_title, post_excerpt, post_content) AGAINST (? IN BOOLEAN MODE)`,
+		ConditionArgs: []interface{}{query},
+		Score: `(MATCH(post_title) AGAINST (? IN BOOLEAN MODE) * 3 + MATCH(post_excerpt) AGAINST (? IN BOOLEAN MODE) * 2 + ` +
+			`MATCH(post_content) AGAINST (? IN BOOLEAN MODE))`,
+		ScoreArgs: []interface{}{query, query, query},
+	}, nil
+}
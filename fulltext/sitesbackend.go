@@ -0,0 +1,107 @@
+package fulltext
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// SitesIndexBackend is index backend of every site of multisite network, each site has IndexBackend of its posts table.
+// Table prefixes of the sites are read on every refresh, so sites that are added to the network are indexed on the next refresh
+// and keywords of site that isn't indexed yet are matched by like backend
+type SitesIndexBackend struct {
+	db       *dialect.DB
+	prefixes func() []string
+	like     Backend
+
+	// refreshMu serializes refreshes, mu guards backends
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	backends  map[string]*IndexBackend
+}
+
+// NewSitesIndexBackend returns backend without index, prefixes returns table prefixes of the sites to index and Refresh
+// must be called to build their indexes
+func NewSitesIndexBackend(db *dialect.DB, prefixes func() []string) *SitesIndexBackend {
+	return &SitesIndexBackend{
+		db:       db,
+		prefixes: prefixes,
+		like:     NewLikeBackend(db.Dialect),
+		backends: map[string]*IndexBackend{},
+	}
+}
+
+// Refresh refreshes index of every site, the first refresh of a site indexes all of its posts. Indexes of sites that are
+// removed from the network are dropped. Error of a site doesn't stop refresh of the other sites, the first error is returned
+func (b *SitesIndexBackend) Refresh(ctx context.Context) error {
+	b.refreshMu.Lock()
+	defer b.refreshMu.Unlock()
+
+	b.mu.RLock()
+	current := b.backends
+	b.mu.RUnlock()
+
+	var firstErr error
+	backends := map[string]*IndexBackend{}
+	for _, prefix := range b.prefixes() {
+		backend, indexed := current[prefix]
+		if !indexed {
+			backend = NewIndexBackend(b.db, prefix)
+		}
+		if err := backend.Refresh(ctx); err != nil {
+			log.WithFields(log.Fields{
+				"params": prefix,
+				"func":   "backend.Refresh",
+			}).Errorf("Failed to refresh search index of site: %s", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			// index of the site keeps serving if it was built before, otherwise the site is indexed on the next refresh
+			if !indexed {
+				continue
+			}
+		}
+		backends[prefix] = backend
+	}
+
+	b.mu.Lock()
+	b.backends = backends
+	b.mu.Unlock()
+	return firstErr
+}
+
+// Run refreshes indexes every interval until the context is done, error of refresh is logged and retried on the next interval
+func (b *SitesIndexBackend) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Refresh(ctx); err != nil {
+				log.WithFields(log.Fields{
+					"params": interval,
+					"func":   "b.Refresh",
+				}).Errorf("Failed to refresh search index: %s", err)
+			}
+		}
+	}
+}
+
+// Match returns match of index of the site of the request, keyword of site without index is matched by like backend
+func (b *SitesIndexBackend) Match(ctx context.Context, search string) (*Match, error) {
+	config, _ := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	b.mu.RLock()
+	backend, ok := b.backends[config.TablePrefix]
+	b.mu.RUnlock()
+	if !ok {
+		return b.like.Match(ctx, search)
+	}
+	return backend.Match(ctx, search)
+}
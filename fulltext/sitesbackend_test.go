@@ -0,0 +1,62 @@
+package fulltext
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSitesIndexBackend(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, query := range []string{
+		"CREATE TABLE wp_posts (ID INTEGER PRIMARY KEY, post_title TEXT, post_excerpt TEXT, post_content TEXT, post_status TEXT, post_type TEXT, post_modified_gmt DATETIME)",
+		"CREATE TABLE wp_2_posts (ID INTEGER PRIMARY KEY, post_title TEXT, post_excerpt TEXT, post_content TEXT, post_status TEXT, post_type TEXT, post_modified_gmt DATETIME)",
+		"INSERT INTO wp_posts VALUES (1, 'Tea', '', '', 'publish', 'post', '2020-01-01 00:00:00')",
+		"INSERT INTO wp_2_posts VALUES (5, 'Coffee', '', '', 'publish', 'post', '2020-01-01 00:00:00')",
+	} {
+		if _, err = db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prefixes := []string{"wp_"}
+	b := NewSitesIndexBackend(db, func() []string { return prefixes })
+	assert.NoError(t, b.Refresh(context.Background()))
+
+	siteContext := func(prefix string) context.Context {
+		return context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: prefix})
+	}
+	match, err := b.Match(siteContext("wp_"), "tea")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{uint64(1)}, match.ConditionArgs)
+
+	// keyword of site that isn't indexed yet is matched by like backend
+	match, err = b.Match(siteContext("wp_2_"), "coffee")
+	assert.NoError(t, err)
+	assert.Contains(t, match.Condition, "LIKE")
+
+	// site that is added to the network is indexed on the next refresh with its own posts
+	prefixes = append(prefixes, "wp_2_")
+	assert.NoError(t, b.Refresh(context.Background()))
+	match, err = b.Match(siteContext("wp_2_"), "coffee")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{uint64(5)}, match.ConditionArgs)
+	match, err = b.Match(siteContext("wp_2_"), "tea")
+	assert.NoError(t, err)
+	assert.Equal(t, "1=0", match.Condition)
+
+	// index of site that is removed is dropped
+	prefixes = prefixes[1:]
+	assert.NoError(t, b.Refresh(context.Background()))
+	match, err = b.Match(siteContext("wp_"), "tea")
+	assert.NoError(t, err)
+	assert.Contains(t, match.Condition, "LIKE")
+}
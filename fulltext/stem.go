@@ -0,0 +1,223 @@
+package fulltext
+
+import "strings"
+
+// minStemLength is minimum length of word that is stemmed, shorter words are indexed as is
+const minStemLength = 3
+
+// suffixRule replaces suffix of word with replacement when stem before the suffix satisfies the condition
+type suffixRule struct {
+	suffix      string
+	replacement string
+}
+
+// step2Rules are suffixes of step 2 of Porter stemmer that are replaced when measure of the stem is greater than 0
+var step2Rules = []suffixRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"}, {"izer", "ize"}, {"bli", "ble"},
+	{"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}, {"aliti", "al"},
+	{"iviti", "ive"}, {"biliti", "ble"}, {"logi", "log"},
+}
+
+// step3Rules are suffixes of step 3 of Porter stemmer that are replaced when measure of the stem is greater than 0
+var step3Rules = []suffixRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"}, {"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+// step4Suffixes are suffixes of step 4 of Porter stemmer that are removed when measure of the stem is greater than 1,
+// longer suffix comes before its shorter ending so the longest suffix is matched
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent", "ion", "ou", "ism", "ate", "iti",
+	"ous", "ive", "ize",
+}
+
+// stemmer stores word that is stemmed by steps of Porter stemming algorithm
+type stemmer struct {
+	b []byte
+}
+
+// Stem returns stem of english word with Porter stemming algorithm, word must be lower case and
+// word that isn't ascii letters is returned as is
+func Stem(word string) string {
+	if len(word) < minStemLength {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.replaceRules(step2Rules)
+	s.replaceRules(step3Rules)
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+// cons returns true if letter at index i is consonant, y is consonant at start of word or after vowel
+func (s *stemmer) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.cons(i-1)
+	}
+	return true
+}
+
+// measure returns number of vowel consonant sequences in the first n letters
+func (s *stemmer) measure(n int) int {
+	m := 0
+	i := 0
+	for i < n && s.cons(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !s.cons(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		m++
+		for i < n && s.cons(i) {
+			i++
+		}
+	}
+	return m
+}
+
+// hasVowel returns true if the first n letters contain vowel
+func (s *stemmer) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleCons returns true if the first n letters end with double consonant
+func (s *stemmer) doubleCons(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.cons(n-1)
+}
+
+// cvc returns true if the first n letters end with consonant vowel consonant and the last consonant isn't w, x or y
+func (s *stemmer) cvc(n int) bool {
+	if n < 3 || !s.cons(n-1) || s.cons(n-2) || !s.cons(n-3) {
+		return false
+	}
+	last := s.b[n-1]
+	return last != 'w' && last != 'x' && last != 'y'
+}
+
+// hasSuffix returns true if word ends with the suffix
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// replace replaces the last n letters of the word with the replacement
+func (s *stemmer) replace(n int, replacement string) {
+	s.b = append(s.b[:len(s.b)-n], replacement...)
+}
+
+func (s *stemmer) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.replace(2, "")
+	case s.hasSuffix("ies"):
+		s.replace(3, "i")
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.replace(1, "")
+	}
+}
+
+func (s *stemmer) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measure(len(s.b)-3) > 0 {
+			s.replace(1, "")
+		}
+		return
+	}
+
+	removed := false
+	for _, suffix := range []string{"ed", "ing"} {
+		if s.hasSuffix(suffix) && s.hasVowel(len(s.b)-len(suffix)) {
+			s.replace(len(suffix), "")
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return
+	}
+
+	n := len(s.b)
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.replace(0, "e")
+	case s.doubleCons(n) && s.b[n-1] != 'l' && s.b[n-1] != 's' && s.b[n-1] != 'z':
+		s.replace(1, "")
+	case s.measure(n) == 1 && s.cvc(n):
+		s.replace(0, "e")
+	}
+}
+
+func (s *stemmer) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(len(s.b)-1) {
+		s.replace(1, "i")
+	}
+}
+
+// replaceRules replaces the first matching suffix of the rules when measure of the stem is greater than 0
+func (s *stemmer) replaceRules(rules []suffixRule) {
+	for _, rule := range rules {
+		if s.hasSuffix(rule.suffix) {
+			if s.measure(len(s.b)-len(rule.suffix)) > 0 {
+				s.replace(len(rule.suffix), rule.replacement)
+			}
+			return
+		}
+	}
+}
+
+func (s *stemmer) step4() {
+	matched := ""
+	for _, suffix := range step4Suffixes {
+		if s.hasSuffix(suffix) && len(suffix) > len(matched) {
+			matched = suffix
+		}
+	}
+	if matched == "" {
+		return
+	}
+
+	n := len(s.b) - len(matched)
+	if s.measure(n) <= 1 {
+		return
+	}
+	if matched == "ion" && (n == 0 || (s.b[n-1] != 's' && s.b[n-1] != 't')) {
+		return
+	}
+	s.replace(len(matched), "")
+}
+
+func (s *stemmer) step5() {
+	if s.hasSuffix("e") {
+		n := len(s.b) - 1
+		if m := s.measure(n); m > 1 || (m == 1 && !s.cvc(n)) {
+			s.replace(1, "")
+		}
+	}
+
+	n := len(s.b)
+	if s.measure(n) > 1 && s.doubleCons(n) && s.b[n-1] == 'l' {
+		s.replace(1, "")
+	}
+}
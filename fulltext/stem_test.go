@@ -0,0 +1,38 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"hopping":        "hop",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"hopeful":        "hope",
+		"adjustment":     "adjust",
+		"controll":       "control",
+		"roll":           "roll",
+		"connections":    "connect",
+		"adoption":       "adopt",
+		"running":        "run",
+		"go":             "go",
+		"café":           "café",
+	}
+	for word, stem := range tests {
+		assert.Equal(t, stem, Stem(word), word)
+	}
+}
@@ -0,0 +1,76 @@
+package fulltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// hiddenHTMLRegexp matches comments including block delimiters and elements whose content isn't visible text
+	hiddenHTMLRegexp = regexp.MustCompile(`(?is)<!--.*?-->|<script[^>]*>.*?</script>|<style[^>]*>.*?</style>`)
+	// tagRegexp matches html tag
+	tagRegexp = regexp.MustCompile(`(?s)<[^>]*>`)
+	// shortcodeRegexp matches opening and closing shortcode tags, the content between them is kept
+	shortcodeRegexp = regexp.MustCompile(`\[/?[a-zA-Z][^\]]*\]`)
+)
+
+// StripHTML returns visible text of html content, tags and shortcodes are replaced by space so words around them stay apart
+func StripHTML(content string) string {
+	text := hiddenHTMLRegexp.ReplaceAllString(content, " ")
+	text = tagRegexp.ReplaceAllString(text, " ")
+	text = shortcodeRegexp.ReplaceAllString(text, " ")
+	return html.UnescapeString(text)
+}
+
+// Tokenize splits text into lower case words, letters and digits are part of word and other characters separate words
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Query represents parsed search keyword, every term and phrase must match and excluded terms must not match
+type Query struct {
+	Terms    []string
+	Phrases  [][]string
+	Excluded []string
+}
+
+// ParseQuery parses search keyword like WP search, words in double quotes are phrase and word with '-' prefix is excluded,
+// word that is split into several words like 'e-mail' is also phrase
+func ParseQuery(search string) Query {
+	var q Query
+	for i, part := range strings.Split(search, `"`) {
+		// every odd part is inside double quotes
+		if i%2 == 1 {
+			q.addPhrase(Tokenize(part))
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			if len(word) > 1 && word[0] == '-' {
+				q.Excluded = append(q.Excluded, Tokenize(word[1:])...)
+				continue
+			}
+			q.addPhrase(Tokenize(word))
+		}
+	}
+	return q
+}
+
+// addPhrase adds words as phrase or as term if it is a single word
+func (q *Query) addPhrase(words []string) {
+	switch len(words) {
+	case 0:
+	case 1:
+		q.Terms = append(q.Terms, words[0])
+	default:
+		q.Phrases = append(q.Phrases, words)
+	}
+}
+
+// IsEmpty returns true if the query doesn't have any term or phrase to match
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
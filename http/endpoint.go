@@ -0,0 +1,19 @@
+package http
+
+import (
+	"github.com/go-kit/kit/endpoint"
+)
+
+// EndpointMiddleware returns go-kit middleware of the named endpoint, it instruments every endpoint of the API
+// like metrics and tracing middlewares do
+type EndpointMiddleware func(name string) endpoint.Middleware
+
+// Instrument returns function that wraps the named endpoint with the middlewares, the first middleware is the outermost
+func Instrument(middlewares []EndpointMiddleware) func(name string, e endpoint.Endpoint) endpoint.Endpoint {
+	return func(name string, e endpoint.Endpoint) endpoint.Endpoint {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			e = middlewares[i](name)(e)
+		}
+		return e
+	}
+}
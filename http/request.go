@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/form"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxMultipartMemory = 32 << 20
+	// maxUploadSize is the maximum request body size of file upload
+	maxUploadSize = 64 << 20
+	// maxBodySize is the maximum request body size of write request that isn't file upload
+	maxBodySize = 10 << 20
+)
+
+// DecodeBody decodes request body of write request to v, body can be json or form encoded like WP Rest API accepts.
+// Body that is larger than maxBodySize is rejected
+func DecodeBody(r *http.Request, v interface{}) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			return model.ErrInvalidParameter
+		}
+		return nil
+	}
+
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+			return model.ErrInvalidParameter
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return model.ErrInvalidParameter
+	}
+
+	return form.NewDecoder().Decode(v, r.Form)
+}
+
+// DecodeUpload decodes file upload request to req, the file can be sent as 'file' field of multipart form or as raw request body
+// with file name in Content-Disposition header like WP Rest API accepts. Other fields are read from the form or the query string
+func DecodeUpload(r *http.Request, req *model.UploadMediaRequest) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadSize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+			return model.ErrInvalidParameter
+		}
+		if file, header, err := r.FormFile("file"); err == nil {
+			defer file.Close()
+			if req.Data, err = ioutil.ReadAll(file); err != nil {
+				return model.ErrInvalidParameter
+			}
+			req.FileName = header.Filename
+			// Content-Type of multipart file is optional, the file type is validated from its name and content
+			req.ContentType = header.Header.Get("Content-Type")
+			if req.ContentType == "" {
+				req.ContentType = "application/octet-stream"
+			}
+		}
+	} else {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return model.ErrInvalidParameter
+		}
+		req.Data = data
+		req.ContentType = r.Header.Get("Content-Type")
+		if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+			req.FileName = params["filename"]
+		}
+		if err = r.ParseForm(); err != nil {
+			return model.ErrInvalidParameter
+		}
+	}
+
+	return form.NewDecoder().Decode(req, r.Form)
+}
+
+// RequireWriteAccess is middleware that only allows request with Authorization header 'Bearer <WriteAPIKey>',
+// all write requests are rejected if WriteAPIKey is not configured
+func RequireWriteAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !HasWriteAccess(r) {
+			EncodeJSONResponse(r.Context(), w, NewErrorResponse(RestForbiddenCode, RestForbiddenMessage, http.StatusUnauthorized))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Timeout is middleware that cancels context of request after timeout, so queries of the request are stopped
+// and the request is responded with 504. Requests have no timeout if timeout is zero
+func Timeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// HasWriteAccess returns true if the request has Authorization header 'Bearer <WriteAPIKey>' and WriteAPIKey is configured
+func HasWriteAccess(r *http.Request) bool {
+	apiConfig, _ := r.Context().Value(model.APIConfigKey).(model.APIConfig)
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return apiConfig.WriteAPIKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(apiConfig.WriteAPIKey)) == 1
+}
+
+// ParseIDList returns ids from query string values, each value can be comma separated list of ids
+func ParseIDList(values []string) ([]uint64, error) {
+	var idList []uint64
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			parsed, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"params": value,
+					"func":   "strconv.ParseUint",
+				}).Errorf("Failed to parse uint from string: %s", err)
+				return nil, model.ErrInvalidParameter
+			}
+			idList = append(idList, parsed)
+		}
+	}
+	return idList, nil
+}
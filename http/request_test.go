@@ -0,0 +1,145 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireWriteAccess(t *testing.T) {
+	handler := RequireWriteAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	newRequest := func(apiKey string, token string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req.WithContext(context.WithValue(req.Context(), model.APIConfigKey, model.APIConfig{WriteAPIKey: apiKey}))
+	}
+
+	//should reject request if write api key is not configured
+	resp1 := httptest.NewRecorder()
+	handler.ServeHTTP(resp1, newRequest("", ""))
+	assert.Equal(t, http.StatusUnauthorized, resp1.Code)
+
+	//should reject request with wrong token
+	resp2 := httptest.NewRecorder()
+	handler.ServeHTTP(resp2, newRequest("secret", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, resp2.Code)
+
+	//should pass request with correct token
+	resp3 := httptest.NewRecorder()
+	handler.ServeHTTP(resp3, newRequest("secret", "secret"))
+	assert.Equal(t, http.StatusNoContent, resp3.Code)
+}
+
+func FuzzParseIDList(f *testing.F) {
+	for _, value := range []string{"", "1", "1,2", " 3 , ,4", "1,x", "-1", "18446744073709551616", "0) OR (1=1", "1 UNION SELECT 2"} {
+		f.Add(value)
+	}
+
+	// value is either rejected or every non-empty element of the list is an id
+	f.Fuzz(func(t *testing.T, value string) {
+		idList, err := ParseIDList([]string{value})
+		if err != nil {
+			assert.Equal(t, model.ErrInvalidParameter, err)
+			return
+		}
+		var elements []string
+		for _, element := range strings.Split(value, ",") {
+			if element = strings.TrimSpace(element); element != "" {
+				elements = append(elements, element)
+			}
+		}
+		assert.Len(t, idList, len(elements))
+		for i, id := range idList {
+			parsed, err := strconv.ParseUint(elements[i], 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, parsed, id)
+		}
+	})
+}
+
+func TestDecodeBody(t *testing.T) {
+	var jsonRequest model.WritePostRequest
+	req1 := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{"title":"Hello","categories":[1,2]}`))
+	req1.Header.Set("Content-Type", "application/json")
+	assert.Nil(t, DecodeBody(req1, &jsonRequest))
+	assert.Equal(t, "Hello", *jsonRequest.Title)
+	assert.Equal(t, []uint64{1, 2}, jsonRequest.Categories)
+
+	var formRequest model.WritePostRequest
+	req2 := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader("title=Hello&status=draft"))
+	req2.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.Nil(t, DecodeBody(req2, &formRequest))
+	assert.Equal(t, "Hello", *formRequest.Title)
+	assert.Equal(t, "draft", *formRequest.Status)
+
+	// body above the size limit is rejected
+	large := `{"title":"` + strings.Repeat("a", maxBodySize) + `"}`
+	for _, contentType := range []string{"application/json", "application/x-www-form-urlencoded"} {
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(large))
+		req.Header.Set("Content-Type", contentType)
+		assert.Equal(t, model.ErrInvalidParameter, DecodeBody(req, &model.WritePostRequest{}), contentType)
+	}
+}
+
+func TestNewWriteErrorResponse(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, NewWriteErrorResponse(model.NewParamError("status", "invalid"), RestCannotCreateCode, "").Data.Status)
+	assert.Equal(t, http.StatusGone, NewWriteErrorResponse(model.ErrAlreadyTrashed, RestCannotDeleteCode, "").Data.Status)
+	assert.Equal(t, http.StatusInternalServerError, NewWriteErrorResponse(errors.New("db error"), RestCannotCreateCode, "").Data.Status)
+	assert.Equal(t, http.StatusGatewayTimeout, NewWriteErrorResponse(context.DeadlineExceeded, RestCannotCreateCode, "").Data.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, NewWriteErrorResponse(context.Canceled, RestCannotCreateCode, "").Data.Status)
+}
+
+func TestTimeout(t *testing.T) {
+	handler := Timeout(time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		EncodeError(r.Context(), r.Context().Err(), w)
+	}))
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, resp.Code)
+	assert.Contains(t, resp.Body.String(), RestRequestTimeoutCode)
+}
+
+func TestDecodeUpload(t *testing.T) {
+	// raw body with file name in Content-Disposition and other fields in query string
+	var rawRequest model.UploadMediaRequest
+	req1 := httptest.NewRequest(http.MethodPost, "/media?title=Photo&post=5", strings.NewReader("data"))
+	req1.Header.Set("Content-Type", "image/png")
+	req1.Header.Set("Content-Disposition", `attachment; filename="my photo.png"`)
+	assert.Nil(t, DecodeUpload(req1, &rawRequest))
+	assert.Equal(t, "my photo.png", rawRequest.FileName)
+	assert.Equal(t, "image/png", rawRequest.ContentType)
+	assert.Equal(t, []byte("data"), rawRequest.Data)
+	assert.Equal(t, "Photo", *rawRequest.Title)
+	assert.Equal(t, uint64(5), *rawRequest.Post)
+
+	// multipart form with file field
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("alt_text", "Alt")
+	part, _ := writer.CreateFormFile("file", "photo.png")
+	part.Write([]byte("data"))
+	writer.Close()
+
+	var multipartRequest model.UploadMediaRequest
+	req2 := httptest.NewRequest(http.MethodPost, "/media", &body)
+	req2.Header.Set("Content-Type", writer.FormDataContentType())
+	assert.Nil(t, DecodeUpload(req2, &multipartRequest))
+	assert.Equal(t, "photo.png", multipartRequest.FileName)
+	assert.Equal(t, []byte("data"), multipartRequest.Data)
+	assert.Equal(t, "Alt", *multipartRequest.AltText)
+}
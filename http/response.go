@@ -3,7 +3,9 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	httpkit "github.com/go-kit/kit/transport/http"
 	"github.com/qreasio/restlr/model"
@@ -16,10 +18,140 @@ const (
 	RestNoRouteCode = "rest_no_route"
 	// RestInvalidIDCode is string response code for invalid id (404) if post/item not found
 	RestInvalidIDCode = "rest_post_invalid_id"
+	// RestIncorrectPasswordCode is string response code for incorrect password (403) of password protected post
+	RestIncorrectPasswordCode = "rest_post_incorrect_password"
+	// RestAlreadyTrashedCode is string response code for deleting post (410) that is already in trash
+	RestAlreadyTrashedCode = "rest_already_trashed"
+	// RestForbiddenCode is string response code for write request (401) without valid api key
+	RestForbiddenCode = "rest_forbidden"
+	// RestCannotCreateCode is string response code for failure (500) on creating item
+	RestCannotCreateCode = "rest_cannot_create"
+	// RestCannotUpdateCode is string response code for failure (500) on updating item
+	RestCannotUpdateCode = "rest_cannot_update"
+	// RestCannotDeleteCode is string response code for failure (500) on deleting item
+	RestCannotDeleteCode = "rest_cannot_delete"
+	// RestUploadNoDataCode is string response code for upload request (400) without file data
+	RestUploadNoDataCode = "rest_upload_no_data"
+	// RestUploadNoContentDispositionCode is string response code for raw upload request (400) without Content-Disposition header
+	RestUploadNoContentDispositionCode = "rest_upload_no_content_disposition"
+	// RestUploadNoContentTypeCode is string response code for raw upload request (400) without Content-Type header
+	RestUploadNoContentTypeCode = "rest_upload_no_content_type"
+	// RestUploadSideloadErrorCode is string response code for upload request (400) with file type that is not allowed
+	RestUploadSideloadErrorCode = "rest_upload_sideload_error"
+	// RestCommentLoginRequiredCode is string response code for anonymous comment (401) on site that requires login
+	RestCommentLoginRequiredCode = "rest_comment_login_required"
+	// RestCommentAuthorDataRequiredCode is string response code for comment (400) without required author name and email
+	RestCommentAuthorDataRequiredCode = "rest_comment_author_data_required"
+	// RestCommentContentInvalidCode is string response code for comment (400) with empty content
+	RestCommentContentInvalidCode = "rest_comment_content_invalid"
+	// RestCommentInvalidPostIDCode is string response code for comment (403) on post that doesn't exist
+	RestCommentInvalidPostIDCode = "rest_comment_invalid_post_id"
+	// RestCommentDraftPostCode is string response code for comment (403) on draft post
+	RestCommentDraftPostCode = "rest_comment_draft_post"
+	// RestCommentTrashPostCode is string response code for comment (403) on trashed post
+	RestCommentTrashPostCode = "rest_comment_trash_post"
+	// RestCannotReadPostCode is string response code for comment (403) on post that can't be read
+	RestCannotReadPostCode = "rest_cannot_read_post"
+	// RestCommentClosedCode is string response code for comment (403) on post with closed comments
+	RestCommentClosedCode = "rest_comment_closed"
+	// CommentDuplicateCode is string response code for duplicate comment (409)
+	CommentDuplicateCode = "comment_duplicate"
+	// CommentFloodCode is string response code for comment (429) that is submitted too quickly
+	CommentFloodCode = "comment_flood"
+	// RestTermInvalidCode is string response code for term (404) that doesn't exist
+	RestTermInvalidCode = "rest_term_invalid"
+	// EmptyTermNameCode is string response code for creating term (400) without name
+	EmptyTermNameCode = "empty_term_name"
+	// TermExistsCode is string response code for creating term (400) with name that already exists
+	TermExistsCode = "term_exists"
+	// DuplicateTermSlugCode is string response code for updating term (400) with slug that is used by another term
+	DuplicateTermSlugCode = "duplicate_term_slug"
+	// RestTrashNotSupportedCode is string response code for deleting term (501) without force parameter
+	RestTrashNotSupportedCode = "rest_trash_not_supported"
+	// RestBatchNotAllowedCode is string response code for batch sub request (400) to route that doesn't support batch
+	RestBatchNotAllowedCode = "rest_batch_not_allowed"
+	// RestTypeInvalidCode is string response code for post type (404) that doesn't exist
+	RestTypeInvalidCode = "rest_type_invalid"
+	// RestTaxonomyInvalidCode is string response code for taxonomy (404) that doesn't exist
+	RestTaxonomyInvalidCode = "rest_taxonomy_invalid"
+	// RestStatusInvalidCode is string response code for post status (404) that doesn't exist
+	RestStatusInvalidCode = "rest_status_invalid"
+	// RestCannotReadStatusCode is string response code for post status (401) that can't be read without write access
+	RestCannotReadStatusCode = "rest_cannot_read_status"
+	// RestSearchInvalidPageNumberCode is string response code for search request (400) with page that is larger than the number of pages
+	RestSearchInvalidPageNumberCode = "rest_search_invalid_page_number"
+	// RestRequestTimeoutCode is string response code for request (504) that isn't completed before request or query timeout
+	RestRequestTimeoutCode = "rest_request_timeout"
+	// RestRequestCanceledCode is string response code for request (503) that is canceled before it is completed
+	RestRequestCanceledCode = "rest_request_canceled"
+	// InternalServerErrorCode is string response code for request (500) that fails on error that isn't caused by the request
+	InternalServerErrorCode = "internal_server_error"
 	// NoRouteMessage is json response message for no route error
 	NoRouteMessage = "No route was found matching the URL and request method"
 	// RestInvalidPostIDMessage is json response message for invalid post id
 	RestInvalidPostIDMessage = "Invalid post ID"
+	// RestIncorrectPasswordMessage is json response message for incorrect post password
+	RestIncorrectPasswordMessage = "Incorrect post password."
+	// RestAlreadyTrashedMessage is json response message for deleting post that is already in trash
+	RestAlreadyTrashedMessage = "The post has already been deleted."
+	// RestForbiddenMessage is json response message for write request without valid api key
+	RestForbiddenMessage = "Sorry, you are not allowed to do that."
+	// RestUploadNoDataMessage is json response message for upload request without file data
+	RestUploadNoDataMessage = "No data supplied."
+	// RestUploadNoContentDispositionMessage is json response message for raw upload request without Content-Disposition header
+	RestUploadNoContentDispositionMessage = "No Content-Disposition supplied."
+	// RestUploadNoContentTypeMessage is json response message for raw upload request without Content-Type header
+	RestUploadNoContentTypeMessage = "No Content-Type supplied."
+	// RestUploadInvalidFileTypeMessage is json response message for upload request with file type that is not allowed
+	RestUploadInvalidFileTypeMessage = "Sorry, this file type is not permitted for security reasons."
+	// RestUploadImageTooLargeMessage is json response message for upload request with image that is too large to be processed
+	RestUploadImageTooLargeMessage = "The uploaded image dimensions are too large to be processed."
+	// RestCommentLoginRequiredMessage is json response message for anonymous comment on site that requires login
+	RestCommentLoginRequiredMessage = "Sorry, you must be logged in to comment."
+	// RestCommentAuthorDataRequiredMessage is json response message for comment without required author name and email
+	RestCommentAuthorDataRequiredMessage = "Creating a comment requires valid author name and email values."
+	// RestCommentContentInvalidMessage is json response message for comment with empty content
+	RestCommentContentInvalidMessage = "Invalid comment content."
+	// RestCommentInvalidPostIDMessage is json response message for comment on post that doesn't exist
+	RestCommentInvalidPostIDMessage = "Sorry, you are not allowed to create this comment without a post."
+	// RestCommentNotAllowedMessage is json response message for comment on draft or trashed post
+	RestCommentNotAllowedMessage = "Sorry, you are not allowed to create a comment on this post."
+	// RestCannotReadPostMessage is json response message for comment on post that can't be read
+	RestCannotReadPostMessage = "Sorry, you are not allowed to read the post for this comment."
+	// RestCommentClosedMessage is json response message for comment on post with closed comments
+	RestCommentClosedMessage = "Sorry, comments are closed for this item."
+	// CommentDuplicateMessage is json response message for duplicate comment
+	CommentDuplicateMessage = "Duplicate comment detected; it looks as though you’ve already said that!"
+	// CommentFloodMessage is json response message for comment that is submitted too quickly
+	CommentFloodMessage = "You are posting comments too quickly. Slow down."
+	// RestBatchNotAllowedMessage is json response message for batch sub request to route that doesn't support batch
+	RestBatchNotAllowedMessage = "The requested route does not support batch requests."
+	// RestTermInvalidMessage is json response message for term that doesn't exist
+	RestTermInvalidMessage = "Term does not exist."
+	// RestTypeInvalidMessage is json response message for post type that doesn't exist
+	RestTypeInvalidMessage = "Invalid post type."
+	// RestTaxonomyInvalidMessage is json response message for taxonomy that doesn't exist
+	RestTaxonomyInvalidMessage = "Invalid taxonomy."
+	// RestStatusInvalidMessage is json response message for post status that doesn't exist
+	RestStatusInvalidMessage = "Invalid status."
+	// RestCannotReadStatusMessage is json response message for post status that can't be read without write access
+	RestCannotReadStatusMessage = "Cannot view status."
+	// RestInvalidPageNumberMessage is json response message for list request with page that is larger than the number of pages
+	RestInvalidPageNumberMessage = "The page number requested is larger than the number of pages available."
+	// EmptyTermNameMessage is json response message for creating term without name
+	EmptyTermNameMessage = "A name is required for this term."
+	// TermExistsMessage is json response message for creating term with name that already exists
+	TermExistsMessage = "A term with the name provided already exists with this parent."
+	// DuplicateTermSlugMessage is json response message for updating term with slug that is used by another term
+	DuplicateTermSlugMessage = "The slug is already in use by another term."
+	// RestTrashNotSupportedMessage is json response message for deleting term without force parameter
+	RestTrashNotSupportedMessage = "Terms do not support trashing. Set 'force=true' to delete."
+	// RestRequestTimeoutMessage is json response message for request that isn't completed before request or query timeout
+	RestRequestTimeoutMessage = "The request took too long to complete."
+	// RestRequestCanceledMessage is json response message for request that is canceled before it is completed
+	RestRequestCanceledMessage = "The request was canceled before it was completed."
+	// InternalServerErrorMessage is json response message for request that fails on error that isn't caused by the request
+	InternalServerErrorMessage = "There has been a critical error on this website."
 )
 
 // APIResponse represent api response mainly on non 200 http status response
@@ -57,6 +189,158 @@ func NewInvalidPostResponse() APIResponse {
 	}
 }
 
+// NewIncorrectPasswordResponse is used to generate incorrect post password api response
+func NewIncorrectPasswordResponse() APIResponse {
+	return APIResponse{
+		Code:    RestIncorrectPasswordCode,
+		Message: RestIncorrectPasswordMessage,
+		Data: ResponseData{
+			Status: http.StatusForbidden,
+		},
+	}
+}
+
+// NewErrorResponse is used to generate api response with custom code, message and status code
+func NewErrorResponse(code string, message string, status int) APIResponse {
+	return APIResponse{
+		Code:    code,
+		Message: message,
+		Data: ResponseData{
+			Status: status,
+		},
+	}
+}
+
+// NewWriteErrorResponse is used to generate api response from error of write request (create, update, delete),
+// error that isn't caused by the request is returned as internal server error with the given code and message
+func NewWriteErrorResponse(err error, code string, message string) APIResponse {
+	if response, ok := NewContextErrorResponse(err); ok {
+		return response
+	}
+	if paramErr, ok := err.(*model.ParamError); ok {
+		return NewInvalidParam(paramErr.Param, paramErr.Message)
+	}
+
+	switch err {
+	case model.ErrInvalidPostID:
+		return NewInvalidPostResponse()
+	case model.ErrIncorrectPassword:
+		return NewIncorrectPasswordResponse()
+	case model.ErrAlreadyTrashed:
+		return NewErrorResponse(RestAlreadyTrashedCode, RestAlreadyTrashedMessage, http.StatusGone)
+	case model.ErrNoUploadData:
+		return NewErrorResponse(RestUploadNoDataCode, RestUploadNoDataMessage, http.StatusBadRequest)
+	case model.ErrNoContentDisposition:
+		return NewErrorResponse(RestUploadNoContentDispositionCode, RestUploadNoContentDispositionMessage, http.StatusBadRequest)
+	case model.ErrNoContentType:
+		return NewErrorResponse(RestUploadNoContentTypeCode, RestUploadNoContentTypeMessage, http.StatusBadRequest)
+	case model.ErrInvalidFileType:
+		return NewErrorResponse(RestUploadSideloadErrorCode, RestUploadInvalidFileTypeMessage, http.StatusBadRequest)
+	case model.ErrImageTooLarge:
+		return NewErrorResponse(RestUploadSideloadErrorCode, RestUploadImageTooLargeMessage, http.StatusBadRequest)
+	case model.ErrCommentLoginRequired:
+		return NewErrorResponse(RestCommentLoginRequiredCode, RestCommentLoginRequiredMessage, http.StatusUnauthorized)
+	case model.ErrCommentAuthorDataRequired:
+		return NewErrorResponse(RestCommentAuthorDataRequiredCode, RestCommentAuthorDataRequiredMessage, http.StatusBadRequest)
+	case model.ErrCommentContentInvalid:
+		return NewErrorResponse(RestCommentContentInvalidCode, RestCommentContentInvalidMessage, http.StatusBadRequest)
+	case model.ErrCommentInvalidPostID:
+		return NewErrorResponse(RestCommentInvalidPostIDCode, RestCommentInvalidPostIDMessage, http.StatusForbidden)
+	case model.ErrCommentDraftPost:
+		return NewErrorResponse(RestCommentDraftPostCode, RestCommentNotAllowedMessage, http.StatusForbidden)
+	case model.ErrCommentTrashPost:
+		return NewErrorResponse(RestCommentTrashPostCode, RestCommentNotAllowedMessage, http.StatusForbidden)
+	case model.ErrCommentCannotReadPost:
+		return NewErrorResponse(RestCannotReadPostCode, RestCannotReadPostMessage, http.StatusForbidden)
+	case model.ErrCommentClosed:
+		return NewErrorResponse(RestCommentClosedCode, RestCommentClosedMessage, http.StatusForbidden)
+	case model.ErrCommentDuplicate:
+		return NewErrorResponse(CommentDuplicateCode, CommentDuplicateMessage, http.StatusConflict)
+	case model.ErrCommentFlood:
+		return NewErrorResponse(CommentFloodCode, CommentFloodMessage, http.StatusTooManyRequests)
+	case model.ErrInvalidTermID:
+		return NewErrorResponse(RestTermInvalidCode, RestTermInvalidMessage, http.StatusNotFound)
+	case model.ErrEmptyTermName:
+		return NewErrorResponse(EmptyTermNameCode, EmptyTermNameMessage, http.StatusBadRequest)
+	case model.ErrTermExists:
+		return NewErrorResponse(TermExistsCode, TermExistsMessage, http.StatusBadRequest)
+	case model.ErrDuplicateTermSlug:
+		return NewErrorResponse(DuplicateTermSlugCode, DuplicateTermSlugMessage, http.StatusBadRequest)
+	case model.ErrTrashNotSupported:
+		return NewErrorResponse(RestTrashNotSupportedCode, RestTrashNotSupportedMessage, http.StatusNotImplemented)
+	}
+
+	return NewErrorResponse(code, message, http.StatusInternalServerError)
+}
+
+// NewContextErrorResponse returns api response of error that is caused by done context, 504 if deadline of request or query
+// is exceeded and 503 if request is canceled. ok is false if the error isn't caused by done context
+func NewContextErrorResponse(err error) (response APIResponse, ok bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewErrorResponse(RestRequestTimeoutCode, RestRequestTimeoutMessage, http.StatusGatewayTimeout), true
+	case errors.Is(err, context.Canceled):
+		return NewErrorResponse(RestRequestCanceledCode, RestRequestCanceledMessage, http.StatusServiceUnavailable), true
+	}
+	return APIResponse{}, false
+}
+
+// CreatedResponse wraps created item so it is encoded with 201 Created status code and Location header
+type CreatedResponse struct {
+	Item     interface{}
+	Location string
+}
+
+// StatusCode implements StatusCoder interface of go-kit http transport
+func (r CreatedResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+// Headers implements Headerer interface of go-kit http transport
+func (r CreatedResponse) Headers() http.Header {
+	return http.Header{"Location": []string{r.Location}}
+}
+
+// MarshalJSON encodes only the created item
+func (r CreatedResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Item)
+}
+
+// MultiStatusResponse wraps response of batch request so it is encoded with 207 Multi-Status status code
+type MultiStatusResponse struct {
+	Item interface{}
+}
+
+// StatusCode implements StatusCoder interface of go-kit http transport
+func (r MultiStatusResponse) StatusCode() int {
+	return http.StatusMultiStatus
+}
+
+// MarshalJSON encodes only the wrapped item
+func (r MultiStatusResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Item)
+}
+
+// PaginatedResponse wraps page of list response so total number of items and pages are sent in X-WP-Total and X-WP-TotalPages headers
+type PaginatedResponse struct {
+	Item       interface{}
+	Total      int
+	TotalPages int
+}
+
+// Headers implements Headerer interface of go-kit http transport
+func (r PaginatedResponse) Headers() http.Header {
+	return http.Header{
+		"X-WP-Total":      []string{strconv.Itoa(r.Total)},
+		"X-WP-TotalPages": []string{strconv.Itoa(r.TotalPages)},
+	}
+}
+
+// MarshalJSON encodes only the wrapped item
+func (r PaginatedResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Item)
+}
+
 // NewInvalidParam is used to generate custom invalid parameter api response
 func NewInvalidParam(invalidParameter string, invalidMessage string) APIResponse {
 	response := APIResponse{
@@ -84,6 +368,15 @@ Example HTTP Error Response
   }
 }
 
+403
+{
+  "code": "rest_post_incorrect_password",
+  "message": "Incorrect post password.",
+  "data": {
+    "status": 403
+  }
+}
+
 404
 {
   "code": "rest_no_route",
@@ -141,7 +434,10 @@ func EncodeJSONResponse(_ context.Context, w http.ResponseWriter, response inter
 func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-	if err == model.ErrInvalidRoute {
+	if response, ok := NewContextErrorResponse(err); ok {
+		w.WriteHeader(response.Data.Status)
+		json.NewEncoder(w).Encode(response)
+	} else if err == model.ErrInvalidRoute {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(NewRouteNotFoundResponse())
 	} else {
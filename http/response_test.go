@@ -54,4 +54,13 @@ func TestEncodeJSONResponse(t *testing.T) {
 
 	assert.Equal(t, resp2.Code, http.StatusAccepted)
 	assert.Equal(t, resp2.Header().Get("message"), "Golang is amazing")
+
+	//if response is page of list
+	resp3 := httptest.NewRecorder()
+	EncodeJSONResponse(ctx, resp3, PaginatedResponse{Item: []int{1, 2}, Total: 12, TotalPages: 6})
+
+	assert.Equal(t, http.StatusOK, resp3.Code)
+	assert.Equal(t, "12", resp3.Header().Get("X-WP-Total"))
+	assert.Equal(t, "6", resp3.Header().Get("X-WP-TotalPages"))
+	assert.Equal(t, "[1,2]\n", resp3.Body.String())
 }
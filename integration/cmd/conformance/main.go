@@ -0,0 +1,73 @@
+// Command conformance records responses of WP to the conformance corpus and renders the fixture as SQL script
+// that is loaded into the WP database before recording. Recorded responses are compared with responses of Restlr
+// by 'go test ./integration -run TestConformance' without WP
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/integration"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	corpusFile := flag.String("corpus", "integration/testdata/conformance/corpus.json", "corpus of the requests")
+	dir := flag.String("dir", "integration/testdata/conformance/recorded", "directory of recorded responses")
+	fixtureFile := flag.String("fixture", "integration/testdata/fixture.json", "fixture of the WP database")
+	tablePrefix := flag.String("table-prefix", "wp_", "table prefix of the WP database")
+	fixtureSQL := flag.Bool("fixture-sql", false, "print MySQL script that replaces content of the WP database with the fixture")
+	record := flag.String("record", "", "url of WP site whose responses are recorded, like http://localhost:8000")
+	only := flag.String("case", "", "comma separated names of recorded cases, every case is recorded if it is empty")
+	flag.Parse()
+
+	if *fixtureSQL {
+		fixture, err := integration.LoadFixture(*fixtureFile)
+		if err != nil {
+			log.Fatal("Error on reading fixture: ", err)
+		}
+		script, err := fixture.Script(dialect.MySQL, model.NewTables(*tablePrefix))
+		if err != nil {
+			log.Fatal("Error on rendering fixture: ", err)
+		}
+		fmt.Print(script)
+		return
+	}
+
+	if *record == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	corpus, err := integration.LoadCorpus(*corpusFile)
+	if err != nil {
+		log.Fatal("Error on reading corpus: ", err)
+	}
+	names := map[string]bool{}
+	for _, name := range strings.Split(*only, ",") {
+		if name != "" {
+			names[name] = true
+		}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, c := range corpus.Cases {
+		if len(names) > 0 && !names[c.Name] {
+			continue
+		}
+		recording, err := integration.Record(client, *record, c)
+		if err != nil {
+			log.Fatalf("Error on recording %s: %s", c.Name, err)
+		}
+		if err := recording.Save(*dir, c); err != nil {
+			log.Fatalf("Error on saving %s: %s", c.Name, err)
+		}
+		log.Infof("Recorded %s %s (%d)", c.Name, c.URL, recording.Status)
+	}
+}
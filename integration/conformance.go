@@ -0,0 +1,273 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Corpus is list of requests whose responses of Restlr must be the same as responses of WP
+type Corpus struct {
+	// Ignore are paths of fields that are not compared in every case, see Compare for the path patterns
+	Ignore []string `json:"ignore"`
+	Cases  []Case   `json:"cases"`
+}
+
+// Case is GET request of the corpus, url is path and query of the request. Divergence describes known difference of
+// Restlr from WP in the case, the case must still differ from its recording until the divergence is fixed
+type Case struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Ignore     []string `json:"ignore"`
+	Divergence string   `json:"divergence"`
+}
+
+// Recording is response of WP to request of a case, urls of WP in the body start with the base url
+type Recording struct {
+	URL     string          `json:"url"`
+	BaseURL string          `json:"base_url"`
+	Status  int             `json:"status"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// Difference is field whose value differs between recorded and actual response
+type Difference struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: expected %s, actual %s", d.Path, d.Expected, d.Actual)
+}
+
+// LoadCorpus reads corpus from JSON file
+func LoadCorpus(filename string) (*Corpus, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	corpus := &Corpus{}
+	if err := json.Unmarshal(data, corpus); err != nil {
+		return nil, fmt.Errorf("invalid corpus %s: %s", filename, err)
+	}
+	return corpus, nil
+}
+
+// Record requests the case from WP site of the base url and returns its response
+func Record(client *http.Client, baseURL string, c Case) (*Recording, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	res, err := client.Get(baseURL + c.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("response of %s is not JSON: %.200s", c.URL, body)
+	}
+	return &Recording{URL: c.URL, BaseURL: baseURL, Status: res.StatusCode, Body: body}, nil
+}
+
+// LoadRecording reads recording of the case from the directory, it returns nil recording if the case is not recorded
+func LoadRecording(dir string, c Case) (*Recording, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, c.Name+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	recording := &Recording{}
+	if err := json.Unmarshal(data, recording); err != nil {
+		return nil, fmt.Errorf("invalid recording of %s: %s", c.Name, err)
+	}
+	return recording, nil
+}
+
+// Save writes recording of the case into the directory with indented body
+func (r *Recording) Save(dir string, c Case) error {
+	var body bytes.Buffer
+	if err := json.Indent(&body, r.Body, "  ", "  "); err != nil {
+		return err
+	}
+	indented := *r
+	indented.Body = body.Bytes()
+
+	data, err := json.MarshalIndent(indented, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, c.Name+".json"), append(data, '\n'), 0644)
+}
+
+// Compare returns differences between recorded response and actual JSON body whose urls start with the api host instead
+// of base url of the recording. Fields are compared by their path like '0._embedded.author.0.name' where array elements
+// are their index. Fields that match one of the ignore patterns are not compared, '*' in pattern matches one segment
+// of path and '**' matches any number of segments, so '**.guid' ignores guid of every object. Actual body that isn't JSON
+// is a difference, error is returned for invalid recorded body only
+func Compare(recording *Recording, status int, actual []byte, apiHost string, ignore []string) ([]Difference, error) {
+	var expectedValue, actualValue interface{}
+	if err := decodeJSON(recording.Body, &expectedValue); err != nil {
+		return nil, fmt.Errorf("invalid recorded body: %s", err)
+	}
+	if recording.BaseURL != "" {
+		expectedValue = replaceURLs(expectedValue, strings.NewReplacer(recording.BaseURL, strings.TrimSuffix(apiHost, "/")))
+	}
+
+	var patterns [][]string
+	for _, pattern := range ignore {
+		patterns = append(patterns, strings.Split(pattern, "."))
+	}
+
+	var differences []Difference
+	if recording.Status != status {
+		differences = append(differences, Difference{Path: "(status)", Expected: strconv.Itoa(recording.Status), Actual: strconv.Itoa(status)})
+	}
+	// actual body that isn't JSON, like plain text error, is a difference of the whole body
+	if err := decodeJSON(actual, &actualValue); err != nil {
+		return append(differences, Difference{Path: "(body)", Expected: "JSON", Actual: fmt.Sprintf("%.100q", actual)}), nil
+	}
+	compareValues(nil, expectedValue, actualValue, patterns, &differences)
+	return differences, nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// replaceURLs returns value whose strings are replaced by the replacer
+func replaceURLs(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replacer.Replace(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceURLs(v[i], replacer)
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = replaceURLs(v[key], replacer)
+		}
+	}
+	return value
+}
+
+// compareValues appends differences of the values at the path that is not ignored
+func compareValues(path []string, expected interface{}, actual interface{}, ignore [][]string, differences *[]Difference) {
+	if ignored(path, ignore) {
+		return
+	}
+	add := func(expected string, actual string) {
+		*differences = append(*differences, Difference{Path: strings.Join(path, "."), Expected: expected, Actual: actual})
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			add("object", describe(actual))
+			return
+		}
+		for _, key := range unionKeys(e, a) {
+			keyPath := append(path[:len(path):len(path)], key)
+			ev, inExpected := e[key]
+			av, inActual := a[key]
+			switch {
+			case ignored(keyPath, ignore):
+			case !inActual:
+				*differences = append(*differences, Difference{Path: strings.Join(keyPath, "."), Expected: describe(ev), Actual: "missing"})
+			case !inExpected:
+				*differences = append(*differences, Difference{Path: strings.Join(keyPath, "."), Expected: "missing", Actual: describe(av)})
+			default:
+				compareValues(keyPath, ev, av, ignore, differences)
+			}
+		}
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			add("array", describe(actual))
+			return
+		}
+		if len(e) != len(a) {
+			add(fmt.Sprintf("%d elements", len(e)), fmt.Sprintf("%d elements", len(a)))
+		}
+		for i := 0; i < len(e) && i < len(a); i++ {
+			compareValues(append(path[:len(path):len(path)], strconv.Itoa(i)), e[i], a[i], ignore, differences)
+		}
+	default:
+		if describe(expected) != describe(actual) {
+			add(describe(expected), describe(actual))
+		}
+	}
+}
+
+// ignored returns true if the path matches one of the patterns
+func ignored(path []string, patterns [][]string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(pattern []string, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchPath(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || (pattern[0] != "*" && pattern[0] != path[0]) {
+		return false
+	}
+	return matchPath(pattern[1:], path[1:])
+}
+
+// describe returns value as compact JSON, objects and arrays are described by their type only
+func describe(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func unionKeys(a map[string]interface{}, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
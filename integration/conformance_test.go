@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	conformanceCorpus = "testdata/conformance/corpus.json"
+	conformanceDir    = "testdata/conformance/recorded"
+)
+
+// TestConformance compares responses of Restlr with responses of WP that are recorded from the same fixture,
+// every case of the corpus must be recorded. Cases with known divergence must differ from their recording, so fixed
+// divergence is removed from the corpus
+func TestConformance(t *testing.T) {
+	corpus, err := LoadCorpus(conformanceCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHarness(t)
+	defer h.Close()
+
+	for _, c := range corpus.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			recording, err := LoadRecording(conformanceDir, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recording == nil {
+				t.Fatalf("%s is not recorded in %s, record it with 'go run ./integration/cmd/conformance -record <wp url> -case %s'",
+					c.Name, conformanceDir, c.Name)
+			}
+
+			w := h.Get(c.URL)
+			differences, err := Compare(recording, w.Code, w.Body.Bytes(), TestAPIConfig().APIHost, append(corpus.Ignore, c.Ignore...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Divergence != "" {
+				if len(differences) == 0 {
+					t.Errorf("%s conforms to WP, remove its divergence %q from the corpus", c.Name, c.Divergence)
+				}
+				for _, d := range differences {
+					t.Logf("known divergence (%s): %s", c.Divergence, d)
+				}
+				return
+			}
+			for _, d := range differences {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	recording := &Recording{
+		BaseURL: "http://wp.test",
+		Status:  200,
+		Body: []byte(`[{"id": 10, "guid": {"rendered": "http://wp.test/?p=10"}, "link": "http://wp.test/hello-world/",
+			"tags": [4, 5], "format": "standard", "_links": {"self": [{"href": "http://wp.test/wp-json/wp/v2/posts/10"}]}}]`),
+	}
+	actual := []byte(`[{"id": 10, "guid": {"rendered": "http://other.test/?p=10"}, "link": "http://example.com/hello-world/",
+		"tags": [4], "sticky": false, "_links": {"self": [{"href": "http://example.com/wp-json/wp/v2/posts/10"}]}}]`)
+
+	differences, err := Compare(recording, 200, actual, "http://example.com", []string{"**.guid"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Difference{
+		{Path: "0.format", Expected: `"standard"`, Actual: "missing"},
+		{Path: "0.sticky", Expected: "missing", Actual: "false"},
+		{Path: "0.tags", Expected: "2 elements", Actual: "1 elements"},
+	}, differences)
+
+	differences, err = Compare(recording, 404, []byte(`{}`), "http://example.com", []string{"**"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Difference{{Path: "(status)", Expected: "200", Actual: "404"}}, differences)
+
+	differences, err = Compare(recording, 500, []byte("Invalid include"), "http://example.com", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Difference{
+		{Path: "(status)", Expected: "200", Actual: "500"},
+		{Path: "(body)", Expected: "JSON", Actual: `"Invalid include"`},
+	}, differences)
+}
+
+func TestRecording_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restlr-conformance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := Case{Name: "posts", URL: "/wp-json/wp/v2/posts"}
+	recording := &Recording{URL: c.URL, BaseURL: "http://wp.test", Status: 200, Body: []byte(`[{"id":10}]`)}
+	assert.NoError(t, recording.Save(dir, c))
+
+	loaded, err := LoadRecording(dir, c)
+	assert.NoError(t, err)
+	differences, err := Compare(loaded, 200, []byte(`[{"id": 10}]`), "http://example.com", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, differences)
+
+	missing, err := LoadRecording(dir, Case{Name: "pages"})
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
@@ -0,0 +1,343 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+)
+
+// Fixture is declarative content of WP site that is seeded into the database, rows have explicit ids
+// so responses are the same on every run
+type Fixture struct {
+	// SiteURL is url of the site that guids of posts and attachments are built from
+	SiteURL     string            `json:"site_url"`
+	Options     map[string]string `json:"options"`
+	Users       []User            `json:"users"`
+	Terms       []Term            `json:"terms"`
+	Posts       []Post            `json:"posts"`
+	Attachments []Attachment      `json:"attachments"`
+	Comments    []Comment         `json:"comments"`
+}
+
+// User is row of users table, description is stored in user meta like WP does
+type User struct {
+	ID          uint64            `json:"id"`
+	Login       string            `json:"login"`
+	Email       string            `json:"email"`
+	URL         string            `json:"url"`
+	NiceName    string            `json:"nicename"`
+	DisplayName string            `json:"display_name"`
+	Registered  string            `json:"registered"`
+	Description string            `json:"description"`
+	Meta        map[string]string `json:"meta"`
+}
+
+// Term is term with its taxonomy, term taxonomy id is the term id
+type Term struct {
+	ID          uint64            `json:"id"`
+	Name        string            `json:"name"`
+	Slug        string            `json:"slug"`
+	Taxonomy    string            `json:"taxonomy"`
+	Description string            `json:"description"`
+	Parent      uint64            `json:"parent"`
+	Meta        map[string]string `json:"meta"`
+}
+
+// Post is row of posts table with its terms and metas, type is 'post' if it is empty
+type Post struct {
+	ID            uint64            `json:"id"`
+	Type          string            `json:"type"`
+	Status        string            `json:"status"`
+	Author        uint64            `json:"author"`
+	Date          string            `json:"date"`
+	DateGmt       string            `json:"date_gmt"`
+	Modified      string            `json:"modified"`
+	ModifiedGmt   string            `json:"modified_gmt"`
+	Title         string            `json:"title"`
+	Content       string            `json:"content"`
+	Excerpt       string            `json:"excerpt"`
+	Slug          string            `json:"slug"`
+	Parent        uint64            `json:"parent"`
+	MenuOrder     int               `json:"menu_order"`
+	CommentStatus string            `json:"comment_status"`
+	PingStatus    string            `json:"ping_status"`
+	Password      string            `json:"password"`
+	MimeType      string            `json:"mime_type"`
+	Sticky        bool              `json:"sticky"`
+	Terms         []uint64          `json:"terms"`
+	Meta          map[string]string `json:"meta"`
+}
+
+// Attachment is attachment post of uploaded file, its '_wp_attached_file' and '_wp_attachment_metadata' metas are
+// built from the file and media details
+type Attachment struct {
+	Post
+	File         string              `json:"file"`
+	AltText      string              `json:"alt_text"`
+	MediaDetails *model.MediaDetails `json:"media_details"`
+}
+
+// Comment is row of comments table, it is approved if approved is empty
+type Comment struct {
+	ID          uint64            `json:"id"`
+	PostID      uint64            `json:"post"`
+	Parent      uint64            `json:"parent"`
+	UserID      uint64            `json:"author"`
+	AuthorName  string            `json:"author_name"`
+	AuthorEmail string            `json:"author_email"`
+	AuthorURL   string            `json:"author_url"`
+	Date        string            `json:"date"`
+	DateGmt     string            `json:"date_gmt"`
+	Content     string            `json:"content"`
+	Approved    string            `json:"approved"`
+	Type        string            `json:"type"`
+	Meta        map[string]string `json:"meta"`
+}
+
+// Statement is SQL statement with ? placeholders and its arguments
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// LoadFixture reads fixture from JSON file
+func LoadFixture(filename string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %s", filename, err)
+	}
+	return fixture, nil
+}
+
+// Statements returns statements that insert rows of the fixture into the tables, post comment counts, term counts and
+// 'sticky_posts' option are computed from the fixture
+func (f *Fixture) Statements(tables model.Tables) ([]Statement, error) {
+	var statements []Statement
+	insert := func(table string, columns []string, args ...interface{}) {
+		statements = append(statements, Statement{
+			SQL:  "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (?" + strings.Repeat(", ?", len(columns)-1) + ")",
+			Args: args,
+		})
+	}
+	insertMeta := func(table string, idColumn string, id uint64, meta map[string]string) {
+		for _, key := range sortedKeys(meta) {
+			insert(table, []string{idColumn, "meta_key", "meta_value"}, id, key, meta[key])
+		}
+	}
+
+	options := map[string]string{"sticky_posts": stickyPosts(f.Posts)}
+	for name, value := range f.Options {
+		options[name] = value
+	}
+	for _, name := range sortedKeys(options) {
+		insert(tables.Options, []string{"option_name", "option_value", "autoload"}, name, options[name], "yes")
+	}
+
+	for _, u := range f.Users {
+		registered, err := fixtureDate(u.Registered, "2020-01-01 00:00:00")
+		if err != nil {
+			return nil, fmt.Errorf("user %d: %s", u.ID, err)
+		}
+		insert(tables.Users, []string{"ID", "user_login", "user_pass", "user_nicename", "user_email", "user_url",
+			"user_registered", "user_activation_key", "user_status", "display_name"},
+			u.ID, u.Login, "", defaultString(u.NiceName, u.Login), u.Email, u.URL, registered, "", 0, defaultString(u.DisplayName, u.Login))
+		meta := map[string]string{"description": u.Description, "nickname": u.Login}
+		for key, value := range u.Meta {
+			meta[key] = value
+		}
+		insertMeta(tables.UserMeta, "user_id", u.ID, meta)
+	}
+
+	posts := f.allPosts()
+	counts := map[uint64]int{}
+	for _, p := range posts {
+		for _, termID := range p.Terms {
+			if p.Status == model.PublishStatus {
+				counts[termID]++
+			}
+		}
+	}
+	for _, t := range f.Terms {
+		insert(tables.Terms, []string{"term_id", "name", "slug", "term_group"}, t.ID, t.Name, t.Slug, 0)
+		insert(tables.TermTaxonomy, []string{"term_taxonomy_id", "term_id", "taxonomy", "description", "parent", "count"},
+			t.ID, t.ID, t.Taxonomy, t.Description, t.Parent, counts[t.ID])
+		insertMeta(tables.TermMeta, "term_id", t.ID, t.Meta)
+	}
+
+	commentCounts := map[uint64]int{}
+	for _, c := range f.Comments {
+		if defaultString(c.Approved, "1") == "1" {
+			commentCounts[c.PostID]++
+		}
+	}
+	for _, p := range posts {
+		date, err := fixtureDate(p.Date, "2020-01-01 00:00:00")
+		if err != nil {
+			return nil, fmt.Errorf("post %d: %s", p.ID, err)
+		}
+		dateGmt, err := fixtureDate(p.DateGmt, date)
+		if err != nil {
+			return nil, fmt.Errorf("post %d: %s", p.ID, err)
+		}
+		modified, err := fixtureDate(p.Modified, date)
+		if err != nil {
+			return nil, fmt.Errorf("post %d: %s", p.ID, err)
+		}
+		modifiedGmt, err := fixtureDate(p.ModifiedGmt, dateGmt)
+		if err != nil {
+			return nil, fmt.Errorf("post %d: %s", p.ID, err)
+		}
+		insert(tables.Posts, []string{"ID", "post_author", "post_date", "post_date_gmt", "post_content", "post_title",
+			"post_excerpt", "post_status", "comment_status", "ping_status", "post_password", "post_name", "to_ping", "pinged",
+			"post_modified", "post_modified_gmt", "post_content_filtered", "post_parent", "guid", "menu_order", "post_type",
+			"post_mime_type", "comment_count"},
+			p.ID, p.Author, date, dateGmt, p.Content, p.Title, p.Excerpt, p.Status, p.CommentStatus, p.PingStatus, p.Password,
+			p.Slug, "", "", modified, modifiedGmt, "", p.Parent, f.guid(p), p.MenuOrder, p.Type, p.MimeType, commentCounts[p.ID])
+		insertMeta(tables.PostMeta, "post_id", p.ID, p.Meta)
+		for _, termID := range p.Terms {
+			insert(tables.TermRelationships, []string{"object_id", "term_taxonomy_id", "term_order"}, p.ID, termID, 0)
+		}
+	}
+
+	for _, c := range f.Comments {
+		date, err := fixtureDate(c.Date, "2020-01-01 00:00:00")
+		if err != nil {
+			return nil, fmt.Errorf("comment %d: %s", c.ID, err)
+		}
+		dateGmt, err := fixtureDate(c.DateGmt, date)
+		if err != nil {
+			return nil, fmt.Errorf("comment %d: %s", c.ID, err)
+		}
+		insert(tables.Comments, []string{"comment_ID", "comment_post_ID", "comment_author", "comment_author_email",
+			"comment_author_url", "comment_author_IP", "comment_date", "comment_date_gmt", "comment_content", "comment_karma",
+			"comment_approved", "comment_agent", "comment_type", "comment_parent", "user_id"},
+			c.ID, c.PostID, c.AuthorName, c.AuthorEmail, c.AuthorURL, "127.0.0.1", date, dateGmt, c.Content, 0,
+			defaultString(c.Approved, "1"), "", defaultString(c.Type, "comment"), c.Parent, c.UserID)
+		insertMeta(tables.CommentMeta, "comment_id", c.ID, c.Meta)
+	}
+	return statements, nil
+}
+
+// allPosts returns posts and attachments with defaults of empty fields, metas of attachments are built from their file
+func (f *Fixture) allPosts() []Post {
+	var posts []Post
+	for _, p := range f.Posts {
+		p.Type = defaultString(p.Type, model.PostType)
+		p.Status = defaultString(p.Status, model.PublishStatus)
+		posts = append(posts, withPostDefaults(p))
+	}
+	for _, a := range f.Attachments {
+		p := a.Post
+		p.Type = model.AttachmentType
+		p.Status = defaultString(p.Status, "inherit")
+		p.MimeType = defaultString(p.MimeType, model.MimeTypeByExtension(a.File))
+		p.Meta = map[string]string{"_wp_attached_file": a.File}
+		for key, value := range a.Post.Meta {
+			p.Meta[key] = value
+		}
+		if a.AltText != "" {
+			p.Meta["_wp_attachment_image_alt"] = a.AltText
+		}
+		if a.MediaDetails != nil {
+			details := *a.MediaDetails
+			details.File = defaultString(details.File, a.File)
+			if metadata, err := details.Serialize(); err == nil {
+				p.Meta["_wp_attachment_metadata"] = metadata
+			}
+		}
+		posts = append(posts, withPostDefaults(p))
+	}
+	return posts
+}
+
+// guid returns guid of the post like WP sets it when the post is created, guid of attachment is url of its file
+func (f *Fixture) guid(p Post) string {
+	siteURL := defaultString(f.SiteURL, "http://example.com")
+	if p.Type == model.AttachmentType {
+		return siteURL + "/wp-content/uploads/" + p.Meta["_wp_attached_file"]
+	}
+	if p.Type == model.PageType {
+		return siteURL + "/?page_id=" + strconv.FormatUint(p.ID, 10)
+	}
+	return siteURL + "/?p=" + strconv.FormatUint(p.ID, 10)
+}
+
+// withPostDefaults returns post with defaults of empty fields, slug is built from the title
+func withPostDefaults(p Post) Post {
+	p.CommentStatus = defaultString(p.CommentStatus, "open")
+	p.PingStatus = defaultString(p.PingStatus, "open")
+	if p.Slug == "" {
+		p.Slug = slugify(p.Title)
+	}
+	if p.Type == model.AttachmentType && p.Slug == "" {
+		p.Slug = strings.TrimSuffix(path.Base(p.Meta["_wp_attached_file"]), path.Ext(p.Meta["_wp_attached_file"]))
+	}
+	return p
+}
+
+// stickyPosts returns php serialized array of ids of sticky posts
+func stickyPosts(posts []Post) string {
+	var b strings.Builder
+	n := 0
+	for _, p := range posts {
+		if p.Sticky {
+			fmt.Fprintf(&b, "i:%d;i:%d;", n, p.ID)
+			n++
+		}
+	}
+	return fmt.Sprintf("a:%d:{%s}", n, b.String())
+}
+
+// fixtureDate returns date of the fixture or default date if it is empty, it must be in DateTimeFormat
+func fixtureDate(date string, defaultDate string) (string, error) {
+	if date == "" {
+		return defaultDate, nil
+	}
+	if date != model.ZeroDateTime {
+		if _, err := time.Parse(model.DateTimeFormat, date); err != nil {
+			return "", fmt.Errorf("invalid date %q, it must be in %s format", date, model.DateTimeFormat)
+		}
+	}
+	return date, nil
+}
+
+// slugify returns lower case title with dashes instead of other characters than letters and digits
+func slugify(title string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(title))
+	for strings.Contains(slug, "--") {
+		slug = strings.Replace(slug, "--", "-", -1)
+	}
+	return strings.Trim(slug, "-")
+}
+
+func defaultString(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
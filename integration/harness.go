@@ -0,0 +1,166 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/server"
+)
+
+// TestTablePrefix is table prefix of the fixture database, it isn't the default 'wp_' so queries that don't use
+// the configured prefix fail
+const TestTablePrefix = "site_"
+
+// TestAPIConfig returns config of the API that is served by the harness
+func TestAPIConfig() model.APIConfig {
+	return model.APIConfig{
+		APIHost:     "http://example.com",
+		SiteURL:     "http://example.com",
+		UploadPath:  "wp-content/uploads",
+		TablePrefix: TestTablePrefix,
+		APIPath:     "/wp-json/wp",
+		Version:     "v2",
+		WriteAPIKey: "secret",
+	}
+}
+
+// Harness serves the API router from SQLite database that is seeded with fixture
+type Harness struct {
+	DB     *dialect.DB
+	Tables model.Tables
+	Router http.Handler
+	dir    string
+}
+
+// New returns harness of the fixture, the test fails if the sqlite3 driver can't open the database
+func New(t testing.TB, fixture *Fixture) *Harness {
+	dir, err := ioutil.TempDir("", "restlr-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenSQLite(filepath.Join(dir, "wordpress.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	h := &Harness{DB: db, Tables: model.NewTables(TestTablePrefix), dir: dir}
+	if err := Seed(context.Background(), db, h.Tables, fixture); err != nil {
+		h.Close()
+		t.Fatal(err)
+	}
+	h.Router = server.NewRouter(h.DB, server.Options{APIConfig: TestAPIConfig()})
+	return h
+}
+
+// Seed creates WP tables and inserts rows of the fixture in one transaction
+func Seed(ctx context.Context, db *dialect.DB, tables model.Tables, fixture *Fixture) error {
+	schema, err := Schema(db.Dialect, tables)
+	if err != nil {
+		return err
+	}
+	statements, err := fixture.Statements(tables)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, statement := range schema {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, db.Dialect.Rebind(statement.SQL), statement.Args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes the database and removes its file
+func (h *Harness) Close() {
+	h.DB.Close()
+	os.RemoveAll(h.dir)
+}
+
+// Do serves the request with the router and returns its response
+func (h *Harness) Do(method string, url string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+	return w
+}
+
+// Get serves GET request of the url
+func (h *Harness) Get(url string) *httptest.ResponseRecorder {
+	return h.Do(http.MethodGet, url, "")
+}
+
+// AssertGolden compares JSON body with golden file testdata/golden/<name>.json, the golden file is written
+// instead if update is true. JSON is indented before it is compared so golden files are readable
+func AssertGolden(t *testing.T, name string, body []byte, update bool) {
+	t.Helper()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		t.Fatalf("%s: response is not JSON: %s\n%s", name, err, body)
+	}
+	indented.WriteByte('\n')
+
+	filename := filepath.Join("testdata", "golden", name+".json")
+	if update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, indented.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%s: %s, run 'go test ./integration -update' to write golden files", name, err)
+	}
+	if !bytes.Equal(golden, indented.Bytes()) {
+		t.Errorf("%s: response differs from %s\n%s", name, filename, diffLines(string(golden), indented.String()))
+	}
+}
+
+// diffLines returns first lines that differ between expected and actual text
+func diffLines(expected string, actual string) string {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var e, a string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if e != a {
+			return "line " + strconv.Itoa(i+1) + ":\n- " + e + "\n+ " + a
+		}
+	}
+	return ""
+}
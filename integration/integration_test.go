@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/server"
+	"github.com/qreasio/restlr/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+// update writes golden files from responses, run 'go test ./integration -update' after intended change of responses
+var update = flag.Bool("update", false, "update golden files")
+
+func newTestHarness(t *testing.T) *Harness {
+	fixture, err := LoadFixture("testdata/fixture.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(t, fixture)
+}
+
+func TestRouter_Golden(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+
+	requests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"posts", "/wp-json/wp/v2/posts", http.StatusOK},
+		{"posts_embed", "/wp-json/wp/v2/posts?_embed", http.StatusOK},
+		{"posts_page", "/wp-json/wp/v2/posts?per_page=1&page=2", http.StatusOK},
+		{"posts_categories", "/wp-json/wp/v2/posts?categories=3", http.StatusOK},
+		{"posts_tags_exclude", "/wp-json/wp/v2/posts?tags_exclude=5", http.StatusOK},
+		{"posts_sticky", "/wp-json/wp/v2/posts?sticky=true", http.StatusOK},
+		{"posts_search", "/wp-json/wp/v2/posts?search=tea", http.StatusOK},
+		{"posts_order_title", "/wp-json/wp/v2/posts?order_by=title&order=asc", http.StatusOK},
+		{"posts_embed_context", "/wp-json/wp/v2/posts?context=embed", http.StatusOK},
+		{"post", "/wp-json/wp/v2/posts/11", http.StatusOK},
+		{"post_not_found", "/wp-json/wp/v2/posts/999", http.StatusNotFound},
+		{"pages", "/wp-json/wp/v2/pages", http.StatusOK},
+		{"page_children", "/wp-json/wp/v2/pages?parent=20", http.StatusOK},
+		{"page", "/wp-json/wp/v2/pages/21", http.StatusOK},
+		{"categories", "/wp-json/wp/v2/categories", http.StatusOK},
+		{"category", "/wp-json/wp/v2/categories/3", http.StatusOK},
+		{"tags", "/wp-json/wp/v2/tags?orderby=count&order=desc", http.StatusOK},
+		{"search", "/wp-json/wp/v2/search?search=tea", http.StatusOK},
+	}
+
+	for _, r := range requests {
+		t.Run(r.name, func(t *testing.T) {
+			w := h.Get(r.url)
+			assert.Equal(t, r.status, w.Code, w.Body.String())
+			AssertGolden(t, r.name, w.Body.Bytes(), *update)
+		})
+	}
+}
+
+func TestRouter_SearchWildcards(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+
+	// '%' and '_' of the keyword are matched literally, so they don't match every post and term
+	for _, url := range []string{
+		"/wp-json/wp/v2/posts?search=%25",
+		"/wp-json/wp/v2/posts?search=_",
+		"/wp-json/wp/v2/categories?search=%25",
+		"/wp-json/wp/v2/tags?search=_",
+		"/wp-json/wp/v2/search?search=%25&type=term",
+	} {
+		w := h.Get(url)
+		assert.Equal(t, http.StatusOK, w.Code, url)
+		assert.Equal(t, "[]\n", w.Body.String(), url)
+	}
+}
+
+func TestRouter_CreateComment(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+
+	w := h.Do(http.MethodPost, "/wp-json/wp/v2/comments",
+		`{"post": 11, "author_name": "Visitor", "author_email": "visitor@example.org", "content": "Steep for two minutes."}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// post of the comment is returned as post like WP does
+	var created map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, float64(11), created["post"])
+
+	var count int
+	err := h.DB.QueryRow("SELECT comment_count FROM " + h.Tables.Posts + " WHERE ID = 11").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestRouter_CreateCommentCache(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	c := cache.New(cache.NewLRUStore(1<<20), 0)
+	router := server.NewRouter(h.DB, server.Options{APIConfig: TestAPIConfig(), Cache: c})
+
+	comment := func(remoteAddr string, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/wp-json/wp/v2/comments", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// comment of new author is held for moderation, it doesn't change cached responses
+	assert.Equal(t, http.StatusCreated, comment("192.0.2.1:1234",
+		`{"post": 11, "author_name": "Stranger", "author_email": "stranger@example.org", "content": "First visit."}`))
+	assert.Equal(t, uint64(0), c.Stats().Invalidations)
+
+	// author with approved comment is approved again, so the comment invalidates the cache
+	assert.Equal(t, http.StatusCreated, comment("192.0.2.2:1234",
+		`{"post": 11, "author_name": "Visitor", "author_email": "visitor@example.org", "content": "Steep for two minutes."}`))
+	assert.Equal(t, uint64(1), c.Stats().Invalidations)
+}
+
+func TestHarness_TransactionRollback(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+
+	ctx := context.Background()
+	failed := errors.New("failed")
+	err := shared.WithTransaction(ctx, h.DB, func(ctx context.Context) error {
+		if _, err := shared.Conn(ctx, h.DB).ExecContext(ctx, "UPDATE "+h.Tables.Posts+" SET post_title = ? WHERE ID = ?", "Rolled back", 11); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.Equal(t, failed, err)
+
+	var title string
+	err = h.DB.QueryRow("SELECT post_title FROM "+h.Tables.Posts+" WHERE ID = ?", 11).Scan(&title)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "Rolled back", title)
+}
@@ -0,0 +1,183 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+)
+
+// column types of the schema
+const (
+	typeID       = "id"
+	typeBigint   = "bigint"
+	typeInt      = "int"
+	typeVarchar  = "varchar"
+	typeText     = "longtext"
+	typeDatetime = "datetime"
+)
+
+type column struct {
+	name   string
+	typ    string
+	size   int
+	defval string
+}
+
+type table struct {
+	name    func(model.Tables) string
+	columns []column
+	// primary is primary key of table without id column
+	primary []string
+	indexes [][]string
+}
+
+func id(name string) column {
+	return column{name: name, typ: typeID}
+}
+
+func bigint(name string) column {
+	return column{name: name, typ: typeBigint, defval: "0"}
+}
+
+func integer(name string) column {
+	return column{name: name, typ: typeInt, defval: "0"}
+}
+
+func varchar(name string, size int, defval string) column {
+	return column{name: name, typ: typeVarchar, size: size, defval: "'" + defval + "'"}
+}
+
+func text(name string) column {
+	return column{name: name, typ: typeText}
+}
+
+func datetime(name string) column {
+	return column{name: name, typ: typeDatetime, defval: "'" + model.ZeroDateTime + "'"}
+}
+
+// wpTables are WP tables of single site with columns that WP creates
+var wpTables = []table{
+	{name: func(t model.Tables) string { return t.Posts }, columns: []column{
+		id("ID"), bigint("post_author"), datetime("post_date"), datetime("post_date_gmt"), text("post_content"),
+		text("post_title"), text("post_excerpt"), varchar("post_status", 20, "publish"), varchar("comment_status", 20, "open"),
+		varchar("ping_status", 20, "open"), varchar("post_password", 255, ""), varchar("post_name", 200, ""), text("to_ping"),
+		text("pinged"), datetime("post_modified"), datetime("post_modified_gmt"), text("post_content_filtered"),
+		bigint("post_parent"), varchar("guid", 255, ""), integer("menu_order"), varchar("post_type", 20, "post"),
+		varchar("post_mime_type", 100, ""), bigint("comment_count"),
+	}, indexes: [][]string{{"post_name"}, {"post_type", "post_status", "post_date", "ID"}, {"post_parent"}, {"post_author"}}},
+	{name: func(t model.Tables) string { return t.PostMeta }, columns: []column{
+		id("meta_id"), bigint("post_id"), varchar("meta_key", 255, ""), text("meta_value"),
+	}, indexes: [][]string{{"post_id"}, {"meta_key"}}},
+	{name: func(t model.Tables) string { return t.Comments }, columns: []column{
+		id("comment_ID"), bigint("comment_post_ID"), text("comment_author"), varchar("comment_author_email", 100, ""),
+		varchar("comment_author_url", 200, ""), varchar("comment_author_IP", 100, ""), datetime("comment_date"),
+		datetime("comment_date_gmt"), text("comment_content"), integer("comment_karma"), varchar("comment_approved", 20, "1"),
+		varchar("comment_agent", 255, ""), varchar("comment_type", 20, ""), bigint("comment_parent"), bigint("user_id"),
+	}, indexes: [][]string{{"comment_post_ID"}, {"comment_approved", "comment_date_gmt"}, {"comment_parent"}}},
+	{name: func(t model.Tables) string { return t.CommentMeta }, columns: []column{
+		id("meta_id"), bigint("comment_id"), varchar("meta_key", 255, ""), text("meta_value"),
+	}, indexes: [][]string{{"comment_id"}, {"meta_key"}}},
+	{name: func(t model.Tables) string { return t.Terms }, columns: []column{
+		id("term_id"), varchar("name", 200, ""), varchar("slug", 200, ""), bigint("term_group"),
+	}, indexes: [][]string{{"slug"}, {"name"}}},
+	{name: func(t model.Tables) string { return t.TermMeta }, columns: []column{
+		id("meta_id"), bigint("term_id"), varchar("meta_key", 255, ""), text("meta_value"),
+	}, indexes: [][]string{{"term_id"}, {"meta_key"}}},
+	{name: func(t model.Tables) string { return t.TermTaxonomy }, columns: []column{
+		id("term_taxonomy_id"), bigint("term_id"), varchar("taxonomy", 32, ""), text("description"),
+		bigint("parent"), bigint("count"),
+	}, indexes: [][]string{{"term_id", "taxonomy"}, {"taxonomy"}}},
+	{name: func(t model.Tables) string { return t.TermRelationships }, columns: []column{
+		bigint("object_id"), bigint("term_taxonomy_id"), integer("term_order"),
+	}, primary: []string{"object_id", "term_taxonomy_id"}, indexes: [][]string{{"term_taxonomy_id"}}},
+	{name: func(t model.Tables) string { return t.Options }, columns: []column{
+		id("option_id"), varchar("option_name", 191, ""), text("option_value"), varchar("autoload", 20, "yes"),
+	}, indexes: [][]string{{"option_name"}}},
+	{name: func(t model.Tables) string { return t.Users }, columns: []column{
+		id("ID"), varchar("user_login", 60, ""), varchar("user_pass", 255, ""), varchar("user_nicename", 50, ""),
+		varchar("user_email", 100, ""), varchar("user_url", 100, ""), datetime("user_registered"),
+		varchar("user_activation_key", 255, ""), integer("user_status"), varchar("display_name", 250, ""),
+	}, indexes: [][]string{{"user_login"}, {"user_nicename"}, {"user_email"}}},
+	{name: func(t model.Tables) string { return t.UserMeta }, columns: []column{
+		id("umeta_id"), bigint("user_id"), varchar("meta_key", 255, ""), text("meta_value"),
+	}, indexes: [][]string{{"user_id"}, {"meta_key"}}},
+}
+
+// Schema returns statements that create WP tables of the tables in the dialect, only MySQL and SQLite are supported
+func Schema(d dialect.Dialect, tables model.Tables) ([]string, error) {
+	if d.Name() != dialect.MySQLName && d.Name() != dialect.SQLiteName {
+		return nil, fmt.Errorf("schema of %s dialect is not supported", d.Name())
+	}
+
+	var statements []string
+	for _, t := range wpTables {
+		name := t.name(tables)
+		statements = append(statements, createTable(d, name, t))
+		for _, index := range t.indexes {
+			statements = append(statements, fmt.Sprintf("CREATE INDEX %s_%s ON %s (%s)",
+				name, strings.Join(index, "_"), name, strings.Join(index, ", ")))
+		}
+	}
+	return statements, nil
+}
+
+// createTable returns statement that creates the table
+func createTable(d dialect.Dialect, name string, t table) string {
+	var definitions []string
+	for _, c := range t.columns {
+		definitions = append(definitions, c.name+" "+columnType(d, c))
+	}
+	if len(t.primary) > 0 {
+		definitions = append(definitions, "PRIMARY KEY ("+strings.Join(t.primary, ", ")+")")
+	}
+
+	statement := "CREATE TABLE " + name + " (\n  " + strings.Join(definitions, ",\n  ") + "\n)"
+	if d.Name() == dialect.MySQLName {
+		statement += " DEFAULT CHARSET=utf8mb4"
+	}
+	return statement
+}
+
+// columnType returns type, constraints and default of the column in the dialect
+func columnType(d dialect.Dialect, c column) string {
+	mysql := d.Name() == dialect.MySQLName
+	var typ string
+	switch c.typ {
+	case typeID:
+		if mysql {
+			return "BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY"
+		}
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	case typeBigint:
+		typ = "BIGINT UNSIGNED"
+	case typeInt:
+		typ = "INT"
+	case typeVarchar:
+		typ = fmt.Sprintf("VARCHAR(%d)", c.size)
+	case typeText:
+		typ = "LONGTEXT"
+	case typeDatetime:
+		typ = "DATETIME"
+	}
+	if !mysql {
+		// datetime columns keep their declared type, so the sqlite3 driver returns their values as time.Time
+		switch c.typ {
+		case typeBigint, typeInt:
+			typ = "INTEGER"
+		case typeDatetime:
+		default:
+			typ = "TEXT"
+		}
+	}
+
+	if c.defval == "" {
+		// MySQL has no default of text columns, it stores empty text if they are omitted in insert
+		if !mysql {
+			return typ + " NOT NULL DEFAULT ''"
+		}
+		return typ + " NOT NULL"
+	}
+	return typ + " NOT NULL DEFAULT " + c.defval
+}
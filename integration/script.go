@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+)
+
+// Script returns SQL script that replaces content of existing WP site with the fixture, it is run on database of WP install
+// to record responses of WP from the same content that integration tests seed. Options of the fixture replace the options
+// of the site and other options are kept, so the site still works
+func (f *Fixture) Script(d dialect.Dialect, tables model.Tables) (string, error) {
+	if d.Name() != dialect.MySQLName && d.Name() != dialect.SQLiteName {
+		return "", fmt.Errorf("script of %s dialect is not supported", d.Name())
+	}
+	statements, err := f.Statements(tables)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, table := range tables.All() {
+		if table != tables.Options {
+			fmt.Fprintf(&b, "DELETE FROM %s;\n", table)
+		}
+	}
+	var optionNames []string
+	for _, statement := range statements {
+		if strings.HasPrefix(statement.SQL, "INSERT INTO "+tables.Options+" ") {
+			optionNames = append(optionNames, scriptLiteral(d, statement.Args[0]))
+		}
+	}
+	fmt.Fprintf(&b, "DELETE FROM %s WHERE option_name IN (%s);\n", tables.Options, strings.Join(optionNames, ", "))
+
+	for _, statement := range statements {
+		parts := strings.Split(statement.SQL, "?")
+		if len(parts) != len(statement.Args)+1 {
+			return "", fmt.Errorf("statement %q has %d arguments", statement.SQL, len(statement.Args))
+		}
+		for i, part := range parts {
+			b.WriteString(part)
+			if i < len(statement.Args) {
+				b.WriteString(scriptLiteral(d, statement.Args[i]))
+			}
+		}
+		b.WriteString(";\n")
+	}
+	return b.String(), nil
+}
+
+// scriptLiteral returns SQL literal of fixture value in the dialect, MySQL escapes backslash in strings
+func scriptLiteral(d dialect.Dialect, value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	s = strings.Replace(s, "'", "''", -1)
+	if d.Name() == dialect.MySQLName {
+		s = strings.Replace(s, `\`, `\\`, -1)
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,347 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/fulltext"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/term"
+	"github.com/qreasio/restlr/toolbox"
+	"github.com/qreasio/restlr/user"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingTermRepository fails to update term count, it is the last step of saving and deleting post
+type failingTermRepository struct {
+	term.Repository
+}
+
+var errTermCount = errors.New("term count failed")
+
+// adminID is id of administrator of the fixture, posts are created by the administrator
+var adminID uint64 = 1
+
+func (r failingTermRepository) UpdateTermCount(ctx context.Context, termTaxonomyIDList []uint64) error {
+	return errTermCount
+}
+
+// newPostService returns post service of the harness database, term repository is wrapped by wrap if it is not nil
+func newPostService(h *Harness, wrap func(term.Repository) term.Repository) post.Service {
+	termRepository := term.NewRepository(h.DB)
+	if wrap != nil {
+		termRepository = wrap(termRepository)
+	}
+	return post.NewService(post.NewRepository(h.DB, fulltext.NewLikeBackend(h.DB.Dialect)), termRepository,
+		shared.NewRepository(h.DB), user.NewRepository(h.DB))
+}
+
+func testContext() context.Context {
+	return context.WithValue(context.Background(), model.APIConfigKey, TestAPIConfig())
+}
+
+// postRow is row of posts table that is checked by the tests
+type postRow struct {
+	Name   string
+	Status string
+	Title  string
+}
+
+func queryPostRow(t *testing.T, h *Harness, id uint64) (*postRow, error) {
+	t.Helper()
+	var row postRow
+	err := h.DB.QueryRow("SELECT post_name, post_status, post_title FROM "+h.Tables.Posts+" WHERE ID = ?", id).
+		Scan(&row.Name, &row.Status, &row.Title)
+	return &row, err
+}
+
+func queryInt(t *testing.T, h *Harness, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := h.DB.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func revisionCount(t *testing.T, h *Harness, id uint64) int {
+	return queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.Posts+" WHERE post_parent = ? AND post_type = 'revision'", id)
+}
+
+func termCount(t *testing.T, h *Harness, termTaxonomyID uint64) int {
+	return queryInt(t, h, "SELECT count FROM "+h.Tables.TermTaxonomy+" WHERE term_taxonomy_id = ?", termTaxonomyID)
+}
+
+// createdPostID returns id of post that is returned by CreatePost
+func createdPostID(t *testing.T, created interface{}) uint64 {
+	t.Helper()
+	p, ok := created.(*model.Post)
+	if !ok {
+		t.Fatalf("created post is %T", created)
+	}
+	return p.ID
+}
+
+func TestPostService_CreatePost(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	ctx := testContext()
+	s := newPostService(h, nil)
+
+	newsCount, teaCount := termCount(t, h, 2), termCount(t, h, 4)
+	created, err := s.CreatePost(ctx, model.WritePostRequest{
+		Author:     &adminID,
+		Title:      toolbox.StringPointer("Brewing Guide"),
+		Content:    toolbox.StringPointer("<p>Use fresh water.</p>"),
+		Status:     toolbox.StringPointer(model.PublishStatus),
+		Categories: []uint64{2},
+		Tags:       []uint64{4},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id := createdPostID(t, created)
+	row, err := queryPostRow(t, h, id)
+	assert.NoError(t, err)
+	assert.Equal(t, &postRow{Name: "brewing-guide", Status: model.PublishStatus, Title: "Brewing Guide"}, row)
+	assert.Equal(t, 1, revisionCount(t, h, id))
+	assert.Equal(t, newsCount+1, termCount(t, h, 2))
+	assert.Equal(t, teaCount+1, termCount(t, h, 4))
+}
+
+func TestPostService_CreatePost_UniqueSlug(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	ctx := testContext()
+	s := newPostService(h, nil)
+
+	var names []string
+	for i := 0; i < 2; i++ {
+		created, err := s.CreatePost(ctx, model.WritePostRequest{
+			Author: &adminID,
+			Title:  toolbox.StringPointer("Hello World"),
+			Status: toolbox.StringPointer(model.PublishStatus),
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		row, err := queryPostRow(t, h, createdPostID(t, created))
+		assert.NoError(t, err)
+		names = append(names, row.Name)
+	}
+	assert.Equal(t, []string{"hello-world-2", "hello-world-3"}, names)
+}
+
+func TestPostService_UpdatePost(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	ctx := testContext()
+	s := newPostService(h, nil)
+	id := uint64(11)
+
+	revisions := revisionCount(t, h, id)
+	_, err := s.UpdatePost(ctx, model.WritePostRequest{ID: &id, Title: toolbox.StringPointer("Green Tea Notes, Revised")})
+	assert.NoError(t, err)
+	row, err := queryPostRow(t, h, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Green Tea Notes, Revised", row.Title)
+	assert.Equal(t, "green-tea-notes", row.Name)
+	assert.Equal(t, revisions+1, revisionCount(t, h, id))
+
+	// revision is not stored if revisioned fields are not changed
+	_, err = s.UpdatePost(ctx, model.WritePostRequest{ID: &id, CommentStatus: toolbox.StringPointer("closed")})
+	assert.NoError(t, err)
+	assert.Equal(t, revisions+1, revisionCount(t, h, id))
+
+	// terms of draft are not counted
+	teaCount := termCount(t, h, 4)
+	_, err = s.UpdatePost(ctx, model.WritePostRequest{ID: &id, Status: toolbox.StringPointer(model.DraftStatus)})
+	assert.NoError(t, err)
+	assert.Equal(t, teaCount-1, termCount(t, h, 4))
+}
+
+func TestPostService_DeletePost_Trash(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	ctx := testContext()
+	s := newPostService(h, nil)
+	id := uint64(11)
+
+	teaCount := termCount(t, h, 4)
+	_, err := s.DeletePost(ctx, model.DeleteItemRequest{ID: &id})
+	assert.NoError(t, err)
+
+	row, err := queryPostRow(t, h, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "trash", row.Status)
+	assert.Equal(t, "green-tea-notes__trashed", row.Name)
+	assert.Equal(t, 1, queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.PostMeta+" WHERE post_id = ? AND meta_key = '_wp_trash_meta_status' AND meta_value = 'publish'", id))
+	assert.Equal(t, teaCount-1, termCount(t, h, 4))
+
+	_, err = s.DeletePost(ctx, model.DeleteItemRequest{ID: &id})
+	assert.Equal(t, model.ErrAlreadyTrashed, err)
+}
+
+func TestPostService_DeletePost_Force(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	ctx := testContext()
+	s := newPostService(h, nil)
+	id := uint64(10)
+
+	newsCount := termCount(t, h, 2)
+	deleted, err := s.DeletePost(ctx, model.DeleteItemRequest{ID: &id, Force: true})
+	assert.NoError(t, err)
+	assert.IsType(t, model.DeletedItem{}, deleted)
+
+	assert.Equal(t, 0, queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.Posts+" WHERE ID = ?", id))
+	assert.Equal(t, 0, queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.PostMeta+" WHERE post_id = ?", id))
+	assert.Equal(t, 0, queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.TermRelationships+" WHERE object_id = ?", id))
+	assert.Equal(t, newsCount-1, termCount(t, h, 2))
+}
+
+func TestPostService_RollbackOnError(t *testing.T) {
+	h := newTestHarness(t)
+	defer h.Close()
+	ctx := testContext()
+	s := newPostService(h, func(r term.Repository) term.Repository {
+		return failingTermRepository{r}
+	})
+
+	posts := queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.Posts)
+	relationships := queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.TermRelationships)
+	_, err := s.CreatePost(ctx, model.WritePostRequest{
+		Author: &adminID,
+		Title:  toolbox.StringPointer("Never Saved"),
+		Status: toolbox.StringPointer(model.PublishStatus),
+		Tags:   []uint64{4},
+	})
+	assert.Equal(t, errTermCount, err)
+	assert.Equal(t, posts, queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.Posts))
+	assert.Equal(t, relationships, queryInt(t, h, "SELECT COUNT(*) FROM "+h.Tables.TermRelationships))
+
+	id := uint64(11)
+	_, err = s.UpdatePost(ctx, model.WritePostRequest{ID: &id, Title: toolbox.StringPointer("Never Saved")})
+	assert.Equal(t, errTermCount, err)
+	row, err := queryPostRow(t, h, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Green Tea Notes", row.Title)
+
+	_, err = s.DeletePost(ctx, model.DeleteItemRequest{ID: &id})
+	assert.Equal(t, errTermCount, err)
+	row, err = queryPostRow(t, h, id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.PublishStatus, row.Status)
+}
+
+// newGrownFixture returns the fixture with n more published posts like post 10, each of them has terms, featured media
+// and approved comment, and posts are written by both users of the fixture
+func newGrownFixture(t testing.TB, n int) *Fixture {
+	fixture, err := LoadFixture("testdata/fixture.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		id := uint64(100 + i)
+		date := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour).Format("2006-01-02 15:04:05")
+		fixture.Posts = append(fixture.Posts, Post{ID: id, Author: uint64(1 + i%2), Date: date, DateGmt: date, Modified: date, ModifiedGmt: date,
+			Title: fmt.Sprintf("Grown %d", i), Content: "Grown post", Slug: fmt.Sprintf("grown-%d", i),
+			Terms: []uint64{2, 4}, Meta: map[string]string{"_thumbnail_id": "30"}})
+		fixture.Comments = append(fixture.Comments, Comment{ID: 1000 + id, PostID: id, AuthorName: "Visitor", AuthorEmail: "visitor@example.org",
+			Date: date, DateGmt: date, Content: "Nice post!"})
+	}
+	return fixture
+}
+
+// queryCounter is query hook that counts queries by repository method
+type queryCounter struct {
+	mu      sync.Mutex
+	methods map[string]int
+}
+
+func (c *queryCounter) hook(ctx context.Context, d dialect.Dialect, method string, query string) func(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods[method]++
+	return func(error) {}
+}
+
+// count runs fn and returns queries of fn by repository method
+func (c *queryCounter) count(fn func()) map[string]int {
+	c.mu.Lock()
+	c.methods = map[string]int{}
+	c.mu.Unlock()
+	fn()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.methods
+}
+
+func TestPostService_ListPostsEmbedQueryCount(t *testing.T) {
+	counter := &queryCounter{}
+	shared.QueryHooks = append(shared.QueryHooks, counter.hook)
+	defer func() { shared.QueryHooks = nil }()
+
+	// related data of embedded posts is pulled once for all posts, so the number of queries doesn't grow with the posts
+	var counts []map[string]int
+	for _, n := range []int{2, 20} {
+		h := New(t, newGrownFixture(t, n))
+		s := newPostService(h, nil)
+		req := model.ListRequest{ListParams: model.ListParams{ListFilter: model.ListFilter{Page: 1, PerPage: 100,
+			Status: toolbox.StringPointer(model.PublishStatus), Type: model.PostType}}, IsEmbed: true}
+
+		counts = append(counts, counter.count(func() {
+			res, err := s.ListPosts(testContext(), req)
+			assert.NoError(t, err)
+			list := res.([]*model.Post)
+			assert.Len(t, list, n+3)
+			for _, p := range list {
+				if p.ID >= 100 {
+					assert.Len(t, p.Embedded.FeaturedMedia, 1)
+				}
+			}
+		}))
+		h.Close()
+	}
+	assert.NotEmpty(t, counts[0])
+	assert.Equal(t, counts[0], counts[1])
+}
+
+// BenchmarkListPostsEmbedLatency runs embedded list of 10 posts on the fixture database, one query at once and with
+// concurrent queries, and reports p50 and p99 latency of the requests
+func BenchmarkListPostsEmbedLatency(b *testing.B) {
+	h := New(b, newGrownFixture(b, 10))
+	defer h.Close()
+	s := newPostService(h, nil)
+	ctx := testContext()
+	req := model.ListRequest{ListParams: model.ListParams{ListFilter: model.ListFilter{Page: 1, PerPage: 10,
+		Status: toolbox.StringPointer(model.PublishStatus), Type: model.PostType}}, IsEmbed: true}
+
+	defer func(limit int) { shared.MaxConcurrentQueries = limit }(shared.MaxConcurrentQueries)
+	for _, limit := range []int{1, shared.MaxConcurrentQueries} {
+		b.Run(fmt.Sprintf("queries=%d", limit), func(b *testing.B) {
+			shared.MaxConcurrentQueries = limit
+			durations := make([]time.Duration, b.N)
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				if _, err := s.ListPosts(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+				durations[i] = time.Since(start)
+			}
+
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			b.ReportMetric(float64(durations[b.N/2].Microseconds())/1000, "p50-ms")
+			b.ReportMetric(float64(durations[b.N*99/100].Microseconds())/1000, "p99-ms")
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package integration
+
+import (
+	"fmt"
+
+	"github.com/qreasio/restlr/dialect"
+)
+
+// OpenSQLite returns db of the sqlite database file that is opened with the SQLite driver of the dialect package, so
+// queries of the harness run with real transactions and bound arguments like they do on the API database.
+// The database is pinged, so error is returned if the driver can't run, like when it is built without cgo
+func OpenSQLite(path string) (*dialect.DB, error) {
+	db, err := dialect.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite3 driver can't open %s, it needs cgo: %s", path, err)
+	}
+	return db, nil
+}
@@ -3,18 +3,25 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-chi/chi"
 	"github.com/joho/godotenv"
-	resthttp "github.com/qreasio/restlr/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/fulltext"
+	"github.com/qreasio/restlr/metrics"
 	"github.com/qreasio/restlr/model"
-	"github.com/qreasio/restlr/page"
-	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/multisite"
+	"github.com/qreasio/restlr/server"
 	"github.com/qreasio/restlr/shared"
-	"github.com/qreasio/restlr/term"
-	"github.com/qreasio/restlr/user"
+	"github.com/qreasio/restlr/sqlbuilder"
+	"github.com/qreasio/restlr/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/xo/dburl"
 )
@@ -26,6 +33,8 @@ var (
 	SiteURL = "http://localhost:8080"
 	// UploadPath is the static files or uploaded file folder path
 	UploadPath = "uploads"
+	// UploadDir is the local directory where uploaded files are stored, it is served as UploadPath of the site
+	UploadDir = ""
 	// TablePrefix is the prefix of tables
 	TablePrefix = "wp_"
 	// APIPath is the path for the API relative to APIHost
@@ -34,26 +43,124 @@ var (
 	Version = "v2"
 	// ServerPort is port of the API server
 	ServerPort = "8080"
+	// WriteAPIKey is the bearer token that is required to create, update and delete content, write is disabled if it is empty
+	WriteAPIKey = ""
+	// PostTypesFile is the json file of custom post types that are registered on start, no custom post type is registered if it is empty
+	PostTypesFile = ""
+	// TaxonomiesFile is the json file of custom taxonomies that are registered on start, no custom taxonomy is registered if it is empty
+	TaxonomiesFile = ""
+	// SearchBackend is the backend that matches search keyword of posts, it is like, mysql or index
+	SearchBackend = fulltext.LikeBackendName
+	// SearchIndexInterval is the interval of polling modified posts to refresh index of index search backend
+	SearchIndexInterval = time.Minute
+	// CacheBackend is the backend of response and repository cache, it is memory or disk and cache is disabled if it is empty
+	CacheBackend = ""
+	// CacheTTL is how long cached values are kept
+	CacheTTL = 5 * time.Minute
+	// CacheSize is max size of memory cache backend in megabytes
+	CacheSize int64 = 64
+	// CacheDir is the directory of disk cache backend
+	CacheDir = "cache"
+	// CachePollInterval is the interval of polling change markers of content to invalidate cache
+	CachePollInterval = 10 * time.Second
 )
 
-// SetAPIContext will set the APIConfig struct instance in context to store the important data that will be used in most all endpoints
-// so it is easily accessible from endpoint by getting it from context
-func SetAPIContext() func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			apiModel := model.APIConfig{
-				APIHost:     APIHost,
-				SiteURL:     SiteURL,
-				UploadPath:  UploadPath,
-				TablePrefix: TablePrefix,
-				APIPath:     APIPath,
-				Version:     Version,
-			}
-			apiModel.APIBaseURL = fmt.Sprintf("%s/%s/%s", apiModel.APIHost, apiModel.APIPath, apiModel.Version)
-			ctx := context.WithValue(r.Context(), model.APIConfigKey, apiModel)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+var (
+	// Multisite enables routing requests to sites of WordPress multisite network, TablePrefix is the base prefix of the network
+	Multisite = false
+	// MultisitePollInterval is the interval of reading sites of the network to route requests to new sites
+	MultisitePollInterval = time.Minute
+)
+
+var (
+	// Metrics enables Prometheus metrics of requests, endpoints, queries, database pool, cache and Go runtime
+	Metrics = false
+	// MetricsPath is the path that serves Prometheus metrics
+	MetricsPath = "/metrics"
+)
+
+var (
+	// TracingExporter is the exporter of OpenTelemetry spans, it is otlp, stdout or none and tracing is disabled if it is empty
+	TracingExporter = ""
+	// TracingEndpoint is base URL of OTLP/HTTP receiver that otlp exporter posts spans to
+	TracingEndpoint = tracing.DefaultOTLPEndpoint
+	// TracingServiceName is service name of spans
+	TracingServiceName = "restlr"
+)
+
+var (
+	// RequestTimeout is the max duration of request, context of request is canceled after it and requests have no timeout if it is zero
+	RequestTimeout = 30 * time.Second
+)
+
+// newCache returns cache of CacheBackend or nil if cache is disabled, version of the cache is polled before it is returned
+// and every CachePollInterval in background, content of the tables that sites returns on every poll is watched
+func newCache(db *dialect.DB, sites func() []model.Tables) (*cache.Cache, error) {
+	var store cache.Store
+	switch CacheBackend {
+	case cache.MemoryBackendName:
+		store = cache.NewLRUStore(CacheSize << 20)
+	case cache.DiskBackendName:
+		diskStore, err := cache.NewDiskStore(CacheDir)
+		if err != nil {
+			return nil, err
+		}
+		store = diskStore
+	default:
+		return nil, nil
+	}
+
+	c := cache.New(store, CacheTTL)
+	watcher := cache.NewSitesWatcher(db, c, sites)
+	if err := watcher.Poll(context.Background()); err != nil {
+		return nil, err
+	}
+	go watcher.Run(context.Background(), CachePollInterval)
+	return c, nil
+}
+
+// newSearchBackend returns search backend of SearchBackend, index of index backend is built for every site of the table
+// prefixes before it is returned and refreshed every SearchIndexInterval in background
+func newSearchBackend(db *dialect.DB, prefixes func() []string) (fulltext.Backend, error) {
+	switch SearchBackend {
+	case fulltext.MySQLBackendName:
+		return fulltext.NewMySQLBackend(), nil
+	case fulltext.IndexBackendName:
+		backend := fulltext.NewSitesIndexBackend(db, prefixes)
+		if err := backend.Refresh(context.Background()); err != nil {
+			return nil, err
+		}
+		go backend.Run(context.Background(), SearchIndexInterval)
+		return backend, nil
 	}
+	return fulltext.NewLikeBackend(db.Dialect), nil
+}
+
+// registerContentTypes registers custom post types from PostTypesFile and custom taxonomies from TaxonomiesFile
+func registerContentTypes() error {
+	loaders := []struct {
+		file string
+		load func(r io.Reader) error
+	}{
+		{PostTypesFile, model.LoadPostTypes},
+		{TaxonomiesFile, model.LoadTaxonomies},
+	}
+
+	for _, loader := range loaders {
+		if loader.file == "" {
+			continue
+		}
+		f, err := os.Open(loader.file)
+		if err != nil {
+			return err
+		}
+		err = loader.load(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s", loader.file, err)
+		}
+	}
+	return nil
 }
 
 func init() {
@@ -72,47 +179,236 @@ func init() {
 		log.Fatal("Error loading .env file")
 	}
 
-	ServerPort = os.Getenv("SERVER_PORT")   // Port of API Server
-	APIHost = os.Getenv("API_HOST")         // The rest api host
-	SiteURL = os.Getenv("SITE_URL")         // The site host
-	UploadPath = os.Getenv("UPLOAD_PATH")   // File upload path relative from site host
-	TablePrefix = os.Getenv("TABLE_PREFIX") // Database table prefix
-	APIPath = os.Getenv("API_PATH")         // Relative API Path to api host
-	Version = os.Getenv("VERSION")          // API Version path
+	ServerPort = os.Getenv("SERVER_PORT")         // Port of API Server
+	APIHost = os.Getenv("API_HOST")               // The rest api host
+	SiteURL = os.Getenv("SITE_URL")               // The site host
+	UploadPath = os.Getenv("UPLOAD_PATH")         // File upload path relative from site host
+	UploadDir = os.Getenv("UPLOAD_DIR")           // Local directory of uploaded files
+	TablePrefix = os.Getenv("TABLE_PREFIX")       // Database table prefix
+	APIPath = os.Getenv("API_PATH")               // Relative API Path to api host
+	Version = os.Getenv("VERSION")                // API Version path
+	WriteAPIKey = os.Getenv("WRITE_API_KEY")      // Bearer token for write requests
+	PostTypesFile = os.Getenv("POST_TYPES_FILE")  // JSON file of custom post types
+	TaxonomiesFile = os.Getenv("TAXONOMIES_FILE") // JSON file of custom taxonomies
+
+	// table prefix is written into queries as part of table names, so it must be plain identifier
+	if !sqlbuilder.ValidIdent(TablePrefix + "posts") {
+		log.Fatal("Error on TABLE_PREFIX:", TablePrefix)
+	}
+
+	if backend := os.Getenv("SEARCH_BACKEND"); backend != "" {
+		if err = fulltext.ValidateBackendName(backend); err != nil {
+			log.Fatal("Error on SEARCH_BACKEND:", err)
+		}
+		SearchBackend = backend
+	}
+	if interval := os.Getenv("SEARCH_INDEX_INTERVAL"); interval != "" {
+		if SearchIndexInterval, err = time.ParseDuration(interval); err != nil || SearchIndexInterval <= 0 {
+			log.Fatal("Error on SEARCH_INDEX_INTERVAL:", interval)
+		}
+	}
+
+	if backend := os.Getenv("CACHE_BACKEND"); backend != "" {
+		if err = cache.ValidateBackendName(backend); err != nil {
+			log.Fatal("Error on CACHE_BACKEND:", err)
+		}
+		CacheBackend = backend
+	}
+	if ttl := os.Getenv("CACHE_TTL"); ttl != "" {
+		if CacheTTL, err = time.ParseDuration(ttl); err != nil || CacheTTL <= 0 {
+			log.Fatal("Error on CACHE_TTL:", ttl)
+		}
+	}
+	if size := os.Getenv("CACHE_SIZE"); size != "" {
+		if CacheSize, err = strconv.ParseInt(size, 10, 64); err != nil || CacheSize <= 0 {
+			log.Fatal("Error on CACHE_SIZE:", size)
+		}
+	}
+	if dir := os.Getenv("CACHE_DIR"); dir != "" {
+		CacheDir = dir
+	}
+	if interval := os.Getenv("CACHE_POLL_INTERVAL"); interval != "" {
+		if CachePollInterval, err = time.ParseDuration(interval); err != nil || CachePollInterval <= 0 {
+			log.Fatal("Error on CACHE_POLL_INTERVAL:", interval)
+		}
+	}
+
+	if limit := os.Getenv("MAX_CONCURRENT_QUERIES"); limit != "" {
+		if shared.MaxConcurrentQueries, err = strconv.Atoi(limit); err != nil || shared.MaxConcurrentQueries <= 0 {
+			log.Fatal("Error on MAX_CONCURRENT_QUERIES:", limit)
+		}
+	}
+
+	if timeout := os.Getenv("REQUEST_TIMEOUT"); timeout != "" {
+		if RequestTimeout, err = time.ParseDuration(timeout); err != nil || RequestTimeout < 0 {
+			log.Fatal("Error on REQUEST_TIMEOUT:", timeout)
+		}
+	}
+	if timeout := os.Getenv("QUERY_TIMEOUT"); timeout != "" {
+		if shared.QueryTimeout, err = time.ParseDuration(timeout); err != nil || shared.QueryTimeout < 0 {
+			log.Fatal("Error on QUERY_TIMEOUT:", timeout)
+		}
+	}
+	if threshold := os.Getenv("SLOW_QUERY_THRESHOLD"); threshold != "" {
+		if shared.SlowQueryThreshold, err = time.ParseDuration(threshold); err != nil || shared.SlowQueryThreshold < 0 {
+			log.Fatal("Error on SLOW_QUERY_THRESHOLD:", threshold)
+		}
+	}
+
+	if multisite := os.Getenv("MULTISITE"); multisite != "" {
+		if Multisite, err = strconv.ParseBool(multisite); err != nil {
+			log.Fatal("Error on MULTISITE:", multisite)
+		}
+	}
+	if interval := os.Getenv("MULTISITE_POLL_INTERVAL"); interval != "" {
+		if MultisitePollInterval, err = time.ParseDuration(interval); err != nil || MultisitePollInterval <= 0 {
+			log.Fatal("Error on MULTISITE_POLL_INTERVAL:", interval)
+		}
+	}
+
+	if enabled := os.Getenv("METRICS"); enabled != "" {
+		if Metrics, err = strconv.ParseBool(enabled); err != nil {
+			log.Fatal("Error on METRICS:", enabled)
+		}
+	}
+	if path := os.Getenv("METRICS_PATH"); path != "" {
+		if !strings.HasPrefix(path, "/") {
+			log.Fatal("Error on METRICS_PATH:", path)
+		}
+		MetricsPath = path
+	}
+
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		if exporter != tracing.OTLPExporterName && exporter != tracing.StdoutExporterName && exporter != tracing.NoopExporterName {
+			log.Fatal("Error on TRACING_EXPORTER:", exporter)
+		}
+		TracingExporter = exporter
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		TracingEndpoint = endpoint
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		TracingServiceName = name
+	}
+
+	if UploadDir == "" {
+		UploadDir = UploadPath
+	}
+}
+
+// newDirectory returns directory of sites of multisite network or nil if Multisite is disabled, sites are read before
+// it is returned and every MultisitePollInterval in background
+func newDirectory(db *dialect.DB, config model.APIConfig) (*multisite.Directory, error) {
+	if !Multisite {
+		return nil, nil
+	}
+
+	directory := multisite.NewDirectory(multisite.NewRepository(db, config.TablePrefix), config)
+	if err := directory.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	go directory.Run(context.Background(), MultisitePollInterval)
+	return directory, nil
+}
+
+// newMetrics returns metrics of the API or nil if Metrics is disabled, queries of repositories, pool stats of the db
+// and stats of the cache if it is enabled are observed
+func newMetrics(db *dialect.DB, c *cache.Cache) *metrics.Metrics {
+	if !Metrics {
+		return nil
+	}
+
+	m := metrics.New(prometheus.NewRegistry())
+	m.RegisterDB(db.DB)
+	if c != nil {
+		m.RegisterCache(c)
+	}
+	shared.QueryHooks = append(shared.QueryHooks, m.QueryHook())
+	return m
+}
+
+// setupTracing sets global tracer provider of OpenTelemetry that exports spans with TracingExporter and starts span of every
+// query of repositories, it returns false if tracing is disabled
+func setupTracing() (bool, error) {
+	if TracingExporter == "" {
+		return false, nil
+	}
+
+	exporter, err := tracing.NewExporter(TracingExporter, TracingEndpoint)
+	if err != nil {
+		return false, err
+	}
+	tracing.Setup(exporter, TracingServiceName)
+	shared.QueryHooks = append(shared.QueryHooks, tracing.QueryHook)
+	return true, nil
 }
 
 func main() {
 	DatabaseURL := os.Getenv("DATABASE_URL")
-	db, err := dburl.Open(DatabaseURL)
+	u, err := dburl.Parse(DatabaseURL)
 	if err != nil {
 		log.Fatal(err)
 	}
+	db, err := dialect.Open(u.Driver, u.DSN)
+	if err != nil {
+		log.Fatal("Error on DATABASE_URL:", err)
+	}
+	if SearchBackend == fulltext.MySQLBackendName && db.Dialect != dialect.MySQL {
+		log.Fatal("Error on SEARCH_BACKEND: mysql backend requires MySQL database")
+	}
+
+	if err = registerContentTypes(); err != nil {
+		log.Fatal("Error on registering post types and taxonomies:", err)
+	}
 
-	//initialize repositories
-	postRepository := post.NewRepository(db)
-	termRepository := term.NewRepository(db)
-	userRepository := user.NewRepository(db)
-	sharedRepository := shared.NewRepository(db)
+	config := model.APIConfig{
+		APIHost:     APIHost,
+		SiteURL:     SiteURL,
+		UploadPath:  UploadPath,
+		UploadDir:   UploadDir,
+		TablePrefix: TablePrefix,
+		APIPath:     APIPath,
+		Version:     Version,
+		WriteAPIKey: WriteAPIKey,
+	}
 
-	//initialize services
-	postService := post.NewService(postRepository, termRepository, sharedRepository, userRepository)
-	pageService := page.NewService(postRepository, sharedRepository, userRepository)
+	directory, err := newDirectory(db, config)
+	if err != nil {
+		log.Fatal("Error on reading sites of multisite network:", err)
+	}
 
-	r := chi.NewRouter()
+	// cache and search index cover every site of the network, sites that are added later are covered after they are read
+	sites := func() []model.Tables { return []model.Tables{config.Tables()} }
+	prefixes := func() []string { return []string{config.TablePrefix} }
+	if directory != nil {
+		sites = directory.Tables
+		prefixes = directory.TablePrefixes
+	}
 
-	//middleware
-	r.Use(SetAPIContext())
+	searchBackend, err := newSearchBackend(db, prefixes)
+	if err != nil {
+		log.Fatal("Error on building search index:", err)
+	}
 
-	//set base api path base on env var
-	baseAPIPath := fmt.Sprintf("%s/%s", APIPath, Version)
+	responseCache, err := newCache(db, sites)
+	if err != nil {
+		log.Fatal("Error on initializing cache:", err)
+	}
 
-	//routing
-	r.Mount(baseAPIPath+"/posts", post.MakeHTTPHandler(postService))
-	r.Mount(baseAPIPath+"/pages", page.MakeHTTPHandler(pageService))
+	tracingEnabled, err := setupTracing()
+	if err != nil {
+		log.Fatal("Error on setting up tracing:", err)
+	}
 
-	//handle 404 notfound/invalid route with custom response
-	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		resthttp.EncodeJSONResponse(context.Background(), w, resthttp.NewRouteNotFoundResponse())
+	r := server.NewRouter(db, server.Options{
+		APIConfig:      config,
+		RequestTimeout: RequestTimeout,
+		SearchBackend:  searchBackend,
+		Cache:          responseCache,
+		Sites:          directory,
+		Metrics:        newMetrics(db, responseCache),
+		MetricsPath:    MetricsPath,
+		Tracing:        tracingEnabled,
 	})
 
 	log.Printf("Restlr API starts to run at port : %s", ServerPort)
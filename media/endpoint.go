@@ -0,0 +1,32 @@
+package media
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeUploadMediaEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.UploadMediaRequest)
+		res, err := s.UploadMedia(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotCreateCode, "The media cannot be created."), nil
+		}
+		return http.CreatedResponse{Item: res, Location: itemLocation(ctx, res)}, nil
+	}
+	return endpoint
+}
+
+// itemLocation returns url of created media for Location header
+func itemLocation(ctx context.Context, item interface{}) string {
+	m, ok := item.(*model.Media)
+	if !ok {
+		return ""
+	}
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	return fmt.Sprintf("%s/media/%d", apiConfig.APIBaseURL, m.ID)
+}
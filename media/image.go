@@ -0,0 +1,262 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/shared"
+)
+
+// jpegQuality is quality of generated jpeg image sizes, it is the default quality of WP image editor
+const jpegQuality = 82
+
+// maxImagePixels is the largest number of pixels of image that is decoded to generate image sizes, decoded image takes
+// 4 bytes per pixel, so a small compressed file with huge dimensions can't exhaust memory
+const maxImagePixels = 50000000
+
+// imageSize is registered image size that is generated for uploaded image
+type imageSize struct {
+	name   string
+	width  int
+	height int
+	crop   bool
+}
+
+// defaultImageSizes are WP default image sizes, their width, height and crop can be changed from media settings
+var defaultImageSizes = []imageSize{
+	{name: "thumbnail", width: 150, height: 150, crop: true},
+	{name: "medium", width: 300, height: 300},
+	{name: "medium_large", width: 768, height: 0},
+	{name: "large", width: 1024, height: 1024},
+}
+
+// registeredImageSizes returns image sizes with width, height and crop from media settings in options table
+func registeredImageSizes(ctx context.Context, sharedRepo shared.Repository) ([]imageSize, error) {
+	sizes := make([]imageSize, len(defaultImageSizes))
+	for i, size := range defaultImageSizes {
+		width, err := post.OptionValue(ctx, sharedRepo, size.name+"_size_w", strconv.Itoa(size.width))
+		if err != nil {
+			return nil, err
+		}
+		height, err := post.OptionValue(ctx, sharedRepo, size.name+"_size_h", strconv.Itoa(size.height))
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = imageSize{name: size.name, width: size.width, height: size.height, crop: size.crop}
+		if w, err := strconv.Atoi(width); err == nil {
+			sizes[i].width = w
+		}
+		if h, err := strconv.Atoi(height); err == nil {
+			sizes[i].height = h
+		}
+		// only thumbnail size can be set to crop from media settings
+		if size.name == "thumbnail" {
+			crop, err := post.OptionValue(ctx, sharedRepo, "thumbnail_crop", "1")
+			if err != nil {
+				return nil, err
+			}
+			sizes[i].crop = crop != "" && crop != "0"
+		}
+	}
+	return sizes, nil
+}
+
+// constrainDimensions returns dimensions scaled to fit in max width and max height while keeping the ratio
+// like wp_constrain_dimensions does, zero max width or max height means no limit
+func constrainDimensions(width, height, maxWidth, maxHeight int) (int, int) {
+	if maxWidth == 0 && maxHeight == 0 {
+		return width, height
+	}
+
+	widthRatio, heightRatio := 1.0, 1.0
+	if maxWidth > 0 && width > maxWidth {
+		widthRatio = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		heightRatio = float64(maxHeight) / float64(height)
+	}
+
+	ratio := math.Max(widthRatio, heightRatio)
+	if (maxWidth > 0 && int(math.Round(float64(width)*ratio)) > maxWidth) ||
+		(maxHeight > 0 && int(math.Round(float64(height)*ratio)) > maxHeight) {
+		ratio = math.Min(widthRatio, heightRatio)
+	}
+
+	return int(math.Max(1, math.Round(float64(width)*ratio))), int(math.Max(1, math.Round(float64(height)*ratio)))
+}
+
+// resizeDimensions returns area of the original image that is resized and the dimensions of the resized image like image_resize_dimensions does.
+// It returns false if the image size doesn't need to be generated because the original image is not larger than the size
+func resizeDimensions(width, height int, size imageSize) (image.Rectangle, int, int, bool) {
+	if width <= 0 || height <= 0 || (size.width <= 0 && size.height <= 0) {
+		return image.Rectangle{}, 0, 0, false
+	}
+
+	src := image.Rect(0, 0, width, height)
+	var newWidth, newHeight int
+
+	if size.crop {
+		newWidth, newHeight = size.width, size.height
+		if newWidth <= 0 || newWidth > width {
+			newWidth = width
+		}
+		if newHeight <= 0 || newHeight > height {
+			newHeight = height
+		}
+		if size.width <= 0 {
+			newWidth = int(float64(newHeight) * float64(width) / float64(height))
+		}
+		if size.height <= 0 {
+			newHeight = int(float64(newWidth) * float64(height) / float64(width))
+		}
+
+		// crop the center of the original image with the ratio of the size
+		ratio := math.Max(float64(newWidth)/float64(width), float64(newHeight)/float64(height))
+		cropWidth := int(math.Round(float64(newWidth) / ratio))
+		cropHeight := int(math.Round(float64(newHeight) / ratio))
+		x := (width - cropWidth) / 2
+		y := (height - cropHeight) / 2
+		src = image.Rect(x, y, x+cropWidth, y+cropHeight)
+	} else {
+		newWidth, newHeight = constrainDimensions(width, height, size.width, size.height)
+	}
+
+	if newWidth >= width && newHeight >= height {
+		return image.Rectangle{}, 0, 0, false
+	}
+
+	return src, newWidth, newHeight, true
+}
+
+// resizeImage scales down the area of the source image to the width and height by averaging the source pixels covered by each pixel
+func resizeImage(src *image.RGBA, area image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	areaWidth, areaHeight := area.Dx(), area.Dy()
+
+	for dy := 0; dy < height; dy++ {
+		y0 := area.Min.Y + dy*areaHeight/height
+		y1 := area.Min.Y + (dy+1)*areaHeight/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < width; dx++ {
+			x0 := area.Min.X + dx*areaWidth/width
+			x1 := area.Min.X + (dx+1)*areaWidth/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count uint32
+			for y := y0; y < y1; y++ {
+				offset := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					offset += 4
+					count++
+				}
+			}
+
+			offset := dst.PixOffset(dx, dy)
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return dst
+}
+
+// encodeImage writes image in the format of the mime type
+func encodeImage(w io.Writer, img image.Image, mimeType string) error {
+	switch mimeType {
+	case "image/jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		return png.Encode(w, img)
+	case "image/gif":
+		return gif.Encode(w, img, nil)
+	}
+	return fmt.Errorf("unsupported image type %s", mimeType)
+}
+
+// isEditableImage returns true if the image mime type can be decoded to generate image sizes
+func isEditableImage(mimeType string) bool {
+	return mimeType == "image/jpeg" || mimeType == "image/png" || mimeType == "image/gif"
+}
+
+// sizeFileName returns file name of the image size like WP image editor does, e.g. photo-150x150.jpg
+func sizeFileName(fileName string, width, height int) string {
+	ext := filepath.Ext(fileName)
+	return fmt.Sprintf("%s-%dx%d%s", strings.TrimSuffix(fileName, ext), width, height, ext)
+}
+
+// generateImageSizes decodes the image data and returns media details with generated image sizes.
+// Each size file is passed to save, so the caller decides where the file is written. Dimensions are read from the header
+// before the image is decoded, and ErrImageTooLarge is returned if the image has more than maxImagePixels
+func generateImageSizes(data []byte, file string, mimeType string, sizes []imageSize, save func(fileName string, data []byte) error) (*model.MediaDetails, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("image has invalid dimensions %dx%d", config.Width, config.Height)
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, model.ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	details := &model.MediaDetails{
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+		File:      file,
+		ImageMeta: &model.ImageMeta{},
+		Sizes:     map[string]*model.ImageSize{},
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	for _, size := range sizes {
+		area, width, height, ok := resizeDimensions(details.Width, details.Height, size)
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err = encodeImage(&buf, resizeImage(rgba, area, width, height), mimeType); err != nil {
+			return nil, err
+		}
+
+		fileName := sizeFileName(filepath.Base(file), width, height)
+		if err = save(fileName, buf.Bytes()); err != nil {
+			return nil, err
+		}
+
+		details.Sizes[size.name] = &model.ImageSize{File: fileName, Width: width, Height: height, MimeType: mimeType}
+	}
+
+	return details, nil
+}
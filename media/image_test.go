@@ -0,0 +1,74 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeDimensions(t *testing.T) {
+	thumbnail := imageSize{name: "thumbnail", width: 150, height: 150, crop: true}
+	medium := imageSize{name: "medium", width: 300, height: 300}
+	mediumLarge := imageSize{name: "medium_large", width: 768}
+
+	// cropped size takes the center of the image
+	area, width, height, ok := resizeDimensions(400, 300, thumbnail)
+	assert.True(t, ok)
+	assert.Equal(t, image.Rect(50, 0, 350, 300), area)
+	assert.Equal(t, 150, width)
+	assert.Equal(t, 150, height)
+
+	// size without crop keeps the ratio
+	area, width, height, ok = resizeDimensions(400, 300, medium)
+	assert.True(t, ok)
+	assert.Equal(t, image.Rect(0, 0, 400, 300), area)
+	assert.Equal(t, 300, width)
+	assert.Equal(t, 225, height)
+
+	// size with zero height only limits the width
+	_, width, height, ok = resizeDimensions(1600, 1200, mediumLarge)
+	assert.True(t, ok)
+	assert.Equal(t, 768, width)
+	assert.Equal(t, 576, height)
+
+	// image that is not larger than the size is not resized
+	_, _, _, ok = resizeDimensions(400, 300, mediumLarge)
+	assert.False(t, ok)
+}
+
+// pngWithDimensions returns png of one pixel whose header declares the width and height
+func pngWithDimensions(t *testing.T, width, height uint32) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// IHDR chunk follows 8 bytes of signature, its data starts after length and type
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:4], width)
+	binary.BigEndian.PutUint32(ihdr[4:8], height)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+	return data
+}
+
+func TestGenerateImageSizes_TooLarge(t *testing.T) {
+	saved := 0
+	save := func(fileName string, data []byte) error {
+		saved++
+		return nil
+	}
+
+	_, err := generateImageSizes(pngWithDimensions(t, 100000, 100000), "2020/01/bomb.png", "image/png", defaultImageSizes, save)
+	assert.Equal(t, model.ErrImageTooLarge, err)
+	assert.Equal(t, 0, saved)
+
+	details, err := generateImageSizes(pngWithDimensions(t, 1, 1), "2020/01/pixel.png", "image/png", defaultImageSizes, save)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, details.Width)
+}
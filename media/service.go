@@ -0,0 +1,304 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/shared"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	attachedFileMetaKey       = "_wp_attached_file"
+	attachmentMetadataMetaKey = "_wp_attachment_metadata"
+	attachmentAltMetaKey      = "_wp_attachment_image_alt"
+)
+
+// sniffedImageTypes are image mime types that are detected from file content by http.DetectContentType,
+// uploaded file with one of these types must have content of the same type
+var sniffedImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/bmp", "image/x-icon"}
+
+// Service is interface for media functions
+type Service interface {
+	UploadMedia(ctx context.Context, req model.UploadMediaRequest) (interface{}, error)
+}
+
+// service is struct that will implement Service interface and store related repositories
+type service struct {
+	post   post.Repository
+	shared shared.Repository
+}
+
+// NewService is a simple helper function to create a service instance
+func NewService(postRepo post.Repository, sharedRepo shared.Repository) Service {
+	return &service{
+		post:   postRepo,
+		shared: sharedRepo,
+	}
+}
+
+// validateFileType returns mime type of the file from its extension, it returns ErrInvalidFileType if the extension is not registered
+// in model.MimeTypes or the file content doesn't match the image type of the extension
+func validateFileType(fileName string, data []byte) (string, error) {
+	mimeType := model.MimeTypeByExtension(fileName)
+	if mimeType == "" {
+		return "", model.ErrInvalidFileType
+	}
+
+	for _, imageType := range sniffedImageTypes {
+		if mimeType == imageType && http.DetectContentType(data) != mimeType {
+			return "", model.ErrInvalidFileType
+		}
+	}
+
+	return mimeType, nil
+}
+
+// createUniqueFile creates new file in the directory with unique name like wp_unique_filename does by adding numeric suffix
+// if the file name is already used, e.g. photo-1.jpg
+func createUniqueFile(dir string, fileName string) (*os.File, string, error) {
+	ext := filepath.Ext(fileName)
+	name := strings.TrimSuffix(fileName, ext)
+	candidate := fileName
+
+	for suffix := 1; ; suffix++ {
+		file, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return file, candidate, nil
+		}
+		if !os.IsExist(err) {
+			return nil, "", err
+		}
+		candidate = name + "-" + strconv.Itoa(suffix) + ext
+	}
+}
+
+// removeFiles removes written files of upload that fails
+func removeFiles(files []string) {
+	for _, file := range files {
+		os.Remove(file)
+	}
+}
+
+// UploadMedia stores uploaded file under year and month folder of upload directory with generated image sizes
+// and creates the attachment with '_wp_attached_file' and '_wp_attachment_metadata' post metas
+func (s *service) UploadMedia(ctx context.Context, req model.UploadMediaRequest) (interface{}, error) {
+	if len(req.Data) == 0 {
+		return nil, model.ErrNoUploadData
+	}
+	if req.ContentType == "" {
+		return nil, model.ErrNoContentType
+	}
+	fileName := model.SanitizeFileName(req.FileName)
+	if fileName == "" {
+		return nil, model.ErrNoContentDisposition
+	}
+
+	mimeType, err := validateFileType(fileName, req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := post.NewPostRecord(ctx, s.shared, model.AttachmentType)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": fileName,
+			"func":   "post.NewPostRecord",
+		}).Errorf("Failed to create new attachment record: %s", err)
+		return nil, err
+	}
+	record.Status = model.InheritStatus
+	record.MimeType = mimeType
+
+	if req.Post != nil && *req.Post != 0 {
+		parent, err := s.post.PostRecordByID(ctx, *req.Post)
+		if err == sql.ErrNoRows || (err == nil && (parent.Type == model.RevisionType || parent.Type == model.AttachmentType)) {
+			return nil, model.NewParamError("post", "Invalid parent type.")
+		}
+		if err != nil {
+			return nil, err
+		}
+		record.Parent = parent.ID
+	}
+
+	title := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	if req.Title != nil {
+		title = *req.Title
+	}
+	writeRequest := model.WritePostRequest{
+		Type:    model.AttachmentType,
+		Author:  req.Author,
+		Title:   &title,
+		Content: req.Description,
+		Excerpt: req.Caption,
+		Meta:    map[string]string{},
+	}
+	if req.AltText != nil {
+		writeRequest.Meta[attachmentAltMetaKey] = *req.AltText
+	}
+
+	loc, err := post.SiteLocation(ctx, s.shared)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"func": "post.SiteLocation",
+		}).Errorf("Failed to get site location: %s", err)
+		return nil, err
+	}
+	now := time.Now()
+	if err = post.ApplyWriteRequest(record, writeRequest, loc, now); err != nil {
+		return nil, err
+	}
+
+	sizes, err := registeredImageSizes(ctx, s.shared)
+	if err != nil {
+		return nil, err
+	}
+
+	// files are written before the attachment is inserted and they are removed if the attachment can't be saved
+	details, files, err := s.storeFiles(ctx, now.In(loc).Format("2006/01"), fileName, mimeType, req.Data, sizes)
+	if err != nil {
+		return nil, err
+	}
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	record.GUID = fmt.Sprintf("%s/%s/%s", config.SiteURL, config.UploadPath, details.File)
+	writeRequest.Meta[attachedFileMetaKey] = details.File
+	if details.Width > 0 {
+		metadata, err := details.Serialize()
+		if err != nil {
+			removeFiles(files)
+			return nil, err
+		}
+		writeRequest.Meta[attachmentMetadataMetaKey] = metadata
+	}
+
+	err = s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		return post.SaveRecord(ctx, s.post, s.shared, record, nil, writeRequest)
+	})
+	if err != nil {
+		removeFiles(files)
+		log.WithFields(log.Fields{
+			"params": details.File,
+			"func":   "s.shared.WithTransaction",
+		}).Errorf("Failed to save attachment: %s", err)
+		return nil, err
+	}
+
+	return newMedia(ctx, record, details, writeRequest.Meta[attachmentAltMetaKey]), nil
+}
+
+// storeFiles writes the file and its generated image sizes to subdirectory of upload directory,
+// it returns media details with file path relative to upload directory and path of all written files
+func (s *service) storeFiles(ctx context.Context, subdir string, fileName string, mimeType string, data []byte, sizes []imageSize) (*model.MediaDetails, []string, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	dir := filepath.Join(config.UploadDir, filepath.FromSlash(subdir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.WithFields(log.Fields{
+			"params": dir,
+			"func":   "os.MkdirAll",
+		}).Errorf("Failed to create upload directory: %s", err)
+		return nil, nil, err
+	}
+
+	file, fileName, err := createUniqueFile(dir, fileName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": fileName,
+			"func":   "createUniqueFile",
+		}).Errorf("Failed to create upload file: %s", err)
+		return nil, nil, err
+	}
+	files := []string{file.Name()}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		removeFiles(files)
+		log.WithFields(log.Fields{
+			"params": fileName,
+			"func":   "file.Write",
+		}).Errorf("Failed to write upload file: %s", err)
+		return nil, nil, err
+	}
+
+	relativePath := subdir + "/" + fileName
+	if !isEditableImage(mimeType) {
+		return &model.MediaDetails{File: relativePath}, files, nil
+	}
+
+	details, err := generateImageSizes(data, relativePath, mimeType, sizes, func(sizeFileName string, sizeData []byte) error {
+		path := filepath.Join(dir, sizeFileName)
+		files = append(files, path)
+		return ioutil.WriteFile(path, sizeData, 0644)
+	})
+	if err != nil {
+		removeFiles(files)
+		log.WithFields(log.Fields{
+			"params": fileName,
+			"func":   "generateImageSizes",
+		}).Errorf("Failed to generate image sizes: %s", err)
+		if err == model.ErrImageTooLarge {
+			return nil, nil, err
+		}
+		return nil, nil, model.ErrInvalidFileType
+	}
+
+	return details, files, nil
+}
+
+// newMedia returns media response of the attachment record
+func newMedia(ctx context.Context, record *model.PostRecord, details *model.MediaDetails, altText string) *model.Media {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	uploadURL := config.SiteURL + "/" + config.UploadPath + "/"
+
+	media := &model.Media{}
+	media.ID = record.ID
+	media.Date = strfmt.DateTime(record.Date)
+	media.Slug = record.Name
+	media.Type = record.Type
+	media.Link = fmt.Sprintf("%s/?attachment_id=%d", config.SiteURL, record.ID)
+	media.Title = &model.Rendered{Rendered: &record.Title}
+	media.Author = record.Author
+	media.CommentStatus = record.CommentStatus
+	media.PingStatus = record.PingStatus
+	media.Status = record.Status
+	media.GUID = &model.Rendered{Rendered: &record.GUID}
+	dateGmt, modified, modifiedGmt := strfmt.DateTime(record.DateGmt), strfmt.DateTime(record.Modified), strfmt.DateTime(record.ModifiedGmt)
+	media.DateGmt, media.Modified, media.ModifiedGmt = &dateGmt, &modified, &modifiedGmt
+	media.Meta = []map[string]string{}
+	media.Description = &model.Rendered{Rendered: &record.Content}
+	media.Caption = &model.Rendered{Rendered: &record.Excerpt}
+	media.Post = &record.Parent
+	media.AltText = altText
+	media.MimeType = record.MimeType
+	media.MediaType = "file"
+	media.SourceURL = record.GUID
+
+	if strings.HasPrefix(record.MimeType, "image/") {
+		media.MediaType = "image"
+	}
+
+	if details.Sizes != nil {
+		dir := uploadURL + strings.TrimSuffix(details.File, filepath.Base(details.File))
+		for _, size := range details.Sizes {
+			size.SourceURL = dir + size.File
+		}
+		details.Sizes["full"] = &model.ImageSize{File: filepath.Base(details.File), Width: details.Width, Height: details.Height,
+			MimeType: record.MimeType, SourceURL: record.GUID}
+	}
+	media.MediaDetails = details
+
+	return media
+}
@@ -0,0 +1,112 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockpost "github.com/qreasio/restlr/post/mock"
+	mockshared "github.com/qreasio/restlr/shared/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/yvasiyarov/php_session_decoder/php_serialize"
+)
+
+func newPNG(width, height int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+func TestService_UploadMedia(t *testing.T) {
+	uploadDir, err := ioutil.TempDir("", "restlr-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(uploadDir)
+
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", UploadPath: "uploads", UploadDir: uploadDir})
+	ctrl := gomock.NewController(t)
+
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+	postRepoMock.EXPECT().UniquePostSlug(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, slug string, record *model.PostRecord) (string, error) {
+		return slug, nil
+	}).AnyTimes()
+	postRepoMock.EXPECT().InsertPost(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, record *model.PostRecord) (uint64, error) {
+		record.ID = 10
+		return record.ID, nil
+	}).AnyTimes()
+	postRepoMock.EXPECT().UpdatePost(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	var metas map[string]string
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().LoadOption(ctx, gomock.Any()).Return(nil, sql.ErrNoRows).AnyTimes()
+	sharedRepoMock.EXPECT().WithTransaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	sharedRepoMock.EXPECT().UpdatePostMetas(ctx, uint64(10), gomock.Any()).DoAndReturn(func(ctx context.Context, postID uint64, m map[string]string) error {
+		metas = m
+		return nil
+	}).AnyTimes()
+
+	s := NewService(postRepoMock, sharedRepoMock)
+	author := uint64(1)
+	req := model.UploadMediaRequest{FileName: "My Photo.png", ContentType: "image/png", Data: newPNG(400, 300), Author: &author}
+
+	res, err := s.UploadMedia(ctx, req)
+	assert.Nil(t, err)
+	media := res.(*model.Media)
+	file := metas[attachedFileMetaKey]
+	assert.Regexp(t, `^\d{4}/\d{2}/My-Photo\.png$`, file)
+	assert.Equal(t, "http://example.com/uploads/"+file, *media.GUID.Rendered)
+	assert.Equal(t, "My-Photo", *media.Title.Rendered)
+	assert.Equal(t, "image", media.MediaType)
+
+	// thumbnail is cropped, medium keeps the ratio and larger sizes are not generated
+	dir := filepath.Join(uploadDir, filepath.Dir(filepath.FromSlash(file)))
+	for _, name := range []string{"My-Photo.png", "My-Photo-150x150.png", "My-Photo-300x225.png"} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		assert.Nil(t, err, name)
+	}
+	assert.Len(t, media.MediaDetails.Sizes, 3)
+
+	metadata, err := php_serialize.NewUnSerializer(metas[attachmentMetadataMetaKey]).Decode()
+	assert.Nil(t, err)
+	metadataArray := metadata.(php_serialize.PhpArray)
+	assert.Equal(t, 400, metadataArray["width"])
+	assert.Equal(t, 300, metadataArray["height"])
+	assert.Equal(t, file, metadataArray["file"])
+	assert.Len(t, metadataArray["sizes"], 2)
+
+	// uploading the same file name again gets unique file name
+	_, err = s.UploadMedia(ctx, req)
+	assert.Nil(t, err)
+	assert.Regexp(t, `/My-Photo-1\.png$`, metas[attachedFileMetaKey])
+
+	// file extension must be registered and image content must match the extension
+	for _, fileName := range []string{"shell.php", "page.html", "page.htm", "script.js"} {
+		req.FileName = fileName
+		_, err = s.UploadMedia(ctx, req)
+		assert.Equal(t, model.ErrInvalidFileType, err)
+	}
+
+	req.FileName, req.Data = "fake.jpg", []byte("<?php echo 1;")
+	_, err = s.UploadMedia(ctx, req)
+	assert.Equal(t, model.ErrInvalidFileType, err)
+
+	// image with huge dimensions is rejected before it is decoded and its file is removed
+	req.FileName, req.Data = "bomb.png", pngWithDimensions(t, 100000, 100000)
+	_, err = s.UploadMedia(ctx, req)
+	assert.Equal(t, model.ErrImageTooLarge, err)
+	_, err = os.Stat(filepath.Join(dir, "bomb.png"))
+	assert.True(t, os.IsNotExist(err))
+
+	req.FileName = ""
+	_, err = s.UploadMedia(ctx, req)
+	assert.Equal(t, model.ErrNoContentDisposition, err)
+}
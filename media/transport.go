@@ -0,0 +1,42 @@
+package media
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// MakeHTTPHandler returns http handler that makes a set of endpoints available on predefined paths
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	UploadMediaHandler := kithttp.NewServer(
+		instrument("media.UploadMedia", makeUploadMediaEndpoint(s)),
+		uploadMediaRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/", UploadMediaHandler)
+
+	return r
+}
+
+func uploadMediaRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var uploadRequest model.UploadMediaRequest
+	if err := resthttp.DecodeUpload(r, &uploadRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Header,
+			"func":   "resthttp.DecodeUpload",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	return uploadRequest, nil
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	kitmetrics "github.com/go-kit/kit/metrics"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qreasio/restlr/batch"
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/shared"
+)
+
+// unmatchedRoute is route label of requests that match no route
+const unmatchedRoute = "unmatched"
+
+// DefaultBuckets are upper bounds of histogram buckets of durations in seconds
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics are metrics of requests, endpoints and queries of the API in the Prometheus registry
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests         kitmetrics.Counter
+	requestDuration  kitmetrics.Histogram
+	endpointDuration kitmetrics.Histogram
+	queries          kitmetrics.Counter
+	queryDuration    kitmetrics.Histogram
+}
+
+// New returns metrics of the API that are registered in the registry with Go runtime and process metrics
+func New(r *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		Registry: r,
+		requests: newCounter(r, "restlr_http_requests_total",
+			"Number of HTTP requests by route template, method and status.", "route", "method", "status"),
+		requestDuration: newHistogram(r, "restlr_http_request_duration_seconds",
+			"Duration of HTTP requests by route template, method and status.", "route", "method", "status"),
+		endpointDuration: newHistogram(r, "restlr_endpoint_duration_seconds",
+			"Duration of go-kit endpoints by endpoint and whether they succeeded.", "endpoint", "success"),
+		queries: newCounter(r, "restlr_db_queries_total",
+			"Number of database queries by repository method and whether they succeeded.", "method", "success"),
+		queryDuration: newHistogram(r, "restlr_db_query_duration_seconds",
+			"Duration of database queries by repository method.", "method"),
+	}
+	r.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// newCounter returns go-kit counter of Prometheus counter with the label names that is registered in the registry
+func newCounter(r *prometheus.Registry, name string, help string, labelNames ...string) kitmetrics.Counter {
+	cv := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames)
+	r.MustRegister(cv)
+	return kitprometheus.NewCounter(cv)
+}
+
+// newHistogram returns go-kit histogram of Prometheus histogram with DefaultBuckets that is registered in the registry
+func newHistogram(r *prometheus.Registry, name string, help string, labelNames ...string) kitmetrics.Histogram {
+	hv := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: DefaultBuckets}, labelNames)
+	r.MustRegister(hv)
+	return kitprometheus.NewHistogram(hv)
+}
+
+// Handler returns handler that serves metrics of the registry to Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts requests and observes their duration by route template like '/wp-json/wp/v2/posts/{id}', method and status,
+// sub requests of batch are part of the batch request and are not counted
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if batch.IsSubRequest(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := unmatchedRoute
+		if rctx, ok := r.Context().Value(chi.RouteCtxKey).(*chi.Context); ok && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		labels := []string{"route", route, "method", r.Method, "status", strconv.Itoa(sw.status)}
+		m.requests.With(labels...).Add(1)
+		m.requestDuration.With(labels...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Endpoint returns middleware that observes duration of the named endpoint, it is EndpointMiddleware of the API handlers
+func (m *Metrics) Endpoint(name string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				m.endpointDuration.With("endpoint", name, "success", strconv.FormatBool(err == nil)).Observe(time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// QueryHook returns hook that counts queries and observes their duration by repository method, it is added to shared.QueryHooks
+func (m *Metrics) QueryHook() shared.QueryHook {
+	return func(ctx context.Context, d dialect.Dialect, method string, query string) func(err error) {
+		start := time.Now()
+		return func(err error) {
+			m.queries.With("method", method, "success", strconv.FormatBool(err == nil)).Add(1)
+			m.queryDuration.With("method", method).Observe(time.Since(start).Seconds())
+		}
+	}
+}
+
+// RegisterDB adds pool stats of the db from sql.DB.Stats() as go_sql_* metrics with db_name "restlr"
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, "restlr"))
+}
+
+// RegisterCache adds hit, miss and size stats of the cache, hit ratio is ratio of hits of all lookups since start
+func (m *Metrics) RegisterCache(c *cache.Cache) {
+	counter := func(name string, help string, fn func(stats cache.Stats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 {
+			return float64(fn(c.Stats()))
+		})
+	}
+	gauge := func(name string, help string, fn func(stats cache.Stats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, func() float64 {
+			return fn(c.Stats())
+		})
+	}
+
+	m.Registry.MustRegister(
+		counter("restlr_cache_hits_total", "Number of cache lookups that found cached value.",
+			func(stats cache.Stats) uint64 { return stats.Hits }),
+		counter("restlr_cache_misses_total", "Number of cache lookups that loaded the value.",
+			func(stats cache.Stats) uint64 { return stats.Misses }),
+		counter("restlr_cache_shared_loads_total", "Number of cache misses that shared load of concurrent lookup.",
+			func(stats cache.Stats) uint64 { return stats.Shared }),
+		counter("restlr_cache_invalidations_total", "Number of times cached values are made stale.",
+			func(stats cache.Stats) uint64 { return stats.Invalidations }),
+		gauge("restlr_cache_hit_ratio", "Ratio of cache lookups that found cached value.",
+			func(stats cache.Stats) float64 {
+				if stats.Hits+stats.Misses == 0 {
+					return 0
+				}
+				return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+			}),
+		gauge("restlr_cache_entries", "Number of cached values.",
+			func(stats cache.Stats) float64 { return float64(stats.Entries) }),
+		gauge("restlr_cache_size_bytes", "Size of cached values.",
+			func(stats cache.Stats) float64 { return float64(stats.Size) }),
+	)
+}
+
+// statusWriter records status code of response that is written to the wrapped ResponseWriter
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
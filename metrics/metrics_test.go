@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+// written returns metrics that are served by the handler of the metrics in text exposition format
+func written(m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	router := chi.NewRouter()
+	router.Use(m.Middleware)
+	router.Get("/wp-json/wp/v2/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-json/wp/v2/posts/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-json/wp/v2/posts/2", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	out := written(m)
+	assert.Contains(t, out, `restlr_http_requests_total{method="GET",route="/wp-json/wp/v2/posts/{id}",status="404"} 2`)
+	assert.Contains(t, out, `restlr_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, out, `restlr_http_request_duration_seconds_count{method="GET",route="/wp-json/wp/v2/posts/{id}",status="404"} 2`)
+	assert.Contains(t, out, "go_goroutines ")
+}
+
+func TestMetrics_EndpointAndQueryHook(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	e := m.Endpoint("post.ListPosts")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	_, err := e(context.Background(), nil)
+	assert.Error(t, err)
+
+	done := m.QueryHook()(context.Background(), dialect.MySQL, "post.QueryPosts", "SELECT 1")
+	done(nil)
+
+	out := written(m)
+	assert.Contains(t, out, `restlr_endpoint_duration_seconds_count{endpoint="post.ListPosts",success="false"} 1`)
+	assert.Contains(t, out, `restlr_db_queries_total{method="post.QueryPosts",success="true"} 1`)
+	assert.Contains(t, out, `restlr_db_query_duration_seconds_count{method="post.QueryPosts"} 1`)
+}
+
+func TestMetrics_RegisterDBAndCache(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m.RegisterDB(db)
+	c := cache.New(cache.NewLRUStore(1024), time.Minute)
+	m.RegisterCache(c)
+
+	for i := 0; i < 2; i++ {
+		var v string
+		assert.NoError(t, c.Fetch(context.Background(), "key", &v, func(context.Context) (interface{}, error) { return "value", nil }))
+	}
+
+	out := written(m)
+	assert.Contains(t, out, `go_sql_max_open_connections{db_name="restlr"} 0`)
+	assert.Contains(t, out, "restlr_cache_hits_total 1")
+	assert.Contains(t, out, "restlr_cache_misses_total 1")
+	assert.Contains(t, out, "restlr_cache_hit_ratio 0.5")
+	assert.Contains(t, out, "restlr_cache_entries 1")
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+const (
+	// BatchValidationNormal runs every request of batch independently
+	BatchValidationNormal = "normal"
+	// BatchValidationRequireAll applies requests of batch only if all of them succeed
+	BatchValidationRequireAll = "require-all-validate"
+	// BatchMaxRequests is the maximum number of requests in a batch like WP Rest API allows
+	BatchMaxRequests = 25
+)
+
+// BatchRequest represents request body of batch endpoint, Header and RemoteAddr are taken from the http request
+// and passed to every sub request
+type BatchRequest struct {
+	Validation string             `json:"validation"`
+	Requests   []*BatchSubRequest `json:"requests"`
+	Header     map[string][]string
+	RemoteAddr string
+}
+
+// BatchSubRequest represents single request of batch, path is relative to rest api root, e.g. /wp/v2/posts
+type BatchSubRequest struct {
+	Method  string                  `json:"method"`
+	Path    string                  `json:"path"`
+	Headers map[string]HeaderValues `json:"headers"`
+	Body    json.RawMessage         `json:"body"`
+}
+
+// HeaderValues stores value of sub request header that can be sent as string or array of strings
+type HeaderValues []string
+
+// UnmarshalJSON decodes header value from string or array of strings
+func (h *HeaderValues) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*h = HeaderValues{value}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*h = values
+	return nil
+}
+
+// BatchResponse represents response of batch endpoint, Failed is set to "validation" if requests are not applied
+// because one of them fails in require-all-validate mode
+type BatchResponse struct {
+	Failed    string              `json:"failed,omitempty"`
+	Responses []*BatchSubResponse `json:"responses"`
+}
+
+// BatchSubResponse represents response of single request in batch
+type BatchSubResponse struct {
+	Body    json.RawMessage   `json:"body"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+}
@@ -14,12 +14,42 @@ type Comment struct {
 	Author          uint64           `json:"author"`
 	AuthorName      string           `json:"author_name"`
 	AuthorAvatarURL string           `json:"author_url"`
-	Date            time.Time        `json:"date""`
+	Date            time.Time        `json:"date"`
 	Content         *ContentRendered `json:"content"`
 	Link            string           `json:"link"`
 	Type            string           `json:"type"`
 	Links           *CommentLink     `json:"_links"`
-	PostID          *uint64          `json:"post_id,omitempty"`
+	PostID          *uint64          `json:"post,omitempty"`
+	Status          string           `json:"status,omitempty"`
+
+	// private attributes that are stored for new comment and never returned
+	AuthorEmail string    `json:"-"`
+	AuthorIP    string    `json:"-"`
+	AuthorAgent string    `json:"-"`
+	DateGmt     time.Time `json:"-"`
+	Approved    string    `json:"-"`
+}
+
+const (
+	// CommentApproved is comment_approved value of approved comment
+	CommentApproved = "1"
+	// CommentHold is comment_approved value of comment that waits for moderation
+	CommentHold = "0"
+	// CommentSpam is comment_approved value of spam comment
+	CommentSpam = "spam"
+	// CommentTrash is comment_approved value of trashed comment
+	CommentTrash = "trash"
+)
+
+// CommentStatus returns status of comment in Rest API response from comment_approved column value
+func CommentStatus(approved string) string {
+	switch approved {
+	case CommentApproved:
+		return "approved"
+	case CommentHold:
+		return "hold"
+	}
+	return approved
 }
 
 // CommentLink is to represents _links in EmbedPostComment
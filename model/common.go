@@ -15,12 +15,33 @@ const (
 	MediaType = "media"
 	// PageType stores string value to define media type
 	PageType = "page"
+	// AttachmentType stores post_type value of media in posts table
+	AttachmentType = "attachment"
 	// EmbedContext stores 'embed' value of context request parameter
 	EmbedContext = "embed"
 	// StandardFormat stores value for standard format
 	StandardFormat = "standard"
+	// RevisionType stores string value to define revision post type
+	RevisionType = "revision"
+	// PublishStatus stores post status value of published post
+	PublishStatus = "publish"
+	// FutureStatus stores post status value of scheduled post
+	FutureStatus = "future"
+	// DraftStatus stores post status value of draft post
+	DraftStatus = "draft"
+	// PendingStatus stores post status value of post that is pending review
+	PendingStatus = "pending"
+	// PrivateStatus stores post status value of private post
+	PrivateStatus = "private"
+	// TrashStatus stores post status value of post in trash
+	TrashStatus = "trash"
+	// InheritStatus stores post status value of revision and attachment
+	InheritStatus = "inherit"
 )
 
+// WritableStatuses is list of post status that can be set from request
+var WritableStatuses = []string{PublishStatus, FutureStatus, DraftStatus, PendingStatus, PrivateStatus}
+
 // Base is struct that represent base of post, page, media data that also usually used inside _embed
 type Base struct {
 	// Unique identifier for the object.
@@ -68,7 +89,17 @@ type APIConfig struct {
 	APIPath            string
 	Version            string
 	UploadPath         string
+	UploadDir          string
 	APIBaseURL         string
+	WriteAPIKey        string
+	// BaseTablePrefix is table prefix of network-wide tables of multisite like users, TablePrefix is used if it is empty
+	BaseTablePrefix string
+}
+
+// DeletedItem represents response of permanently deleted item with the item data before it is deleted
+type DeletedItem struct {
+	Deleted  bool        `json:"deleted"`
+	Previous interface{} `json:"previous"`
 }
 
 // ContentRendered represents content in post json response
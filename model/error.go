@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 )
 
 // ErrInvalidPostID for invalid post id error
@@ -12,3 +13,103 @@ var ErrInvalidParameter = errors.New("invalid parameter")
 
 // ErrInvalidRoute for invalid route error
 var ErrInvalidRoute = errors.New("no route was found matching the URL and request method")
+
+// ErrIncorrectPassword for incorrect post password error
+var ErrIncorrectPassword = errors.New("incorrect post password")
+
+// ErrAlreadyTrashed for deleting post that has been moved to trash without force parameter
+var ErrAlreadyTrashed = errors.New("post has already been trashed")
+
+// ErrNoUploadData for upload request without file data
+var ErrNoUploadData = errors.New("no upload data supplied")
+
+// ErrNoContentDisposition for raw body upload request without file name in Content-Disposition header
+var ErrNoContentDisposition = errors.New("no content disposition supplied")
+
+// ErrNoContentType for raw body upload request without Content-Type header
+var ErrNoContentType = errors.New("no content type supplied")
+
+// ErrInvalidFileType for uploaded file with extension or content that is not allowed
+var ErrInvalidFileType = errors.New("file type is not permitted")
+
+// ErrImageTooLarge for uploaded image whose dimensions are larger than can be decoded to generate image sizes
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// ErrCommentLoginRequired for anonymous comment on site that only allows registered user to comment
+var ErrCommentLoginRequired = errors.New("login is required to comment")
+
+// ErrCommentAuthorDataRequired for anonymous comment without author name or email on site that requires them
+var ErrCommentAuthorDataRequired = errors.New("comment author name and email are required")
+
+// ErrCommentContentInvalid for comment with empty content
+var ErrCommentContentInvalid = errors.New("invalid comment content")
+
+// ErrCommentInvalidPostID for comment on post that doesn't exist
+var ErrCommentInvalidPostID = errors.New("invalid comment post id")
+
+// ErrCommentDraftPost for comment on draft post
+var ErrCommentDraftPost = errors.New("comment on draft post")
+
+// ErrCommentTrashPost for comment on trashed post
+var ErrCommentTrashPost = errors.New("comment on trashed post")
+
+// ErrCommentCannotReadPost for comment on post that is not public or is password protected without the correct password
+var ErrCommentCannotReadPost = errors.New("comment post cannot be read")
+
+// ErrCommentClosed for comment on post that doesn't accept comment
+var ErrCommentClosed = errors.New("comments are closed")
+
+// ErrCommentDuplicate for comment that has been submitted before
+var ErrCommentDuplicate = errors.New("duplicate comment")
+
+// ErrCommentFlood for comment that is submitted too soon after previous comment from the same author
+var ErrCommentFlood = errors.New("comment flood")
+
+// ErrInvalidTermID for term that doesn't exist in the taxonomy
+var ErrInvalidTermID = errors.New("invalid term id")
+
+// ErrEmptyTermName for creating term without name
+var ErrEmptyTermName = errors.New("term name is required")
+
+// ErrTermExists for creating term with name that is already used under the same parent
+var ErrTermExists = errors.New("term already exists")
+
+// ErrDuplicateTermSlug for updating term with slug that is already used by another term of the taxonomy
+var ErrDuplicateTermSlug = errors.New("duplicate term slug")
+
+// ErrTrashNotSupported for deleting term without force parameter because terms can't be trashed
+var ErrTrashNotSupported = errors.New("terms do not support trashing")
+
+// ErrCannotDeleteDefaultTerm for deleting default category
+var ErrCannotDeleteDefaultTerm = errors.New("default term cannot be deleted")
+
+// ParamError represents error of invalid request parameter with the reason
+type ParamError struct {
+	Param   string
+	Message string
+}
+
+// NewParamError returns new ParamError
+func NewParamError(param string, message string) *ParamError {
+	return &ParamError{Param: param, Message: message}
+}
+
+// Error returns error message of ParamError
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid parameter %s: %s", e.Param, e.Message)
+}
+
+// ErrInvalidPostType for post type that isn't built in or registered
+var ErrInvalidPostType = errors.New("invalid post type")
+
+// ErrInvalidTaxonomy for taxonomy that isn't built in or registered
+var ErrInvalidTaxonomy = errors.New("invalid taxonomy")
+
+// ErrInvalidStatus for post status that doesn't exist
+var ErrInvalidStatus = errors.New("invalid status")
+
+// ErrCannotReadStatus for post status that isn't public and is requested without write access
+var ErrCannotReadStatus = errors.New("status cannot be read")
+
+// ErrInvalidPageNumber for list request with page that is larger than the number of pages
+var ErrInvalidPageNumber = errors.New("invalid page number")
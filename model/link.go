@@ -45,7 +45,7 @@ type EmbeddableLink struct {
 
 // AboutPrefix returns prefix for about url
 func (t *LinkURL) AboutPrefix() string {
-	if t.Type == "category" || t.Type == "post_tag" {
+	if _, ok := RegisteredTaxonomy(t.Type); ok || t.Type == "category" || t.Type == "post_tag" {
 		return "taxonomies"
 	}
 	return "types"
@@ -68,7 +68,6 @@ func (t *LinkURL) About() string {
 
 // Self returns full url path for self
 func (t *LinkURL) Self(id string) string {
-	return t.BaseURL + "/categories/" + id
 	return fmt.Sprintf("%s/%s/%s", t.BaseURL, Plural(t.Type), id)
 }
 
@@ -108,6 +107,11 @@ func (t *LinkURL) Tags(id string) string {
 	return fmt.Sprintf("%s/tags?post=%s", t.BaseURL, id)
 }
 
+// Terms returns full url path for terms of the taxonomy that are assigned to the post
+func (t *LinkURL) Terms(taxonomy string, id string) string {
+	return fmt.Sprintf("%s/%s?post=%s", t.BaseURL, Plural(taxonomy), id)
+}
+
 // Curies returns full url path for curies
 func (t *LinkURL) Curies() string {
 	return "https://api.w.org/{rel}"
@@ -146,3 +150,21 @@ func GetTermLinks(postType string, baseURL string, id string) *TermLink {
 
 	return tLink
 }
+
+// GetSchemaLinks returns SchemaLink of post type or taxonomy, collection is the collection of types or taxonomies
+// and items is the collection of its posts or terms
+func GetSchemaLinks(baseURL string, collection string, restBase string) *SchemaLink {
+	return &SchemaLink{
+		Collection: []map[string]string{HrefMap(fmt.Sprintf("%s/%s", baseURL, collection))},
+		Items:      []map[string]string{HrefMap(fmt.Sprintf("%s/%s", baseURL, restBase))},
+		Curies:     []*Curie{{Name: "wp", Href: "https://api.w.org/{rel}", Templated: true}},
+	}
+}
+
+// StatusArchives returns full url path for posts of the status
+func StatusArchives(baseURL string, status string) string {
+	if status == PublishStatus {
+		return fmt.Sprintf("%s/posts", baseURL)
+	}
+	return fmt.Sprintf("%s/posts?status=%s", baseURL, status)
+}
@@ -1,5 +1,12 @@
 package model
 
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/yvasiyarov/php_session_decoder/php_serialize"
+)
+
 // BaseMedia represents "media" element as parf of "embedded" json response of post
 type BaseMedia struct {
 	Base
@@ -21,6 +28,12 @@ type MediaData struct {
 type Media struct {
 	ContentView
 	MediaData
+
+	// The attachment description.
+	Description *Rendered `json:"description"`
+
+	// The ID for the associated post of the attachment.
+	Post *uint64 `json:"post"`
 }
 
 // MediaDetails represents detail of media
@@ -56,7 +69,8 @@ type ImageMeta struct {
 	Orientation      string `json:"orientation"`
 }
 
-// MimeTypes is map contains registered mime types
+// MimeTypes is map contains registered mime types, html and javascript are not registered like get_allowed_mime_types
+// removes them for users without unfiltered_html capability because uploaded files are served from the site
 var MimeTypes = map[string]string{
 	"jpg|jpeg|jpe": "image/jpeg",
 	"gif":          "image/gif",
@@ -86,7 +100,6 @@ var MimeTypes = map[string]string{
 	"ics":                "text/calendar",
 	"rtx":                "text/richtext",
 	"css":                "text/css",
-	"htm|html":           "text/html",
 	"vtt":                "text/vtt",
 	"dfxp":               "application/ttaf+xml",
 	// Audio formats.
@@ -101,7 +114,6 @@ var MimeTypes = map[string]string{
 	"mka":         "audio/x-matroska",
 	// Misc application formats.
 	"rtf":     "application/rtf",
-	"js":      "application/javascript",
 	"pdf":     "application/pdf",
 	"swf":     "application/x-shockwave-flash",
 	"class":   "application/java",
@@ -156,3 +168,68 @@ var MimeTypes = map[string]string{
 	"key":     "application/vnd.apple.keynote",
 	"numbers": "application/vnd.apple.numbers",
 	"pages":   "application/vnd.apple.pages"}
+
+// MimeTypeByExtension returns registered mime type of the file name extension or empty string if it is not registered
+func MimeTypeByExtension(fileName string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	if ext == "" {
+		return ""
+	}
+	for extensions, mimeType := range MimeTypes {
+		for _, e := range strings.Split(extensions, "|") {
+			if e == ext {
+				return mimeType
+			}
+		}
+	}
+	return ""
+}
+
+// Serialize returns media details as php serialized array that is stored in '_wp_attachment_metadata' post meta,
+// the 'full' size is not stored because it is the original file
+func (d *MediaDetails) Serialize() (string, error) {
+	sizes := php_serialize.PhpArray{}
+	for name, size := range d.Sizes {
+		if name == "full" {
+			continue
+		}
+		sizes[name] = php_serialize.PhpArray{
+			"file":      size.File,
+			"width":     size.Width,
+			"height":    size.Height,
+			"mime-type": size.MimeType,
+		}
+	}
+
+	imageMeta := d.ImageMeta
+	if imageMeta == nil {
+		imageMeta = &ImageMeta{}
+	}
+	zeroIfEmpty := func(value string) string {
+		if value == "" {
+			return "0"
+		}
+		return value
+	}
+
+	return php_serialize.Serialize(php_serialize.PhpArray{
+		"width":  d.Width,
+		"height": d.Height,
+		"file":   d.File,
+		"sizes":  sizes,
+		"image_meta": php_serialize.PhpArray{
+			"aperture":          zeroIfEmpty(imageMeta.Aperture),
+			"credit":            imageMeta.Credit,
+			"camera":            imageMeta.Camera,
+			"caption":           imageMeta.Caption,
+			"created_timestamp": zeroIfEmpty(imageMeta.CreatedTimestamp),
+			"copyright":         imageMeta.Copyright,
+			"focal_length":      zeroIfEmpty(imageMeta.FocalLength),
+			"iso":               zeroIfEmpty(imageMeta.Iso),
+			"shutter_speed":     zeroIfEmpty(imageMeta.ShutterSpeed),
+			"title":             imageMeta.Title,
+			"orientation":       zeroIfEmpty(imageMeta.Orientation),
+			"keywords":          php_serialize.PhpArray{},
+		},
+	})
+}
@@ -2,9 +2,12 @@ package model
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/json"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/qreasio/restlr/toolbox"
@@ -118,6 +121,56 @@ type Post struct {
 	MediaType *string `json:"media_type,omitempty"`
 
 	Embedded *Embedded `json:"_embedded,omitempty"`
+
+	// TaxonomyTerms stores term ids of registered taxonomies by rest base of the taxonomy, they are encoded as fields of the post
+	TaxonomyTerms map[string][]uint64 `json:"-"`
+}
+
+// MarshalJSON encodes post with term ids of registered taxonomies as additional fields like WP Rest API does
+func (p Post) MarshalJSON() ([]byte, error) {
+	type post Post
+	data, err := json.Marshal(post(p))
+	if err != nil || len(p.TaxonomyTerms) == 0 {
+		return data, err
+	}
+
+	fields, err := json.Marshal(p.TaxonomyTerms)
+	if err != nil {
+		return nil, err
+	}
+	// replace closing brace of the post object with the fields of the map
+	return append(append(data[:len(data)-1], ','), fields[1:]...), nil
+}
+
+// PostRecord represents a row of 'posts' table that is inserted or updated
+type PostRecord struct {
+	ID            uint64
+	Author        uint64
+	Date          time.Time
+	DateGmt       time.Time // zero value is stored as '0000-00-00 00:00:00' like WP does for draft without date
+	Content       string
+	Title         string
+	Excerpt       string
+	Status        string
+	CommentStatus string
+	PingStatus    string
+	Password      string
+	Name          string
+	Modified      time.Time
+	ModifiedGmt   time.Time
+	Parent        uint64
+	GUID          string
+	MenuOrder     int
+	Type          string
+	MimeType      string
+}
+
+// PostNode represents position of a post in post hierarchy
+type PostNode struct {
+	ID     uint64
+	Parent uint64
+	Name   string
+	Type   string
 }
 
 // RawPost store unprocessed required raw data to construct post
@@ -129,6 +182,11 @@ type RawPost struct {
 	User           map[uint64]*UserDetail
 	FeaturedMedia  map[uint64]uint64
 	StickyPostIDs  map[int]bool
+	Predecessors   map[uint64]map[int]uint64
+	// Comments, Media and MediaMetas are only pulled for _embed, media and their metas are keyed by media id
+	Comments   map[uint64][]*Comment
+	Media      map[uint64]*Post
+	MediaMetas map[uint64]map[string]string
 }
 
 // SetViewAttributes will set post attributes that will be required on request with context = view
@@ -143,17 +201,25 @@ func (p *Post) SetViewAttributes(
 		p.Template = template
 	}
 
-	if p.Type == PostType {
-		// set Tags
-		tags, ok := taxonomies[p.ID][TagType]
-		if ok {
-			p.Tags = tags
+	for _, taxonomy := range PostTypeTaxonomies(p.Type) {
+		termIDs, ok := taxonomies[p.ID][taxonomy]
+		if !ok {
+			continue
 		}
-		// set Categories
-		categories, ok := taxonomies[p.ID][CategoryType]
-		if ok {
-			p.Categories = categories
+		switch taxonomy {
+		case TagType:
+			p.Tags = termIDs
+		case CategoryType:
+			p.Categories = termIDs
+		default:
+			if p.TaxonomyTerms == nil {
+				p.TaxonomyTerms = map[string][]uint64{}
+			}
+			p.TaxonomyTerms[Plural(taxonomy)] = termIDs
 		}
+	}
+
+	if p.Type == PostType {
 		// set Format
 		format, ok := formatMap[p.ID]
 		if ok {
@@ -201,14 +267,42 @@ func (p *Post) SetLinks(ctx context.Context) {
 
 	links.Curies = append(links.Curies, &Curie{Name: "wp", Href: url.Curies(), Templated: true})
 
-	if p.Type == PostType {
-		links.Term = append(links.Term, TermPost{Href: url.Categories(idStr), Embeddable: true, Taxonomy: TagType})
-		links.Term = append(links.Term, TermPost{Href: url.Tags(idStr), Embeddable: true, Taxonomy: CategoryType})
+	for _, taxonomy := range PostTypeTaxonomies(p.Type) {
+		if Plural(taxonomy) != "" {
+			links.Term = append(links.Term, TermPost{Href: url.Terms(taxonomy, idStr), Embeddable: true, Taxonomy: taxonomy})
+		}
 	}
 
 	p.Links = links
 }
 
+// SetPostTypeSupports removes fields of registered post type that are not in its supported features
+func (p *Post) SetPostTypeSupports() {
+	postType, ok := RegisteredPostType(p.Type)
+	if !ok {
+		return
+	}
+	if !postType.SupportsFeature("title") {
+		p.Title = nil
+	}
+	if !postType.SupportsFeature("editor") {
+		p.Content = nil
+	}
+	if !postType.SupportsFeature("excerpt") {
+		p.Excerpt = nil
+	}
+	if !postType.SupportsFeature("author") {
+		p.Author = 0
+	}
+	if !postType.SupportsFeature("comments") {
+		p.CommentStatus = ""
+		p.PingStatus = ""
+	}
+	if !postType.SupportsFeature("page-attributes") {
+		p.MenuOrder = nil
+	}
+}
+
 // SetSticky set sticky value of post
 func (p *Post) SetSticky(stickyPostIDs map[int]bool) {
 	if stickyPostIDs[int(p.ID)] {
@@ -222,3 +316,32 @@ func (p *Post) SetPredecessorVersion(baseURL string, predecessor uint64) {
 	versionLink := VersionLink{ID: predecessor, Href: url}
 	p.Links.PredecessorVersion = append(p.Links.PredecessorVersion, versionLink)
 }
+
+// IsPasswordProtected returns true if post has post_password value
+func (p *Post) IsPasswordProtected() bool {
+	return p.Password != nil && *p.Password != ""
+}
+
+// SetProtectedContent masks content and excerpt of password protected post unless the password parameter matches the post password.
+// It returns ErrIncorrectPassword if password parameter is given but doesn't match, and it never exposes the post password in response
+func (p *Post) SetProtectedContent(password *string) error {
+	defer func() { p.Password = nil }()
+
+	if !p.IsPasswordProtected() {
+		return nil
+	}
+
+	p.Content.Protected = true
+	p.Excerpt.Protected = true
+
+	if password != nil && *password != "" {
+		if subtle.ConstantTimeCompare([]byte(*p.Password), []byte(*password)) != 1 {
+			return ErrIncorrectPassword
+		}
+		return nil
+	}
+
+	p.Content.Rendered = ""
+	p.Excerpt.Rendered = ""
+	return nil
+}
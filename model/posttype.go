@@ -0,0 +1,195 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// maxPostTypeLength is maximum length of post type slug, post_type column of posts table is varchar(20)
+const maxPostTypeLength = 20
+
+// postTypeSlugRegexp matches post type slug that is valid for register_post_type, it is the result of sanitize_key
+var postTypeSlugRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// reservedPostTypes are post types that are built in WP and can't be registered
+var reservedPostTypes = []string{PostType, PageType, MediaType, AttachmentType, RevisionType, "nav_menu_item",
+	"custom_css", "customize_changeset", "oembed_cache", "user_request", "wp_block"}
+
+// reservedRestBases are rest base of built in routes that can't be used by registered post type
+var reservedRestBases = []string{"posts", "pages", "media", "comments", "categories", "tags", "users",
+	"types", "taxonomies", "statuses", "settings", "search", "blocks"}
+
+// defaultPostTypeSupports is list of features that post type supports when supports is not configured, like register_post_type does
+var defaultPostTypeSupports = []string{"title", "editor"}
+
+// PostTypeConfig represents post type that is registered from configuration, the fields follow arguments of register_post_type
+type PostTypeConfig struct {
+	Slug         string   `json:"slug"`
+	Name         string   `json:"name"`
+	SingularName string   `json:"singular_name"`
+	Description  string   `json:"description"`
+	RestBase     string   `json:"rest_base"`
+	Hierarchical bool     `json:"hierarchical"`
+	Supports     []string `json:"supports"`
+	Taxonomies   []string `json:"taxonomies"`
+}
+
+// postTypes stores registered post types by slug
+var postTypes = map[string]*PostTypeConfig{}
+
+// builtinPostTypes are post types of WP that are returned by the API, media is stored as attachment post type
+var builtinPostTypes = []*PostTypeConfig{
+	{Slug: PostType, Name: "Posts", SingularName: "Post", RestBase: "posts",
+		Supports: []string{"title", "editor", "author", "thumbnail", "excerpt", "trackbacks", "custom-fields", "comments", "revisions", "post-formats"}},
+	{Slug: PageType, Name: "Pages", SingularName: "Page", RestBase: "pages", Hierarchical: true,
+		Supports: []string{"title", "editor", "author", "thumbnail", "page-attributes", "custom-fields", "comments", "revisions"}},
+	{Slug: AttachmentType, Name: "Media", SingularName: "Media", RestBase: "media",
+		Supports: []string{"title", "author", "comments"}},
+}
+
+// RegisterPostType validates post type and adds it to registered post types, its rest base is added to PluralContentTypeMap
+// so links of the post type are generated by LinkURL
+func RegisterPostType(postType PostTypeConfig) error {
+	if len(postType.Slug) > maxPostTypeLength || !postTypeSlugRegexp.MatchString(postType.Slug) {
+		return fmt.Errorf("invalid post type slug %q", postType.Slug)
+	}
+	for _, reserved := range reservedPostTypes {
+		if postType.Slug == reserved {
+			return fmt.Errorf("post type %q is reserved", postType.Slug)
+		}
+	}
+	if _, ok := PluralContentTypeMap[postType.Slug]; ok {
+		return fmt.Errorf("post type %q is already registered", postType.Slug)
+	}
+
+	if postType.RestBase == "" {
+		postType.RestBase = postType.Slug
+	}
+	if postType.Name == "" {
+		postType.Name = postType.Slug
+	}
+	if postType.SingularName == "" {
+		postType.SingularName = postType.Name
+	}
+	if postType.Supports == nil {
+		postType.Supports = defaultPostTypeSupports
+	}
+	if err := validateRestBase(postType.Slug, postType.RestBase); err != nil {
+		return err
+	}
+
+	postTypes[postType.Slug] = &postType
+	PluralContentTypeMap[postType.Slug] = postType.RestBase
+	return nil
+}
+
+// validateRestBase checks rest base of registered post type or taxonomy isn't used by built in route or other content type
+func validateRestBase(slug string, restBase string) error {
+	for _, reserved := range reservedRestBases {
+		if restBase == reserved {
+			return fmt.Errorf("rest base %q of %q is reserved", restBase, slug)
+		}
+	}
+	for contentType, plural := range PluralContentTypeMap {
+		if plural == restBase {
+			return fmt.Errorf("rest base %q of %q is used by %q", restBase, slug, contentType)
+		}
+	}
+	return nil
+}
+
+// LoadPostTypes registers post types from json array in the reader
+func LoadPostTypes(r io.Reader) error {
+	var configs []PostTypeConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return err
+	}
+	for _, config := range configs {
+		if err := RegisterPostType(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisteredPostType returns registered post type by its slug
+func RegisteredPostType(slug string) (*PostTypeConfig, bool) {
+	postType, ok := postTypes[slug]
+	return postType, ok
+}
+
+// RegisteredPostTypes returns all registered post types ordered by slug
+func RegisteredPostTypes() []*PostTypeConfig {
+	list := make([]*PostTypeConfig, 0, len(postTypes))
+	for _, postType := range postTypes {
+		list = append(list, postType)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Slug < list[j].Slug
+	})
+	return list
+}
+
+// PostTypes returns built in post types followed by registered post types, they are post types that are returned by the API
+func PostTypes() []*PostTypeConfig {
+	return append(append([]*PostTypeConfig{}, builtinPostTypes...), RegisteredPostTypes()...)
+}
+
+// PostTypeBySlug returns built in or registered post type by its slug
+func PostTypeBySlug(slug string) (*PostTypeConfig, bool) {
+	for _, postType := range builtinPostTypes {
+		if postType.Slug == slug {
+			return postType, true
+		}
+	}
+	return RegisteredPostType(slug)
+}
+
+// IsHierarchicalPostType returns true if the post type can have parent, page is the only built in hierarchical post type
+func IsHierarchicalPostType(postType string) bool {
+	if registered, ok := postTypes[postType]; ok {
+		return registered.Hierarchical
+	}
+	return postType == PageType
+}
+
+// PostTypeTaxonomies returns taxonomies that are associated with the post type, either in taxonomies of the post type
+// or in object types of the registered taxonomy
+func PostTypeTaxonomies(postType string) []string {
+	var list []string
+	if registered, ok := postTypes[postType]; ok {
+		list = append(list, registered.Taxonomies...)
+	} else if postType == PostType {
+		list = append(list, CategoryType, TagType)
+	}
+
+	for _, taxonomy := range RegisteredTaxonomies() {
+		if inStrings(postType, taxonomy.ObjectTypes) && !inStrings(taxonomy.Slug, list) {
+			list = append(list, taxonomy.Slug)
+		}
+	}
+	return list
+}
+
+// inStrings returns true if the value is in the list
+func inStrings(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsFeature returns true if the post type supports the feature like post_type_supports does
+func (t *PostTypeConfig) SupportsFeature(feature string) bool {
+	return inStrings(feature, t.Supports)
+}
+
+// Labels returns labels of the post type
+func (t *PostTypeConfig) Labels() Labels {
+	return Labels{Name: t.Name, SingularName: t.SingularName}
+}
@@ -3,12 +3,141 @@ package model
 // GetItemRequest is struct to represents common HTTP URL request values to get specific post or item in API
 type GetItemRequest struct {
 	ID       *uint64
+	Type     string  `form:"-"`
 	Context  string  `form:"context"`
 	Password *string `form:"password"`
 	Embed    *string `form:"_embed"`
 	IsEmbed  bool
 }
 
+// WritePostRequest represents request body to create or update post/page, fields that are nil keep current value on update
+type WritePostRequest struct {
+	ID            *uint64
+	Type          string
+	Date          *string           `json:"date" form:"date"`
+	DateGmt       *string           `json:"date_gmt" form:"date_gmt"`
+	Slug          *string           `json:"slug" form:"slug"`
+	Status        *string           `json:"status" form:"status"`
+	Password      *string           `json:"password" form:"password"`
+	Title         *string           `json:"title" form:"title"`
+	Content       *string           `json:"content" form:"content"`
+	Excerpt       *string           `json:"excerpt" form:"excerpt"`
+	Author        *uint64           `json:"author" form:"author"`
+	FeaturedMedia *uint64           `json:"featured_media" form:"featured_media"`
+	CommentStatus *string           `json:"comment_status" form:"comment_status"`
+	PingStatus    *string           `json:"ping_status" form:"ping_status"`
+	Meta          map[string]string `json:"meta" form:"meta"`
+	Categories    []uint64          `json:"categories" form:"categories"`
+	Tags          []uint64          `json:"tags" form:"tags"`
+	Parent        *uint64           `json:"parent" form:"parent"`
+	MenuOrder     *int              `json:"menu_order" form:"menu_order"`
+	Template      *string           `json:"template" form:"template"`
+}
+
+// UploadMediaRequest represents request to upload media file, the file is sent as 'file' field of multipart form
+// or as raw request body with Content-Disposition and Content-Type header
+type UploadMediaRequest struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+	Author      *uint64 `json:"author" form:"author"`
+	Title       *string `json:"title" form:"title"`
+	AltText     *string `json:"alt_text" form:"alt_text"`
+	Caption     *string `json:"caption" form:"caption"`
+	Description *string `json:"description" form:"description"`
+	Post        *uint64 `json:"post" form:"post"`
+}
+
+// CreateCommentRequest represents request body to submit new comment, AuthorIP and AuthorUserAgent are taken from the http request
+type CreateCommentRequest struct {
+	Post            *uint64 `json:"post" form:"post"`
+	Parent          uint64  `json:"parent" form:"parent"`
+	AuthorName      string  `json:"author_name" form:"author_name"`
+	AuthorEmail     string  `json:"author_email" form:"author_email"`
+	AuthorURL       string  `json:"author_url" form:"author_url"`
+	Content         string  `json:"content" form:"content"`
+	Password        *string `json:"password" form:"password"`
+	AuthorIP        string
+	AuthorUserAgent string
+}
+
+// WriteTermRequest represents request body to create or update term of the taxonomy, fields that are nil keep current value on update
+type WriteTermRequest struct {
+	ID          *uint64
+	Taxonomy    string
+	Name        *string `json:"name" form:"name"`
+	Slug        *string `json:"slug" form:"slug"`
+	Description *string `json:"description" form:"description"`
+	Parent      *uint64 `json:"parent" form:"parent"`
+}
+
+// GetTermRequest represents HTTP URL request values to get term of the taxonomy
+type GetTermRequest struct {
+	ID       *uint64
+	Taxonomy string
+}
+
+// ListTermsRequest represents query string to list terms of the taxonomy
+type ListTermsRequest struct {
+	Taxonomy  string   `form:"-"`
+	Page      int      `form:"page"`
+	PerPage   int      `form:"per_page"`
+	Search    *string  `form:"search"`
+	Exclude   []uint64 `form:"exclude"`
+	Include   []uint64 `form:"include"`
+	Order     string   `form:"order"`
+	OrderBy   string   `form:"orderby"`
+	HideEmpty bool     `form:"hide_empty"`
+	Parent    *uint64  `form:"parent"`
+	Post      *uint64  `form:"post"`
+	Slug      []string `form:"slug"`
+}
+
+// GetTypeRequest represents request to get post type from types endpoint
+type GetTypeRequest struct {
+	Type string
+}
+
+// ListTaxonomiesRequest represents request to list taxonomies, the taxonomies can be filtered by post type
+type ListTaxonomiesRequest struct {
+	Type *string `form:"type"`
+}
+
+// GetTaxonomyRequest represents request to get taxonomy from taxonomies endpoint
+type GetTaxonomyRequest struct {
+	Taxonomy string
+}
+
+// ListStatusesRequest represents request to list post statuses, statuses that aren't public are listed only with write access
+type ListStatusesRequest struct {
+	WriteAccess bool
+}
+
+// GetStatusRequest represents request to get post status from statuses endpoint
+type GetStatusRequest struct {
+	Status      string
+	WriteAccess bool
+}
+
+// MergeTermRequest represents request to merge term into another term of the same taxonomy
+type MergeTermRequest struct {
+	ID       *uint64
+	Taxonomy string
+	Into     *uint64 `json:"into" form:"into"`
+}
+
+// DeleteTermRequest represents HTTP URL request values to delete term of the taxonomy
+type DeleteTermRequest struct {
+	DeleteItemRequest
+	Taxonomy string
+}
+
+// DeleteItemRequest is struct to represents HTTP URL request values to delete specific post or item in API
+type DeleteItemRequest struct {
+	ID    *uint64
+	Force bool `form:"force"`
+}
+
 // ListRequest represents query string to browse/list post/page with context and embed parameter
 // This model is used inside service
 type ListRequest struct {
@@ -26,6 +155,9 @@ type ListParams struct {
 	CategoriesExclude []uint64 `form:"categories_exclude"`
 	Tags              []uint64 `form:"tags"`
 	TagsExclude       []uint64 `form:"tags_exclude"`
+	// TaxonomyTerms and TaxonomyTermsExclude store term ids of registered taxonomies by taxonomy slug
+	TaxonomyTerms        map[string][]uint64 `form:"-"`
+	TaxonomyTermsExclude map[string][]uint64 `form:"-"`
 }
 
 // ListFilter represents parameters to call QueryPosts function in repository to get list of posts that
@@ -0,0 +1,66 @@
+package model
+
+// Labels represents labels of post type or taxonomy
+type Labels struct {
+	Name         string `json:"name"`
+	SingularName string `json:"singular_name"`
+}
+
+// SchemaLink represents _links of post type and taxonomy response
+type SchemaLink struct {
+	Collection []map[string]string `json:"collection"`
+	Items      []map[string]string `json:"wp:items"`
+	Curies     []*Curie            `json:"curies"`
+}
+
+// StatusLink represents _links of post status response
+type StatusLink struct {
+	Archives []map[string]string `json:"archives"`
+}
+
+// PostTypeResponse represents json response of post type in types endpoint
+type PostTypeResponse struct {
+	Description  string      `json:"description"`
+	Hierarchical bool        `json:"hierarchical"`
+	Name         string      `json:"name"`
+	Slug         string      `json:"slug"`
+	Labels       Labels      `json:"labels"`
+	Supports     []string    `json:"supports"`
+	Taxonomies   []string    `json:"taxonomies"`
+	RestBase     string      `json:"rest_base"`
+	Links        *SchemaLink `json:"_links"`
+}
+
+// TaxonomyResponse represents json response of taxonomy in taxonomies endpoint
+type TaxonomyResponse struct {
+	Name         string      `json:"name"`
+	Slug         string      `json:"slug"`
+	Description  string      `json:"description"`
+	Labels       Labels      `json:"labels"`
+	Types        []string    `json:"types"`
+	Hierarchical bool        `json:"hierarchical"`
+	RestBase     string      `json:"rest_base"`
+	Links        *SchemaLink `json:"_links"`
+}
+
+// PostStatus represents post status that is returned by statuses endpoint, the fields follow arguments of register_post_status
+type PostStatus struct {
+	Name         string      `json:"name"`
+	Private      bool        `json:"private"`
+	Protected    bool        `json:"protected"`
+	Public       bool        `json:"public"`
+	Queryable    bool        `json:"queryable"`
+	ShowInList   bool        `json:"show_in_list"`
+	Slug         string      `json:"slug"`
+	DateFloating bool        `json:"date_floating"`
+	Links        *StatusLink `json:"_links,omitempty"`
+}
+
+// PostStatuses are post statuses of WP that are not internal, only public status can be read without write access
+var PostStatuses = []PostStatus{
+	{Slug: PublishStatus, Name: "Published", Public: true, Queryable: true, ShowInList: true},
+	{Slug: FutureStatus, Name: "Scheduled", Protected: true, ShowInList: true},
+	{Slug: DraftStatus, Name: "Draft", Protected: true, ShowInList: true, DateFloating: true},
+	{Slug: PendingStatus, Name: "Pending", Protected: true, ShowInList: true, DateFloating: true},
+	{Slug: PrivateStatus, Name: "Private", Private: true, ShowInList: true},
+}
@@ -0,0 +1,63 @@
+package model
+
+const (
+	// SearchTypePost stores type of search result that is a post of any post type
+	SearchTypePost = "post"
+	// SearchTypeTerm stores type of search result that is a term of any taxonomy
+	SearchTypeTerm = "term"
+	// SearchSubtypeAny stores subtype value to search in every post type or taxonomy of the search type
+	SearchSubtypeAny = "any"
+	// SearchMaxPerPage is maximum number of search results that are returned per page
+	SearchMaxPerPage = 100
+)
+
+// SearchRequest represents URL query string of search endpoint
+type SearchRequest struct {
+	Search  string   `form:"search"`
+	Type    string   `form:"type"`
+	Subtype []string `form:"-"`
+	Page    int      `form:"page"`
+	PerPage int      `form:"per_page"`
+	Include []uint64 `form:"-"`
+	Exclude []uint64 `form:"-"`
+	IsEmbed bool     `form:"-"`
+}
+
+// SearchFilter represents parameters to search posts or terms in repository, subtypes are post types or taxonomies
+type SearchFilter struct {
+	Search   string
+	Subtypes []string
+	Include  []uint64
+	Exclude  []uint64
+	Page     int
+	PerPage  int
+}
+
+// SearchResult represents json response of item in search endpoint
+type SearchResult struct {
+	ID       uint64          `json:"id"`
+	Title    string          `json:"title"`
+	URL      string          `json:"url"`
+	Type     string          `json:"type"`
+	Subtype  string          `json:"subtype"`
+	Links    *SearchLink     `json:"_links"`
+	Embedded *SearchEmbedded `json:"_embedded,omitempty"`
+}
+
+// SearchLink represents _links of search result, self is the post or term that is found
+type SearchLink struct {
+	Self  []EmbeddableLink    `json:"self"`
+	About []map[string]string `json:"about"`
+}
+
+// SearchEmbedded represents _embedded of search result that contains embed context of the post or term
+type SearchEmbedded struct {
+	Self []interface{} `json:"self"`
+}
+
+// SearchResponse stores search results of the requested page with total number of results
+type SearchResponse struct {
+	Results    []*SearchResult
+	Total      int
+	TotalPages int
+}
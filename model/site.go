@@ -0,0 +1,36 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MainSiteID is blog id of the main site of multisite network, its tables have the base prefix like WP does
+const MainSiteID = 1
+
+// Site is a site of WordPress multisite network, it is a row of blogs table
+type Site struct {
+	ID        uint64 `json:"id"`
+	NetworkID uint64 `json:"network_id"`
+	Domain    string `json:"domain"`
+	Path      string `json:"path"`
+	Public    bool   `json:"public"`
+}
+
+// TablePrefix returns table prefix of the site, it is base prefix with blog id like wp_2_ except for the main site
+func (s Site) TablePrefix(basePrefix string) string {
+	if s.ID == MainSiteID {
+		return basePrefix
+	}
+	return fmt.Sprintf("%s%d_", basePrefix, s.ID)
+}
+
+// URL returns url of the site with the scheme, it has no trailing slash
+func (s Site) URL(scheme string) string {
+	return scheme + "://" + s.Domain + strings.TrimSuffix(s.Path, "/")
+}
+
+// MatchPath returns true if the request path is inside path of the site
+func (s Site) MatchPath(path string) bool {
+	return strings.HasPrefix(path+"/", s.Path)
+}
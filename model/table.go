@@ -0,0 +1,65 @@
+package model
+
+import "context"
+
+// Tables is registry of table names of a site, every name is derived from table prefix of the site
+// except users and multisite tables that are shared by every site of the network
+type Tables struct {
+	Posts             string
+	PostMeta          string
+	Comments          string
+	CommentMeta       string
+	Terms             string
+	TermMeta          string
+	TermTaxonomy      string
+	TermRelationships string
+	Options           string
+	Users             string
+	UserMeta          string
+	Blogs             string
+	Site              string
+}
+
+// NewTables returns table names of site with the table prefix
+func NewTables(prefix string) Tables {
+	return NewSiteTables(prefix, prefix)
+}
+
+// NewSiteTables returns table names of multisite site with the table prefix, network-wide tables have the base prefix
+func NewSiteTables(basePrefix string, prefix string) Tables {
+	return Tables{
+		Posts:             prefix + "posts",
+		PostMeta:          prefix + "postmeta",
+		Comments:          prefix + "comments",
+		CommentMeta:       prefix + "commentmeta",
+		Terms:             prefix + "terms",
+		TermMeta:          prefix + "termmeta",
+		TermTaxonomy:      prefix + "term_taxonomy",
+		TermRelationships: prefix + "term_relationships",
+		Options:           prefix + "options",
+		Users:             basePrefix + "users",
+		UserMeta:          basePrefix + "usermeta",
+		Blogs:             basePrefix + "blogs",
+		Site:              basePrefix + "site",
+	}
+}
+
+// All returns names of tables that are used by single site install
+func (t Tables) All() []string {
+	return []string{t.Posts, t.PostMeta, t.Comments, t.CommentMeta, t.Terms, t.TermMeta, t.TermTaxonomy, t.TermRelationships,
+		t.Options, t.Users, t.UserMeta}
+}
+
+// Tables returns table names of the site of the config
+func (c APIConfig) Tables() Tables {
+	if c.BaseTablePrefix == "" {
+		return NewTables(c.TablePrefix)
+	}
+	return NewSiteTables(c.BaseTablePrefix, c.TablePrefix)
+}
+
+// TablesFromContext returns table names of the site of APIConfig that is stored in context
+func TablesFromContext(ctx context.Context) Tables {
+	config, _ := ctx.Value(APIConfigKey).(APIConfig)
+	return config.Tables()
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+const (
+	// PostFormatType stores string value to define post format taxonomy type
+	PostFormatType = "post_format"
+	// maxTaxonomyLength is maximum length of taxonomy slug, taxonomy column of term_taxonomy table is varchar(32)
+	maxTaxonomyLength = 32
+)
+
+// taxonomySlugRegexp matches taxonomy slug that is valid for register_taxonomy
+var taxonomySlugRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// reservedTaxonomies are taxonomies that are built in WP and can't be registered
+var reservedTaxonomies = []string{CategoryType, TagType, PostFormatType, "nav_menu", "link_category", "wp_theme"}
+
+// TaxonomyConfig represents taxonomy that is registered from configuration, the fields follow arguments of register_taxonomy
+type TaxonomyConfig struct {
+	Slug         string   `json:"slug"`
+	Name         string   `json:"name"`
+	SingularName string   `json:"singular_name"`
+	Description  string   `json:"description"`
+	RestBase     string   `json:"rest_base"`
+	Hierarchical bool     `json:"hierarchical"`
+	ObjectTypes  []string `json:"object_types"`
+}
+
+// taxonomies stores registered taxonomies by slug
+var taxonomies = map[string]*TaxonomyConfig{}
+
+// builtinTaxonomies are taxonomies of WP that are returned by the API
+var builtinTaxonomies = []*TaxonomyConfig{
+	{Slug: CategoryType, Name: "Categories", SingularName: "Category", RestBase: "categories", Hierarchical: true},
+	{Slug: TagType, Name: "Tags", SingularName: "Tag", RestBase: "tags"},
+}
+
+// RegisterTaxonomy validates taxonomy and adds it to registered taxonomies, its rest base is added to PluralContentTypeMap
+// so links of its terms are generated by LinkURL
+func RegisterTaxonomy(taxonomy TaxonomyConfig) error {
+	if len(taxonomy.Slug) > maxTaxonomyLength || !taxonomySlugRegexp.MatchString(taxonomy.Slug) {
+		return fmt.Errorf("invalid taxonomy slug %q", taxonomy.Slug)
+	}
+	if inStrings(taxonomy.Slug, reservedTaxonomies) {
+		return fmt.Errorf("taxonomy %q is reserved", taxonomy.Slug)
+	}
+	if _, ok := PluralContentTypeMap[taxonomy.Slug]; ok {
+		return fmt.Errorf("taxonomy %q is already registered", taxonomy.Slug)
+	}
+
+	if taxonomy.RestBase == "" {
+		taxonomy.RestBase = taxonomy.Slug
+	}
+	if taxonomy.Name == "" {
+		taxonomy.Name = taxonomy.Slug
+	}
+	if taxonomy.SingularName == "" {
+		taxonomy.SingularName = taxonomy.Name
+	}
+	if err := validateRestBase(taxonomy.Slug, taxonomy.RestBase); err != nil {
+		return err
+	}
+
+	taxonomies[taxonomy.Slug] = &taxonomy
+	PluralContentTypeMap[taxonomy.Slug] = taxonomy.RestBase
+	return nil
+}
+
+// LoadTaxonomies registers taxonomies from json array in the reader
+func LoadTaxonomies(r io.Reader) error {
+	var configs []TaxonomyConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return err
+	}
+	for _, config := range configs {
+		if err := RegisterTaxonomy(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisteredTaxonomy returns registered taxonomy by its slug
+func RegisteredTaxonomy(slug string) (*TaxonomyConfig, bool) {
+	taxonomy, ok := taxonomies[slug]
+	return taxonomy, ok
+}
+
+// RegisteredTaxonomies returns all registered taxonomies ordered by slug
+func RegisteredTaxonomies() []*TaxonomyConfig {
+	list := make([]*TaxonomyConfig, 0, len(taxonomies))
+	for _, taxonomy := range taxonomies {
+		list = append(list, taxonomy)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Slug < list[j].Slug
+	})
+	return list
+}
+
+// Taxonomies returns built in taxonomies followed by registered taxonomies, they are taxonomies that are returned by the API
+func Taxonomies() []*TaxonomyConfig {
+	return append(append([]*TaxonomyConfig{}, builtinTaxonomies...), RegisteredTaxonomies()...)
+}
+
+// TaxonomyBySlug returns built in or registered taxonomy by its slug
+func TaxonomyBySlug(slug string) (*TaxonomyConfig, bool) {
+	for _, taxonomy := range builtinTaxonomies {
+		if taxonomy.Slug == slug {
+			return taxonomy, true
+		}
+	}
+	return RegisteredTaxonomy(slug)
+}
+
+// Labels returns labels of the taxonomy
+func (t *TaxonomyConfig) Labels() Labels {
+	return Labels{Name: t.Name, SingularName: t.SingularName}
+}
+
+// TermTaxonomies returns category, tag and registered taxonomies, they are taxonomies whose terms are returned by the API
+func TermTaxonomies() []string {
+	list := []string{CategoryType, TagType}
+	for _, taxonomy := range RegisteredTaxonomies() {
+		list = append(list, taxonomy.Slug)
+	}
+	return list
+}
+
+// TaxonomyObjectTypes returns post types of the taxonomy, either in object types of the taxonomy or in taxonomies of registered post type
+func TaxonomyObjectTypes(taxonomy string) []string {
+	var list []string
+	if registered, ok := taxonomies[taxonomy]; ok {
+		list = append(list, registered.ObjectTypes...)
+	} else if taxonomy == CategoryType || taxonomy == TagType {
+		list = append(list, PostType)
+	}
+
+	for _, postType := range RegisteredPostTypes() {
+		if inStrings(taxonomy, postType.Taxonomies) && !inStrings(postType.Slug, list) {
+			list = append(list, postType.Slug)
+		}
+	}
+	return list
+}
+
+// TaxonomyTermLink returns url of the term archive on the site, archive of registered taxonomy uses taxonomy slug as rewrite slug
+func TaxonomyTermLink(siteURL string, taxonomy string, slug string) string {
+	switch taxonomy {
+	case CategoryType:
+		return CategoryLink(siteURL, slug)
+	case TagType:
+		return TagLink(siteURL, slug)
+	}
+	return fmt.Sprintf("%s/%s/%s", siteURL, taxonomy, slug)
+}
@@ -30,6 +30,14 @@ type TermTaxonomyJoin struct {
 	Count          int64  `json:"count"`       // count
 }
 
+// IsHierarchicalTaxonomy returns true if terms of the taxonomy can have parent, category is the only built in hierarchical taxonomy
+func IsHierarchicalTaxonomy(taxonomy string) bool {
+	if registered, ok := RegisteredTaxonomy(taxonomy); ok {
+		return registered.Hierarchical
+	}
+	return taxonomy == CategoryType
+}
+
 // TermWithPostTaxonomy link TermTaxonomyJoin with object id
 type TermWithPostTaxonomy struct {
 	TermTaxonomyJoin
@@ -54,7 +62,7 @@ type TermLink struct {
 
 // TermPost represents specific post taxonomy term
 type TermPost struct {
-	Taxonomy   string `json:"taxonomy, omitempty"`
-	Embeddable bool   `json:"embeddable, omitempty"`
-	Href       string `json:"href, omitempty"`
+	Taxonomy   string `json:"taxonomy,omitempty"`
+	Embeddable bool   `json:"embeddable,omitempty"`
+	Href       string `json:"href,omitempty"`
 }
@@ -4,7 +4,33 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"fmt"
+	"regexp"
 	"strings"
+	"time"
+)
+
+// DateTimeFormat is the format of datetime columns in database
+const DateTimeFormat = "2006-01-02 15:04:05"
+
+// ZeroDateTime is datetime value that WP stores for empty date
+const ZeroDateTime = "0000-00-00 00:00:00"
+
+// accents maps accented latin characters to their ascii equivalent like remove_accents does
+var accents = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'þ': "th", 'ß': "s",
+}
+
+var (
+	tagRegexp     = regexp.MustCompile(`<[^>]*>`)
+	entityRegexp  = regexp.MustCompile(`&.+?;`)
+	dashRegexp    = regexp.MustCompile(`-+`)
+	timezoneRegex = regexp.MustCompile(`(Z|[+-]\d{2}:?\d{2})$`)
+	spaceRegexp   = regexp.MustCompile(`[\r\n\t -]+`)
 )
 
 // GetMD5Hash returns md5 hash from string (it is used to generate gravatar url)
@@ -44,3 +70,64 @@ func HrefMap(url string) map[string]string {
 func GetEmbeddableLink(url string) EmbeddableLink {
 	return EmbeddableLink{Href: url, Embeddable: true}
 }
+
+// SanitizeTitle is function to generate slug from title like sanitize_title_with_dashes does,
+// accented latin characters are replaced and other non ascii characters are percent encoded
+func SanitizeTitle(title string) string {
+	title = tagRegexp.ReplaceAllString(title, "")
+	title = entityRegexp.ReplaceAllString(title, "")
+	title = strings.ToLower(title)
+
+	var b strings.Builder
+	for _, r := range title {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		case r == ' ' || r == '.' || r == '/' || r == '\t' || r == '\n':
+			b.WriteRune('-')
+		case r > 127:
+			if ascii, ok := accents[r]; ok {
+				b.WriteString(ascii)
+				continue
+			}
+			for _, c := range []byte(string(r)) {
+				b.WriteString(fmt.Sprintf("%%%02x", c))
+			}
+		}
+	}
+
+	return strings.Trim(dashRegexp.ReplaceAllString(b.String(), "-"), "-")
+}
+
+// fileNameSpecialChars are characters that are removed from uploaded file name like sanitize_file_name does
+const fileNameSpecialChars = "?[]/\\=<>:;,'\"&$#*()|~`!{}%+\u2019\u00ab\u00bb\u201d\u201c\x00"
+
+// SanitizeFileName removes special characters from file name and replaces whitespaces with dash like sanitize_file_name does
+func SanitizeFileName(fileName string) string {
+	fileName = strings.NewReplacer("%20", "-", "+", "-").Replace(fileName)
+	fileName = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(fileNameSpecialChars, r) || r < 0x20 {
+			return -1
+		}
+		return r
+	}, fileName)
+	fileName = spaceRegexp.ReplaceAllString(fileName, "-")
+	return strings.Trim(fileName, ".-_")
+}
+
+// ParseDate parses date value of request parameter in RFC3339 format,
+// date without timezone is parsed as date in loc location
+func ParseDate(value string, loc *time.Location) (time.Time, error) {
+	if timezoneRegex.MatchString(value) {
+		return time.Parse(time.RFC3339, value)
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05", value, loc)
+}
+
+// FormatDate returns datetime string of t to be stored in database, zero time is formatted as ZeroDateTime
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ZeroDateTime
+	}
+	return t.Format(DateTimeFormat)
+}
@@ -0,0 +1,178 @@
+package multisite
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// siteKey is context key of the site of the request
+type siteKey struct{}
+
+// Directory stores sites of the network and routes requests to them, sites are read from blogs table
+// on Refresh so sites that are added in WP admin are routed after the next refresh
+type Directory struct {
+	repo Repository
+	base model.APIConfig
+
+	mu    sync.RWMutex
+	sites []model.Site
+}
+
+// NewDirectory returns Directory without sites, Refresh must be called to read the sites.
+// Table prefix of the base config is the base prefix of the network
+func NewDirectory(repo Repository, base model.APIConfig) *Directory {
+	return &Directory{
+		repo: repo,
+		base: base,
+	}
+}
+
+// Refresh reads sites of the network
+func (d *Directory) Refresh(ctx context.Context) error {
+	sites, err := d.repo.Sites(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.SetSites(sites)
+	return nil
+}
+
+// Run refreshes sites every interval until ctx is done
+func (d *Directory) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Refresh(ctx); err != nil {
+				log.WithFields(log.Fields{
+					"params": interval,
+					"func":   "d.Refresh",
+				}).Errorf("Failed to refresh sites: %s", err)
+			}
+		}
+	}
+}
+
+// SetSites replaces sites of the directory
+func (d *Directory) SetSites(sites []model.Site) {
+	sorted := append([]model.Site(nil), sites...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	d.mu.Lock()
+	d.sites = sorted
+	d.mu.Unlock()
+}
+
+// Sites returns sites of the network ordered by blog id
+func (d *Directory) Sites() []model.Site {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]model.Site(nil), d.sites...)
+}
+
+// Tables returns table names of the sites
+func (d *Directory) Tables() []model.Tables {
+	sites := d.Sites()
+	tables := make([]model.Tables, len(sites))
+	for i, site := range sites {
+		tables[i] = model.NewSiteTables(d.base.TablePrefix, site.TablePrefix(d.base.TablePrefix))
+	}
+	return tables
+}
+
+// TablePrefixes returns table prefixes of the sites
+func (d *Directory) TablePrefixes() []string {
+	sites := d.Sites()
+	prefixes := make([]string, len(sites))
+	for i, site := range sites {
+		prefixes[i] = site.TablePrefix(d.base.TablePrefix)
+	}
+	return prefixes
+}
+
+// Match returns site of the request host and path, the site with the longest path wins among sites of the host.
+// Sites are matched only by path if no site has the host, so a network can also be served from single API host.
+// hostMatched is true if the site is matched by the host
+func (d *Directory) Match(host string, path string) (site model.Site, hostMatched bool, ok bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	sites := d.Sites()
+	candidates := make([]model.Site, 0, len(sites))
+	for _, s := range sites {
+		if strings.EqualFold(s.Domain, host) {
+			candidates = append(candidates, s)
+		}
+	}
+	hostMatched = len(candidates) > 0
+	if !hostMatched {
+		candidates = sites
+	}
+
+	for _, s := range candidates {
+		if s.MatchPath(path) && (!ok || len(s.Path) > len(site.Path)) {
+			site, ok = s, true
+		}
+	}
+	return site, hostMatched, ok
+}
+
+// SwitchToSite returns context whose APIConfig is config of the site like switch_to_blog does, repositories that are
+// called with the context query tables of the site
+func (d *Directory) SwitchToSite(ctx context.Context, site model.Site) context.Context {
+	apiHost := site.URL(scheme(d.base.APIHost))
+	ctx = context.WithValue(ctx, model.APIConfigKey, siteConfig(site, d.base, apiHost))
+	return context.WithValue(ctx, siteKey{}, site)
+}
+
+// Middleware routes request to its site by host or path prefix, it sets APIConfig of the site in request context
+// in place of APIConfig of SetAPIContext and removes path of the site from request path, so API routes match the rest of it
+func (d *Directory) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site, hostMatched, ok := d.Match(r.Host, r.URL.Path)
+		if !ok {
+			resthttp.EncodeJSONResponse(r.Context(), w, resthttp.NewRouteNotFoundResponse())
+			return
+		}
+
+		sitePath := strings.TrimSuffix(site.Path, "/")
+		apiHost := strings.TrimSuffix(d.base.APIHost, "/") + sitePath
+		if hostMatched {
+			apiHost = scheme(d.base.APIHost) + "://" + r.Host + sitePath
+		}
+
+		ctx := context.WithValue(r.Context(), model.APIConfigKey, siteConfig(site, d.base, apiHost))
+		ctx = context.WithValue(ctx, siteKey{}, site)
+
+		u := *r.URL
+		u.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(u.Path, sitePath), "/")
+		u.RawPath = ""
+		r = r.WithContext(ctx)
+		r.URL = &u
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SiteFromContext returns site of the request that is set by Middleware or SwitchToSite
+func SiteFromContext(ctx context.Context) (model.Site, bool) {
+	site, ok := ctx.Value(siteKey{}).(model.Site)
+	return site, ok
+}
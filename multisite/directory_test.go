@@ -0,0 +1,154 @@
+package multisite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockmultisite "github.com/qreasio/restlr/multisite/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testNetworkConfig = model.APIConfig{
+	APIHost:     "https://api.example.com",
+	SiteURL:     "https://example.com",
+	TablePrefix: "wp_",
+	APIPath:     "wp-json/wp",
+	Version:     "v2",
+	UploadPath:  "wp-content/uploads",
+	UploadDir:   "uploads",
+}
+
+var testSites = []model.Site{
+	{ID: 3, NetworkID: 1, Domain: "shop.example.com", Path: "/", Public: true},
+	{ID: 1, NetworkID: 1, Domain: "example.com", Path: "/", Public: true},
+	{ID: 2, NetworkID: 1, Domain: "example.com", Path: "/news/", Public: true},
+	{ID: 4, NetworkID: 1, Domain: "example.com", Path: "/private/", Public: false},
+	{ID: 5, NetworkID: 2, Domain: "other.org", Path: "/", Public: true},
+}
+
+func newTestDirectory() *Directory {
+	d := NewDirectory(nil, testNetworkConfig)
+	d.SetSites(testSites)
+	return d
+}
+
+func TestSite_TablePrefix(t *testing.T) {
+	assert.Equal(t, "wp_", model.Site{ID: 1}.TablePrefix("wp_"))
+	assert.Equal(t, "wp_2_", model.Site{ID: 2}.TablePrefix("wp_"))
+}
+
+func TestSiteConfig(t *testing.T) {
+	config := siteConfig(model.Site{ID: 2, Domain: "example.com", Path: "/news/"}, testNetworkConfig, "https://example.com/news")
+	assert.Equal(t, "wp_2_", config.TablePrefix)
+	assert.Equal(t, "wp_", config.BaseTablePrefix)
+	assert.Equal(t, "https://example.com/news", config.SiteURL)
+	assert.Equal(t, "https://example.com/news/wp-json/wp/v2", config.APIBaseURL)
+	assert.Equal(t, "wp-content/uploads/sites/2", config.UploadPath)
+	assert.Equal(t, "uploads/sites/2", config.UploadDir)
+
+	tables := config.Tables()
+	assert.Equal(t, "wp_2_posts", tables.Posts)
+	assert.Equal(t, "wp_users", tables.Users)
+	assert.Equal(t, "wp_usermeta", tables.UserMeta)
+
+	config = siteConfig(model.Site{ID: 1, Domain: "example.com", Path: "/"}, testNetworkConfig, "https://example.com")
+	assert.Equal(t, "wp_", config.TablePrefix)
+	assert.Equal(t, "https://example.com", config.SiteURL)
+	assert.Equal(t, "wp-content/uploads", config.UploadPath)
+}
+
+func TestDirectory_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoMock := mockmultisite.NewMockRepository(ctrl)
+	repoMock.EXPECT().Sites(gomock.Any()).Return(testSites, nil)
+
+	d := NewDirectory(repoMock, testNetworkConfig)
+	assert.NoError(t, d.Refresh(context.Background()))
+
+	sites := d.Sites()
+	assert.Len(t, sites, len(testSites))
+	for i, site := range sites {
+		assert.Equal(t, uint64(i+1), site.ID)
+	}
+	assert.Equal(t, "wp_2_posts", d.Tables()[1].Posts)
+	assert.Equal(t, "wp_users", d.Tables()[1].Users)
+	assert.Equal(t, []string{"wp_", "wp_2_"}, d.TablePrefixes()[:2])
+}
+
+func TestDirectory_Match(t *testing.T) {
+	d := newTestDirectory()
+
+	cases := []struct {
+		host        string
+		path        string
+		id          uint64
+		hostMatched bool
+	}{
+		{"example.com", "/wp-json/wp/v2/posts", 1, true},
+		{"EXAMPLE.com:443", "/news/wp-json/wp/v2/posts", 2, true},
+		{"example.com", "/news", 2, true},
+		{"example.com", "/newsroom/wp-json/wp/v2/posts", 1, true},
+		{"shop.example.com", "/news/wp-json/wp/v2/posts", 3, true},
+		{"localhost:8080", "/news/wp-json/wp/v2/posts", 2, false},
+		{"localhost:8080", "/wp-json/wp/v2/posts", 1, false},
+	}
+	for _, c := range cases {
+		site, hostMatched, ok := d.Match(c.host, c.path)
+		assert.True(t, ok, c.host+c.path)
+		assert.Equal(t, c.id, site.ID, c.host+c.path)
+		assert.Equal(t, c.hostMatched, hostMatched, c.host+c.path)
+	}
+
+	_, _, ok := NewDirectory(nil, testNetworkConfig).Match("example.com", "/")
+	assert.False(t, ok)
+}
+
+func TestDirectory_Middleware(t *testing.T) {
+	d := newTestDirectory()
+
+	var config model.APIConfig
+	var site model.Site
+	var path string
+	handler := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		config = r.Context().Value(model.APIConfigKey).(model.APIConfig)
+		site, _ = SiteFromContext(r.Context())
+		path = r.URL.Path
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/news/wp-json/wp/v2/posts?page=2", nil))
+	assert.Equal(t, uint64(2), site.ID)
+	assert.Equal(t, "/wp-json/wp/v2/posts", path)
+	assert.Equal(t, "wp_2_", config.TablePrefix)
+	assert.Equal(t, "https://example.com/news", config.APIHost)
+	assert.Equal(t, "https://example.com/news", config.SiteURL)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost:8080/news/wp-json/wp/v2/posts", nil))
+	assert.Equal(t, uint64(2), site.ID)
+	assert.Equal(t, "/wp-json/wp/v2/posts", path)
+	assert.Equal(t, "https://api.example.com/news", config.APIHost)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://shop.example.com/wp-json/wp/v2/posts", nil))
+	assert.Equal(t, uint64(3), site.ID)
+	assert.Equal(t, "/wp-json/wp/v2/posts", path)
+	assert.Equal(t, "wp_3_", config.TablePrefix)
+	assert.Equal(t, "https://shop.example.com", config.APIHost)
+}
+
+func TestDirectory_SwitchToSite(t *testing.T) {
+	d := newTestDirectory()
+
+	ctx := d.SwitchToSite(context.Background(), testSites[0])
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	assert.Equal(t, "wp_3_", config.TablePrefix)
+	assert.Equal(t, "https://shop.example.com", config.APIHost)
+	assert.Equal(t, "wp_3_posts", model.TablesFromContext(ctx).Posts)
+	assert.Equal(t, "wp_users", model.TablesFromContext(ctx).Users)
+
+	site, ok := SiteFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), site.ID)
+}
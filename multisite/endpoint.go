@@ -0,0 +1,16 @@
+package multisite
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeListNetworkPostsEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.ListRequest)
+		return s.ListNetworkPosts(ctx, req)
+	}
+	return endpoint
+}
@@ -0,0 +1,63 @@
+package multisite
+
+import (
+	"context"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository is interface for functions to read sites of the network from database
+type Repository interface {
+	Sites(ctx context.Context) ([]model.Site, error)
+}
+
+type repository struct {
+	db         *dialect.DB
+	basePrefix string
+}
+
+// NewRepository is function to create new repository struct instance that implements Repository interface,
+// blogs and site tables have the base prefix of the network
+func NewRepository(db *dialect.DB, basePrefix string) Repository {
+	return &repository{
+		db:         db,
+		basePrefix: basePrefix,
+	}
+}
+
+// Sites returns sites of every network that are not archived, deleted or marked as spam, ordered by blog id
+func (repo *repository) Sites(ctx context.Context) ([]model.Site, error) {
+	tables := model.NewTables(repo.basePrefix)
+
+	sqlQuery := `SELECT b.blog_id, b.site_id, b.domain, b.path, b.public FROM ` + tables.Blogs + ` b ` +
+		`INNER JOIN ` + tables.Site + ` s ON s.id = b.site_id ` +
+		`WHERE b.archived = 0 AND b.deleted = 0 AND b.spam = 0 ORDER BY b.blog_id`
+
+	rows, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": repo.basePrefix,
+			"func":   "conn.QueryContext",
+		}).Errorf("Failed to query sites: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sites := make([]model.Site, 0)
+	for rows.Next() {
+		var site model.Site
+		if err = rows.Scan(&site.ID, &site.NetworkID, &site.Domain, &site.Path, &site.Public); err != nil {
+			log.WithFields(log.Fields{
+				"params": repo.basePrefix,
+				"func":   "rows.Scan",
+			}).Errorf("Failed to scan site: %s", err)
+			return nil, err
+		}
+		sites = append(sites, site)
+	}
+
+	return sites, rows.Err()
+}
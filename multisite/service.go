@@ -0,0 +1,127 @@
+package multisite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/shared"
+	log "github.com/sirupsen/logrus"
+)
+
+// Service is interface of network-wide functions of multisite
+type Service interface {
+	ListNetworkPosts(ctx context.Context, params model.ListRequest) (interface{}, error)
+}
+
+type service struct {
+	directory *Directory
+	post      post.Service
+}
+
+// NewService is a simple helper function to create a service instance
+func NewService(directory *Directory, postService post.Service) Service {
+	return &service{
+		directory: directory,
+		post:      postService,
+	}
+}
+
+// NetworkPost is post of network-wide feed with id of its site
+type NetworkPost struct {
+	SiteID uint64      `json:"site_id"`
+	Post   interface{} `json:"post"`
+
+	date time.Time
+}
+
+// ListNetworkPosts returns the newest posts of public sites in network of the request site, posts of every site are listed
+// by post service with context that is switched to the site, then they are merged by date like a single list
+func (s *service) ListNetworkPosts(ctx context.Context, params model.ListRequest) (interface{}, error) {
+	log.WithFields(log.Fields{
+		"params": params,
+	}).Debug("service.ListNetworkPosts")
+
+	var sites []model.Site
+	current, _ := SiteFromContext(ctx)
+	for _, site := range s.directory.Sites() {
+		if site.NetworkID == current.NetworkID && site.Public {
+			sites = append(sites, site)
+		}
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PerPage < 1 {
+		params.PerPage = 1
+	}
+
+	// every site lists posts of all pages up to the requested page, so the merged list has the posts of the page
+	offset := (params.Page - 1) * params.PerPage
+	siteParams := params
+	siteParams.Page = 1
+	siteParams.PerPage = offset + params.PerPage
+	siteParams.OrderBy = nil
+
+	results := make([][]NetworkPost, len(sites))
+	g, gctx := shared.NewQueryGroup(ctx)
+	for i, site := range sites {
+		i, site := i, site
+		g.Go(func() error {
+			items, err := s.post.ListPosts(s.directory.SwitchToSite(gctx, site), siteParams)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"params": fmt.Sprintf("site: %d, params: %v", site.ID, siteParams),
+					"func":   "s.post.ListPosts",
+				}).Errorf("Failed to list posts of site: %s", err)
+				return err
+			}
+			results[i] = networkPosts(site.ID, items)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	posts := make([]NetworkPost, 0)
+	for _, result := range results {
+		posts = append(posts, result...)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].date.After(posts[j].date)
+	})
+
+	if offset >= len(posts) {
+		return []NetworkPost{}, nil
+	}
+	if offset+params.PerPage < len(posts) {
+		posts = posts[:offset+params.PerPage]
+	}
+	return posts[offset:], nil
+}
+
+// networkPosts returns posts of list posts result with the site id, posts are dated by GMT date if it is available
+// because sites may have different timezones
+func networkPosts(siteID uint64, items interface{}) []NetworkPost {
+	var posts []NetworkPost
+	switch items := items.(type) {
+	case []*model.Post:
+		for _, p := range items {
+			date := time.Time(p.Date)
+			if p.DateGmt != nil {
+				date = time.Time(*p.DateGmt)
+			}
+			posts = append(posts, NetworkPost{SiteID: siteID, Post: p, date: date})
+		}
+	case []*model.ContentBase:
+		for _, p := range items {
+			posts = append(posts, NetworkPost{SiteID: siteID, Post: p, date: time.Time(p.Date)})
+		}
+	}
+	return posts
+}
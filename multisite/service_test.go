@@ -0,0 +1,53 @@
+package multisite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockpost "github.com/qreasio/restlr/post/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPost(id uint64, date string) *model.Post {
+	p := &model.Post{}
+	p.ID = id
+	gmt, _ := time.Parse("2006-01-02 15:04", date)
+	dateGmt := strfmt.DateTime(gmt)
+	p.DateGmt = &dateGmt
+	return p
+}
+
+func TestService_ListNetworkPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	postServiceMock := mockpost.NewMockService(ctrl)
+
+	sitePosts := map[string][]*model.Post{
+		"wp_":   {newTestPost(10, "2020-01-05 10:00"), newTestPost(11, "2020-01-01 10:00")},
+		"wp_2_": {newTestPost(20, "2020-01-04 10:00"), newTestPost(21, "2020-01-03 10:00")},
+		"wp_3_": {newTestPost(30, "2020-01-06 10:00")},
+	}
+	postServiceMock.EXPECT().ListPosts(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, params model.ListRequest) (interface{}, error) {
+		assert.Equal(t, 1, params.Page)
+		assert.Equal(t, 4, params.PerPage)
+		config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+		return sitePosts[config.TablePrefix], nil
+	}).Times(3)
+
+	d := newTestDirectory()
+	s := NewService(d, postServiceMock)
+	ctx := d.SwitchToSite(context.Background(), testSites[1])
+
+	res, err := s.ListNetworkPosts(ctx, model.ListRequest{ListParams: model.ListParams{ListFilter: model.ListFilter{Page: 2, PerPage: 2}}})
+	assert.NoError(t, err)
+
+	posts := res.([]NetworkPost)
+	assert.Len(t, posts, 2)
+	assert.Equal(t, uint64(2), posts[0].SiteID)
+	assert.Equal(t, uint64(20), posts[0].Post.(*model.Post).ID)
+	assert.Equal(t, uint64(2), posts[1].SiteID)
+	assert.Equal(t, uint64(21), posts[1].Post.(*model.Post).ID)
+}
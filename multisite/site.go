@@ -0,0 +1,33 @@
+package multisite
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qreasio/restlr/model"
+)
+
+// siteConfig returns APIConfig of the site that is derived from config of the network, links of the API have the api host.
+// Uploads of sites other than the main site are in sites/<blog id> of the upload path like WP does
+func siteConfig(site model.Site, base model.APIConfig, apiHost string) model.APIConfig {
+	config := base
+	config.BaseTablePrefix = base.TablePrefix
+	config.TablePrefix = site.TablePrefix(base.TablePrefix)
+	config.SiteURL = site.URL(scheme(base.SiteURL))
+	config.APIHost = apiHost
+	config.APIBaseURL = fmt.Sprintf("%s/%s/%s", config.APIHost, config.APIPath, config.Version)
+	if site.ID != model.MainSiteID {
+		config.UploadPath = fmt.Sprintf("%s/sites/%d", base.UploadPath, site.ID)
+		config.UploadDir = fmt.Sprintf("%s/sites/%d", base.UploadDir, site.ID)
+	}
+	return config
+}
+
+// scheme returns scheme of the url, it is http if the url has no scheme
+func scheme(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" {
+		return "http"
+	}
+	return u.Scheme
+}
@@ -0,0 +1,28 @@
+package multisite
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/post"
+)
+
+// MakeHTTPHandler returns http handler of network-wide endpoints, posts feed accepts the same parameters as posts endpoint
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	ListNetworkPostsHandler := kithttp.NewServer(
+		instrument("multisite.ListNetworkPosts", makeListNetworkPostsEndpoint(s)),
+		post.DecodeListPostsRequest,
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	r.Method(http.MethodGet, "/posts", ListNetworkPostsHandler)
+
+	return r
+}
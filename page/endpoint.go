@@ -6,6 +6,7 @@ import (
 	"github.com/go-kit/kit/endpoint"
 	"github.com/qreasio/restlr/http"
 	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post"
 )
 
 func makeGetPageEndpoint(s Service) endpoint.Endpoint {
@@ -16,7 +17,10 @@ func makeGetPageEndpoint(s Service) endpoint.Endpoint {
 		if err == model.ErrInvalidPostID {
 			return http.NewInvalidPostResponse(), nil
 		}
-		return res, nil
+		if err == model.ErrIncorrectPassword {
+			return http.NewIncorrectPasswordResponse(), nil
+		}
+		return res, err
 	}
 	return endpoint
 }
@@ -28,3 +32,39 @@ func makeListPagesEndpoint(s Service) endpoint.Endpoint {
 	}
 	return endpoint
 }
+
+func makeCreatePageEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.WritePostRequest)
+		res, err := s.CreatePage(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotCreateCode, "The page cannot be created."), nil
+		}
+		return http.CreatedResponse{Item: res, Location: post.ItemLocation(ctx, res)}, nil
+	}
+	return endpoint
+}
+
+func makeUpdatePageEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.WritePostRequest)
+		res, err := s.UpdatePage(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotUpdateCode, "The page cannot be updated."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
+
+func makeDeletePageEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.DeleteItemRequest)
+		res, err := s.DeletePage(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotDeleteCode, "The page cannot be deleted."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
@@ -66,3 +66,48 @@ func (mr *MockServiceMockRecorder) ListPages(ctx, params interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPages", reflect.TypeOf((*MockService)(nil).ListPages), ctx, params)
 }
+
+// CreatePage mocks base method
+func (m *MockService) CreatePage(ctx context.Context, req model.WritePostRequest) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePage", ctx, req)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePage indicates an expected call of CreatePage
+func (mr *MockServiceMockRecorder) CreatePage(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePage", reflect.TypeOf((*MockService)(nil).CreatePage), ctx, req)
+}
+
+// UpdatePage mocks base method
+func (m *MockService) UpdatePage(ctx context.Context, req model.WritePostRequest) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePage", ctx, req)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePage indicates an expected call of UpdatePage
+func (mr *MockServiceMockRecorder) UpdatePage(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePage", reflect.TypeOf((*MockService)(nil).UpdatePage), ctx, req)
+}
+
+// DeletePage mocks base method
+func (m *MockService) DeletePage(ctx context.Context, req model.DeleteItemRequest) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePage", ctx, req)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePage indicates an expected call of DeletePage
+func (mr *MockServiceMockRecorder) DeletePage(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePage", reflect.TypeOf((*MockService)(nil).DeletePage), ctx, req)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/qreasio/restlr/model"
 	"github.com/qreasio/restlr/post"
@@ -16,6 +17,9 @@ import (
 type Service interface {
 	GetPage(ctx context.Context, req model.GetItemRequest) (interface{}, error)
 	ListPages(ctx context.Context, params model.ListRequest) (interface{}, error)
+	CreatePage(ctx context.Context, req model.WritePostRequest) (interface{}, error)
+	UpdatePage(ctx context.Context, req model.WritePostRequest) (interface{}, error)
+	DeletePage(ctx context.Context, req model.DeleteItemRequest) (interface{}, error)
 }
 
 // service is struct that will implement Service interface and store related repositories
@@ -47,35 +51,59 @@ func (s *service) GetPage(ctx context.Context, params model.GetItemRequest) (int
 		return nil, err
 	}
 
-	p.Meta = []map[string]string{}
-	metas, err := s.shared.PostMetasByPostIDs(ctx, []uint64{p.ID})
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": params.ID,
-			"func":   "s.shared.PostMetasByPostIDs",
-		}).Errorf("Failed to get post meta by id: %s", err)
+	// mask content of password protected page and reject request with incorrect password
+	if err = p.SetProtectedContent(params.Password); err != nil {
 		return nil, err
 	}
 
-	p.SetFeaturedMediaID(metas)
-	p.SetLinks(ctx)
+	p.Meta = []map[string]string{}
+
+	// metas, ancestors, author and predecessor version are independent, so they are pulled concurrently
+	var metas map[uint64]map[string]string
+	var postData *model.RawPost
+	var predecessors map[uint64]map[int]uint64
+	g, gctx := shared.NewQueryGroup(ctx)
+	g.Go(func() (err error) {
+		metas, err = s.shared.PostMetasByPostIDs(gctx, []uint64{p.ID})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": params.ID,
+				"func":   "s.shared.PostMetasByPostIDs",
+			}).Errorf("Failed to get post meta by id: %s", err)
+		}
+		return err
+	})
+	g.Go(func() error {
+		return s.SetPageLinks(gctx, []*model.Post{p})
+	})
 	// pull required related data to construct complete post response
-	postData, err := s.PullRawPostData(ctx, []uint64{p.ID}, []uint64{p.Author}, params.IsEmbed)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": params.ID,
-			"func":   "s.PullRawPostData",
-		}).Errorf("Failed to pull raw post data: %s", err)
+	g.Go(func() (err error) {
+		postData, err = s.PullRawPostData(gctx, []uint64{p.ID}, []uint64{p.Author}, params.IsEmbed)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": params.ID,
+				"func":   "s.PullRawPostData",
+			}).Errorf("Failed to pull raw post data: %s", err)
+		}
+		return err
+	})
+	g.Go(func() (err error) {
+		predecessors, err = s.page.GetPredecessorVersion(gctx, []uint64{p.ID})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": params.ID,
+				"func":   "s.page.GetPredecessorVersion",
+			}).Errorf("Failed to get predecessor version: %s", err)
+		}
+		return err
+	})
+	if err = g.Wait(); err != nil {
 		return nil, err
 	}
 
-	if err = s.SetPredecessorVersion(ctx, p); err != nil {
-		log.WithFields(log.Fields{
-			"params": params.ID,
-			"func":   "s.SetPredecessorVersion",
-		}).Errorf("Failed to set predecessor version: %s", err)
-		return nil, err
-	}
+	p.SetFeaturedMediaID(metas)
+	p.SetLinks(ctx)
+	s.SetPredecessorVersion(ctx, p, predecessors)
 
 	if params.IsEmbed {
 		err := s.SetEmbedded(ctx, p, postData.User[p.Author])
@@ -124,28 +152,43 @@ func (s *service) ListPages(ctx context.Context, params model.ListRequest) (inte
 		}).Errorf("Failed to get posts by ids: %s", err)
 		return nil, err
 	}
-	// pull required related data to construct complete post response
-	postData, err := s.PullRawPostData(ctx, postIDList, authorIDList, params.IsEmbed)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": fmt.Sprintf("postIDList: %v, authors :%v, is_embed: %t", postIDList, authorIDList, params.IsEmbed),
-			"func":   "s.PullRawPostData",
-		}).Errorf("Failed to pull raw post data: %s", err)
-		return nil, err
-	}
-
-	predecessors, err := s.page.GetPredecessorVersion(ctx, postIDList)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": postIDList,
-			"func":   "s.page.GetPredecessorVersion",
-		}).Errorf("Failed to get predecessor version: %s", err)
+	// pull required related data to construct complete post response, authors, predecessor versions and ancestors
+	// are independent, so they are pulled concurrently
+	var postData *model.RawPost
+	var predecessors map[uint64]map[int]uint64
+	g, gctx := shared.NewQueryGroup(ctx)
+	g.Go(func() (err error) {
+		postData, err = s.PullRawPostData(gctx, postIDList, authorIDList, params.IsEmbed)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("postIDList: %v, authors :%v, is_embed: %t", postIDList, authorIDList, params.IsEmbed),
+				"func":   "s.PullRawPostData",
+			}).Errorf("Failed to pull raw post data: %s", err)
+		}
+		return err
+	})
+	g.Go(func() (err error) {
+		predecessors, err = s.page.GetPredecessorVersion(gctx, postIDList)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": postIDList,
+				"func":   "s.page.GetPredecessorVersion",
+			}).Errorf("Failed to get predecessor version: %s", err)
+		}
+		return err
+	})
+	g.Go(func() error {
+		return s.SetPageLinks(gctx, posts)
+	})
+	if err = g.Wait(); err != nil {
 		return nil, err
 	}
 
 	var basePosts = make([]*model.ContentBase, 0)
 
 	for _, p := range posts {
+		// page list never accepts password, so content of password protected page is always masked
+		p.SetProtectedContent(nil)
 		p.FeaturedMedia = postData.FeaturedMedia[p.ID]
 		p.SetLinks(ctx)
 		p.SetPredecessorVersion(model.GetBaseURL(ctx), predecessors[p.ID][0])
@@ -205,10 +248,161 @@ func (s *service) SetEmbedded(ctx context.Context, p *model.Post, user *model.Us
 	return nil
 }
 
-func (s *service) SetPredecessorVersion(ctx context.Context, page *model.Post) (err error) {
-	predecessorVersions, err := s.page.GetPredecessorVersion(ctx, []uint64{page.ID})
+// SetPredecessorVersion sets link of predecessor version of the page from its predecessor versions
+func (s *service) SetPredecessorVersion(ctx context.Context, page *model.Post, predecessorVersions map[uint64]map[int]uint64) {
 	href := fmt.Sprintf("%s/pages/%d/revisions/%d", model.GetBaseURL(ctx), page.ID, predecessorVersions[page.ID][0])
 	versionLink := model.VersionLink{ID: predecessorVersions[page.ID][0], Href: href}
 	page.Links.PredecessorVersion = append(page.Links.PredecessorVersion, versionLink)
-	return err
+}
+
+// SetPageLinks sets link of the pages from slugs of their ancestors, so link of every descendant follows its ancestor when it is moved or renamed
+func (s *service) SetPageLinks(ctx context.Context, pages []*model.Post) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	idList := make([]uint64, len(pages))
+	for i, p := range pages {
+		idList[i] = p.ID
+	}
+
+	nodes, err := s.page.PostAncestors(ctx, idList)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": idList,
+			"func":   "s.page.PostAncestors",
+		}).Errorf("Failed to get page ancestors: %s", err)
+		return err
+	}
+
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	for _, p := range pages {
+		// draft and pending page don't have pretty permalink yet
+		if p.Slug == "" || p.Status == model.DraftStatus || p.Status == model.PendingStatus {
+			p.Link = fmt.Sprintf("%s/?page_id=%d", config.SiteURL, p.ID)
+			continue
+		}
+		p.Link = fmt.Sprintf("%s/%s/", config.SiteURL, post.PostPath(nodes, p.ID))
+	}
+
+	return nil
+}
+
+// CreatePage inserts new page with its metas and revision in a single transaction and returns the created page
+func (s *service) CreatePage(ctx context.Context, req model.WritePostRequest) (interface{}, error) {
+	record, err := post.NewPostRecord(ctx, s.shared, model.PageType)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": req,
+			"func":   "post.NewPostRecord",
+		}).Errorf("Failed to create new page record: %s", err)
+		return nil, err
+	}
+
+	return s.savePage(ctx, record, nil, req)
+}
+
+// UpdatePage updates existing page with its metas and revision in a single transaction and returns the updated page
+func (s *service) UpdatePage(ctx context.Context, req model.WritePostRequest) (interface{}, error) {
+	record, err := s.page.PostRecordByID(ctx, *req.ID)
+	if err == sql.ErrNoRows || (err == nil && record.Type != model.PageType) {
+		return nil, model.ErrInvalidPostID
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": *req.ID,
+			"func":   "s.page.PostRecordByID",
+		}).Errorf("Failed to get page record by id: %s", err)
+		return nil, err
+	}
+
+	previous := *record
+	return s.savePage(ctx, record, &previous, req)
+}
+
+// savePage applies write request to the record and saves it, previous is nil for new page
+func (s *service) savePage(ctx context.Context, record *model.PostRecord, previous *model.PostRecord, req model.WritePostRequest) (interface{}, error) {
+	loc, err := post.SiteLocation(ctx, s.shared)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"func": "post.SiteLocation",
+		}).Errorf("Failed to get site location: %s", err)
+		return nil, err
+	}
+
+	if req.Parent != nil {
+		record.Parent = *req.Parent
+	}
+	if req.MenuOrder != nil {
+		record.MenuOrder = *req.MenuOrder
+	}
+	if err = post.ApplyWriteRequest(record, req, loc, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err = s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		// parent is validated inside transaction so the hierarchy can't be changed between the check and the write
+		if err := post.ValidateParent(ctx, s.page, record); err != nil {
+			return err
+		}
+		return post.SaveRecord(ctx, s.page, s.shared, record, previous, req)
+	})
+	if err != nil {
+		if _, ok := err.(*model.ParamError); !ok {
+			log.WithFields(log.Fields{
+				"params": req,
+				"func":   "s.shared.WithTransaction",
+			}).Errorf("Failed to save page: %s", err)
+		}
+		return nil, err
+	}
+
+	return s.GetPage(ctx, model.GetItemRequest{ID: &record.ID, Password: &record.Password})
+}
+
+// DeletePage moves page to trash, or deletes it permanently with its metas if force is true.
+// Children of permanently deleted page are moved to its parent
+func (s *service) DeletePage(ctx context.Context, req model.DeleteItemRequest) (interface{}, error) {
+	record, err := s.page.PostRecordByID(ctx, *req.ID)
+	if err == sql.ErrNoRows || (err == nil && record.Type != model.PageType) {
+		return nil, model.ErrInvalidPostID
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": *req.ID,
+			"func":   "s.page.PostRecordByID",
+		}).Errorf("Failed to get page record by id: %s", err)
+		return nil, err
+	}
+
+	previous, err := s.GetPage(ctx, model.GetItemRequest{ID: req.ID, Password: &record.Password})
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := post.SiteLocation(ctx, s.shared)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		if !req.Force {
+			return post.TrashRecord(ctx, s.page, s.shared, record, loc, time.Now())
+		}
+		return post.DeleteRecord(ctx, s.page, s.shared, record)
+	})
+	if err != nil {
+		if err != model.ErrAlreadyTrashed {
+			log.WithFields(log.Fields{
+				"params": req,
+				"func":   "s.shared.WithTransaction",
+			}).Errorf("Failed to delete page: %s", err)
+		}
+		return nil, err
+	}
+
+	if req.Force {
+		return model.DeletedItem{Deleted: true, Previous: previous}, nil
+	}
+	return s.GetPage(ctx, model.GetItemRequest{ID: req.ID, Password: &record.Password})
 }
@@ -2,6 +2,7 @@ package page
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -9,6 +10,7 @@ import (
 	"github.com/qreasio/restlr/post"
 	mockpost "github.com/qreasio/restlr/post/mock"
 	mockshared "github.com/qreasio/restlr/shared/mock"
+	"github.com/qreasio/restlr/toolbox"
 	mockuser "github.com/qreasio/restlr/user/mock"
 	"github.com/stretchr/testify/assert"
 )
@@ -31,26 +33,32 @@ func TestService_GetPage(t *testing.T) {
 	page1.ID = id
 	page1.Type = model.PageType
 	page1.Author = id
+	page1.Slug = "child"
+	page1.Status = model.PublishStatus
 
 	predecessorVersion := map[uint64]map[int]uint64{id: map[int]uint64{0: id}}
 
-	postRepoMock.EXPECT().PostByID(ctx, id, "page").Return(&page1, nil)
-	postRepoMock.EXPECT().PostByID(ctx, invalidID, "page").Return(nil, model.ErrInvalidPostID)
-	postRepoMock.EXPECT().GetPredecessorVersion(ctx, idList).Return(predecessorVersion, nil)
+	postRepoMock.EXPECT().PostByID(gomock.Any(), id, "page").Return(&page1, nil)
+	postRepoMock.EXPECT().PostByID(gomock.Any(), invalidID, "page").Return(nil, model.ErrInvalidPostID)
+	postRepoMock.EXPECT().GetPredecessorVersion(gomock.Any(), idList).Return(predecessorVersion, nil)
+	postRepoMock.EXPECT().PostAncestors(gomock.Any(), idList).Return(map[uint64]*model.PostNode{
+		id: &model.PostNode{ID: id, Parent: 5, Name: "child", Type: model.PageType},
+		5:  &model.PostNode{ID: 5, Name: "parent", Type: model.PageType},
+	}, nil)
 	postRepo := postRepoMock
 
 	thumbnailID := map[string]string{"_thumbnail_id": "10"}
 	metas := map[uint64]map[string]string{id: thumbnailID}
 
 	sharedRepoMock := mockshared.NewMockRepository(ctrl)
-	sharedRepoMock.EXPECT().PostMetasByPostIDs(ctx, idList).Return(metas, nil)
+	sharedRepoMock.EXPECT().PostMetasByPostIDs(gomock.Any(), idList).Return(metas, nil)
 	sharedRepo := sharedRepoMock
 
 	user := model.UserDetail{User: model.User{ID: 1}}
 	userMap := map[uint64]*model.UserDetail{id: &user}
 	userRepoMock := mockuser.NewMockRepository(ctrl)
 
-	userRepoMock.EXPECT().GetUserByIDList(ctx, idList).Return(userMap, nil)
+	userRepoMock.EXPECT().GetUserByIDList(gomock.Any(), idList).Return(userMap, nil)
 	userRepo := userRepoMock
 
 	s := NewService(postRepo, sharedRepo, userRepo)
@@ -63,6 +71,7 @@ func TestService_GetPage(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, page.ID, id)
+	assert.Equal(t, "/parent/child/", page.Link)
 
 	invalidParam := model.GetItemRequest{ID: &invalidID}
 	_, err = s.GetPage(ctx, invalidParam)
@@ -70,3 +79,91 @@ func TestService_GetPage(t *testing.T) {
 	assert.NotNil(t, err)
 
 }
+
+func TestService_GetPageProtected(t *testing.T) {
+	ctx = context.WithValue(ctx, model.APIConfigKey, apiConfig)
+	ctrl := gomock.NewController(t)
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+
+	id := uint64(2)
+	idList := []uint64{id}
+
+	newProtectedPage := func() *model.Post {
+		page := post.NewPost()
+		page.ID = id
+		page.Type = model.PageType
+		page.Author = id
+		page.Content.Rendered = "secret content"
+		page.Excerpt.Rendered = "secret excerpt"
+		page.Password = toolbox.StringPointer("secret")
+		return &page
+	}
+
+	postRepoMock.EXPECT().PostByID(gomock.Any(), id, "page").Return(newProtectedPage(), nil)
+	postRepoMock.EXPECT().PostByID(gomock.Any(), id, "page").Return(newProtectedPage(), nil)
+	postRepoMock.EXPECT().PostByID(gomock.Any(), id, "page").Return(newProtectedPage(), nil)
+	postRepoMock.EXPECT().GetPredecessorVersion(gomock.Any(), idList).Return(map[uint64]map[int]uint64{}, nil).Times(2)
+	postRepoMock.EXPECT().PostAncestors(gomock.Any(), idList).Return(map[uint64]*model.PostNode{}, nil).Times(2)
+
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().PostMetasByPostIDs(gomock.Any(), idList).Return(map[uint64]map[string]string{}, nil).Times(2)
+
+	user := model.UserDetail{User: model.User{ID: id}}
+	userRepoMock := mockuser.NewMockRepository(ctrl)
+	userRepoMock.EXPECT().GetUserByIDList(gomock.Any(), idList).Return(map[uint64]*model.UserDetail{id: &user}, nil).Times(2)
+
+	s := NewService(postRepoMock, sharedRepoMock, userRepoMock)
+
+	// without password, content and excerpt are masked
+	p, err := s.GetPage(ctx, model.GetItemRequest{ID: &id})
+	page := p.(*model.Post)
+	assert.Nil(t, err)
+	assert.True(t, page.Content.Protected)
+	assert.True(t, page.Excerpt.Protected)
+	assert.Equal(t, "", page.Content.Rendered)
+	assert.Equal(t, "", page.Excerpt.Rendered)
+	assert.Nil(t, page.Password)
+
+	// with correct password, content and excerpt are rendered
+	p, err = s.GetPage(ctx, model.GetItemRequest{ID: &id, Password: toolbox.StringPointer("secret")})
+	page = p.(*model.Post)
+	assert.Nil(t, err)
+	assert.True(t, page.Content.Protected)
+	assert.Equal(t, "secret content", page.Content.Rendered)
+	assert.Equal(t, "secret excerpt", page.Excerpt.Rendered)
+	assert.Nil(t, page.Password)
+
+	// with incorrect password, it returns error
+	_, err = s.GetPage(ctx, model.GetItemRequest{ID: &id, Password: toolbox.StringPointer("wrong")})
+	assert.Equal(t, model.ErrIncorrectPassword, err)
+}
+
+func TestService_UpdatePageParentLoop(t *testing.T) {
+	ctx = context.WithValue(ctx, model.APIConfigKey, apiConfig)
+	ctrl := gomock.NewController(t)
+
+	id := uint64(1)
+	record := &model.PostRecord{ID: id, Author: id, Type: model.PageType, Status: model.PublishStatus, Name: "root"}
+
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+	postRepoMock.EXPECT().PostRecordByID(gomock.Any(), id).Return(record, nil)
+	// page 3 is grandchild of page 1, so page 1 can't be moved under page 3
+	postRepoMock.EXPECT().PostAncestors(gomock.Any(), []uint64{3}).Return(map[uint64]*model.PostNode{
+		1: &model.PostNode{ID: 1, Name: "root", Type: model.PageType},
+		2: &model.PostNode{ID: 2, Parent: 1, Name: "child", Type: model.PageType},
+		3: &model.PostNode{ID: 3, Parent: 2, Name: "grandchild", Type: model.PageType},
+	}, nil)
+
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().LoadOption(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows).AnyTimes()
+	sharedRepoMock.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	})
+
+	s := NewService(postRepoMock, sharedRepoMock, mockuser.NewMockRepository(ctrl))
+
+	parent := uint64(3)
+	_, err := s.UpdatePage(ctx, model.WritePostRequest{ID: &id, Type: model.PageType, Parent: &parent})
+	assert.IsType(t, &model.ParamError{}, err)
+	assert.Equal(t, "parent", err.(*model.ParamError).Param)
+}
@@ -17,11 +17,12 @@ import (
 var decoder *form.Decoder
 
 // MakeHTTPHandler returns http handler that makes a set of endpoints available on predefined paths
-func MakeHTTPHandler(s Service) http.Handler {
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
 	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
 
 	GetPageHandler := kithttp.NewServer(
-		makeGetPageEndpoint(s),
+		instrument("page.GetPage", makeGetPageEndpoint(s)),
 		getPageRequestDecoder,
 		resthttp.EncodeJSONResponse,
 		[]kithttp.ServerOption{
@@ -31,15 +32,107 @@ func MakeHTTPHandler(s Service) http.Handler {
 	r.Method(http.MethodGet, "/{id}", GetPageHandler)
 
 	ListPagesHandler := kithttp.NewServer(
-		makeListPagesEndpoint(s),
+		instrument("page.ListPages", makeListPagesEndpoint(s)),
 		listPagesRequestDecoder,
 		resthttp.EncodeJSONResponse,
 	)
 	r.Method(http.MethodGet, "/", ListPagesHandler)
 
+	writeOptions := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(resthttp.EncodeError),
+	}
+
+	CreatePageHandler := kithttp.NewServer(
+		instrument("page.CreatePage", makeCreatePageEndpoint(s)),
+		createPageRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		writeOptions...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/", CreatePageHandler)
+
+	UpdatePageHandler := kithttp.NewServer(
+		instrument("page.UpdatePage", makeUpdatePageEndpoint(s)),
+		updatePageRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		writeOptions...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/{id}", UpdatePageHandler)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPut, "/{id}", UpdatePageHandler)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPatch, "/{id}", UpdatePageHandler)
+
+	DeletePageHandler := kithttp.NewServer(
+		instrument("page.DeletePage", makeDeletePageEndpoint(s)),
+		deletePageRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		writeOptions...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodDelete, "/{id}", DeletePageHandler)
+
 	return r
 }
 
+// parseIDParam returns id parameter of the route, it returns ErrInvalidRoute if id is not a number
+func parseIDParam(r *http.Request) (*uint64, error) {
+	id := chi.URLParam(r, "id")
+	pageID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": id,
+			"func":   "strconv.ParseUint",
+		}).Errorf("Failed to parse uint from string: %s", err)
+		return nil, model.ErrInvalidRoute
+	}
+	return &pageID, nil
+}
+
+func createPageRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	writeRequest := model.WritePostRequest{Type: model.PageType}
+	if err := resthttp.DecodeBody(r, &writeRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "resthttp.DecodeBody",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	return writeRequest, nil
+}
+
+func updatePageRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	writeRequest := model.WritePostRequest{Type: model.PageType}
+	if err = resthttp.DecodeBody(r, &writeRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "resthttp.DecodeBody",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	writeRequest.ID = id
+	return writeRequest, nil
+}
+
+func deletePageRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var deleteRequest model.DeleteItemRequest
+	r.ParseForm()
+	decoder = form.NewDecoder()
+	if err = decoder.Decode(&deleteRequest, r.Form); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "decoder.Decode",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	deleteRequest.ID = id
+	return deleteRequest, nil
+}
+
 func getPageRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
 	var getRequest model.GetItemRequest
 	r.ParseForm()
@@ -2,6 +2,7 @@ package post
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/qreasio/restlr/http"
@@ -15,7 +16,10 @@ func makeGetPostEndpoint(s Service) endpoint.Endpoint {
 		if err == model.ErrInvalidPostID {
 			return http.NewInvalidPostResponse(), nil
 		}
-		return res, nil
+		if err == model.ErrIncorrectPassword {
+			return http.NewIncorrectPasswordResponse(), nil
+		}
+		return res, err
 	}
 	return endpoint
 }
@@ -27,3 +31,49 @@ func makeListPostsEndpoint(s Service) endpoint.Endpoint {
 	}
 	return endpoint
 }
+
+func makeCreatePostEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.WritePostRequest)
+		res, err := s.CreatePost(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotCreateCode, "The post cannot be created."), nil
+		}
+		return http.CreatedResponse{Item: res, Location: ItemLocation(ctx, res)}, nil
+	}
+	return endpoint
+}
+
+func makeUpdatePostEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.WritePostRequest)
+		res, err := s.UpdatePost(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotUpdateCode, "The post cannot be updated."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
+
+func makeDeletePostEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.DeleteItemRequest)
+		res, err := s.DeletePost(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotDeleteCode, "The post cannot be deleted."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
+
+// ItemLocation returns url of created post or page for Location header
+func ItemLocation(ctx context.Context, item interface{}) string {
+	p, ok := item.(*model.Post)
+	if !ok {
+		return ""
+	}
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	return fmt.Sprintf("%s/%s/%d", apiConfig.APIBaseURL, model.Plural(p.Type), p.ID)
+}
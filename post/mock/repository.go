@@ -65,6 +65,22 @@ func (mr *MockRepositoryMockRecorder) QueryPosts(ctx, listRequest interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPosts", reflect.TypeOf((*MockRepository)(nil).QueryPosts), ctx, listRequest)
 }
 
+// SearchPosts mocks base method
+func (m *MockRepository) SearchPosts(ctx context.Context, filter model.SearchFilter) ([]uint64, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPosts", ctx, filter)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchPosts indicates an expected call of SearchPosts
+func (mr *MockRepositoryMockRecorder) SearchPosts(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPosts", reflect.TypeOf((*MockRepository)(nil).SearchPosts), ctx, filter)
+}
+
 // PostsByIDs mocks base method
 func (m *MockRepository) PostsByIDs(ctx context.Context, postType string, idList []uint64) ([]*model.Post, []uint64, error) {
 	m.ctrl.T.Helper()
@@ -96,18 +112,18 @@ func (mr *MockRepositoryMockRecorder) ParseStickyPostID(option interface{}) *gom
 }
 
 // CommentsByPostIDs mocks base method
-func (m *MockRepository) CommentsByPostIDs(commentPostIDStr []string) ([]*model.Comment, error) {
+func (m *MockRepository) CommentsByPostIDs(ctx context.Context, commentPostIDStr []string) ([]*model.Comment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CommentsByPostIDs", commentPostIDStr)
+	ret := m.ctrl.Call(m, "CommentsByPostIDs", ctx, commentPostIDStr)
 	ret0, _ := ret[0].([]*model.Comment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CommentsByPostIDs indicates an expected call of CommentsByPostIDs
-func (mr *MockRepositoryMockRecorder) CommentsByPostIDs(commentPostIDStr interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CommentsByPostIDs(ctx, commentPostIDStr interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommentsByPostIDs", reflect.TypeOf((*MockRepository)(nil).CommentsByPostIDs), commentPostIDStr)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommentsByPostIDs", reflect.TypeOf((*MockRepository)(nil).CommentsByPostIDs), ctx, commentPostIDStr)
 }
 
 // GetPredecessorVersion mocks base method
@@ -124,3 +140,91 @@ func (mr *MockRepositoryMockRecorder) GetPredecessorVersion(ctx, idList interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPredecessorVersion", reflect.TypeOf((*MockRepository)(nil).GetPredecessorVersion), ctx, idList)
 }
+
+// PostRecordByID mocks base method
+func (m *MockRepository) PostRecordByID(ctx context.Context, id uint64) (*model.PostRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostRecordByID", ctx, id)
+	ret0, _ := ret[0].(*model.PostRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostRecordByID indicates an expected call of PostRecordByID
+func (mr *MockRepositoryMockRecorder) PostRecordByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostRecordByID", reflect.TypeOf((*MockRepository)(nil).PostRecordByID), ctx, id)
+}
+
+// InsertPost mocks base method
+func (m *MockRepository) InsertPost(ctx context.Context, record *model.PostRecord) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPost", ctx, record)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPost indicates an expected call of InsertPost
+func (mr *MockRepositoryMockRecorder) InsertPost(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPost", reflect.TypeOf((*MockRepository)(nil).InsertPost), ctx, record)
+}
+
+// UpdatePost mocks base method
+func (m *MockRepository) UpdatePost(ctx context.Context, record *model.PostRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePost indicates an expected call of UpdatePost
+func (mr *MockRepositoryMockRecorder) UpdatePost(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockRepository)(nil).UpdatePost), ctx, record)
+}
+
+// DeletePost mocks base method
+func (m *MockRepository) DeletePost(ctx context.Context, record *model.PostRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost
+func (mr *MockRepositoryMockRecorder) DeletePost(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockRepository)(nil).DeletePost), ctx, record)
+}
+
+// UniquePostSlug mocks base method
+func (m *MockRepository) UniquePostSlug(ctx context.Context, slug string, record *model.PostRecord) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UniquePostSlug", ctx, slug, record)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UniquePostSlug indicates an expected call of UniquePostSlug
+func (mr *MockRepositoryMockRecorder) UniquePostSlug(ctx, slug, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UniquePostSlug", reflect.TypeOf((*MockRepository)(nil).UniquePostSlug), ctx, slug, record)
+}
+
+// PostAncestors mocks base method
+func (m *MockRepository) PostAncestors(ctx context.Context, idList []uint64) (map[uint64]*model.PostNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostAncestors", ctx, idList)
+	ret0, _ := ret[0].(map[uint64]*model.PostNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostAncestors indicates an expected call of PostAncestors
+func (mr *MockRepositoryMockRecorder) PostAncestors(ctx, idList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostAncestors", reflect.TypeOf((*MockRepository)(nil).PostAncestors), ctx, idList)
+}
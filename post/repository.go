@@ -5,10 +5,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/fulltext"
 	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/sqlbuilder"
 	"github.com/qreasio/restlr/toolbox"
 	log "github.com/sirupsen/logrus"
 	"github.com/yvasiyarov/php_session_decoder/php_serialize"
@@ -18,39 +24,56 @@ const (
 	postNamePermalink = "/%postname%/"
 	postTableAlias    = "postp"
 	orderByInclude    = "include"
+	maxSlugLength     = 200
 )
 
+// feedSlugs are slugs that conflict with feed rewrite rules so they always get suffix
+var feedSlugs = []string{"feed", "rdf", "rss", "rss2", "atom"}
+
+// recordColumns is list of posts table columns that are written from PostRecord
+var recordColumns = []string{"post_author", "post_date", "post_date_gmt", "post_content", "post_title", "post_excerpt",
+	"post_status", "comment_status", "ping_status", "post_password", "post_name", "post_modified", "post_modified_gmt",
+	"post_parent", "guid", "menu_order", "post_type", "post_mime_type"}
+
 // Repository is interface for functions to interact with database
 type Repository interface {
 	PostByID(ctx context.Context, postID uint64, postType string) (*model.Post, error)
 	QueryPosts(ctx context.Context, listRequest model.ListFilter) ([]uint64, error)
+	SearchPosts(ctx context.Context, filter model.SearchFilter) ([]uint64, int, error)
 	PostsByIDs(ctx context.Context, postType string, idList []uint64) ([]*model.Post, []uint64, error)
 	ParseStickyPostID(option string) map[int]bool
-	CommentsByPostIDs(commentPostIDStr []string) ([]*model.Comment, error)
+	CommentsByPostIDs(ctx context.Context, commentPostIDStr []string) ([]*model.Comment, error)
 	GetPredecessorVersion(ctx context.Context, idList []uint64) (map[uint64]map[int]uint64, error)
+	PostRecordByID(ctx context.Context, id uint64) (*model.PostRecord, error)
+	InsertPost(ctx context.Context, record *model.PostRecord) (uint64, error)
+	UpdatePost(ctx context.Context, record *model.PostRecord) error
+	DeletePost(ctx context.Context, record *model.PostRecord) error
+	UniquePostSlug(ctx context.Context, slug string, record *model.PostRecord) (string, error)
+	PostAncestors(ctx context.Context, idList []uint64) (map[uint64]*model.PostNode, error)
 }
 
 type repository struct {
-	db *sql.DB
+	db     *dialect.DB
+	search fulltext.Backend
 }
 
-// NewRepository is function to create new repository struct instance that implements Repository interface
-func NewRepository(db *sql.DB) Repository {
+// NewRepository is function to create new repository struct instance that implements Repository interface,
+// search backend matches posts with search parameter of list and search requests
+func NewRepository(db *dialect.DB, searchBackend fulltext.Backend) Repository {
 	return &repository{
-		db: db,
+		db:     db,
+		search: searchBackend,
 	}
 }
 
-// getQueryColumns returns slice of table columns
-func getQueryColumns(postType string, siteURL string, permalinkStructure string, alias string) []string {
+// getQueryColumns returns slice of table columns in the dialect, permalink column has placeholders of site url and permalink structure
+func getQueryColumns(d dialect.Dialect, postType string, alias string) []string {
 	dottedAlias := alias + "."
-	permalink := `CONCAT('` +
-		siteURL +
-		`', REPLACE( REPLACE( REPLACE( REPLACE( REPLACE( '` +
-		permalinkStructure + `', '%year%', DATE_FORMAT( ` + dottedAlias + `post_date, '%Y' ) ) ,'%monthnum%', 
-			DATE_FORMAT( ` + dottedAlias + `post_date, '%m' ) ) , '%day%', 
-			DATE_FORMAT( ` + dottedAlias + `post_date, '%d' ) ) , 
-			postNamePermalink, ` + dottedAlias + `post_name ) , '%category%', ` + dottedAlias + `post_type ) ) AS permalink `
+	permalink := `REPLACE( REPLACE( REPLACE( REPLACE( REPLACE( ?, '%year%', ` + d.DateFormat(dottedAlias+"post_date", "%Y") + ` ) ,'%monthnum%', 
+			` + d.DateFormat(dottedAlias+"post_date", "%m") + ` ) , '%day%', 
+			` + d.DateFormat(dottedAlias+"post_date", "%d") + ` ) , 
+			'%postname%', ` + dottedAlias + `post_name ) , '%category%', ` + dottedAlias + `post_type )`
+	permalink = d.Concat("?", permalink) + ` AS permalink `
 
 	fields := []string{
 		dottedAlias + "ID",
@@ -72,13 +95,13 @@ func getQueryColumns(postType string, siteURL string, permalinkStructure string,
 		permalink,
 	}
 
-	switch postType {
+	switch {
 
-	case model.PageType:
+	case model.IsHierarchicalPostType(postType):
 		fields = append(fields, alias+"."+"menu_order")
 		fields = append(fields, alias+"."+"post_parent")
 
-	case model.MediaType:
+	case postType == model.MediaType:
 		fields = append(fields, alias+"."+"post_mime_type")
 	}
 
@@ -90,7 +113,7 @@ func getQueryProperties(post *model.Post, postType string) []interface{} {
 	fields := []interface{}{&post.ID, &post.Author, &post.Date, &post.DateGmt, &post.Content.Rendered, &post.Title.Rendered, &post.Excerpt.Rendered, &post.Status,
 		&post.CommentStatus, &post.PingStatus, &post.Password, &post.Slug, &post.Modified, &post.ModifiedGmt, &post.GUID.Rendered, &post.Type, &post.Link}
 
-	if postType == model.PageType {
+	if model.IsHierarchicalPostType(postType) {
 		fields = append(fields, &post.MenuOrder)
 		fields = append(fields, &post.Parent)
 	}
@@ -113,267 +136,276 @@ func NewPost() model.Post {
 	return post
 }
 
-// getPostByIDSQL return string sql to get post by id
-func getPostByIDSQL(ctx context.Context, postType string) string {
-	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := config.TablePrefix + "posts"
-
-	columnsList := strings.Join(getQueryColumns(postType, config.SiteURL, postNamePermalink, postTableAlias), ",")
-
-	sqlQuery := fmt.Sprintf(`SELECT %s `+
-		` FROM %s %s`+
-		` WHERE %s.ID = ?`,
-		columnsList,
-		tableName,
-		postTableAlias,
-		postTableAlias,
-	)
-
-	return sqlQuery
-}
-
-// getSQLFilterAndArgs return sql query and arguments from filter
-func getSQLFilterAndArgs(tablePrefix string, params model.ListFilter) (string, []interface{}, string, string, error) {
-	var args []interface{}
-	sqlFilter := ""
-
-	if params.Search != nil {
-		searchSQL := fmt.Sprintf(`AND ((post_title LIKE ?) OR (post_excerpt LIKE ?) OR (post_content LIKE ?)) ` +
-			`AND (post_password = '')`)
-		sqlFilter += searchSQL
-		searchKeyword := fmt.Sprintf("%%%s%%", *params.Search)
-		args = append(args, searchKeyword)
-		args = append(args, searchKeyword)
-		args = append(args, searchKeyword)
-	}
-
-	if params.Before != nil && params.After == nil {
-		//if before is exists but after is not
-		sqlFilter += " AND (post_date < ?)"
-		args = append(args, *params.Before)
-	} else if params.Before == nil && params.After != nil {
-		//if after param is exists
-		sqlFilter += " AND (post_date > ?)"
-		args = append(args, *params.After)
-	} else if params.Before != nil && params.After != nil {
-		//if both after and before param are exists
-		sqlFilter += " AND (post_date < ? AND post_date > ?)"
-		args = append(args, *params.Before)
-		args = append(args, *params.After)
+// selectPostsSQL returns builder of query in the dialect that selects post columns of the post type from posts table,
+// site url and permalink structure of permalink column are bound as arguments
+func selectPostsSQL(d dialect.Dialect, config model.APIConfig, postType string) *sqlbuilder.Builder {
+	columnsList := strings.Join(getQueryColumns(d, postType, postTableAlias), ",")
+
+	return sqlbuilder.New(`SELECT `+columnsList+` FROM `, config.SiteURL, postNamePermalink).
+		Ident(config.Tables().Posts).
+		SQL(` ` + postTableAlias)
+}
+
+// postOrderColumns are columns of orderby parameter values, include and relevance have their own order expression
+var postOrderColumns = map[string]string{
+	"title":      "post_title",
+	"author":     "post_author",
+	"date":       "post_date",
+	"id":         "ID",
+	"modified":   "post_modified",
+	"parent":     "post_parent",
+	"slug":       "post_name",
+	"menu_order": "menu_order",
+}
+
+// postFilterSQL returns builder of conditions and their arguments from filter, match is the search match of search parameter
+func postFilterSQL(tables model.Tables, params model.ListFilter, match *fulltext.Match) (*sqlbuilder.Builder, error) {
+	b := sqlbuilder.New("")
+
+	if match != nil {
+		b.Append(searchFilterSQL(match))
+	}
+
+	if params.Before != nil {
+		b.And("(post_date < ?)", *params.Before)
+	}
+
+	if params.After != nil {
+		b.And("(post_date > ?)", *params.After)
 	}
 
 	if len(params.Include) > 0 {
-		sqlFilter += " AND ID IN (" + toolbox.UInt64SliceToCSV(params.Include) + ")"
+		b.AndIn("ID", sqlbuilder.Uint64s(params.Include))
 	}
 
 	if len(params.Exclude) > 0 {
-		sqlFilter += " AND ID NOT IN (" + toolbox.UInt64SliceToCSV(params.Exclude) + ")"
+		b.AndNotIn("ID", sqlbuilder.Uint64s(params.Exclude))
 	}
 
 	if params.Slug != nil {
-		slugArr := strings.Split(*params.Slug, ",")
-		var slugArrWithQuote []string
-		for _, slug := range slugArr {
-			slugArrWithQuote = append(slugArrWithQuote, "'"+slug+"'")
-		}
-		slugString := strings.Join(slugArrWithQuote, ",")
-		sqlFilter += " AND post_name IN (" + slugString + ")"
+		b.AndIn("post_name", sqlbuilder.Strings(strings.Split(*params.Slug, ",")))
 	}
 
 	if len(params.Author) > 0 {
-		sqlFilter += " AND post_author  IN (" + toolbox.UInt64SliceToCSV(params.Author) + ")"
+		b.AndIn("post_author", sqlbuilder.Uint64s(params.Author))
 	}
 
 	if len(params.AuthorExclude) > 0 {
-		sqlFilter += " AND post_author NOT IN (" + toolbox.UInt64SliceToCSV(params.AuthorExclude) + ")"
+		b.AndNotIn("post_author", sqlbuilder.Uint64s(params.AuthorExclude))
 	}
 
 	if len(params.StickyIDs) > 0 {
-		var postIDList []string
-
+		var stickyIDs []uint64
 		for postID := range params.StickyIDs {
-			postIDString := fmt.Sprintf("%v", postID)
-			postIDList = append(postIDList, postIDString)
+			stickyIDs = append(stickyIDs, uint64(postID))
 		}
-
-		postIDCSV := strings.Join(postIDList, ",")
+		sort.Slice(stickyIDs, func(i, j int) bool { return stickyIDs[i] < stickyIDs[j] })
 
 		if *params.Sticky {
-			sqlFilter += " AND ID IN (" + postIDCSV + ")"
-		}
-
-		if !*params.Sticky {
-			sqlFilter += " AND ID NOT IN (" + postIDCSV + ")"
-		}
-	}
-
-	var taxonomyIDs = make([]string, 0)
-
-	if len(params.TermTaxonomies[model.TagType]) > 0 {
-		for _, tt := range params.TermTaxonomies[model.TagType] {
-			taxonomyIDs = append(taxonomyIDs, strconv.FormatUint(tt.TermTaxonomyID, 10))
-		}
-	}
-
-	if len(params.TermTaxonomies[model.CategoryType]) > 0 {
-		for _, tt := range params.TermTaxonomies[model.CategoryType] {
-			taxonomyIDs = append(taxonomyIDs, strconv.FormatUint(tt.TermTaxonomyID, 10))
+			b.AndIn("ID", sqlbuilder.Uint64s(stickyIDs))
+		} else {
+			b.AndNotIn("ID", sqlbuilder.Uint64s(stickyIDs))
 		}
 	}
 
-	if len(taxonomyIDs) > 0 {
-		sqlFilter += "AND (  term_relationship.term_taxonomy_id IN ( " + strings.Join(taxonomyIDs, ",") + " ) )"
-	}
-
-	var taxonomyIDsExclude = make([]string, 0)
-
-	if len(params.TermTaxonomiesExclude[model.TagType]) > 0 {
-		for _, tt := range params.TermTaxonomiesExclude[model.TagType] {
-			taxonomyIDsExclude = append(taxonomyIDs, strconv.FormatUint(tt.TermTaxonomyID, 10))
+	// every taxonomy is a separate condition, so post must have one of the terms of each taxonomy like tax_query with AND relation
+	for _, taxonomy := range sortedTaxonomies(params.TermTaxonomies) {
+		if ids := termTaxonomyIDs(params.TermTaxonomies[taxonomy]); len(ids) > 0 {
+			b.SQL(" AND wpp.ID IN (SELECT object_id FROM ").Ident(tables.TermRelationships).
+				SQL(" WHERE ").In("term_taxonomy_id", sqlbuilder.Uint64s(ids)).SQL(")")
 		}
 	}
 
-	if len(params.TermTaxonomiesExclude[model.CategoryType]) > 0 {
-		for _, tt := range params.TermTaxonomiesExclude[model.CategoryType] {
-			taxonomyIDsExclude = append(taxonomyIDs, strconv.FormatUint(tt.TermTaxonomyID, 10))
+	for _, taxonomy := range sortedTaxonomies(params.TermTaxonomiesExclude) {
+		if ids := termTaxonomyIDs(params.TermTaxonomiesExclude[taxonomy]); len(ids) > 0 {
+			b.SQL(" AND wpp.ID NOT IN (SELECT object_id FROM ").Ident(tables.TermRelationships).
+				SQL(" WHERE ").In("term_taxonomy_id", sqlbuilder.Uint64s(ids)).SQL(")")
 		}
 	}
 
-	if len(taxonomyIDsExclude) > 0 {
-		sqlFilter += "AND (  ID NOT IN ( SELECT object_id FROM " + tablePrefix + "term_relationship WHERE term_taxonomy_id IN (" + strings.Join(taxonomyIDsExclude, ",") + " ) ) )"
-	}
-
-	if params.Type == "page" {
-
+	postType := model.PostType
+	switch {
+	case model.IsHierarchicalPostType(params.Type):
 		if params.MenuOrder != nil {
-			sqlFilter += " AND menu_order = ?"
-			args = append(args, *params.MenuOrder)
+			b.And("menu_order = ?", *params.MenuOrder)
 		}
 
 		if params.Parent != nil {
-			sqlFilter += " AND post_parent IN (" + *params.Parent + ")"
+			parents, err := toolbox.CSVToUInt64Slice(*params.Parent)
+			if err != nil {
+				return nil, model.NewParamError("parent", "parent is not of type integer.")
+			}
+			b.AndIn("post_parent", sqlbuilder.Uint64s(parents))
 		}
 
 		if params.ParentExclude != nil {
-			sqlFilter += " AND post_parent NOT IN (" + *params.ParentExclude + ")"
+			parents, err := toolbox.CSVToUInt64Slice(*params.ParentExclude)
+			if err != nil {
+				return nil, model.NewParamError("parent_exclude", "parent_exclude is not of type integer.")
+			}
+			b.AndNotIn("post_parent", sqlbuilder.Uint64s(parents))
 		}
 
-		args = append(args, "page")
-
-	} else if params.Type == "attachment" {
+		postType = params.Type
 
+	case params.Type == model.AttachmentType:
 		if params.MediaType != nil {
-
-			var mediaTypeSQL []string
+			var mimeTypes []string
 			for _, postMimeType := range model.MimeTypes {
 				if strings.Contains(postMimeType, *params.MediaType) {
-					mediaTypeSQL = append(mediaTypeSQL, "wpp.post_mime_type = '"+postMimeType+"'")
+					mimeTypes = append(mimeTypes, postMimeType)
 				}
 			}
-
-			sqlFilter += strings.Join(mediaTypeSQL, " OR ")
-			sqlFilter += ") "
+			b.AndIn("wpp.post_mime_type", sqlbuilder.Strings(mimeTypes))
 		}
 
 		if params.MimeType != nil {
-			sqlFilter += " AND post_mime_type = ?"
-			args = append(args, *params.MimeType)
+			b.And("post_mime_type = ?", *params.MimeType)
 		}
 
 		if params.Parent != nil {
-			sqlFilter += " AND post_parent = ?"
-			args = append(args, *params.Parent)
+			b.And("post_parent = ?", *params.Parent)
 		}
 
-		args = append(args, "attachment")
-
-	} else {
-		args = append(args, model.PostType)
+		postType = model.AttachmentType
 
+	default:
+		if _, ok := model.RegisteredPostType(params.Type); ok {
+			postType = params.Type
+		}
 	}
 
+	status := model.PublishStatus
 	if params.Status != nil {
-		args = append(args, *params.Status)
-	} else {
-		args = append(args, "publish")
+		status = *params.Status
 	}
 
-	orderBy := ""
-	sortOrder := "desc"
-	search := ""
-
-	if params.Search != nil {
-		search = *params.Search
-	}
+	return b.And("post_type = ?", postType).And("post_status = ?", status), nil
+}
 
-	orderFieldMap := map[string]string{"title": "post_title",
-		"author":       "post_author",
-		"date":         "post_date",
-		"id":           "post_id",
-		"modified":     "post_modified",
-		"parent":       "post_parent",
-		"slug":         "post_name",
-		orderByInclude: "FIELD(ID, " + toolbox.UInt64SliceToCSV(params.Include) + ")",
-		"relevance":    "post_title LIKE '%" + search + "%'"}
+// postOrderSQL returns builder of order by clause of the filter in the dialect, posts are ordered by date if orderby parameter is not set
+func postOrderSQL(d dialect.Dialect, params model.ListFilter, match *fulltext.Match) (*sqlbuilder.Builder, error) {
+	b := sqlbuilder.New("")
 
-	if params.OrderBy != nil {
+	if params.OrderBy == nil {
+		return b.OrderBy("post_date", "desc"), nil
+	}
 
-		if len(params.Include) == 0 && *params.OrderBy == orderByInclude {
-			return "", nil, "", "", errors.New("you need to define an include parameter to order by include")
+	switch *params.OrderBy {
+	case orderByInclude:
+		if len(params.Include) == 0 {
+			return nil, errors.New("you need to define an include parameter to order by include")
 		}
-
 		//if order by parameter is 'include', we ignore the ascending and descending order
-		if len(params.Include) > 0 && *params.OrderBy == orderByInclude {
-			sortOrder = ""
-		}
+		return b.OrderByField(d, "ID", sqlbuilder.Uint64s(params.Include)), nil
 
-		if params.Search == nil && *params.OrderBy == "relevance" {
-			return "", nil, "", "", errors.New("you need to define a search term to order by relevance")
+	case "relevance":
+		if match == nil {
+			return nil, errors.New("you need to define a search term to order by relevance")
 		}
-		orderBy = orderFieldMap[*params.OrderBy]
+		// relevance is ordered by score expression of search match, so its arguments come before limit arguments
+		return b.SQL(" ORDER BY "+match.Score+" DESC", match.ScoreArgs...), nil
+	}
 
-	} else {
-		orderBy = "post_date"
+	column, ok := postOrderColumns[*params.OrderBy]
+	if !ok {
+		return nil, model.NewParamError("orderby", "orderby is not one of "+strings.Join(postOrderValues(), ", ")+".")
 	}
+	return b.OrderBy(column, "desc"), nil
+}
 
-	offset := (params.Page - 1) * params.PerPage
-	args = append(args, offset)
-	args = append(args, params.PerPage)
+// postOrderValues returns valid values of orderby parameter in order
+func postOrderValues() []string {
+	values := []string{orderByInclude, "relevance"}
+	for value := range postOrderColumns {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
 
-	return sqlFilter, args, orderBy, sortOrder, nil
+// searchFilterSQL returns condition and arguments of search match that also excludes password protected posts
+func searchFilterSQL(match *fulltext.Match) *sqlbuilder.Builder {
+	return sqlbuilder.New(` AND (`+match.Condition+`) AND (post_password = '')`, match.ConditionArgs...)
 }
 
-// getSQLQuery return sql query string and argument slice to filter posts
-func getSQLQuery(ctx context.Context, params model.ListFilter) (string, []interface{}, error) {
+// searchMatch returns search match of the search keyword from search backend, it returns nil match without search keyword
+func (repo *repository) searchMatch(ctx context.Context, search *string) (*fulltext.Match, error) {
+	if search == nil {
+		return nil, nil
+	}
+	match, err := repo.search.Match(ctx, *search)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": *search,
+			"func":   "repo.search.Match",
+		}).Errorf("Failed to match search keyword: %s", err)
+		return nil, err
+	}
+	return match, nil
+}
+
+// sortedTaxonomies returns taxonomies of the term taxonomy map in order, so generated sql query is the same for the same filter
+func sortedTaxonomies(termTaxonomies map[string][]*model.TermTaxonomy) []string {
+	var list []string
+	for taxonomy := range termTaxonomies {
+		list = append(list, taxonomy)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// termTaxonomyIDs returns term taxonomy ids of the term taxonomies
+func termTaxonomyIDs(termTaxonomies []*model.TermTaxonomy) []uint64 {
+	var ids []uint64
+	for _, tt := range termTaxonomies {
+		ids = append(ids, tt.TermTaxonomyID)
+	}
+	return ids
+}
+
+// getSQLQuery return sql query string in the dialect and argument slice to filter posts
+func getSQLQuery(ctx context.Context, d dialect.Dialect, params model.ListFilter, match *fulltext.Match) (string, []interface{}, error) {
 	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := config.TablePrefix + "posts"
-	var args []interface{}
-	sqlFilter, args, orderBy, sortDirection, err := getSQLFilterAndArgs(config.TablePrefix, params)
+
+	tables := config.Tables()
+
+	filter, err := postFilterSQL(tables, params, match)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": fmt.Sprintf("params: %v, tablePrefix: %v", params, config.TablePrefix),
-			"func":   "getSQLFilterAndArgsgetSQLQuery",
-		}).Errorf("Failed to run getSQLFilterAndArgs: %s", err)
+			"func":   "postFilterSQL",
+		}).Errorf("Failed to run postFilterSQL: %s", err)
 		return "", nil, err
 	}
 
-	sqlQuery := `SELECT wpp.ID ` +
-		`FROM ` + tableName + ` wpp ` +
-		`LEFT JOIN ` + config.TablePrefix + `term_relationships term_relationship ON (wpp.ID = term_relationship.object_id) ` +
-		`WHERE 1=1 ` +
-		sqlFilter +
-		` AND post_type = ?` +
-		` AND post_status = ?` +
-		` GROUP BY wpp.ID ` +
-		` ORDER BY ` + orderBy + ` ` + sortDirection +
-		` LIMIT ?, ?`
+	order, err := postOrderSQL(d, params, match)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": params,
+			"func":   "postOrderSQL",
+		}).Errorf("Failed to run postOrderSQL: %s", err)
+		return "", nil, err
+	}
 
-	return sqlQuery, args, nil
+	return sqlbuilder.New(`SELECT wpp.ID FROM `).Ident(tables.Posts).
+		SQL(` wpp LEFT JOIN `).Ident(tables.TermRelationships).
+		SQL(` term_relationship ON (wpp.ID = term_relationship.object_id) WHERE 1=1`).
+		Append(filter).
+		SQL(` GROUP BY wpp.ID`).
+		Append(order).
+		Limit((params.Page-1)*params.PerPage, params.PerPage).
+		Build()
 }
 
 // QueryPosts will query posts base on filter parameters
 func (repo *repository) QueryPosts(ctx context.Context, params model.ListFilter) ([]uint64, error) {
-	sqlQuery, args, err := getSQLQuery(ctx, params)
+	match, err := repo.searchMatch(ctx, params.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery, args, err := getSQLQuery(ctx, repo.db.Dialect, params, match)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": params,
@@ -387,7 +419,7 @@ func (repo *repository) QueryPosts(ctx context.Context, params model.ListFilter)
 		"func":   "repository.QueryPosts",
 	}).Errorf("QueryPosts SQL : %s", sqlQuery)
 
-	queryRes, err := repo.db.Query(sqlQuery, args...)
+	queryRes, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": args,
@@ -395,6 +427,7 @@ func (repo *repository) QueryPosts(ctx context.Context, params model.ListFilter)
 		}).Errorf("Failed to run db query: %s", err)
 		return nil, err
 	}
+	defer queryRes.Close()
 
 	var postID uint64
 	var postIDs []uint64
@@ -414,34 +447,89 @@ func (repo *repository) QueryPosts(ctx context.Context, params model.ListFilter)
 	return postIDs, err
 }
 
-// getPostsByIDsSQL return sql query string to get post from some post IDs in csv format (comma separated string)
-func (repo *repository) getPostsByIDsSQL(ctx context.Context, postType string, postIDList string) string {
+// SearchPosts returns ids of published posts of the post types that match the search filter ordered by relevance
+// and total number of matching posts
+func (repo *repository) SearchPosts(ctx context.Context, filter model.SearchFilter) ([]uint64, int, error) {
 	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := config.TablePrefix + "posts"
+	tableName := config.Tables().Posts
+
+	sqlFilter := sqlbuilder.New("").AndIn("post_type", sqlbuilder.Strings(filter.Subtypes)).And("post_status = ?", model.PublishStatus)
+
+	var match *fulltext.Match
+	if filter.Search != "" {
+		var err error
+		if match, err = repo.searchMatch(ctx, &filter.Search); err != nil {
+			return nil, 0, err
+		}
+		sqlFilter.Append(searchFilterSQL(match))
+	}
+	if len(filter.Include) > 0 {
+		sqlFilter.AndIn("ID", sqlbuilder.Uint64s(filter.Include))
+	}
+	if len(filter.Exclude) > 0 {
+		sqlFilter.AndNotIn("ID", sqlbuilder.Uint64s(filter.Exclude))
+	}
+
+	countSQL, args, err := sqlbuilder.New(`SELECT COUNT(*) FROM `).Ident(tableName).SQL(` WHERE 1=1`).Append(sqlFilter).Build()
+	if err != nil {
+		return nil, 0, err
+	}
 
-	columnsList := strings.Join(getQueryColumns(postType, config.SiteURL, postNamePermalink, postTableAlias), ",")
+	var total int
+	if err = shared.Conn(ctx, repo.db).QueryRowContext(ctx, countSQL, args...).Scan(&total); err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to count search posts: %s", err)
+		return nil, 0, err
+	}
+
+	query := sqlbuilder.New(`SELECT ID FROM `).Ident(tableName).SQL(` WHERE 1=1`).Append(sqlFilter).SQL(` ORDER BY `)
+	if match != nil {
+		query.SQL(match.Score+` DESC, `, match.ScoreArgs...)
+	}
+	sqlQuery, args, err := query.SQL(`post_date DESC`).Limit((filter.Page-1)*filter.PerPage, filter.PerPage).Build()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryContext",
+		}).Errorf("Failed to search posts: %s", err)
+		return nil, 0, err
+	}
+	defer q.Close()
+
+	var idList []uint64
+	for q.Next() {
+		var id uint64
+		if err = q.Scan(&id); err != nil {
+			log.WithFields(log.Fields{
+				"params": args,
+				"func":   "q.Scan",
+			}).Errorf("Failed to scan search post: %s", err)
+			return nil, 0, err
+		}
+		idList = append(idList, id)
+	}
 
-	sqlQuery := fmt.Sprintf(`SELECT %s `+
-		` FROM %s %s`+
-		` WHERE %s.ID IN (%s)`,
-		columnsList,
-		tableName,
-		postTableAlias,
-		postTableAlias,
-		postIDList,
-	)
-	return sqlQuery
+	return idList, total, q.Err()
 }
 
 // PostsByIDs get Post by array of post ID string
 func (repo *repository) PostsByIDs(ctx context.Context, postType string, idList []uint64) ([]*model.Post, []uint64, error) {
 	var userIDArr []uint64
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
 
-	postIDList := toolbox.UInt64SliceToCSV(idList)
-
-	sqlQuery := repo.getPostsByIDsSQL(ctx, postType, postIDList)
+	sqlQuery, args, err := selectPostsSQL(repo.db.Dialect, config, postType).SQL(` WHERE `).In(postTableAlias+".ID", sqlbuilder.Uint64s(idList)).Build()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	q, err := repo.db.Query(sqlQuery)
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -450,6 +538,7 @@ func (repo *repository) PostsByIDs(ctx context.Context, postType string, idList
 		}).Errorf("Failed to run db query: %s", err)
 		return nil, nil, err
 	}
+	defer q.Close()
 
 	var posts = make([]*model.Post, 0)
 
@@ -469,6 +558,11 @@ func (repo *repository) PostsByIDs(ctx context.Context, postType string, idList
 			return nil, nil, err
 		}
 
+		// if media and mime type contains image, we set media type to image
+		if postType == model.MediaType && p.MimeType != nil && strings.Contains(*p.MimeType, "image") {
+			p.MediaType = toolbox.StringPointer("image")
+		}
+
 		if p.Excerpt.Rendered == "" {
 			p.Excerpt.Rendered = model.GenerateExcerpt(p.Content.Rendered)
 		}
@@ -488,9 +582,14 @@ func (repo *repository) PostByID(ctx context.Context, id uint64, postType string
 		post.Format = model.StandardFormat
 	}
 
-	sqlQuery := getPostByIDSQL(ctx, postType)     //generate SQL
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	sqlQuery, args, err := selectPostsSQL(repo.db.Dialect, config, postType).SQL(` WHERE `+postTableAlias+`.ID = ?`, id).Build() //generate SQL
+	if err != nil {
+		return nil, err
+	}
+
 	fields := getQueryProperties(&post, postType) // get list of target fields to be scanned
-	err = repo.db.QueryRow(sqlQuery, id).Scan(fields...)
+	err = shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, args...).Scan(fields...)
 
 	if err == sql.ErrNoRows {
 		log.WithFields(log.Fields{
@@ -544,16 +643,20 @@ func (repo *repository) ParseStickyPostID(optionValue string) map[int]bool {
 }
 
 // CommentsByPostIDs query comments from array of string post id
-func (repo *repository) CommentsByPostIDs(commentPostIDStr []string) ([]*model.Comment, error) {
-	var err error
-	postIDParameters := strings.Join(commentPostIDStr, ",")
-
-	sqlQuery := `SELECT ` +
-		`comment_ID, user_id, comment_author, comment_author_url, comment_date, comment_content, comment_parent, comment_post_id ` +
-		`FROM wp_comments ` +
-		`WHERE comment_post_ID IN (` + postIDParameters + `) and comment_approved = '1' and comment_type in ('')`
+func (repo *repository) CommentsByPostIDs(ctx context.Context, commentPostIDStr []string) ([]*model.Comment, error) {
+	tableName := model.TablesFromContext(ctx).Comments
+
+	sqlQuery, args, err := sqlbuilder.New(`SELECT `+
+		`comment_ID, user_id, comment_author, comment_author_url, comment_date, comment_content, comment_parent, comment_post_id `+
+		`FROM `).Ident(tableName).SQL(` `+
+		`WHERE `).In("comment_post_ID", sqlbuilder.Strings(commentPostIDStr)).
+		SQL(` and comment_approved = '1' and comment_type in ('')`).
+		Build()
+	if err != nil {
+		return nil, err
+	}
 
-	q, err := repo.db.Query(sqlQuery)
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": sqlQuery,
@@ -584,19 +687,20 @@ func (repo *repository) CommentsByPostIDs(commentPostIDStr []string) ([]*model.C
 
 // GetPredecessorVersion is function to get post ID of previous version from [prefix]posts table
 func (repo *repository) GetPredecessorVersion(ctx context.Context, idList []uint64) (map[uint64]map[int]uint64, error) {
-	var err error
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := apiConfig.TablePrefix + "posts"
-	idParameters := toolbox.UInt64SliceToCSV(idList)
+	tableName := apiConfig.Tables().Posts
 
-	sqlQuery := fmt.Sprintf(`SELECT post_parent, ID FROM %s WHERE 1=1 AND post_parent IN ( %s )  
-								AND post_type = 'revision' 
+	sqlQuery, args, err := sqlbuilder.New(`SELECT post_parent, ID FROM `).Ident(tableName).
+		SQL(` WHERE 1=1`).AndIn("post_parent", sqlbuilder.Uint64s(idList)).
+		SQL(` AND post_type = 'revision' 
 								AND ((post_status = 'inherit'))  
-								ORDER BY post_date DESC, wp_posts.ID DESC`,
-		tableName,
-		idParameters)
+								ORDER BY post_date DESC, ID DESC`).
+		Build()
+	if err != nil {
+		return nil, err
+	}
 
-	q, err := repo.db.Query(sqlQuery)
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": sqlQuery,
@@ -634,3 +738,281 @@ func (repo *repository) GetPredecessorVersion(ctx context.Context, idList []uint
 
 	return res, nil
 }
+
+// recordValues returns values of PostRecord in the same order with recordColumns
+func recordValues(record *model.PostRecord) []interface{} {
+	return []interface{}{record.Author, model.FormatDate(record.Date), model.FormatDate(record.DateGmt), record.Content, record.Title, record.Excerpt,
+		record.Status, record.CommentStatus, record.PingStatus, record.Password, record.Name, model.FormatDate(record.Modified), model.FormatDate(record.ModifiedGmt),
+		record.Parent, record.GUID, record.MenuOrder, record.Type, record.MimeType}
+}
+
+// PostRecordByID retrieves a row from prefix+'_posts' as a PostRecord that can be modified and written back
+func (repo *repository) PostRecordByID(ctx context.Context, id uint64) (*model.PostRecord, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+
+	sqlQuery, args, err := sqlbuilder.New(`SELECT ID, `+strings.Join(recordColumns, ", ")+` FROM `).Ident(tableName).
+		SQL(` WHERE ID = ?`, id).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.PostRecord{}
+	var date, dateGmt, modified, modifiedGmt mysqlTime
+	err = shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, args...).Scan(&record.ID, &record.Author, &date, &dateGmt,
+		&record.Content, &record.Title, &record.Excerpt, &record.Status, &record.CommentStatus, &record.PingStatus,
+		&record.Password, &record.Name, &modified, &modifiedGmt, &record.Parent, &record.GUID, &record.MenuOrder,
+		&record.Type, &record.MimeType)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": id,
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to scan db query row scan: %s", err)
+		return nil, err
+	}
+
+	record.Date, record.DateGmt, record.Modified, record.ModifiedGmt = date.Time, dateGmt.Time, modified.Time, modifiedGmt.Time
+	return record, nil
+}
+
+// InsertPost inserts PostRecord as new row of prefix+'_posts' table and returns the new post ID
+func (repo *repository) InsertPost(ctx context.Context, record *model.PostRecord) (uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+
+	// to_ping, pinged and post_content_filtered are text columns without default value
+	sqlQuery, args, err := sqlbuilder.New(`INSERT INTO `).Ident(tableName).
+		SQL(` (`+strings.Join(recordColumns, ", ")+`, to_ping, pinged, post_content_filtered) `+
+			`VALUES (`+sqlbuilder.Placeholders(len(recordColumns))+`, '', '', '')`, recordValues(record)...).
+		Build()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := repo.db.Dialect.Insert(ctx, shared.Conn(ctx, repo.db), sqlQuery, "ID", args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": record,
+			"func":   "Dialect.Insert",
+		}).Errorf("Failed to insert post: %s", err)
+		return 0, err
+	}
+
+	record.ID = uint64(id)
+	return record.ID, nil
+}
+
+// UpdatePost writes PostRecord to its existing row of prefix+'_posts' table
+func (repo *repository) UpdatePost(ctx context.Context, record *model.PostRecord) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+
+	sqlQuery, args, err := sqlbuilder.New(`UPDATE `).Ident(tableName).
+		SQL(` SET `+strings.Join(recordColumns, " = ?, ")+` = ? WHERE ID = ?`, append(recordValues(record), record.ID)...).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = shared.Conn(ctx, repo.db).ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": record,
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to update post: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeletePost permanently deletes post row with its revisions and comments, children of the post with the same type are moved to the post parent like wp_delete_post does.
+// Post metas and term relationships are deleted by their own repositories
+func (repo *repository) DeletePost(ctx context.Context, record *model.PostRecord) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+	commentsTableName := config.Tables().Comments
+	commentMetaTableName := config.Tables().CommentMeta
+	conn := shared.Conn(ctx, repo.db)
+
+	queries := []*sqlbuilder.Builder{
+		sqlbuilder.New(`DELETE FROM `).Ident(tableName).SQL(` WHERE post_parent = ? AND post_type = ?`, record.ID, model.RevisionType),
+		sqlbuilder.New(`UPDATE `).Ident(tableName).SQL(` SET post_parent = ? WHERE post_parent = ? AND post_type = ?`, record.Parent, record.ID, record.Type),
+		sqlbuilder.New(`DELETE FROM `).Ident(commentMetaTableName).SQL(` WHERE comment_id IN (SELECT comment_ID FROM `).Ident(commentsTableName).
+			SQL(` WHERE comment_post_ID = ?)`, record.ID),
+		sqlbuilder.New(`DELETE FROM `).Ident(commentsTableName).SQL(` WHERE comment_post_ID = ?`, record.ID),
+		sqlbuilder.New(`DELETE FROM `).Ident(tableName).SQL(` WHERE ID = ?`, record.ID),
+	}
+
+	for _, query := range queries {
+		sqlQuery, args, err := query.Build()
+		if err != nil {
+			return err
+		}
+		if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("%s, %v", sqlQuery, args),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to delete post: %s", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UniquePostSlug returns slug that is unique for the post like wp_unique_post_slug does, by adding numeric suffix if the slug is already used.
+// Attachment slug is unique across all post types and hierarchical post slug is unique under the same parent
+func (repo *repository) UniquePostSlug(ctx context.Context, slug string, record *model.PostRecord) (string, error) {
+	// draft and pending post don't reserve slug
+	if record.Status == model.DraftStatus || record.Status == model.PendingStatus || record.Type == model.RevisionType {
+		return slug, nil
+	}
+
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+
+	sqlQuery := `SELECT post_name FROM ` + tableName + ` WHERE post_name = ? AND ID != ?`
+	args := []interface{}{record.ID}
+
+	switch {
+	case record.Type == model.AttachmentType:
+	case record.Type == model.PageType:
+		sqlQuery += ` AND post_type IN (?, ?) AND post_parent = ?`
+		args = append(args, model.PageType, model.AttachmentType, record.Parent)
+	default:
+		sqlQuery += ` AND post_type = ?`
+		args = append(args, record.Type)
+	}
+	sqlQuery += ` LIMIT 1`
+
+	conn := shared.Conn(ctx, repo.db)
+	isUsed := func(candidate string) (bool, error) {
+		for _, feed := range feedSlugs {
+			if candidate == feed {
+				return true, nil
+			}
+		}
+		var name string
+		err := conn.QueryRowContext(ctx, sqlQuery, append([]interface{}{candidate}, args...)...).Scan(&name)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("%s, %s, %v", sqlQuery, candidate, args),
+				"func":   "conn.QueryRowContext.Scan",
+			}).Errorf("Failed to check post slug: %s", err)
+			return false, err
+		}
+		return true, nil
+	}
+
+	used, err := isUsed(slug)
+	if err != nil || !used {
+		return slug, err
+	}
+
+	for suffix := 2; ; suffix++ {
+		suffixString := "-" + strconv.Itoa(suffix)
+		candidate := shared.TruncateSlug(slug, maxSlugLength-len(suffixString)) + suffixString
+		used, err = isUsed(candidate)
+		if err != nil || !used {
+			return candidate, err
+		}
+	}
+}
+
+// PostAncestors returns nodes of the posts and all of their ancestors keyed by post ID, it loads one hierarchy level per query.
+// Every post is loaded only once, so it stops on existing loop in post_parent
+func (repo *repository) PostAncestors(ctx context.Context, idList []uint64) (map[uint64]*model.PostNode, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := config.Tables().Posts
+	conn := shared.Conn(ctx, repo.db)
+
+	nodes := map[uint64]*model.PostNode{}
+	pending := idList
+	for len(pending) > 0 {
+		sqlQuery, args, err := sqlbuilder.New(`SELECT ID, post_parent, post_name, post_type FROM `).Ident(tableName).
+			SQL(` WHERE `).In("ID", sqlbuilder.Uint64s(pending)).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+
+		q, err := conn.QueryContext(ctx, sqlQuery, args...)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": pending,
+				"func":   "conn.QueryContext",
+			}).Errorf("Failed to run db query: %s", err)
+			return nil, err
+		}
+
+		var parents []uint64
+		for q.Next() {
+			node := &model.PostNode{}
+			if err = q.Scan(&node.ID, &node.Parent, &node.Name, &node.Type); err != nil {
+				q.Close()
+				log.WithFields(log.Fields{
+					"params": pending,
+					"func":   "q.Scan",
+				}).Errorf("Failed to run query scan: %s", err)
+				return nil, err
+			}
+			nodes[node.ID] = node
+			if node.Parent != 0 {
+				parents = append(parents, node.Parent)
+			}
+		}
+		q.Close()
+		if err = q.Err(); err != nil {
+			return nil, err
+		}
+
+		pending = nil
+		for _, id := range parents {
+			if _, ok := nodes[id]; !ok && !toolbox.UInt64InSlice(id, pending) {
+				pending = append(pending, id)
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+// mysqlTime scans datetime column that may contain zero date ('0000-00-00 00:00:00') as zero time.Time
+type mysqlTime struct {
+	Time time.Time
+}
+
+// Scan implements sql.Scanner interface
+func (t *mysqlTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		t.Time = v
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	case nil:
+		t.Time = time.Time{}
+	default:
+		return fmt.Errorf("unsupported datetime value %v", value)
+	}
+	return nil
+}
+
+func (t *mysqlTime) parse(value string) (err error) {
+	if value == model.ZeroDateTime {
+		t.Time = time.Time{}
+		return nil
+	}
+	t.Time, err = time.Parse(model.DateTimeFormat, value)
+	return err
+}
@@ -0,0 +1,134 @@
+package post
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/fulltext"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/toolbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSQLQuery_SearchRelevance(t *testing.T) {
+	search := "x' OR '1'='1"
+	params := model.ListFilter{Page: 2, PerPage: 10, Search: &search, OrderBy: toolbox.StringPointer("relevance"), Type: model.PostType}
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: "wp_"})
+
+	match, err := fulltext.NewLikeBackend(dialect.MySQL).Match(context.Background(), search)
+	assert.NoError(t, err)
+
+	sqlQuery, args, err := getSQLQuery(ctx, dialect.MySQL, params, match)
+	assert.NoError(t, err)
+	assert.Contains(t, sqlQuery, "(post_title LIKE ? ESCAPE '\\\\')")
+	assert.Contains(t, sqlQuery, "post_password = ''")
+	assert.Contains(t, sqlQuery, " ORDER BY "+match.Score+" DESC LIMIT ? OFFSET ?")
+	assert.False(t, strings.Contains(sqlQuery, search))
+
+	// condition arguments, post type and status, score arguments and limit arguments are in the order of placeholders
+	keyword := "%" + search + "%"
+	assert.Equal(t, []interface{}{keyword, keyword, keyword, model.PostType, "publish", keyword, keyword, keyword, 10, 10}, args)
+
+	_, _, err = getSQLQuery(ctx, dialect.MySQL, model.ListFilter{Page: 1, PerPage: 10, OrderBy: toolbox.StringPointer("relevance")}, nil)
+	assert.Error(t, err)
+}
+
+// hostileParams are seeds of fuzz targets of list parameters
+var hostileParams = []string{"x' OR '1'='1", "1); DROP TABLE wp_posts; --", "\\' OR 1=1 #", "?", "'; SELECT SLEEP(10); --", "\x00", "",
+	"0) OR (1=1", "1 UNION SELECT user_pass FROM wp_users", "post_date; DROP TABLE wp_posts", "1,2", " 3 , ,4", "1,x"}
+
+func FuzzGetSQLQuery(f *testing.F) {
+	for _, value := range hostileParams {
+		f.Add(value)
+	}
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: "wp_"})
+
+	// filter with the given string in every string parameter that is bound to the query
+	filter := func(value string) model.ListFilter {
+		return model.ListFilter{Page: 1, PerPage: 10, Type: model.PostType,
+			Slug: &value, Before: &value, After: &value, Status: &value}
+	}
+	benignSQL, _, err := getSQLQuery(ctx, dialect.MySQL, filter("hello"), nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		// slug is comma separated list, so comma adds placeholder of another slug
+		value = strings.Replace(value, ",", "", -1)
+		sqlQuery, args, err := getSQLQuery(ctx, dialect.MySQL, filter(value), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, benignSQL, sqlQuery)
+		assert.Equal(t, strings.Count(sqlQuery, "?"), len(args))
+		assert.Equal(t, value, args[0])
+	})
+}
+
+func FuzzGetSQLQuery_Parent(f *testing.F) {
+	for _, value := range hostileParams {
+		f.Add(value)
+	}
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: "wp_"})
+
+	// parent is list of ids that are bound to the query, parent that isn't list of ids and orderby that isn't known
+	// orderby value never reach the query
+	f.Fuzz(func(t *testing.T, value string) {
+		sqlQuery, args, err := getSQLQuery(ctx, dialect.MySQL, model.ListFilter{Page: 1, PerPage: 10, Type: model.PageType, ParentExclude: &value}, nil)
+		parents, parseErr := toolbox.CSVToUInt64Slice(value)
+		if parseErr != nil {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, strings.Count(sqlQuery, "?"), len(args))
+			assert.Equal(t, len(parents)+4, len(args))
+		}
+
+		_, _, err = getSQLQuery(ctx, dialect.MySQL, model.ListFilter{Page: 1, PerPage: 10, Type: model.PostType, OrderBy: &value}, nil)
+		known := false
+		for _, orderBy := range postOrderValues() {
+			known = known || value == orderBy
+		}
+		if !known {
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestGetSQLQuery_HostileParent(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: "wp_"})
+
+	sqlQuery, args, err := getSQLQuery(ctx, dialect.MySQL, model.ListFilter{Page: 1, PerPage: 10, Type: model.PageType, Parent: toolbox.StringPointer("1, 2")}, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, sqlQuery, "post_parent IN (?, ?)")
+	assert.Equal(t, []interface{}{uint64(1), uint64(2), model.PageType, "publish", 10, 0}, args)
+}
+
+func TestGetQueryColumns_Permalink(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE wp_posts (post_date DATETIME, post_name TEXT, post_type TEXT)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO wp_posts VALUES ('2020-01-10 10:00:00', 'hello-world', 'post')`)
+	assert.NoError(t, err)
+
+	columns := getQueryColumns(db.Dialect, model.PostType, "p")
+	permalink := columns[len(columns)-1]
+	assert.NotContains(t, permalink, "postNamePermalink")
+
+	for structure, link := range map[string]string{
+		postNamePermalink:                "http://example.com/hello-world/",
+		"/%year%/%monthnum%/%postname%/": "http://example.com/2020/01/hello-world/",
+	} {
+		var actual string
+		err = db.QueryRow(`SELECT `+permalink+` FROM wp_posts p`, "http://example.com", structure).Scan(&actual)
+		assert.NoError(t, err)
+		assert.Equal(t, link, actual)
+	}
+}
@@ -6,20 +6,26 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/qreasio/restlr/model"
 	"github.com/qreasio/restlr/shared"
 	"github.com/qreasio/restlr/term"
 	"github.com/qreasio/restlr/toolbox"
+	"github.com/qreasio/restlr/tracing"
 	"github.com/qreasio/restlr/user"
 	log "github.com/sirupsen/logrus"
 	"github.com/yvasiyarov/php_session_decoder/php_serialize"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Service handles async log of audit event
 type Service interface {
 	GetPost(ctx context.Context, req model.GetItemRequest) (interface{}, error)
 	ListPosts(ctx context.Context, params model.ListRequest) (interface{}, error)
+	CreatePost(ctx context.Context, req model.WritePostRequest) (interface{}, error)
+	UpdatePost(ctx context.Context, req model.WritePostRequest) (interface{}, error)
+	DeletePost(ctx context.Context, req model.DeleteItemRequest) (interface{}, error)
 }
 
 type service struct {
@@ -49,122 +55,228 @@ func GetFeaturedMedia(postID uint64, metas map[uint64]map[string]string) uint64
 	return 0
 }
 
-// PullRawPostData pull and store post metas, user, taxonomies term taxonomies and format for post
-func (s *service) PullRawPostData(ctx context.Context, idList []uint64, authors []uint64, embed bool) (*model.RawPost, error) {
-	metas, err := s.shared.PostMetasByPostIDs(ctx, idList)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": idList,
-			"func":   "s.shared.PostMetasByPostIDs",
-		}).Errorf("Failed to post meta: %s", err)
-		return nil, err
-	}
+// PullRawPostData pull and store post metas, user, sticky posts, taxonomies term taxonomies, format and predecessor version for post,
+// with embed it also pulls comments and featured media of all posts, so the number of queries doesn't grow with the posts.
+// Independent queries run concurrently and the first error cancels the others
+func (s *service) PullRawPostData(ctx context.Context, idList []uint64, authors []uint64, embed bool) (_ *model.RawPost, err error) {
+	ctx, span := tracing.Start(ctx, "post.Service.PullRawPostData", attribute.Int("posts", len(idList)), attribute.Bool("embed", embed))
+	defer func() { tracing.End(span, err) }()
 
 	rawPost := &model.RawPost{
-		Metas:         metas,
 		FeaturedMedia: map[uint64]uint64{},
 		User:          map[uint64]*model.UserDetail{},
 	}
 
-	rawPost.StickyPostIDs, err = s.GetStickyPostID(ctx)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": ctx,
-			"func":   "s.GetStickyPostID",
-		}).Errorf("Failed to get sticky post id: %s", err)
-		return nil, err
+	var postIDList []string
+	for _, ID := range idList {
+		postIDList = append(postIDList, toolbox.UInt64ToStr(ID))
 	}
 
-	usersDict, err := s.user.GetUserByIDList(ctx, authors)
-
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": ctx,
-			"func":   "s.GetUserByIDList",
-		}).Errorf("Failed to get user by id list: %s", err)
-		return nil, err
-	}
-
-	for idx, ID := range idList {
-		postID := toolbox.UInt64ToStr(ID)
-
-		rawPost.FeaturedMedia[ID] = GetFeaturedMedia(ID, metas)
+	var usersDict map[uint64]*model.UserDetail
+	g, gctx := shared.NewQueryGroup(ctx)
 
-		rawPost.User[authors[idx]] = usersDict[authors[idx]]
+	g.Go(func() error {
+		metas, err := s.shared.PostMetasByPostIDs(gctx, idList)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": idList,
+				"func":   "s.shared.PostMetasByPostIDs",
+			}).Errorf("Failed to post meta: %s", err)
+			return err
+		}
 
+		rawPost.Metas = metas
+		for _, ID := range idList {
+			rawPost.FeaturedMedia[ID] = GetFeaturedMedia(ID, metas)
+		}
 		if embed {
-			rawPost.TermTaxonomies, rawPost.Taxonomies, rawPost.FormatMap, err = s.term.GetPostTaxonomyAndFormat(ctx, []string{postID})
-			if err != nil {
-				log.WithFields(log.Fields{
-					"params": fmt.Sprintf("context: %v, postIDList: %v", ctx, []string{postID}),
-					"func":   "s.term.GetPostTaxonomyAndFormat",
-				}).Errorf("Failed to get GetPostTaxonomyAndFormat: %s", err)
-				return nil, err
-			}
+			return s.pullFeaturedMedia(gctx, idList, rawPost)
 		}
-	}
-
-	return rawPost, nil
-}
-
-// ListPosts returns list of post data base on list posts request parameter
-func (s *service) ListPosts(ctx context.Context, params model.ListRequest) (interface{}, error) {
-	log.WithFields(log.Fields{
-		"params": params,
-	}).Debug("service.ListPosts")
+		return nil
+	})
 
-	//set sticky IDs
-	if params.Sticky != nil {
-		stickyIDs, err := s.GetStickyPostID(ctx)
+	g.Go(func() (err error) {
+		rawPost.StickyPostIDs, err = s.GetStickyPostID(gctx)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"params": ctx,
+				"params": idList,
 				"func":   "s.GetStickyPostID",
 			}).Errorf("Failed to get sticky post id: %s", err)
-			return nil, err
 		}
-		params.ListParams.StickyIDs = stickyIDs
+		return err
+	})
+
+	g.Go(func() (err error) {
+		usersDict, err = s.user.GetUserByIDList(gctx, authors)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": authors,
+				"func":   "s.GetUserByIDList",
+			}).Errorf("Failed to get user by id list: %s", err)
+		}
+		return err
+	})
+
+	// terms are pulled for every request because term ids of each taxonomy are fields of the post
+	g.Go(func() (err error) {
+		rawPost.TermTaxonomies, rawPost.Taxonomies, rawPost.FormatMap, err = s.term.GetPostTaxonomyAndFormat(gctx, postIDList)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": postIDList,
+				"func":   "s.term.GetPostTaxonomyAndFormat",
+			}).Errorf("Failed to get GetPostTaxonomyAndFormat: %s", err)
+		}
+		return err
+	})
+
+	g.Go(func() (err error) {
+		rawPost.Predecessors, err = s.post.GetPredecessorVersion(gctx, idList)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": idList,
+				"func":   "s.post.GetPredecessorVersion",
+			}).Errorf("Failed to get predecessor version: %s", err)
+		}
+		return err
+	})
+
+	if embed {
+		g.Go(func() error {
+			return s.pullComments(gctx, postIDList, rawPost)
+		})
 	}
 
-	//set tag and category term taxonomies that will be used to filter posts
-	tags, err := s.term.TermTaxonomyByTermIDListTaxonomy(params.Tags, model.TagType)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": params.Tags,
-			"func":   "s.term.TermTaxonomyByTermIDListTaxonomy",
-		}).Errorf("Failed to get term taxonomy for tags: %s", err)
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
-	categories, err := s.term.TermTaxonomyByTermIDListTaxonomy(params.Categories, model.CategoryType)
+
+	for idx := range idList {
+		rawPost.User[authors[idx]] = usersDict[authors[idx]]
+	}
+
+	return rawPost, nil
+}
+
+// pullComments pulls approved comments of the posts in one query
+func (s *service) pullComments(ctx context.Context, postIDList []string, rawPost *model.RawPost) error {
+	comments, err := s.post.CommentsByPostIDs(ctx, postIDList)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"params": params.Categories,
-			"func":   "s.term.TermTaxonomyByTermIDListTaxonomy",
-		}).Errorf("Failed to get term taxonomy for categories: %s", err)
-		return nil, err
+			"params": postIDList,
+			"func":   "s.post.CommentsByPostIDs",
+		}).Errorf("Failed to get comments by post id: %s", err)
+		return err
+	}
+
+	rawPost.Comments = map[uint64][]*model.Comment{}
+	for _, comment := range comments {
+		rawPost.Comments[*comment.PostID] = append(rawPost.Comments[*comment.PostID], comment)
+	}
+	return nil
+}
+
+// pullFeaturedMedia pulls featured media of the posts and metas of the media in one query each
+func (s *service) pullFeaturedMedia(ctx context.Context, idList []uint64, rawPost *model.RawPost) error {
+	var mediaIDList []uint64
+	seen := map[uint64]bool{}
+	for _, id := range idList {
+		if mediaID := rawPost.FeaturedMedia[id]; mediaID != 0 && !seen[mediaID] {
+			seen[mediaID] = true
+			mediaIDList = append(mediaIDList, mediaID)
+		}
 	}
-	termTaxonomiesMap := map[string][]*model.TermTaxonomy{model.TagType: tags, model.CategoryType: categories}
-	params.ListParams.TermTaxonomies = termTaxonomiesMap
 
-	//set excluded tag and category term taxonomies that will be used to filter posts
-	tagsExclude, err := s.term.TermTaxonomyByTermIDListTaxonomy(params.TagsExclude, model.TagType)
+	rawPost.Media = map[uint64]*model.Post{}
+	if len(mediaIDList) == 0 {
+		return nil
+	}
+
+	media, _, err := s.post.PostsByIDs(ctx, model.MediaType, mediaIDList)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"params": params.TagsExclude,
-			"func":   "s.term.TermTaxonomyByTermIDListTaxonomy",
-		}).Errorf("Failed to get term taxonomy for tags exclude: %s", err)
-		return nil, err
+			"params": mediaIDList,
+			"func":   "s.post.PostsByIDs",
+		}).Errorf("Failed to get featured media: %s", err)
+		return err
+	}
+	for _, m := range media {
+		rawPost.Media[m.ID] = m
 	}
-	categoriesExclude, err := s.term.TermTaxonomyByTermIDListTaxonomy(params.CategoriesExclude, model.CategoryType)
+
+	rawPost.MediaMetas, err = s.shared.PostMetasByPostIDs(ctx, mediaIDList)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"params": params.CategoriesExclude,
-			"func":   "s.term.TermTaxonomyByTermIDListTaxonomy",
-		}).Errorf("Failed to get term taxonomy for categories exclude: %s", err)
+			"params": mediaIDList,
+			"func":   "s.shared.PostMetasByPostIDs",
+		}).Errorf("Failed to get post meta by post id: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// termTaxonomiesByTaxonomy returns term taxonomies of term ids of each taxonomy that are used to filter posts
+func (s *service) termTaxonomiesByTaxonomy(ctx context.Context, termIDs map[string][]uint64) (map[string][]*model.TermTaxonomy, error) {
+	termTaxonomiesMap := map[string][]*model.TermTaxonomy{}
+	for taxonomy, termIDList := range termIDs {
+		termTaxonomies, err := s.term.TermTaxonomyByTermIDListTaxonomy(ctx, termIDList, taxonomy)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("termIDList: %v, taxonomy: %s", termIDList, taxonomy),
+				"func":   "s.term.TermTaxonomyByTermIDListTaxonomy",
+			}).Errorf("Failed to get term taxonomy: %s", err)
+			return nil, err
+		}
+		termTaxonomiesMap[taxonomy] = termTaxonomies
+	}
+	return termTaxonomiesMap, nil
+}
+
+// ListPosts returns list of post data base on list posts request parameter
+func (s *service) ListPosts(ctx context.Context, params model.ListRequest) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "post.Service.ListPosts", attribute.String("type", params.Type), attribute.Bool("embed", params.IsEmbed))
+	defer func() { tracing.End(span, err) }()
+
+	log.WithFields(log.Fields{
+		"params": params,
+	}).Debug("service.ListPosts")
+
+	//set term taxonomies of tags, categories and registered taxonomies that will be used to filter posts
+	include := map[string][]uint64{model.TagType: params.Tags, model.CategoryType: params.Categories}
+	exclude := map[string][]uint64{model.TagType: params.TagsExclude, model.CategoryType: params.CategoriesExclude}
+	for taxonomy, termIDList := range params.TaxonomyTerms {
+		include[taxonomy] = termIDList
+	}
+	for taxonomy, termIDList := range params.TaxonomyTermsExclude {
+		exclude[taxonomy] = termIDList
+	}
+
+	// sticky IDs and term taxonomies of filters are independent, so they are pulled concurrently
+	g, gctx := shared.NewQueryGroup(ctx)
+	if params.Sticky != nil {
+		g.Go(func() (err error) {
+			params.ListParams.StickyIDs, err = s.GetStickyPostID(gctx)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"params": params.Sticky,
+					"func":   "s.GetStickyPostID",
+				}).Errorf("Failed to get sticky post id: %s", err)
+			}
+			return err
+		})
+	}
+	g.Go(func() (err error) {
+		params.ListParams.TermTaxonomies, err = s.termTaxonomiesByTaxonomy(gctx, include)
+		return err
+	})
+	g.Go(func() (err error) {
+		params.ListParams.TermTaxonomiesExclude, err = s.termTaxonomiesByTaxonomy(gctx, exclude)
+		return err
+	})
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
-	termTaxonomiesMapExclude := map[string][]*model.TermTaxonomy{model.TagType: tagsExclude, model.CategoryType: categoriesExclude}
-	params.ListParams.TermTaxonomiesExclude = termTaxonomiesMapExclude
+
 	postIDList, err := s.post.QueryPosts(ctx, params.ListParams.ListFilter)
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -201,33 +313,27 @@ func (s *service) ListPosts(ctx context.Context, params model.ListRequest) (inte
 		return nil, err
 	}
 
-	predecessors, err := s.post.GetPredecessorVersion(ctx, postIDList)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": postIDList,
-			"func":   "s.post.GetPredecessorVersion",
-		}).Errorf("Failed to get predecessor version: %s", err)
-		return nil, err
-	}
-
 	var basePosts = make([]*model.ContentBase, 0)
 
 	for _, p := range posts {
+		// post list never accepts password, so content of password protected post is always masked
+		p.SetProtectedContent(nil)
 		p.FeaturedMedia = postData.FeaturedMedia[p.ID]
 		p.SetLinks(ctx)
-		p.SetPredecessorVersion(model.GetBaseURL(ctx), predecessors[p.ID][0])
+		p.SetPredecessorVersion(model.GetBaseURL(ctx), postData.Predecessors[p.ID][0])
 
 		// if post is embed ( _embed is on query string ) we need to pull all embedded attributes like author, term, replies, and featured media
 		if params.IsEmbed {
-			err = s.SetPostEmbedded(ctx, p, postData.TermTaxonomies[p.ID], postData.FormatMap, postData.User[p.Author])
+			err = s.SetPostEmbedded(ctx, p, postData)
 			if err != nil {
 				log.WithFields(log.Fields{
-					"params": fmt.Sprintf("termtaxonomies :%v, formatmap: %v, user : %v", postData.TermTaxonomies[p.ID], postData.FormatMap, postData.User[p.ID]),
+					"params": fmt.Sprintf("termtaxonomies :%v, user : %v", postData.TermTaxonomies[p.ID], postData.User[p.Author]),
 					"func":   "s.SetPostEmbedded",
 				}).Errorf("Failed to set post embedded: %s", err)
 				return nil, err
 			}
 		}
+		p.SetPostTypeSupports()
 
 		if params.Context != nil && *params.Context == model.EmbedContext {
 			basePosts = append(basePosts, &model.ContentBase{Base: p.Base, SharedContent: p.SharedContent, Embedded: p.Embedded})
@@ -244,25 +350,35 @@ func (s *service) ListPosts(ctx context.Context, params model.ListRequest) (inte
 	return posts, nil
 }
 
-// GetPost returns post data base on get post request parameter
+// GetPost returns post data base on get post request parameter, request type is set for registered post type
 func (s *service) GetPost(ctx context.Context, params model.GetItemRequest) (interface{}, error) {
-	p, err := s.post.PostByID(ctx, *params.ID, "post")
-	if err == sql.ErrNoRows {
+	postType := params.Type
+	if postType == "" {
+		postType = model.PostType
+	}
+
+	p, err := s.post.PostByID(ctx, *params.ID, postType)
+	if err == sql.ErrNoRows || (err == nil && p.Type != postType) {
 		return nil, model.ErrInvalidPostID
 	}
 	if err != nil {
 		log.WithFields(log.Fields{
-			"params": fmt.Sprintf("ID: %d, Type: %s", params.ID, "post"),
+			"params": fmt.Sprintf("ID: %d, Type: %s", *params.ID, postType),
 			"func":   "s.post.PostByID",
 		}).Errorf("Failed to get post by id: %s", err)
 		return nil, err
 	}
 
+	// mask content of password protected post and reject request with incorrect password
+	if err = p.SetProtectedContent(params.Password); err != nil {
+		return nil, err
+	}
+
 	// pull required related data to construct complete post response
 	postData, err := s.PullRawPostData(ctx, []uint64{p.ID}, []uint64{p.Author}, params.IsEmbed)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"params": fmt.Sprintf("ID: %v, Authors: %s, IsEmbed: %t", []uint64{p.ID}, []uint64{p.Author}, params.IsEmbed),
+			"params": fmt.Sprintf("ID: %v, Authors: %v, IsEmbed: %t", []uint64{p.ID}, []uint64{p.Author}, params.IsEmbed),
 			"func":   "s..PullRawPostData",
 		}).Errorf("Failed to get raw post: %s", err)
 		return nil, err
@@ -270,20 +386,20 @@ func (s *service) GetPost(ctx context.Context, params model.GetItemRequest) (int
 
 	p.FeaturedMedia = postData.FeaturedMedia[p.ID]
 	p.SetLinks(ctx)
-	predecessorVersions, err := s.post.GetPredecessorVersion(ctx, []uint64{p.ID})
-	p.SetPredecessorVersion(model.GetBaseURL(ctx), predecessorVersions[p.ID][0])
+	p.SetPredecessorVersion(model.GetBaseURL(ctx), postData.Predecessors[p.ID][0])
 
 	// if post is embed ( _embed is on query string ) we need to pull all embedded attributes like author, term, replies, and featured media
 	if params.IsEmbed {
-		err = s.SetPostEmbedded(ctx, p, postData.TermTaxonomies[p.ID], postData.FormatMap, postData.User[p.ID])
+		err = s.SetPostEmbedded(ctx, p, postData)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"params": fmt.Sprintf("termtaxonomies :%v, formatmap: %v, user : %v", postData.TermTaxonomies[p.ID], postData.FormatMap, postData.User[p.ID]),
+				"params": fmt.Sprintf("termtaxonomies :%v, user : %v", postData.TermTaxonomies[p.ID], postData.User[p.Author]),
 				"func":   "s.SetPostEmbedded",
 			}).Errorf("Failed to set post embedded: %s", err)
 			return nil, err
 		}
 	}
+	p.SetPostTypeSupports()
 
 	// if context = embed, we only return core attributes of post
 	if params.Context == model.EmbedContext {
@@ -296,89 +412,58 @@ func (s *service) GetPost(ctx context.Context, params model.GetItemRequest) (int
 	return p, err
 }
 
-// SetPostEmbedded set required attributes of post for _embed
-func (s *service) SetPostEmbedded(ctx context.Context, p *model.Post, taxonomies []*model.TermWithPostTaxonomy, formatMap map[uint64]string, user *model.UserDetail) error {
+// SetPostEmbedded set required attributes of post for _embed from author, terms, comments and featured media that are pulled
+// for all posts by PullRawPostData
+func (s *service) SetPostEmbedded(ctx context.Context, p *model.Post, postData *model.RawPost) (err error) {
+	ctx, span := tracing.Start(ctx, "post.Service.SetPostEmbedded", attribute.Int64("post", int64(p.ID)))
+	defer func() { tracing.End(span, err) }()
+
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
 
 	p.Embedded = &model.Embedded{}
 	// set author
-	p.Embedded.Author = user.UserDetailAsUserSlice(apiConfig.APIBaseURL, apiConfig.APIHost)
+	p.Embedded.Author = postData.User[p.Author].UserDetailAsUserSlice(apiConfig.APIBaseURL, apiConfig.APIHost)
 	// set comments
-	ids := toolbox.UInt64ToStrSlice(p.ID)
-	comments, err := s.post.CommentsByPostIDs(ids)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": ids,
-			"func":   "s.post.CommentsByPostIDs",
-		}).Errorf("Failed to get comments by post id: %s", err)
-		return err
-	}
-	if embeddedComments, err := model.CommentsAsEmbeddedComments(apiConfig.APIBaseURL, p.Link, comments); err == nil {
+	if embeddedComments, err := model.CommentsAsEmbeddedComments(apiConfig.APIBaseURL, p.Link, postData.Comments[p.ID]); err == nil {
 		p.Embedded.Replies = embeddedComments
 	} else {
 		return err
 	}
 
 	// set term
-	p.Embedded.Term = s.TermPostTaxonomiesAsEmbeddedTerms(apiConfig.APIBaseURL, taxonomies)
-	// set featured media
-	featuredMedia, err := s.GetEmbeddedFeaturedMedia(ctx, p)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": p,
-			"func":   "s.GetEmbeddedFeaturedMedia",
-		}).Errorf("Failed to get embedded featured media: %s", err)
-		return err
+	p.Embedded.Term = s.TermPostTaxonomiesAsEmbeddedTerms(apiConfig.APIBaseURL, postData.TermTaxonomies[p.ID])
+	// set featured media, media that doesn't exist anymore is not embedded
+	if m, ok := postData.Media[p.FeaturedMedia]; ok {
+		p.Embedded.FeaturedMedia = EmbeddedFeaturedMedia(ctx, m, postData.MediaMetas[m.ID])
 	}
-	p.Embedded.FeaturedMedia = featuredMedia
 	return nil
 }
 
 // GetStickyPostID return slice of post id that is set as sticky
 func (s *service) GetStickyPostID(ctx context.Context) (map[int]bool, error) {
 	stickyPostOption, err := s.shared.LoadOption(ctx, "sticky_posts")
-	return s.post.ParseStickyPostID(stickyPostOption.OptionValue), err
-}
-
-func (s *service) GetEmbeddedFeaturedMedia(ctx context.Context, p *model.Post) ([]*model.BaseMedia, error) {
-	if p.FeaturedMedia == 0 {
-		return nil, nil
-	}
-
-	m, err := s.post.PostByID(ctx, p.FeaturedMedia, "media")
 	if err != nil {
-		log.WithFields(log.Fields{
-			"params": fmt.Sprintf("ID: %d, Type: %s", p.ID, "media"),
-			"func":   "s.post.PostByID",
-		}).Errorf("Failed to get post by id: %s", err)
 		return nil, err
 	}
+	return s.post.ParseStickyPostID(stickyPostOption.OptionValue), nil
+}
 
+// EmbeddedFeaturedMedia returns featured media m with alt text and media details from its metas for _embed
+func EmbeddedFeaturedMedia(ctx context.Context, m *model.Post, mediaMetas map[string]string) []*model.BaseMedia {
 	media := model.BaseMedia{Base: m.Base}
-	media.MimeType = *m.MimeType
-	media.MediaType = *m.MediaType
-
-	mediaMetas, err := s.shared.PostMetasByPostIDs(ctx, []uint64{p.FeaturedMedia})
-	if err != nil {
-		log.WithFields(log.Fields{
-			"params": []uint64{p.FeaturedMedia},
-			"func":   "s.shared.PostMetasByPostIDs",
-		}).Errorf("Failed to get post meta by post id: %s", err)
-		return nil, err
+	if m.MimeType != nil {
+		media.MimeType = *m.MimeType
+	}
+	if m.MediaType != nil {
+		media.MediaType = *m.MediaType
 	}
 
-	altMeta, altTextOk := mediaMetas[m.ID]["_wp_attachment_image_alt"]
+	altMeta, altTextOk := mediaMetas["_wp_attachment_image_alt"]
 	if altTextOk {
 		media.AltText = altMeta
 	}
 
-	metaValue, metadataOk := mediaMetas[m.ID]["_wp_attachment_metadata"]
-	valueString := ""
-	if metadataOk {
-		if err == nil {
-			valueString = metaValue
-		}
-	}
+	valueString, metadataOk := mediaMetas["_wp_attachment_metadata"]
 
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
 	mediaDetail := &model.MediaDetails{}
@@ -510,7 +595,7 @@ func (s *service) GetEmbeddedFeaturedMedia(ctx context.Context, p *model.Post) (
 								}
 
 							}
-							if width, widthOk := sizeMapValue[php_serialize.PhpValue("height")]; widthOk {
+							if width, widthOk := sizeMapValue[php_serialize.PhpValue("width")]; widthOk {
 								theWidth, ok := width.(int)
 								if !ok {
 									widthString := width.(string)
@@ -521,9 +606,9 @@ func (s *service) GetEmbeddedFeaturedMedia(ctx context.Context, p *model.Post) (
 											"func":   " strconv.Atoi",
 										}).Errorf("Error while convert theWidth %v\n", err)
 									}
-									imgSize.Height = theWidth
+									imgSize.Width = theWidth
 								} else {
-									imgSize.Height = theWidth
+									imgSize.Width = theWidth
 								}
 
 							}
@@ -552,7 +637,7 @@ func (s *service) GetEmbeddedFeaturedMedia(ctx context.Context, p *model.Post) (
 
 	media.SourceURL = apiConfig.SiteURL + "/" + apiConfig.UploadPath + "/" + mediaDetail.File
 	media.MediaDetails = mediaDetail
-	return []*model.BaseMedia{&media}, nil
+	return []*model.BaseMedia{&media}
 }
 
 func (s *service) TermPostTaxonomiesAsEmbeddedTerms(APIBaseURL string, taxonomies []*model.TermWithPostTaxonomy) []*model.Term {
@@ -560,17 +645,166 @@ func (s *service) TermPostTaxonomiesAsEmbeddedTerms(APIBaseURL string, taxonomie
 	for _, t := range taxonomies {
 		term := &t.Term
 
-		if t.Taxonomy == model.CategoryType {
-			term.Link = model.CategoryLink(APIBaseURL, t.Slug)
-
-		} else if t.Taxonomy == model.TagType {
-			term.Link = model.TagLink(APIBaseURL, t.Slug)
-
+		if t.Taxonomy != model.PostFormatType {
+			term.Link = model.TaxonomyTermLink(APIBaseURL, t.Taxonomy, t.Slug)
 		}
 
 		id := strconv.FormatUint(t.TermID, 10)
-		term.Links = model.GetTermLinks("post", APIBaseURL, id)
+		term.Links = model.GetTermLinks(t.Taxonomy, APIBaseURL, id)
 		terms = append(terms, term)
 	}
 	return terms
 }
+
+// CreatePost inserts new post with its terms, metas and revision in a single transaction and returns the created post
+func (s *service) CreatePost(ctx context.Context, req model.WritePostRequest) (interface{}, error) {
+	record, err := NewPostRecord(ctx, s.shared, model.PostType)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": req,
+			"func":   "NewPostRecord",
+		}).Errorf("Failed to create new post record: %s", err)
+		return nil, err
+	}
+
+	return s.savePost(ctx, record, nil, req)
+}
+
+// UpdatePost updates existing post with its terms, metas and revision in a single transaction and returns the updated post
+func (s *service) UpdatePost(ctx context.Context, req model.WritePostRequest) (interface{}, error) {
+	record, err := s.post.PostRecordByID(ctx, *req.ID)
+	if err == sql.ErrNoRows || (err == nil && record.Type != model.PostType) {
+		return nil, model.ErrInvalidPostID
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": *req.ID,
+			"func":   "s.post.PostRecordByID",
+		}).Errorf("Failed to get post record by id: %s", err)
+		return nil, err
+	}
+
+	previous := *record
+	return s.savePost(ctx, record, &previous, req)
+}
+
+// savePost applies write request to the record and saves it, previous is nil for new post
+func (s *service) savePost(ctx context.Context, record *model.PostRecord, previous *model.PostRecord, req model.WritePostRequest) (interface{}, error) {
+	loc, err := SiteLocation(ctx, s.shared)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"func": "SiteLocation",
+		}).Errorf("Failed to get site location: %s", err)
+		return nil, err
+	}
+
+	if err = ApplyWriteRequest(record, req, loc, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// new post without category is assigned to default category
+	if previous == nil && len(req.Categories) == 0 {
+		defaultCategory, err := OptionValue(ctx, s.shared, "default_category", "1")
+		if err != nil {
+			return nil, err
+		}
+		if categoryID, err := strconv.ParseUint(defaultCategory, 10, 64); err == nil {
+			req.Categories = []uint64{categoryID}
+		}
+	}
+
+	err = s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := SaveRecord(ctx, s.post, s.shared, record, previous, req); err != nil {
+			return err
+		}
+
+		var affected []uint64
+		for taxonomy, termIDList := range map[string][]uint64{model.CategoryType: req.Categories, model.TagType: req.Tags} {
+			if termIDList == nil {
+				continue
+			}
+			termTaxonomyIDs, err := s.term.SetObjectTerms(ctx, record.ID, termIDList, taxonomy)
+			if err != nil {
+				return err
+			}
+			affected = append(affected, termTaxonomyIDs...)
+		}
+
+		// status change affects count of all terms of the post
+		current, err := s.term.ObjectTermTaxonomyIDs(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+		return s.term.UpdateTermCount(ctx, append(affected, current...))
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": req,
+			"func":   "s.shared.WithTransaction",
+		}).Errorf("Failed to save post: %s", err)
+		return nil, err
+	}
+
+	return s.GetPost(ctx, model.GetItemRequest{ID: &record.ID, Password: &record.Password})
+}
+
+// DeletePost moves post to trash, or deletes it permanently with its metas and term relationships if force is true
+func (s *service) DeletePost(ctx context.Context, req model.DeleteItemRequest) (interface{}, error) {
+	record, err := s.post.PostRecordByID(ctx, *req.ID)
+	if err == sql.ErrNoRows || (err == nil && record.Type != model.PostType) {
+		return nil, model.ErrInvalidPostID
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": *req.ID,
+			"func":   "s.post.PostRecordByID",
+		}).Errorf("Failed to get post record by id: %s", err)
+		return nil, err
+	}
+
+	previous, err := s.GetPost(ctx, model.GetItemRequest{ID: req.ID, Password: &record.Password})
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := SiteLocation(ctx, s.shared)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		termTaxonomyIDs, err := s.term.ObjectTermTaxonomyIDs(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+
+		if !req.Force {
+			if err = TrashRecord(ctx, s.post, s.shared, record, loc, time.Now()); err != nil {
+				return err
+			}
+			return s.term.UpdateTermCount(ctx, termTaxonomyIDs)
+		}
+
+		if err = s.term.DeleteObjectTerms(ctx, record.ID); err != nil {
+			return err
+		}
+		if err = DeleteRecord(ctx, s.post, s.shared, record); err != nil {
+			return err
+		}
+		return s.term.UpdateTermCount(ctx, termTaxonomyIDs)
+	})
+	if err != nil {
+		if err != model.ErrAlreadyTrashed {
+			log.WithFields(log.Fields{
+				"params": req,
+				"func":   "s.shared.WithTransaction",
+			}).Errorf("Failed to delete post: %s", err)
+		}
+		return nil, err
+	}
+
+	if req.Force {
+		return model.DeletedItem{Deleted: true, Previous: previous}, nil
+	}
+	return s.GetPost(ctx, model.GetItemRequest{ID: req.ID, Password: &record.Password})
+}
@@ -0,0 +1,180 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockpost "github.com/qreasio/restlr/post/mock"
+	mockshared "github.com/qreasio/restlr/shared/mock"
+	mockterm "github.com/qreasio/restlr/term/mock"
+	"github.com/qreasio/restlr/toolbox"
+	mockuser "github.com/qreasio/restlr/user/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFeaturedMedia returns image attachment of the id with metas that are serialized the same way as uploaded media
+func newFeaturedMedia(t *testing.T, id uint64) (*model.Post, map[string]string) {
+	media := NewPost()
+	media.ID = id
+	media.MimeType = toolbox.StringPointer("image/png")
+	media.MediaType = toolbox.StringPointer("image")
+	media.GUID.Rendered = toolbox.StringPointer("http://example.com/uploads/2020/01/photo.png")
+
+	details := &model.MediaDetails{Width: 400, Height: 300, File: "2020/01/photo.png", Sizes: map[string]*model.ImageSize{
+		"thumbnail": &model.ImageSize{File: "photo-150x150.png", Width: 150, Height: 150, MimeType: "image/png"},
+		"medium":    &model.ImageSize{File: "photo-300x225.png", Width: 300, Height: 225, MimeType: "image/png"},
+	}}
+	metadata, err := details.Serialize()
+	assert.Nil(t, err)
+
+	return &media, map[string]string{"_wp_attachment_metadata": metadata, "_wp_attachment_image_alt": "alt"}
+}
+
+func TestEmbeddedFeaturedMedia(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", UploadPath: "uploads"})
+	media, metas := newFeaturedMedia(t, 10)
+
+	res := EmbeddedFeaturedMedia(ctx, media, metas)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "alt", res[0].AltText)
+	assert.Equal(t, 400, res[0].MediaDetails.Width)
+	assert.Equal(t, 300, res[0].MediaDetails.Height)
+	assert.Equal(t, "http://example.com/uploads/2020/01/photo.png", res[0].SourceURL)
+	thumbnail := res[0].MediaDetails.Sizes["thumbnail"]
+	assert.Equal(t, "http://example.com/uploads/2020/01/photo-150x150.png", thumbnail.SourceURL)
+	// width and height of sizes are read from their own keys
+	medium := res[0].MediaDetails.Sizes["medium"]
+	assert.Equal(t, 300, medium.Width)
+	assert.Equal(t, 225, medium.Height)
+}
+
+func TestService_ListPostsEmbedQueryCount(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{
+		APIBaseURL: "http://example.com/wp-json/wp/v2", SiteURL: "http://example.com", UploadPath: "uploads"})
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// every post has its own author, comment and featured media, two posts share the same media
+	idList := []uint64{1, 2, 3}
+	var posts []*model.Post
+	metas := map[uint64]map[string]string{}
+	users := map[uint64]*model.UserDetail{}
+	var comments []*model.Comment
+	for _, id := range idList {
+		p := NewPost()
+		p.ID = id
+		p.Type = model.PostType
+		p.Author = id + 10
+		posts = append(posts, &p)
+		users[p.Author] = &model.UserDetail{User: model.User{ID: p.Author}}
+		postID := id
+		comments = append(comments, &model.Comment{ID: id + 100, PostID: &postID})
+	}
+	metas[1] = map[string]string{"_thumbnail_id": "20"}
+	metas[2] = map[string]string{"_thumbnail_id": "20"}
+	metas[3] = map[string]string{"_thumbnail_id": "30"}
+	media20, mediaMetas20 := newFeaturedMedia(t, 20)
+	media30, mediaMetas30 := newFeaturedMedia(t, 30)
+
+	// each related data is pulled once for all posts, whatever the number of posts is
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+	postRepoMock.EXPECT().QueryPosts(gomock.Any(), gomock.Any()).Return(idList, nil).Times(1)
+	postRepoMock.EXPECT().PostsByIDs(gomock.Any(), model.PostType, idList).Return(posts, []uint64{11, 12, 13}, nil).Times(1)
+	postRepoMock.EXPECT().GetPredecessorVersion(gomock.Any(), idList).Return(map[uint64]map[int]uint64{}, nil).Times(1)
+	postRepoMock.EXPECT().ParseStickyPostID(gomock.Any()).Return(map[int]bool{}).Times(1)
+	postRepoMock.EXPECT().CommentsByPostIDs(gomock.Any(), []string{"1", "2", "3"}).Return(comments, nil).Times(1)
+	postRepoMock.EXPECT().PostsByIDs(gomock.Any(), model.MediaType, []uint64{20, 30}).Return([]*model.Post{media20, media30}, []uint64{0, 0}, nil).Times(1)
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().LoadOption(gomock.Any(), "sticky_posts").Return(&model.Option{}, nil).Times(1)
+	sharedRepoMock.EXPECT().PostMetasByPostIDs(gomock.Any(), idList).Return(metas, nil).Times(1)
+	sharedRepoMock.EXPECT().PostMetasByPostIDs(gomock.Any(), []uint64{20, 30}).Return(map[uint64]map[string]string{20: mediaMetas20, 30: mediaMetas30}, nil).Times(1)
+	userRepoMock := mockuser.NewMockRepository(ctrl)
+	userRepoMock.EXPECT().GetUserByIDList(gomock.Any(), []uint64{11, 12, 13}).Return(users, nil).Times(1)
+	termRepoMock := mockterm.NewMockRepository(ctrl)
+	termRepoMock.EXPECT().TermTaxonomyByTermIDListTaxonomy(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*model.TermTaxonomy{}, nil).AnyTimes()
+	termRepoMock.EXPECT().GetPostTaxonomyAndFormat(gomock.Any(), []string{"1", "2", "3"}).Return(nil, nil, map[uint64]string{}, nil).Times(1)
+
+	s := &service{post: postRepoMock, term: termRepoMock, shared: sharedRepoMock, user: userRepoMock}
+
+	res, err := s.ListPosts(ctx, model.ListRequest{ListParams: model.ListParams{ListFilter: model.ListFilter{Type: model.PostType}}, IsEmbed: true})
+	assert.Nil(t, err)
+	list := res.([]*model.Post)
+	assert.Len(t, list, 3)
+	for i, p := range list {
+		assert.Equal(t, p.Author, p.Embedded.Author[0].ID)
+		assert.Len(t, p.Embedded.Replies, 1)
+		assert.Equal(t, idList[i]+100, p.Embedded.Replies[0].ID)
+		assert.Len(t, p.Embedded.FeaturedMedia, 1)
+		assert.Equal(t, p.FeaturedMedia, p.Embedded.FeaturedMedia[0].ID)
+	}
+	assert.Equal(t, uint64(30), list[2].Embedded.FeaturedMedia[0].ID)
+}
+
+func TestService_GetPostOfPostType(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	ctrl := gomock.NewController(t)
+	// registering twice fails when the test runs more than once, the first registration is kept
+	_ = model.RegisterPostType(model.PostTypeConfig{Slug: "event", RestBase: "events", Supports: []string{"title"}, Taxonomies: []string{model.CategoryType}})
+	_ = model.RegisterTaxonomy(model.TaxonomyConfig{Slug: "region", RestBase: "regions", ObjectTypes: []string{"event"}})
+
+	event := NewPost()
+	event.ID = 5
+	event.Type = "event"
+	event.Author = 1
+	event.Title.Rendered = toolbox.StringPointer("Meetup")
+
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+	postRepoMock.EXPECT().PostByID(gomock.Any(), uint64(5), "event").DoAndReturn(func(ctx context.Context, id uint64, postType string) (*model.Post, error) {
+		p := event
+		return &p, nil
+	})
+	postRepoMock.EXPECT().PostByID(gomock.Any(), uint64(5), model.PostType).DoAndReturn(func(ctx context.Context, id uint64, postType string) (*model.Post, error) {
+		p := event
+		return &p, nil
+	})
+	postRepoMock.EXPECT().ParseStickyPostID(gomock.Any()).Return(map[int]bool{}).AnyTimes()
+	postRepoMock.EXPECT().GetPredecessorVersion(gomock.Any(), []uint64{5}).Return(map[uint64]map[int]uint64{5: {0: 0}}, nil).AnyTimes()
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().PostMetasByPostIDs(gomock.Any(), []uint64{5}).Return(map[uint64]map[string]string{}, nil).AnyTimes()
+	sharedRepoMock.EXPECT().LoadOption(gomock.Any(), "sticky_posts").Return(&model.Option{}, nil).AnyTimes()
+	userRepoMock := mockuser.NewMockRepository(ctrl)
+	userRepoMock.EXPECT().GetUserByIDList(gomock.Any(), []uint64{1}).Return(map[uint64]*model.UserDetail{}, nil).AnyTimes()
+
+	termRepoMock := mockterm.NewMockRepository(ctrl)
+	termRepoMock.EXPECT().GetPostTaxonomyAndFormat(gomock.Any(), []string{"5"}).Return(nil, map[uint64]map[string][]uint64{
+		5: {model.CategoryType: {1}, "region": {3, 4}},
+	}, map[uint64]string{}, nil)
+
+	s := &service{post: postRepoMock, term: termRepoMock, shared: sharedRepoMock, user: userRepoMock}
+
+	id := uint64(5)
+	res, err := s.GetPost(ctx, model.GetItemRequest{ID: &id, Type: "event"})
+	assert.Nil(t, err)
+	p := res.(*model.Post)
+	assert.Equal(t, "Meetup", *p.Title.Rendered)
+	// features that event doesn't support are not returned
+	assert.Nil(t, p.Content)
+	assert.Nil(t, p.Excerpt)
+	assert.Equal(t, uint64(0), p.Author)
+	assert.Equal(t, "http://example.com/wp-json/wp/v2/events/5", p.Links.SelfLink[0]["href"])
+	assert.Equal(t, []model.TermPost{
+		{Href: "http://example.com/wp-json/wp/v2/categories?post=5", Embeddable: true, Taxonomy: model.CategoryType},
+		{Href: "http://example.com/wp-json/wp/v2/regions?post=5", Embeddable: true, Taxonomy: "region"},
+	}, p.Links.Term)
+	assert.Equal(t, []uint64{1}, p.Categories)
+
+	// term ids of registered taxonomy are encoded as field named by its rest base
+	data, err := json.Marshal(p)
+	assert.Nil(t, err)
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, []interface{}{float64(3), float64(4)}, fields["regions"])
+	assert.Equal(t, "Meetup", fields["title"].(map[string]interface{})["rendered"])
+
+	// post of other type is not returned by posts endpoint
+	_, err = s.GetPost(ctx, model.GetItemRequest{ID: &id})
+	assert.Equal(t, model.ErrInvalidPostID, err)
+}
@@ -17,18 +17,19 @@ import (
 var decoder *form.Decoder
 
 // MakeHTTPHandler returns http handler that makes a set of endpoints available on predefined paths
-func MakeHTTPHandler(s Service) http.Handler {
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
 	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
 
 	ListPostsHandler := kithttp.NewServer(
-		makeListPostsEndpoint(s),
+		instrument("post.ListPosts", makeListPostsEndpoint(s)),
 		listPostsRequestDecoder,
 		resthttp.EncodeJSONResponse,
 	)
 	r.Method(http.MethodGet, "/", ListPostsHandler)
 
 	GetPostHandler := kithttp.NewServer(
-		makeGetPostEndpoint(s),
+		instrument("post.GetPost", makeGetPostEndpoint(s)),
 		getPostRequestDecoder,
 		resthttp.EncodeJSONResponse,
 		[]kithttp.ServerOption{
@@ -37,9 +38,129 @@ func MakeHTTPHandler(s Service) http.Handler {
 	)
 	r.Method(http.MethodGet, "/{id}", GetPostHandler)
 
+	writeOptions := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(resthttp.EncodeError),
+	}
+
+	CreatePostHandler := kithttp.NewServer(
+		instrument("post.CreatePost", makeCreatePostEndpoint(s)),
+		createPostRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		writeOptions...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/", CreatePostHandler)
+
+	UpdatePostHandler := kithttp.NewServer(
+		instrument("post.UpdatePost", makeUpdatePostEndpoint(s)),
+		updatePostRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		writeOptions...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/{id}", UpdatePostHandler)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPut, "/{id}", UpdatePostHandler)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPatch, "/{id}", UpdatePostHandler)
+
+	DeletePostHandler := kithttp.NewServer(
+		instrument("post.DeletePost", makeDeletePostEndpoint(s)),
+		deletePostRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		writeOptions...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodDelete, "/{id}", DeletePostHandler)
+
+	return r
+}
+
+// MakePostTypeHTTPHandler returns http handler with read endpoints for the registered post type, it uses the same queries as posts endpoints
+func MakePostTypeHTTPHandler(s Service, postType string, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	ListHandler := kithttp.NewServer(
+		instrument("post.ListPosts", makeListPostsEndpoint(s)),
+		listPostTypeRequestDecoder(postType),
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	r.Method(http.MethodGet, "/", ListHandler)
+
+	GetHandler := kithttp.NewServer(
+		instrument("post.GetPost", makeGetPostEndpoint(s)),
+		getPostTypeRequestDecoder(postType),
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	r.Method(http.MethodGet, "/{id}", GetHandler)
+
 	return r
 }
 
+// parseIDParam returns id parameter of the route, it returns ErrInvalidRoute if id is not a number
+func parseIDParam(r *http.Request) (*uint64, error) {
+	id := chi.URLParam(r, "id")
+	postID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": id,
+			"func":   "strconv.ParseUint",
+		}).Errorf("Failed to parse uint from string: %s", err)
+		return nil, model.ErrInvalidRoute
+	}
+	return &postID, nil
+}
+
+func createPostRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	writeRequest := model.WritePostRequest{Type: model.PostType}
+	if err := resthttp.DecodeBody(r, &writeRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "resthttp.DecodeBody",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	return writeRequest, nil
+}
+
+func updatePostRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	writeRequest := model.WritePostRequest{Type: model.PostType}
+	if err = resthttp.DecodeBody(r, &writeRequest); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "resthttp.DecodeBody",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	writeRequest.ID = id
+	return writeRequest, nil
+}
+
+func deletePostRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var deleteRequest model.DeleteItemRequest
+	r.ParseForm()
+	decoder = form.NewDecoder()
+	if err = decoder.Decode(&deleteRequest, r.Form); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "decoder.Decode",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, err
+	}
+	deleteRequest.ID = id
+	return deleteRequest, nil
+}
+
 func getPostRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
 	var getRequest model.GetItemRequest
 	r.ParseForm()
@@ -70,6 +191,11 @@ func getPostRequestDecoder(ctx context.Context, r *http.Request) (interface{}, e
 	return getRequest, nil
 }
 
+// DecodeListPostsRequest decodes ListRequest of posts from query string of the request
+func DecodeListPostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listPostsRequestDecoder(ctx, r)
+}
+
 func listPostsRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
 	var filter = model.ListFilter{Page: 1, PerPage: 100, Status: toolbox.StringPointer("publish"), Type: "post"}
 	var params = model.ListParams{ListFilter: filter}
@@ -90,5 +216,74 @@ func listPostsRequestDecoder(ctx context.Context, r *http.Request) (interface{},
 		isEmbed = true
 	}
 	listRequest.IsEmbed = isEmbed
+	if err = decodeTaxonomyFilters(r, &listRequest); err != nil {
+		return nil, err
+	}
 	return listRequest, nil
 }
+
+// decodeTaxonomyFilters sets term ids of registered taxonomies of the post type from query string, the parameter is rest base
+// of the taxonomy like 'region=3,4' and 'region_exclude=5'
+func decodeTaxonomyFilters(r *http.Request, listRequest *model.ListRequest) error {
+	for _, taxonomy := range model.PostTypeTaxonomies(listRequest.Type) {
+		if _, ok := model.RegisteredTaxonomy(taxonomy); !ok {
+			continue
+		}
+		restBase := model.Plural(taxonomy)
+
+		termIDList, err := resthttp.ParseIDList(r.Form[restBase])
+		if err != nil {
+			return err
+		}
+		if len(termIDList) > 0 {
+			if listRequest.TaxonomyTerms == nil {
+				listRequest.TaxonomyTerms = map[string][]uint64{}
+			}
+			listRequest.TaxonomyTerms[taxonomy] = termIDList
+		}
+
+		termIDList, err = resthttp.ParseIDList(r.Form[restBase+"_exclude"])
+		if err != nil {
+			return err
+		}
+		if len(termIDList) > 0 {
+			if listRequest.TaxonomyTermsExclude == nil {
+				listRequest.TaxonomyTermsExclude = map[string][]uint64{}
+			}
+			listRequest.TaxonomyTermsExclude[taxonomy] = termIDList
+		}
+	}
+	return nil
+}
+
+// listPostTypeRequestDecoder returns decoder of list request for the post type
+func listPostTypeRequestDecoder(postType string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		req, err := listPostsRequestDecoder(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		listRequest := req.(model.ListRequest)
+		listRequest.Type = postType
+		// filters of taxonomies that are decoded for posts are replaced by the taxonomies of the post type
+		listRequest.TaxonomyTerms = nil
+		listRequest.TaxonomyTermsExclude = nil
+		if err = decodeTaxonomyFilters(r, &listRequest); err != nil {
+			return nil, err
+		}
+		return listRequest, nil
+	}
+}
+
+// getPostTypeRequestDecoder returns decoder of get request for the post type
+func getPostTypeRequestDecoder(postType string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		req, err := getPostRequestDecoder(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		getRequest := req.(model.GetItemRequest)
+		getRequest.Type = postType
+		return getRequest, nil
+	}
+}
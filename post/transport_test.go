@@ -0,0 +1,41 @@
+package post
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/post/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport_PostTypeListTaxonomyFilters(t *testing.T) {
+	// registering twice fails when the test runs more than once, the first registration is kept
+	_ = model.RegisterPostType(model.PostTypeConfig{Slug: "event", RestBase: "events", Supports: []string{"title"}, Taxonomies: []string{model.CategoryType}})
+	_ = model.RegisterTaxonomy(model.TaxonomyConfig{Slug: "region", RestBase: "regions", ObjectTypes: []string{"event"}})
+
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockService(ctrl)
+	r := chi.NewRouter()
+	r.Mount("/events", MakePostTypeHTTPHandler(s, "event"))
+
+	var listRequest model.ListRequest
+	s.EXPECT().ListPosts(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, req model.ListRequest) (interface{}, error) {
+		listRequest = req
+		return []model.Post{}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?regions=3,4&regions_exclude=5&regions_exclude=6", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "event", listRequest.Type)
+	assert.Equal(t, map[string][]uint64{"region": {3, 4}}, listRequest.TaxonomyTerms)
+	assert.Equal(t, map[string][]uint64{"region": {5, 6}}, listRequest.TaxonomyTermsExclude)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?regions=north", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
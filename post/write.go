@@ -0,0 +1,384 @@
+package post
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/toolbox"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	thumbnailMetaKey       = "_thumbnail_id"
+	trashStatusMetaKey     = "_wp_trash_meta_status"
+	trashTimeMetaKey       = "_wp_trash_meta_time"
+	desiredPostSlugMetaKey = "_wp_desired_post_slug"
+	pageTemplateMetaKey    = "_wp_page_template"
+	trashedSlugSuffix      = "__trashed"
+	openStatus             = "open"
+	closedStatus           = "closed"
+)
+
+// OptionValue returns value of option from options table or defaultValue if the option doesn't exist
+func OptionValue(ctx context.Context, sharedRepo shared.Repository, optionName string, defaultValue string) (string, error) {
+	option, err := sharedRepo.LoadOption(ctx, optionName)
+	if err == sql.ErrNoRows {
+		return defaultValue, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return option.OptionValue, nil
+}
+
+// SiteLocation returns time location of the site from timezone_string option, or from gmt_offset option if timezone_string is empty
+func SiteLocation(ctx context.Context, sharedRepo shared.Repository) (*time.Location, error) {
+	timezone, err := OptionValue(ctx, sharedRepo, "timezone_string", "")
+	if err != nil {
+		return nil, err
+	}
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return loc, nil
+		}
+	}
+
+	gmtOffset, err := OptionValue(ctx, sharedRepo, "gmt_offset", "0")
+	if err != nil {
+		return nil, err
+	}
+	offset, err := strconv.ParseFloat(gmtOffset, 64)
+	if err != nil {
+		offset = 0
+	}
+
+	return time.FixedZone("UTC"+gmtOffset, int(offset*3600)), nil
+}
+
+// NewPostRecord returns PostRecord for new post with default values like wp_insert_post does
+func NewPostRecord(ctx context.Context, sharedRepo shared.Repository, postType string) (*model.PostRecord, error) {
+	commentStatus, err := OptionValue(ctx, sharedRepo, "default_comment_status", openStatus)
+	if err != nil {
+		return nil, err
+	}
+	pingStatus, err := OptionValue(ctx, sharedRepo, "default_ping_status", openStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	// pages don't get comment and ping status from discussion settings
+	if postType == model.PageType {
+		commentStatus, pingStatus = closedStatus, closedStatus
+	}
+
+	return &model.PostRecord{
+		Type:          postType,
+		Status:        model.DraftStatus,
+		CommentStatus: commentStatus,
+		PingStatus:    pingStatus,
+	}, nil
+}
+
+// isDraft returns true if post status doesn't have a fixed publish date
+func isDraft(status string) bool {
+	return status == model.DraftStatus || status == model.PendingStatus
+}
+
+// isValidValue checks whether value is one of the allowed values
+func isValidValue(value string, allowed []string) bool {
+	for _, v := range allowed {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyWriteRequest validates write request and applies it to the record, loc is the site time location and now is the time of the write
+func ApplyWriteRequest(record *model.PostRecord, req model.WritePostRequest, loc *time.Location, now time.Time) error {
+	if record.ID == 0 && req.Author == nil {
+		return model.NewParamError("author", "author is a required property.")
+	}
+
+	if req.Status != nil {
+		if !isValidValue(*req.Status, model.WritableStatuses) {
+			return model.NewParamError("status", "status is not one of publish, future, draft, pending, private.")
+		}
+		record.Status = *req.Status
+	}
+	if req.CommentStatus != nil {
+		if !isValidValue(*req.CommentStatus, []string{openStatus, closedStatus}) {
+			return model.NewParamError("comment_status", "comment_status is not one of open, closed.")
+		}
+		record.CommentStatus = *req.CommentStatus
+	}
+	if req.PingStatus != nil {
+		if !isValidValue(*req.PingStatus, []string{openStatus, closedStatus}) {
+			return model.NewParamError("ping_status", "ping_status is not one of open, closed.")
+		}
+		record.PingStatus = *req.PingStatus
+	}
+	if req.Author != nil {
+		record.Author = *req.Author
+	}
+	if req.Title != nil {
+		record.Title = *req.Title
+	}
+	if req.Content != nil {
+		record.Content = *req.Content
+	}
+	if req.Excerpt != nil {
+		record.Excerpt = *req.Excerpt
+	}
+	if req.Password != nil {
+		record.Password = *req.Password
+	}
+	if req.Slug != nil {
+		record.Name = model.SanitizeTitle(*req.Slug)
+	}
+
+	if err := applyDates(record, req, loc, now); err != nil {
+		return err
+	}
+
+	// published and scheduled post always have slug, it is generated from title if it is empty
+	if record.Name == "" && !isDraft(record.Status) {
+		record.Name = model.SanitizeTitle(record.Title)
+	}
+
+	return nil
+}
+
+// applyDates sets post date, post modified date and their gmt version of record, it also switches status between publish and future base on the date
+func applyDates(record *model.PostRecord, req model.WritePostRequest, loc *time.Location, now time.Time) error {
+	switch {
+	case req.Date != nil:
+		date, err := model.ParseDate(*req.Date, loc)
+		if err != nil {
+			return model.NewParamError("date", "Invalid date.")
+		}
+		record.Date, record.DateGmt = date.In(loc), date.UTC()
+	case req.DateGmt != nil:
+		date, err := model.ParseDate(*req.DateGmt, time.UTC)
+		if err != nil {
+			return model.NewParamError("date_gmt", "Invalid date.")
+		}
+		record.Date, record.DateGmt = date.In(loc), date.UTC()
+	case record.ID == 0 && isDraft(record.Status):
+		// draft without date has floating date, it gets actual date when it is published
+		record.Date, record.DateGmt = now.In(loc), time.Time{}
+	case record.DateGmt.IsZero() && !isDraft(record.Status):
+		record.Date, record.DateGmt = now.In(loc), now.UTC()
+	}
+
+	record.Modified, record.ModifiedGmt = now.In(loc), now.UTC()
+
+	if record.Status == model.PublishStatus && record.DateGmt.After(now) {
+		record.Status = model.FutureStatus
+	} else if record.Status == model.FutureStatus && !record.DateGmt.After(now) {
+		record.Status = model.PublishStatus
+	}
+
+	return nil
+}
+
+// ValidateParent checks that parent of the record exists with the same post type and that the record is not one of the parent ancestors,
+// so moving the record doesn't create a loop in post hierarchy
+func ValidateParent(ctx context.Context, postRepo Repository, record *model.PostRecord) error {
+	if record.Parent == 0 {
+		return nil
+	}
+	if record.Parent == record.ID {
+		return model.NewParamError("parent", "Post can not be its own parent.")
+	}
+
+	nodes, err := postRepo.PostAncestors(ctx, []uint64{record.Parent})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": record.Parent,
+			"func":   "postRepo.PostAncestors",
+		}).Errorf("Failed to get post ancestors: %s", err)
+		return err
+	}
+
+	parent, ok := nodes[record.Parent]
+	if !ok || parent.Type != record.Type {
+		return model.NewParamError("parent", "Invalid post parent ID.")
+	}
+
+	for _, id := range AncestorIDs(nodes, record.Parent) {
+		if id == record.ID {
+			return model.NewParamError("parent", "Post parent can not be one of the post descendants.")
+		}
+	}
+
+	return nil
+}
+
+// AncestorIDs returns ID of the post and its ancestors ordered from the post to the root, it stops on loop in post hierarchy
+func AncestorIDs(nodes map[uint64]*model.PostNode, id uint64) []uint64 {
+	var ancestors []uint64
+	for id != 0 && !toolbox.UInt64InSlice(id, ancestors) {
+		node, ok := nodes[id]
+		if !ok {
+			break
+		}
+		ancestors = append(ancestors, id)
+		id = node.Parent
+	}
+	return ancestors
+}
+
+// PostPath returns slugs of the post ancestors and the post joined by slash like get_page_uri does
+func PostPath(nodes map[uint64]*model.PostNode, id uint64) string {
+	ancestors := AncestorIDs(nodes, id)
+	slugs := make([]string, 0, len(ancestors))
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if name := nodes[ancestors[i]].Name; name != "" {
+			slugs = append(slugs, name)
+		}
+	}
+	return strings.Join(slugs, "/")
+}
+
+// guid returns guid of new post like WP does for post without pretty permalink
+func guid(ctx context.Context, record *model.PostRecord) string {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	if record.Type == model.PageType {
+		return fmt.Sprintf("%s/?page_id=%d", config.SiteURL, record.ID)
+	}
+	return fmt.Sprintf("%s/?p=%d", config.SiteURL, record.ID)
+}
+
+// SaveRecord inserts new record or updates existing record with unique slug, guid and post metas, and stores the revision.
+// previous is the record before it is modified and it is nil for new record. It must be called inside transaction
+func SaveRecord(ctx context.Context, postRepo Repository, sharedRepo shared.Repository, record *model.PostRecord, previous *model.PostRecord, req model.WritePostRequest) error {
+	var err error
+	if record.Name != "" {
+		if record.Name, err = postRepo.UniquePostSlug(ctx, record.Name, record); err != nil {
+			return err
+		}
+	}
+
+	if previous == nil {
+		if _, err = postRepo.InsertPost(ctx, record); err != nil {
+			return err
+		}
+		// post id is used as slug if title is empty
+		if record.Name == "" && !isDraft(record.Status) {
+			record.Name = strconv.FormatUint(record.ID, 10)
+		}
+		if record.GUID == "" {
+			record.GUID = guid(ctx, record)
+		}
+	}
+
+	if err = postRepo.UpdatePost(ctx, record); err != nil {
+		return err
+	}
+
+	metas := map[string]string{}
+	for key, value := range req.Meta {
+		metas[key] = value
+	}
+	if req.Template != nil {
+		metas[pageTemplateMetaKey] = *req.Template
+	}
+	if req.FeaturedMedia != nil {
+		if *req.FeaturedMedia == 0 {
+			if err = sharedRepo.DeletePostMetas(ctx, record.ID, []string{thumbnailMetaKey}); err != nil {
+				return err
+			}
+		} else {
+			metas[thumbnailMetaKey] = strconv.FormatUint(*req.FeaturedMedia, 10)
+		}
+	}
+	if len(metas) > 0 {
+		if err = sharedRepo.UpdatePostMetas(ctx, record.ID, metas); err != nil {
+			return err
+		}
+	}
+
+	// revision is stored only if revisioned fields are changed, like wp_save_post_revision does, attachment doesn't support revision
+	if record.Type == model.AttachmentType {
+		return nil
+	}
+	if previous == nil || previous.Title != record.Title || previous.Content != record.Content || previous.Excerpt != record.Excerpt {
+		return saveRevision(ctx, postRepo, record)
+	}
+
+	return nil
+}
+
+// saveRevision inserts revision row of the record
+func saveRevision(ctx context.Context, postRepo Repository, record *model.PostRecord) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	name := fmt.Sprintf("%d-revision-v1", record.ID)
+
+	revision := &model.PostRecord{
+		Author:        record.Author,
+		Date:          record.Modified,
+		DateGmt:       record.ModifiedGmt,
+		Content:       record.Content,
+		Title:         record.Title,
+		Excerpt:       record.Excerpt,
+		Status:        model.InheritStatus,
+		CommentStatus: closedStatus,
+		PingStatus:    closedStatus,
+		Name:          name,
+		Modified:      record.Modified,
+		ModifiedGmt:   record.ModifiedGmt,
+		Parent:        record.ID,
+		GUID:          fmt.Sprintf("%s/%s/", config.SiteURL, name),
+		Type:          model.RevisionType,
+	}
+
+	if _, err := postRepo.InsertPost(ctx, revision); err != nil {
+		log.WithFields(log.Fields{
+			"params": record.ID,
+			"func":   "postRepo.InsertPost",
+		}).Errorf("Failed to save post revision: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// TrashRecord moves record to trash and stores its previous status and slug in post metas like wp_trash_post does.
+// It must be called inside transaction
+func TrashRecord(ctx context.Context, postRepo Repository, sharedRepo shared.Repository, record *model.PostRecord, loc *time.Location, now time.Time) error {
+	if record.Status == model.TrashStatus {
+		return model.ErrAlreadyTrashed
+	}
+
+	metas := map[string]string{
+		trashStatusMetaKey: record.Status,
+		trashTimeMetaKey:   strconv.FormatInt(now.Unix(), 10),
+	}
+	if record.Name != "" {
+		metas[desiredPostSlugMetaKey] = record.Name
+		record.Name = shared.TruncateSlug(record.Name, maxSlugLength-len(trashedSlugSuffix)) + trashedSlugSuffix
+	}
+	if err := sharedRepo.UpdatePostMetas(ctx, record.ID, metas); err != nil {
+		return err
+	}
+
+	record.Status = model.TrashStatus
+	record.Modified, record.ModifiedGmt = now.In(loc), now.UTC()
+
+	return postRepo.UpdatePost(ctx, record)
+}
+
+// DeleteRecord permanently deletes record with its post metas, it must be called inside transaction
+func DeleteRecord(ctx context.Context, postRepo Repository, sharedRepo shared.Repository, record *model.PostRecord) error {
+	if err := sharedRepo.DeletePostMetas(ctx, record.ID, nil); err != nil {
+		return err
+	}
+	return postRepo.DeletePost(ctx, record)
+}
@@ -0,0 +1,72 @@
+package post
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/toolbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyWriteRequest(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*3600)
+	now := time.Date(2020, 1, 10, 5, 0, 0, 0, time.UTC)
+	author := uint64(1)
+
+	// new post without author is rejected
+	record := &model.PostRecord{Type: model.PostType, Status: model.DraftStatus}
+	err := ApplyWriteRequest(record, model.WritePostRequest{}, loc, now)
+	assert.IsType(t, &model.ParamError{}, err)
+
+	// draft without date has floating gmt date and no slug
+	record = &model.PostRecord{Type: model.PostType, Status: model.DraftStatus}
+	err = ApplyWriteRequest(record, model.WritePostRequest{Author: &author, Title: toolbox.StringPointer("Hello World")}, loc, now)
+	assert.Nil(t, err)
+	assert.True(t, record.DateGmt.IsZero())
+	assert.Equal(t, "2020-01-10 12:00:00", model.FormatDate(record.Date))
+	assert.Equal(t, "", record.Name)
+
+	// publishing the draft sets gmt date and generates slug from title
+	record.ID = 10
+	err = ApplyWriteRequest(record, model.WritePostRequest{Status: toolbox.StringPointer(model.PublishStatus)}, loc, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-01-10 05:00:00", model.FormatDate(record.DateGmt))
+	assert.Equal(t, "2020-01-10 12:00:00", model.FormatDate(record.Modified))
+	assert.Equal(t, "hello-world", record.Name)
+
+	// published post with future date becomes scheduled post
+	record = &model.PostRecord{Type: model.PostType, Status: model.DraftStatus}
+	req := model.WritePostRequest{Author: &author, Status: toolbox.StringPointer(model.PublishStatus), Date: toolbox.StringPointer("2020-02-01T10:00:00")}
+	err = ApplyWriteRequest(record, req, loc, now)
+	assert.Nil(t, err)
+	assert.Equal(t, model.FutureStatus, record.Status)
+	assert.Equal(t, "2020-02-01 10:00:00", model.FormatDate(record.Date))
+	assert.Equal(t, "2020-02-01 03:00:00", model.FormatDate(record.DateGmt))
+
+	// invalid status is rejected
+	record.ID = 10
+	err = ApplyWriteRequest(record, model.WritePostRequest{Status: toolbox.StringPointer("deleted")}, loc, now)
+	assert.Equal(t, "status", err.(*model.ParamError).Param)
+}
+
+func TestPostPath(t *testing.T) {
+	nodes := map[uint64]*model.PostNode{
+		1: &model.PostNode{ID: 1, Name: "about"},
+		2: &model.PostNode{ID: 2, Parent: 1, Name: "team"},
+		3: &model.PostNode{ID: 3, Parent: 2, Name: "john"},
+		// 4 and 5 are parent of each other
+		4: &model.PostNode{ID: 4, Parent: 5, Name: "loop-a"},
+		5: &model.PostNode{ID: 5, Parent: 4, Name: "loop-b"},
+	}
+
+	assert.Equal(t, "about/team/john", PostPath(nodes, 3))
+	assert.Equal(t, []uint64{3, 2, 1}, AncestorIDs(nodes, 3))
+
+	// link of descendant follows its renamed ancestor
+	nodes[1].Name = "company"
+	assert.Equal(t, "company/team/john", PostPath(nodes, 3))
+
+	// existing loop in hierarchy doesn't hang
+	assert.Equal(t, "loop-a/loop-b", PostPath(nodes, 5))
+}
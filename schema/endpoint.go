@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"context"
+	nethttp "net/http"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeListTypesEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return s.ListTypes(ctx)
+	}
+	return endpoint
+}
+
+func makeGetTypeEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.GetTypeRequest)
+		res, err := s.GetType(ctx, req)
+		if err == model.ErrInvalidPostType {
+			return http.NewErrorResponse(http.RestTypeInvalidCode, http.RestTypeInvalidMessage, nethttp.StatusNotFound), nil
+		}
+		return res, err
+	}
+	return endpoint
+}
+
+func makeListTaxonomiesEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.ListTaxonomiesRequest)
+		return s.ListTaxonomies(ctx, req)
+	}
+	return endpoint
+}
+
+func makeGetTaxonomyEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.GetTaxonomyRequest)
+		res, err := s.GetTaxonomy(ctx, req)
+		if err == model.ErrInvalidTaxonomy {
+			return http.NewErrorResponse(http.RestTaxonomyInvalidCode, http.RestTaxonomyInvalidMessage, nethttp.StatusNotFound), nil
+		}
+		return res, err
+	}
+	return endpoint
+}
+
+func makeListStatusesEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.ListStatusesRequest)
+		return s.ListStatuses(ctx, req)
+	}
+	return endpoint
+}
+
+func makeGetStatusEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.GetStatusRequest)
+		res, err := s.GetStatus(ctx, req)
+		switch err {
+		case model.ErrInvalidStatus:
+			return http.NewErrorResponse(http.RestStatusInvalidCode, http.RestStatusInvalidMessage, nethttp.StatusNotFound), nil
+		case model.ErrCannotReadStatus:
+			return http.NewErrorResponse(http.RestCannotReadStatusCode, http.RestCannotReadStatusMessage, nethttp.StatusUnauthorized), nil
+		}
+		return res, err
+	}
+	return endpoint
+}
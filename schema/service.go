@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/qreasio/restlr/model"
+)
+
+// Service is interface for functions that describe post types, taxonomies and post statuses of the site
+type Service interface {
+	ListTypes(ctx context.Context) (interface{}, error)
+	GetType(ctx context.Context, req model.GetTypeRequest) (interface{}, error)
+	ListTaxonomies(ctx context.Context, req model.ListTaxonomiesRequest) (interface{}, error)
+	GetTaxonomy(ctx context.Context, req model.GetTaxonomyRequest) (interface{}, error)
+	ListStatuses(ctx context.Context, req model.ListStatusesRequest) (interface{}, error)
+	GetStatus(ctx context.Context, req model.GetStatusRequest) (interface{}, error)
+}
+
+// service is struct that will implement Service interface, the responses are built from built in and registered definitions
+// so it doesn't need repository
+type service struct{}
+
+// NewService is a simple helper function to create a service instance
+func NewService() Service {
+	return &service{}
+}
+
+// ListTypes returns built in and registered post types keyed by their slug
+func (s *service) ListTypes(ctx context.Context) (interface{}, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	types := map[string]*model.PostTypeResponse{}
+	for _, postType := range model.PostTypes() {
+		types[postType.Slug] = newPostTypeResponse(postType, apiConfig.APIBaseURL)
+	}
+	return types, nil
+}
+
+// GetType returns post type by its slug, it returns ErrInvalidPostType if the post type doesn't exist
+func (s *service) GetType(ctx context.Context, req model.GetTypeRequest) (interface{}, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	postType, ok := model.PostTypeBySlug(req.Type)
+	if !ok {
+		return nil, model.ErrInvalidPostType
+	}
+	return newPostTypeResponse(postType, apiConfig.APIBaseURL), nil
+}
+
+// ListTaxonomies returns built in and registered taxonomies keyed by their slug, only taxonomies of the post type
+// are returned if type parameter is set
+func (s *service) ListTaxonomies(ctx context.Context, req model.ListTaxonomiesRequest) (interface{}, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	taxonomies := map[string]*model.TaxonomyResponse{}
+	for _, taxonomy := range model.Taxonomies() {
+		res := newTaxonomyResponse(taxonomy, apiConfig.APIBaseURL)
+		if req.Type != nil && !inStrings(*req.Type, res.Types) {
+			continue
+		}
+		taxonomies[taxonomy.Slug] = res
+	}
+	return taxonomies, nil
+}
+
+// GetTaxonomy returns taxonomy by its slug, it returns ErrInvalidTaxonomy if the taxonomy doesn't exist
+func (s *service) GetTaxonomy(ctx context.Context, req model.GetTaxonomyRequest) (interface{}, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	taxonomy, ok := model.TaxonomyBySlug(req.Taxonomy)
+	if !ok {
+		return nil, model.ErrInvalidTaxonomy
+	}
+	return newTaxonomyResponse(taxonomy, apiConfig.APIBaseURL), nil
+}
+
+// ListStatuses returns post statuses keyed by their slug, statuses that aren't public are skipped without write access
+func (s *service) ListStatuses(ctx context.Context, req model.ListStatusesRequest) (interface{}, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	statuses := map[string]*model.PostStatus{}
+	for _, status := range model.PostStatuses {
+		if !status.Public && !req.WriteAccess {
+			continue
+		}
+		statuses[status.Slug] = newStatusResponse(status, apiConfig.APIBaseURL)
+	}
+	return statuses, nil
+}
+
+// GetStatus returns post status by its slug, it returns ErrInvalidStatus if the status doesn't exist
+// and ErrCannotReadStatus if the status isn't public and the request doesn't have write access
+func (s *service) GetStatus(ctx context.Context, req model.GetStatusRequest) (interface{}, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	for _, status := range model.PostStatuses {
+		if status.Slug != req.Status {
+			continue
+		}
+		if !status.Public && !req.WriteAccess {
+			return nil, model.ErrCannotReadStatus
+		}
+		return newStatusResponse(status, apiConfig.APIBaseURL), nil
+	}
+	return nil, model.ErrInvalidStatus
+}
+
+// newPostTypeResponse returns json response of the post type
+func newPostTypeResponse(postType *model.PostTypeConfig, baseURL string) *model.PostTypeResponse {
+	return &model.PostTypeResponse{
+		Description:  postType.Description,
+		Hierarchical: postType.Hierarchical,
+		Name:         postType.Name,
+		Slug:         postType.Slug,
+		Labels:       postType.Labels(),
+		Supports:     postType.Supports,
+		Taxonomies:   nonNilStrings(model.PostTypeTaxonomies(postType.Slug)),
+		RestBase:     postType.RestBase,
+		Links:        model.GetSchemaLinks(baseURL, "types", postType.RestBase),
+	}
+}
+
+// newTaxonomyResponse returns json response of the taxonomy
+func newTaxonomyResponse(taxonomy *model.TaxonomyConfig, baseURL string) *model.TaxonomyResponse {
+	return &model.TaxonomyResponse{
+		Name:         taxonomy.Name,
+		Slug:         taxonomy.Slug,
+		Description:  taxonomy.Description,
+		Labels:       taxonomy.Labels(),
+		Types:        nonNilStrings(model.TaxonomyObjectTypes(taxonomy.Slug)),
+		Hierarchical: taxonomy.Hierarchical,
+		RestBase:     taxonomy.RestBase,
+		Links:        model.GetSchemaLinks(baseURL, "taxonomies", taxonomy.RestBase),
+	}
+}
+
+// newStatusResponse returns copy of the post status with its links
+func newStatusResponse(status model.PostStatus, baseURL string) *model.PostStatus {
+	status.Links = &model.StatusLink{
+		Archives: []map[string]string{model.HrefMap(model.StatusArchives(baseURL, status.Slug))},
+	}
+	return &status
+}
+
+// nonNilStrings returns empty slice for nil list, so the list is encoded as empty json array
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// inStrings returns true if the value is in the list
+func inStrings(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSchemaTestContext() context.Context {
+	return context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{APIBaseURL: "http://localhost/wp-json/wp/v2"})
+}
+
+func TestService_Types(t *testing.T) {
+	ctx := newSchemaTestContext()
+	err := model.RegisterPostType(model.PostTypeConfig{Slug: "book", Name: "Books", RestBase: "books", Taxonomies: []string{model.CategoryType}})
+	assert.NoError(t, err)
+	err = model.RegisterTaxonomy(model.TaxonomyConfig{Slug: "genre", Name: "Genres", SingularName: "Genre", RestBase: "genres", Hierarchical: true, ObjectTypes: []string{"book"}})
+	assert.NoError(t, err)
+
+	s := NewService()
+
+	res, err := s.ListTypes(ctx)
+	assert.NoError(t, err)
+	types := res.(map[string]*model.PostTypeResponse)
+	assert.Len(t, types, 4)
+	assert.Equal(t, []string{model.CategoryType, model.TagType}, types[model.PostType].Taxonomies)
+	assert.Equal(t, []string{}, types[model.PageType].Taxonomies)
+	assert.True(t, types[model.PageType].Hierarchical)
+	assert.Equal(t, "media", types[model.AttachmentType].RestBase)
+
+	res, err = s.GetType(ctx, model.GetTypeRequest{Type: "book"})
+	assert.NoError(t, err)
+	book := res.(*model.PostTypeResponse)
+	assert.Equal(t, model.Labels{Name: "Books", SingularName: "Books"}, book.Labels)
+	assert.Equal(t, []string{model.CategoryType, "genre"}, book.Taxonomies)
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/types", book.Links.Collection[0]["href"])
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/books", book.Links.Items[0]["href"])
+
+	_, err = s.GetType(ctx, model.GetTypeRequest{Type: "revision"})
+	assert.Equal(t, model.ErrInvalidPostType, err)
+
+	res, err = s.ListTaxonomies(ctx, model.ListTaxonomiesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, res, 3)
+
+	bookType := "book"
+	res, err = s.ListTaxonomies(ctx, model.ListTaxonomiesRequest{Type: &bookType})
+	assert.NoError(t, err)
+	taxonomies := res.(map[string]*model.TaxonomyResponse)
+	assert.Len(t, taxonomies, 2)
+	assert.Equal(t, []string{model.PostType, "book"}, taxonomies[model.CategoryType].Types)
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/genres", taxonomies["genre"].Links.Items[0]["href"])
+
+	res, err = s.GetTaxonomy(ctx, model.GetTaxonomyRequest{Taxonomy: model.TagType})
+	assert.NoError(t, err)
+	assert.Equal(t, "tags", res.(*model.TaxonomyResponse).RestBase)
+
+	_, err = s.GetTaxonomy(ctx, model.GetTaxonomyRequest{Taxonomy: model.PostFormatType})
+	assert.Equal(t, model.ErrInvalidTaxonomy, err)
+}
+
+func TestService_Statuses(t *testing.T) {
+	ctx := newSchemaTestContext()
+	s := NewService()
+
+	res, err := s.ListStatuses(ctx, model.ListStatusesRequest{})
+	assert.NoError(t, err)
+	statuses := res.(map[string]*model.PostStatus)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/posts", statuses[model.PublishStatus].Links.Archives[0]["href"])
+
+	res, err = s.ListStatuses(ctx, model.ListStatusesRequest{WriteAccess: true})
+	assert.NoError(t, err)
+	assert.Len(t, res, 5)
+
+	_, err = s.GetStatus(ctx, model.GetStatusRequest{Status: model.DraftStatus})
+	assert.Equal(t, model.ErrCannotReadStatus, err)
+
+	res, err = s.GetStatus(ctx, model.GetStatusRequest{Status: model.DraftStatus, WriteAccess: true})
+	assert.NoError(t, err)
+	draft := res.(*model.PostStatus)
+	assert.True(t, draft.DateFloating)
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/posts?status=draft", draft.Links.Archives[0]["href"])
+	assert.Nil(t, model.PostStatuses[2].Links)
+
+	_, err = s.GetStatus(ctx, model.GetStatusRequest{Status: model.TrashStatus, WriteAccess: true})
+	assert.Equal(t, model.ErrInvalidStatus, err)
+}
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/form"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// options are server options of every handler in the package
+var options = []kithttp.ServerOption{
+	kithttp.ServerErrorEncoder(resthttp.EncodeError),
+}
+
+// MakeTypesHTTPHandler returns http handler that makes post type endpoints available on predefined paths
+func MakeTypesHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	ListTypesHandler := kithttp.NewServer(
+		instrument("schema.ListTypes", makeListTypesEndpoint(s)),
+		listTypesRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/", ListTypesHandler)
+
+	GetTypeHandler := kithttp.NewServer(
+		instrument("schema.GetType", makeGetTypeEndpoint(s)),
+		getTypeRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/{type}", GetTypeHandler)
+
+	return r
+}
+
+// MakeTaxonomiesHTTPHandler returns http handler that makes taxonomy endpoints available on predefined paths
+func MakeTaxonomiesHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	ListTaxonomiesHandler := kithttp.NewServer(
+		instrument("schema.ListTaxonomies", makeListTaxonomiesEndpoint(s)),
+		listTaxonomiesRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/", ListTaxonomiesHandler)
+
+	GetTaxonomyHandler := kithttp.NewServer(
+		instrument("schema.GetTaxonomy", makeGetTaxonomyEndpoint(s)),
+		getTaxonomyRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/{taxonomy}", GetTaxonomyHandler)
+
+	return r
+}
+
+// MakeStatusesHTTPHandler returns http handler that makes post status endpoints available on predefined paths
+func MakeStatusesHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	ListStatusesHandler := kithttp.NewServer(
+		instrument("schema.ListStatuses", makeListStatusesEndpoint(s)),
+		listStatusesRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/", ListStatusesHandler)
+
+	GetStatusHandler := kithttp.NewServer(
+		instrument("schema.GetStatus", makeGetStatusEndpoint(s)),
+		getStatusRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/{status}", GetStatusHandler)
+
+	return r
+}
+
+func listTypesRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func getTypeRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return model.GetTypeRequest{Type: chi.URLParam(r, "type")}, nil
+}
+
+func listTaxonomiesRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var listRequest model.ListTaxonomiesRequest
+	r.ParseForm()
+	if err := form.NewDecoder().Decode(&listRequest, r.Form); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "decoder.Decode",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, model.ErrInvalidParameter
+	}
+	return listRequest, nil
+}
+
+func getTaxonomyRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return model.GetTaxonomyRequest{Taxonomy: chi.URLParam(r, "taxonomy")}, nil
+}
+
+func listStatusesRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return model.ListStatusesRequest{WriteAccess: resthttp.HasWriteAccess(r)}, nil
+}
+
+func getStatusRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return model.GetStatusRequest{Status: chi.URLParam(r, "status"), WriteAccess: resthttp.HasWriteAccess(r)}, nil
+}
@@ -0,0 +1,29 @@
+package search
+
+import (
+	"context"
+	nethttp "net/http"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeSearchEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.SearchRequest)
+		res, err := s.Search(ctx, req)
+		if paramErr, ok := err.(*model.ParamError); ok {
+			return http.NewInvalidParam(paramErr.Param, paramErr.Message), nil
+		}
+		if err == model.ErrInvalidPageNumber {
+			return http.NewErrorResponse(http.RestSearchInvalidPageNumberCode, http.RestInvalidPageNumberMessage, nethttp.StatusBadRequest), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		searchResponse := res.(*model.SearchResponse)
+		return http.PaginatedResponse{Item: searchResponse.Results, Total: searchResponse.Total, TotalPages: searchResponse.TotalPages}, nil
+	}
+	return endpoint
+}
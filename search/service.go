@@ -0,0 +1,277 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/page"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// Service is interface for search functions
+type Service interface {
+	Search(ctx context.Context, req model.SearchRequest) (interface{}, error)
+}
+
+// service is struct that will implement Service interface, results are searched with repositories and
+// embedded from services of the post or term like WP embeds self link of the result
+type service struct {
+	post        post.Repository
+	term        term.Repository
+	postService post.Service
+	pageService page.Service
+	termService term.Service
+}
+
+// NewService is a simple helper function to create a service instance
+func NewService(postRepo post.Repository, termRepo term.Repository, postService post.Service, pageService page.Service, termService term.Service) Service {
+	return &service{
+		post:        postRepo,
+		term:        termRepo,
+		postService: postService,
+		pageService: pageService,
+		termService: termService,
+	}
+}
+
+// Subtypes returns post types or taxonomies that can be searched for the search type, attachment is not searched like in WP
+func Subtypes(searchType string) []string {
+	var list []string
+	if searchType == model.SearchTypeTerm {
+		return model.TermTaxonomies()
+	}
+	for _, postType := range model.PostTypes() {
+		if postType.Slug != model.AttachmentType {
+			list = append(list, postType.Slug)
+		}
+	}
+	return list
+}
+
+// validateRequest sets default type and subtype of the request and checks parameters like the schema of WP search endpoint,
+// it returns subtypes to search with 'any' replaced by every subtype of the search type
+func validateRequest(req *model.SearchRequest) ([]string, error) {
+	if req.Type == "" {
+		req.Type = model.SearchTypePost
+	}
+	if req.Type != model.SearchTypePost && req.Type != model.SearchTypeTerm {
+		return nil, model.NewParamError("type", fmt.Sprintf("type is not one of %s, %s.", model.SearchTypePost, model.SearchTypeTerm))
+	}
+	if req.Page < 1 {
+		return nil, model.NewParamError("page", "page must be greater than or equal to 1")
+	}
+	if req.PerPage < 1 || req.PerPage > model.SearchMaxPerPage {
+		return nil, model.NewParamError("per_page", fmt.Sprintf("per_page must be between 1 (inclusive) and %d (inclusive)", model.SearchMaxPerPage))
+	}
+
+	subtypes := Subtypes(req.Type)
+	if len(req.Subtype) == 0 {
+		return subtypes, nil
+	}
+	for i, subtype := range req.Subtype {
+		if subtype == model.SearchSubtypeAny {
+			return subtypes, nil
+		}
+		valid := false
+		for _, name := range subtypes {
+			valid = valid || name == subtype
+		}
+		if !valid {
+			return nil, model.NewParamError("subtype", fmt.Sprintf("subtype[%d] is not one of %s, %s.", i, strings.Join(subtypes, ", "), model.SearchSubtypeAny))
+		}
+	}
+	return req.Subtype, nil
+}
+
+// Search returns posts or terms that match the search keyword as search results with total number of results and pages,
+// it returns ErrInvalidPageNumber if the page is larger than the number of pages
+func (s *service) Search(ctx context.Context, req model.SearchRequest) (interface{}, error) {
+	subtypes, err := validateRequest(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.SearchFilter{
+		Search:   req.Search,
+		Subtypes: subtypes,
+		Include:  req.Include,
+		Exclude:  req.Exclude,
+		Page:     req.Page,
+		PerPage:  req.PerPage,
+	}
+
+	var results []*model.SearchResult
+	var total int
+	if req.Type == model.SearchTypeTerm {
+		results, total, err = s.searchTerms(ctx, filter)
+	} else {
+		results, total, err = s.searchPosts(ctx, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + req.PerPage - 1) / req.PerPage
+	if req.Page > totalPages && total > 0 {
+		return nil, model.ErrInvalidPageNumber
+	}
+
+	if req.IsEmbed {
+		if err = s.embedResults(ctx, results); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.SearchResponse{Results: results, Total: total, TotalPages: totalPages}, nil
+}
+
+// searchPosts returns search results of posts in order of relevance
+func (s *service) searchPosts(ctx context.Context, filter model.SearchFilter) ([]*model.SearchResult, int, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	results := make([]*model.SearchResult, 0)
+
+	idList, total, err := s.post.SearchPosts(ctx, filter)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": filter,
+			"func":   "s.post.SearchPosts",
+		}).Errorf("Failed to search posts: %s", err)
+		return nil, 0, err
+	}
+	if len(idList) == 0 {
+		return results, total, nil
+	}
+
+	posts, _, err := s.post.PostsByIDs(ctx, model.PostType, idList)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": idList,
+			"func":   "s.post.PostsByIDs",
+		}).Errorf("Failed to get posts by ids: %s", err)
+		return nil, 0, err
+	}
+	if err = s.setPageLinks(ctx, posts); err != nil {
+		return nil, 0, err
+	}
+
+	postMap := map[uint64]*model.Post{}
+	for _, p := range posts {
+		postMap[p.ID] = p
+	}
+	for _, id := range idList {
+		p, ok := postMap[id]
+		if !ok {
+			continue
+		}
+		title := ""
+		if p.Title != nil && p.Title.Rendered != nil {
+			title = *p.Title.Rendered
+		}
+		results = append(results, newSearchResult(apiConfig.APIBaseURL, p.ID, title, p.Link, model.SearchTypePost, p.Type))
+	}
+
+	return results, total, nil
+}
+
+// setPageLinks sets link of pages from slugs of their ancestors like page service does
+func (s *service) setPageLinks(ctx context.Context, posts []*model.Post) error {
+	var idList []uint64
+	for _, p := range posts {
+		if p.Type == model.PageType {
+			idList = append(idList, p.ID)
+		}
+	}
+	if len(idList) == 0 {
+		return nil
+	}
+
+	nodes, err := s.post.PostAncestors(ctx, idList)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": idList,
+			"func":   "s.post.PostAncestors",
+		}).Errorf("Failed to get page ancestors: %s", err)
+		return err
+	}
+
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	for _, p := range posts {
+		if p.Type == model.PageType {
+			p.Link = fmt.Sprintf("%s/%s/", config.SiteURL, post.PostPath(nodes, p.ID))
+		}
+	}
+	return nil
+}
+
+// searchTerms returns search results of terms in order of relevance
+func (s *service) searchTerms(ctx context.Context, filter model.SearchFilter) ([]*model.SearchResult, int, error) {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	results := make([]*model.SearchResult, 0)
+
+	terms, total, err := s.term.SearchTerms(ctx, filter)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": filter,
+			"func":   "s.term.SearchTerms",
+		}).Errorf("Failed to search terms: %s", err)
+		return nil, 0, err
+	}
+
+	for _, t := range terms {
+		link := model.TaxonomyTermLink(apiConfig.SiteURL, t.Taxonomy, t.Slug)
+		results = append(results, newSearchResult(apiConfig.APIBaseURL, t.TermID, t.Name, link, model.SearchTypeTerm, t.Taxonomy))
+	}
+
+	return results, total, nil
+}
+
+// newSearchResult returns search result with self link to the post or term and about link to its post type or taxonomy
+func newSearchResult(baseURL string, id uint64, title string, url string, searchType string, subtype string) *model.SearchResult {
+	aboutPrefix := "types"
+	if searchType == model.SearchTypeTerm {
+		aboutPrefix = "taxonomies"
+	}
+
+	return &model.SearchResult{
+		ID:      id,
+		Title:   title,
+		URL:     url,
+		Type:    searchType,
+		Subtype: subtype,
+		Links: &model.SearchLink{
+			Self:  []model.EmbeddableLink{{Embeddable: true, Href: fmt.Sprintf("%s/%s/%d", baseURL, model.Plural(subtype), id)}},
+			About: []map[string]string{model.HrefMap(fmt.Sprintf("%s/%s/%s", baseURL, aboutPrefix, subtype))},
+		},
+	}
+}
+
+// embedResults sets embed context of the post or term of every search result to _embedded
+func (s *service) embedResults(ctx context.Context, results []*model.SearchResult) error {
+	for _, result := range results {
+		id := result.ID
+
+		var embedded interface{}
+		var err error
+		switch {
+		case result.Type == model.SearchTypeTerm:
+			embedded, err = s.termService.GetTerm(ctx, model.GetTermRequest{ID: &id, Taxonomy: result.Subtype})
+		case result.Subtype == model.PageType:
+			embedded, err = s.pageService.GetPage(ctx, model.GetItemRequest{ID: &id, Context: model.EmbedContext})
+		default:
+			embedded, err = s.postService.GetPost(ctx, model.GetItemRequest{ID: &id, Type: result.Subtype, Context: model.EmbedContext})
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("ID: %d, Type: %s, Subtype: %s", id, result.Type, result.Subtype),
+				"func":   "s.embedResults",
+			}).Errorf("Failed to embed search result: %s", err)
+			return err
+		}
+		result.Embedded = &model.SearchEmbedded{Self: []interface{}{embedded}}
+	}
+	return nil
+}
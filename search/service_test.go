@@ -0,0 +1,129 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockpage "github.com/qreasio/restlr/page/mock"
+	mockpost "github.com/qreasio/restlr/post/mock"
+	mockterm "github.com/qreasio/restlr/term/mock"
+	"github.com/qreasio/restlr/toolbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSearchTestContext() context.Context {
+	return context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{
+		APIBaseURL: "http://localhost/wp-json/wp/v2",
+		SiteURL:    "http://localhost",
+	})
+}
+
+func newTestPost(id uint64, title string, postType string, link string) *model.Post {
+	p := &model.Post{}
+	p.ID = id
+	p.Title = &model.Rendered{Rendered: toolbox.StringPointer(title)}
+	p.Type = postType
+	p.Link = link
+	return p
+}
+
+func TestService_SearchValidation(t *testing.T) {
+	ctx := newSearchTestContext()
+	s := NewService(nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		req   model.SearchRequest
+		param string
+	}{
+		{model.SearchRequest{Type: "post-format", Page: 1, PerPage: 10}, "type"},
+		{model.SearchRequest{Page: 0, PerPage: 10}, "page"},
+		{model.SearchRequest{Page: 1, PerPage: 101}, "per_page"},
+		{model.SearchRequest{Page: 1, PerPage: 10, Subtype: []string{"category"}}, "subtype"},
+		{model.SearchRequest{Type: model.SearchTypeTerm, Page: 1, PerPage: 10, Subtype: []string{model.PostType}}, "subtype"},
+	}
+	for _, test := range tests {
+		_, err := s.Search(ctx, test.req)
+		paramErr, ok := err.(*model.ParamError)
+		if assert.True(t, ok, "%+v", test.req) {
+			assert.Equal(t, test.param, paramErr.Param)
+		}
+	}
+}
+
+func TestService_SearchPosts(t *testing.T) {
+	ctx := newSearchTestContext()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postRepoMock := mockpost.NewMockRepository(ctrl)
+	postRepoMock.EXPECT().SearchPosts(ctx, model.SearchFilter{
+		Search: "hello", Subtypes: []string{model.PostType, model.PageType}, Page: 2, PerPage: 2,
+	}).Return([]uint64{8, 5}, 3, nil)
+	postRepoMock.EXPECT().PostsByIDs(ctx, model.PostType, []uint64{8, 5}).Return([]*model.Post{
+		newTestPost(5, "Hello world", model.PostType, "http://localhost/hello-world/"),
+		newTestPost(8, "Hello", model.PageType, "http://localhost/hello/"),
+	}, []uint64{1, 1}, nil)
+	postRepoMock.EXPECT().PostAncestors(ctx, []uint64{8}).Return(map[uint64]*model.PostNode{
+		8: {ID: 8, Parent: 2, Name: "hello", Type: model.PageType},
+		2: {ID: 2, Name: "about", Type: model.PageType},
+	}, nil)
+
+	// page service mock returns page without recording the call
+	pageServiceMock := mockpage.NewMockService(ctrl)
+	postServiceMock := mockpost.NewMockService(ctrl)
+	postServiceMock.EXPECT().GetPost(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, req model.GetItemRequest) (interface{}, error) {
+		assert.Equal(t, uint64(5), *req.ID)
+		assert.Equal(t, model.PostType, req.Type)
+		assert.Equal(t, model.EmbedContext, req.Context)
+		return &model.ContentBase{}, nil
+	})
+
+	s := NewService(postRepoMock, nil, postServiceMock, pageServiceMock, nil)
+	res, err := s.Search(ctx, model.SearchRequest{Search: "hello", Subtype: []string{model.SearchSubtypeAny}, Page: 2, PerPage: 2, IsEmbed: true})
+	assert.NoError(t, err)
+
+	searchResponse := res.(*model.SearchResponse)
+	assert.Equal(t, 3, searchResponse.Total)
+	assert.Equal(t, 2, searchResponse.TotalPages)
+	if assert.Len(t, searchResponse.Results, 2) {
+		page := searchResponse.Results[0]
+		assert.Equal(t, uint64(8), page.ID)
+		assert.Equal(t, "http://localhost/about/hello/", page.URL)
+		assert.Equal(t, model.SearchTypePost, page.Type)
+		assert.Equal(t, model.PageType, page.Subtype)
+		assert.Equal(t, "http://localhost/wp-json/wp/v2/pages/8", page.Links.Self[0].Href)
+		assert.Equal(t, "http://localhost/wp-json/wp/v2/types/page", page.Links.About[0]["href"])
+		assert.NotNil(t, page.Embedded)
+		assert.Equal(t, "Hello world", searchResponse.Results[1].Title)
+	}
+}
+
+func TestService_SearchTerms(t *testing.T) {
+	ctx := newSearchTestContext()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	termRepoMock := mockterm.NewMockRepository(ctrl)
+	termRepoMock.EXPECT().SearchTerms(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, filter model.SearchFilter) ([]*model.TermTaxonomyJoin, int, error) {
+		assert.Equal(t, []string{model.TagType}, filter.Subtypes)
+		if filter.Page > 1 {
+			return []*model.TermTaxonomyJoin{}, 1, nil
+		}
+		return []*model.TermTaxonomyJoin{{Term: model.Term{TermID: 4, Name: "News", Slug: "news", Taxonomy: model.TagType}}}, 1, nil
+	}).Times(2)
+
+	s := NewService(nil, termRepoMock, nil, nil, nil)
+	res, err := s.Search(ctx, model.SearchRequest{Search: "new", Type: model.SearchTypeTerm, Subtype: []string{model.TagType}, Page: 1, PerPage: 10})
+	assert.NoError(t, err)
+	result := res.(*model.SearchResponse).Results[0]
+	assert.Equal(t, "News", result.Title)
+	assert.Equal(t, "http://localhost/tag/news", result.URL)
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/tags/4", result.Links.Self[0].Href)
+	assert.Equal(t, "http://localhost/wp-json/wp/v2/taxonomies/post_tag", result.Links.About[0]["href"])
+	assert.Nil(t, result.Embedded)
+
+	_, err = s.Search(ctx, model.SearchRequest{Search: "new", Type: model.SearchTypeTerm, Subtype: []string{model.TagType}, Page: 2, PerPage: 10})
+	assert.Equal(t, model.ErrInvalidPageNumber, err)
+}
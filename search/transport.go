@@ -0,0 +1,64 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/form"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// MakeHTTPHandler returns http handler that makes a set of endpoints available on predefined paths
+func MakeHTTPHandler(s Service, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	SearchHandler := kithttp.NewServer(
+		instrument("search.Search", makeSearchEndpoint(s)),
+		searchRequestDecoder,
+		resthttp.EncodeJSONResponse,
+		[]kithttp.ServerOption{
+			kithttp.ServerErrorEncoder(resthttp.EncodeError),
+		}...,
+	)
+	r.Method(http.MethodGet, "/", SearchHandler)
+
+	return r
+}
+
+func searchRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	searchRequest := model.SearchRequest{Type: model.SearchTypePost, Page: 1, PerPage: 10}
+	r.ParseForm()
+	if err := form.NewDecoder().Decode(&searchRequest, r.Form); err != nil {
+		log.WithFields(log.Fields{
+			"params": r.Form,
+			"func":   "decoder.Decode",
+		}).Errorf("Failed to decode request: %s", err)
+		return nil, model.ErrInvalidParameter
+	}
+
+	// subtype can be comma separated list or repeated parameter like WP accepts
+	for _, value := range r.Form["subtype"] {
+		for _, subtype := range strings.Split(value, ",") {
+			if subtype = strings.TrimSpace(subtype); subtype != "" {
+				searchRequest.Subtype = append(searchRequest.Subtype, subtype)
+			}
+		}
+	}
+
+	var err error
+	if searchRequest.Include, err = resthttp.ParseIDList(r.Form["include"]); err != nil {
+		return nil, err
+	}
+	if searchRequest.Exclude, err = resthttp.ParseIDList(r.Form["exclude"]); err != nil {
+		return nil, err
+	}
+
+	_, searchRequest.IsEmbed = r.URL.Query()["_embed"]
+	return searchRequest, nil
+}
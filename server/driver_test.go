@@ -0,0 +1,111 @@
+package server
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+
+	"github.com/qreasio/restlr/dialect"
+)
+
+// recordingDriver is database driver that records every statement and returns no rows,
+// so queries of the API can be inspected without database server
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, query)
+}
+
+// Statements returns recorded statements
+func (d *recordingDriver) Statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.statements...)
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return recordingTx{}, nil
+}
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error {
+	return nil
+}
+
+func (recordingTx) Rollback() error {
+	return nil
+}
+
+type recordingStmt struct {
+	driver *recordingDriver
+	query  string
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.record(s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.record(s.query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string {
+	return nil
+}
+
+func (emptyRows) Close() error {
+	return nil
+}
+
+func (emptyRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+var (
+	registerOnce sync.Once
+	recorder     = &recordingDriver{}
+)
+
+// openRecordingDB returns db of recordingDriver with the dialect and the driver
+func openRecordingDB(d dialect.Dialect) (*dialect.DB, *recordingDriver) {
+	registerOnce.Do(func() {
+		sql.Register("recording", recorder)
+	})
+	db, _ := sql.Open("recording", "")
+	return dialect.NewDB(db, d), recorder
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/qreasio/restlr/batch"
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/comment"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/fulltext"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/media"
+	"github.com/qreasio/restlr/metrics"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/multisite"
+	"github.com/qreasio/restlr/page"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/schema"
+	"github.com/qreasio/restlr/search"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/term"
+	"github.com/qreasio/restlr/tracing"
+	"github.com/qreasio/restlr/user"
+)
+
+// Options is configuration of the API router
+type Options struct {
+	// APIConfig is config that is set in context of every request
+	APIConfig model.APIConfig
+	// RequestTimeout is the max duration of request, requests have no timeout if it is zero
+	RequestTimeout time.Duration
+	// SearchBackend matches posts with search parameter, like backend is used if it is nil
+	SearchBackend fulltext.Backend
+	// Cache caches responses and repository results, nothing is cached if it is nil
+	Cache *cache.Cache
+	// Sites routes requests to sites of multisite network, APIConfig is config of the network if it is set
+	Sites *multisite.Directory
+	// Metrics observes requests and endpoints and is served on MetricsPath, nothing is observed if it is nil
+	Metrics *metrics.Metrics
+	// MetricsPath is path of Prometheus metrics, it is /metrics if it is empty
+	MetricsPath string
+	// Tracing starts spans of requests and endpoints with the global tracer provider of OpenTelemetry
+	Tracing bool
+}
+
+// NewRouter returns router of all API routes that are served by repositories of the db, their queries are in the dialect of the db
+func NewRouter(db *dialect.DB, options Options) http.Handler {
+	searchBackend := options.SearchBackend
+	if searchBackend == nil {
+		searchBackend = fulltext.NewLikeBackend(db.Dialect)
+	}
+	responseCache := options.Cache
+
+	//initialize repositories
+	postRepository := post.NewRepository(db, searchBackend)
+	termRepository := term.NewRepository(db)
+	userRepository := user.NewRepository(db)
+	sharedRepository := shared.NewRepository(db)
+	commentRepository := comment.NewRepository(db)
+	if responseCache != nil {
+		termRepository = term.NewCachedRepository(termRepository, responseCache)
+		userRepository = user.NewCachedRepository(userRepository, responseCache)
+		sharedRepository = shared.NewCachedRepository(sharedRepository, responseCache)
+	}
+
+	//initialize services
+	postService := post.NewService(postRepository, termRepository, sharedRepository, userRepository)
+	pageService := page.NewService(postRepository, sharedRepository, userRepository)
+	mediaService := media.NewService(postRepository, sharedRepository)
+	commentService := comment.NewService(commentRepository, postRepository, sharedRepository)
+	termService := term.NewService(termRepository, sharedRepository)
+	schemaService := schema.NewService()
+	searchService := search.NewService(postRepository, termRepository, postService, pageService, termService)
+
+	r := chi.NewRouter()
+	batchService := batch.NewService(r, sharedRepository)
+
+	//every endpoint is instrumented by the endpoint middlewares
+	var middlewares []resthttp.EndpointMiddleware
+	if options.Tracing {
+		r.Use(tracing.Middleware)
+		middlewares = append(middlewares, tracing.Endpoint)
+	}
+	if options.Metrics != nil {
+		r.Use(options.Metrics.Middleware)
+		middlewares = append(middlewares, options.Metrics.Endpoint)
+	}
+
+	//middleware, sites of multisite network have their own config
+	if options.Sites != nil {
+		r.Use(options.Sites.Middleware)
+	} else {
+		r.Use(SetAPIContext(options.APIConfig))
+	}
+	r.Use(resthttp.Timeout(options.RequestTimeout))
+
+	//set base api path base on config
+	apiPath := options.APIConfig.APIPath
+	baseAPIPath := fmt.Sprintf("%s/%s", apiPath, options.APIConfig.Version)
+
+	//routing, responses of the API routes are cached if cache is enabled
+	r.Group(func(r chi.Router) {
+		if responseCache != nil {
+			r.Use(responseCache.Middleware)
+		}
+		r.Mount(baseAPIPath+"/posts", post.MakeHTTPHandler(postService, middlewares...))
+		r.Mount(baseAPIPath+"/pages", page.MakeHTTPHandler(pageService, middlewares...))
+		r.Mount(baseAPIPath+"/media", media.MakeHTTPHandler(mediaService, middlewares...))
+		r.Mount(baseAPIPath+"/comments", comment.MakeHTTPHandler(commentService, middlewares...))
+		r.Mount(baseAPIPath+"/categories", term.MakeHTTPHandler(termService, model.CategoryType, middlewares...))
+		r.Mount(baseAPIPath+"/tags", term.MakeHTTPHandler(termService, model.TagType, middlewares...))
+		for _, taxonomy := range model.RegisteredTaxonomies() {
+			r.Mount(baseAPIPath+"/"+taxonomy.RestBase, term.MakeHTTPHandler(termService, taxonomy.Slug, middlewares...))
+		}
+		for _, postType := range model.RegisteredPostTypes() {
+			r.Mount(baseAPIPath+"/"+postType.RestBase, post.MakePostTypeHTTPHandler(postService, postType.Slug, middlewares...))
+		}
+		r.Mount(baseAPIPath+"/types", schema.MakeTypesHTTPHandler(schemaService, middlewares...))
+		r.Mount(baseAPIPath+"/taxonomies", schema.MakeTaxonomiesHTTPHandler(schemaService, middlewares...))
+		r.Mount(baseAPIPath+"/statuses", schema.MakeStatusesHTTPHandler(schemaService, middlewares...))
+		r.Mount(baseAPIPath+"/search", search.MakeHTTPHandler(searchService, middlewares...))
+		r.Mount(path.Dir(apiPath)+"/batch/v1", batch.MakeHTTPHandler(batchService, middlewares...))
+		if options.Sites != nil {
+			r.Mount(path.Dir(apiPath)+"/restlr/v1/network", multisite.MakeHTTPHandler(multisite.NewService(options.Sites, postService), middlewares...))
+		}
+	})
+	if options.Metrics != nil {
+		metricsPath := options.MetricsPath
+		if metricsPath == "" {
+			metricsPath = "/metrics"
+		}
+		r.Method(http.MethodGet, metricsPath, options.Metrics.Handler())
+	}
+	if responseCache != nil {
+		r.Mount(path.Dir(apiPath)+"/restlr/v1/cache", cache.MakeHTTPHandler(responseCache))
+	}
+
+	//handle 404 notfound/invalid route with custom response
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		resthttp.EncodeJSONResponse(context.Background(), w, resthttp.NewRouteNotFoundResponse())
+	})
+
+	return r
+}
+
+// SetAPIContext will set the APIConfig struct instance in context to store the important data that will be used in most all endpoints
+// so it is easily accessible from endpoint by getting it from context
+func SetAPIContext(config model.APIConfig) func(next http.Handler) http.Handler {
+	config.APIBaseURL = fmt.Sprintf("%s/%s/%s", config.APIHost, config.APIPath, config.Version)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), model.APIConfigKey, config)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qreasio/restlr/comment"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/fulltext"
+	"github.com/qreasio/restlr/metrics"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/multisite"
+	"github.com/qreasio/restlr/post"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/term"
+	"github.com/qreasio/restlr/tracing"
+	"github.com/qreasio/restlr/user"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tablePattern matches table name of FROM, JOIN, INTO and UPDATE clauses
+var tablePattern = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE)\s+([A-Za-z0-9_$]+)`)
+
+const testTablePrefix = "site_"
+
+func testAPIConfig() model.APIConfig {
+	return model.APIConfig{
+		APIHost:     "http://localhost:8080",
+		SiteURL:     "http://localhost:8080",
+		TablePrefix: testTablePrefix,
+		APIPath:     "/wp-json/wp",
+		Version:     "v2",
+		WriteAPIKey: "secret",
+	}
+}
+
+// queriedTables returns tables of the statements
+func queriedTables(statements []string) map[string]bool {
+	tables := map[string]bool{}
+	for _, statement := range statements {
+		for _, match := range tablePattern.FindAllStringSubmatch(statement, -1) {
+			tables[match[1]] = true
+		}
+	}
+	return tables
+}
+
+func TestNewRouter_TablePrefix(t *testing.T) {
+	db, recorder := openRecordingDB(dialect.MySQL)
+	start := len(recorder.Statements())
+	router := NewRouter(db, Options{APIConfig: testAPIConfig()})
+
+	requests := []struct {
+		method string
+		url    string
+	}{
+		{http.MethodGet, "/wp-json/wp/v2/posts?_embed"},
+		{http.MethodGet, "/wp-json/wp/v2/posts?sticky=true&categories=1&tags_exclude=2&search=tea&order_by=relevance"},
+		{http.MethodGet, "/wp-json/wp/v2/posts/1?_embed"},
+		{http.MethodGet, "/wp-json/wp/v2/pages?_embed&parent=1"},
+		{http.MethodGet, "/wp-json/wp/v2/pages/1"},
+		{http.MethodGet, "/wp-json/wp/v2/media"},
+		{http.MethodGet, "/wp-json/wp/v2/media/1"},
+		{http.MethodGet, "/wp-json/wp/v2/comments/1"},
+		{http.MethodGet, "/wp-json/wp/v2/categories?post=1"},
+		{http.MethodGet, "/wp-json/wp/v2/tags/1"},
+		{http.MethodGet, "/wp-json/wp/v2/search?search=tea"},
+		{http.MethodGet, "/wp-json/wp/v2/search?search=tea&type=term"},
+		{http.MethodPost, "/wp-json/wp/v2/posts?title=Hello&status=publish&categories=1"},
+		{http.MethodDelete, "/wp-json/wp/v2/posts/1?force=true"},
+		{http.MethodDelete, "/wp-json/wp/v2/categories/1?force=true"},
+	}
+	for _, request := range requests {
+		req := httptest.NewRequest(request.method, request.url, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// repositories are called directly too, because requests stop on the first query that finds no row
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, testAPIConfig())
+	postRepository := post.NewRepository(db, fulltext.NewLikeBackend(dialect.MySQL))
+	postRepository.PostsByIDs(ctx, model.PageType, []uint64{1})
+	postRepository.CommentsByPostIDs(ctx, []string{"1"})
+	postRepository.GetPredecessorVersion(ctx, []uint64{1})
+	postRepository.PostAncestors(ctx, []uint64{1})
+	postRepository.UniquePostSlug(ctx, "hello", &model.PostRecord{Type: model.PageType, Status: model.PublishStatus})
+	postRepository.UpdatePost(ctx, &model.PostRecord{ID: 1})
+	postRepository.DeletePost(ctx, &model.PostRecord{ID: 1})
+	termRepository := term.NewRepository(db)
+	termRepository.GetPostTaxonomyAndFormat(ctx, []string{"1"})
+	termRepository.TermTaxonomyByTermIDListTaxonomy(ctx, []uint64{1}, model.CategoryType)
+	termRepository.SetObjectTerms(ctx, 1, []uint64{1}, model.CategoryType)
+	termRepository.UpdateTermCount(ctx, []uint64{1})
+	termRepository.UniqueTermSlug(ctx, "tea", &model.TermTaxonomyJoin{})
+	termRepository.InsertTerm(ctx, &model.TermTaxonomyJoin{})
+	termRepository.UpdateTerm(ctx, &model.TermTaxonomyJoin{})
+	termRepository.DeleteTerm(ctx, &model.TermTaxonomyJoin{})
+	termRepository.ReparentTerms(ctx, model.CategoryType, 1, 0)
+	termRepository.SoleTermObjectIDs(ctx, 1, model.CategoryType)
+	termRepository.AddObjectsTerm(ctx, []uint64{1}, 1)
+	user.NewRepository(db).GetUserByIDList(ctx, []uint64{1})
+	sharedRepository := shared.NewRepository(db)
+	sharedRepository.PostMetasByPostIDs(ctx, []uint64{1})
+	sharedRepository.UpdatePostMetas(ctx, 1, map[string]string{"key": "value"})
+	sharedRepository.DeletePostMetas(ctx, 1, []string{"key"})
+	commentRepository := comment.NewRepository(db)
+	newComment := &model.Comment{PostID: new(uint64), Content: &model.ContentRendered{}}
+	commentRepository.InsertComment(ctx, newComment)
+	commentRepository.IsDuplicateComment(ctx, newComment)
+	commentRepository.HasApprovedComment(ctx, "name", "email")
+	commentRepository.UpdateCommentCount(ctx, 1)
+
+	tables := queriedTables(recorder.Statements()[start:])
+	for table := range tables {
+		assert.True(t, strings.HasPrefix(table, testTablePrefix), table)
+	}
+	for _, table := range model.NewTables(testTablePrefix).All() {
+		assert.True(t, tables[table], table)
+	}
+}
+
+func TestNewRouter_Multisite(t *testing.T) {
+	db, recorder := openRecordingDB(dialect.MySQL)
+	start := len(recorder.Statements())
+	config := testAPIConfig()
+	sites := multisite.NewDirectory(multisite.NewRepository(db, config.TablePrefix), config)
+	sites.SetSites([]model.Site{
+		{ID: 1, NetworkID: 1, Domain: "example.com", Path: "/", Public: true},
+		{ID: 2, NetworkID: 1, Domain: "example.com", Path: "/news/", Public: true},
+	})
+	router := NewRouter(db, Options{APIConfig: config, Sites: sites})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/news/wp-json/wp/v2/posts?_embed", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	tables := queriedTables(recorder.Statements()[start:])
+	assert.True(t, tables["site_2_posts"])
+	for table := range tables {
+		assert.True(t, strings.HasPrefix(table, "site_2_"), table)
+	}
+
+	start = len(recorder.Statements())
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/wp-json/restlr/v1/network/posts", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	tables = queriedTables(recorder.Statements()[start:])
+	assert.True(t, tables["site_posts"])
+	assert.True(t, tables["site_2_posts"])
+}
+
+// quotedPattern matches quoted strings and identifiers of sql
+var quotedPattern = regexp.MustCompile(`'[^']*'|"[^"]*"`)
+
+func TestNewRouter_PostgresDialect(t *testing.T) {
+	db, recorder := openRecordingDB(dialect.Postgres)
+	start := len(recorder.Statements())
+	router := NewRouter(db, Options{APIConfig: testAPIConfig()})
+
+	for _, url := range []string{
+		"/wp-json/wp/v2/posts?_embed&search=tea&order_by=relevance",
+		"/wp-json/wp/v2/posts?include=3&include=1&order_by=include",
+		"/wp-json/wp/v2/pages/1?_embed",
+		"/wp-json/wp/v2/search?search=tea",
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	}
+
+	statements := recorder.Statements()[start:]
+	assert.NotEmpty(t, statements)
+	for _, statement := range statements {
+		unquoted := quotedPattern.ReplaceAllString(statement, "")
+		assert.NotContains(t, unquoted, "?", statement)
+		for _, mysqlOnly := range []string{"CONCAT(", "DATE_FORMAT(", "FIELD(", "LIMIT $1, "} {
+			assert.NotContains(t, unquoted, mysqlOnly, statement)
+		}
+	}
+}
+
+func TestNewRouter_Metrics(t *testing.T) {
+	db, _ := openRecordingDB(dialect.MySQL)
+	m := metrics.New(prometheus.NewRegistry())
+	shared.QueryHooks = append(shared.QueryHooks, m.QueryHook())
+	defer func() { shared.QueryHooks = nil }()
+	router := NewRouter(db, Options{APIConfig: testAPIConfig(), Metrics: m})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-json/wp/v2/posts/1", nil))
+	// sub requests of batch are dispatched in process and only the batch request is counted
+	batchBody := `{"requests": [{"method": "DELETE", "path": "/wp/v2/posts/1"}, {"method": "DELETE", "path": "/wp/v2/posts/2"}]}`
+	batchReq := httptest.NewRequest(http.MethodPost, "/wp-json/batch/v1", strings.NewReader(batchBody))
+	batchReq.Header.Set("Content-Type", "application/json")
+	batchReq.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(httptest.NewRecorder(), batchReq)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, `restlr_http_requests_total{method="GET",route="/wp-json/wp/v2/posts/{id}",status="`)
+	assert.Contains(t, body, `restlr_http_requests_total{method="POST",route="/wp-json/batch/v1/",status="207"} 1`+"\n")
+	assert.Contains(t, body, `restlr_endpoint_duration_seconds_count{endpoint="post.DeletePost",`)
+	assert.NotContains(t, body, `method="DELETE"`)
+	assert.Contains(t, body, `restlr_endpoint_duration_seconds_count{endpoint="post.GetPost",`)
+	assert.Contains(t, body, `restlr_db_queries_total{method="post.`)
+}
+
+func TestNewRouter_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	shared.QueryHooks = append(shared.QueryHooks, tracing.QueryHook)
+	defer func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		shared.QueryHooks = nil
+	}()
+
+	db, _ := openRecordingDB(dialect.MySQL)
+	router := NewRouter(db, Options{APIConfig: testAPIConfig(), Tracing: true})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-json/wp/v2/posts?_embed", nil))
+
+	names := map[string]bool{}
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = true
+	}
+	for _, name := range []string{"GET /wp-json/wp/v2/posts/", "post.ListPosts", "post.Service.ListPosts", "post.QueryPosts"} {
+		assert.True(t, names[name], name)
+	}
+}
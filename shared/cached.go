@@ -0,0 +1,36 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/model"
+)
+
+// cachedRepository is Repository that caches options, reads inside transaction skip the cache
+// so values that may be rolled back are never cached
+type cachedRepository struct {
+	Repository
+	cache *cache.Cache
+}
+
+// NewCachedRepository returns Repository that caches results of repo in c
+func NewCachedRepository(repo Repository, c *cache.Cache) Repository {
+	return &cachedRepository{
+		Repository: repo,
+		cache:      c,
+	}
+}
+
+// LoadOption returns cached option of the name
+func (repo *cachedRepository) LoadOption(ctx context.Context, optionName string) (*model.Option, error) {
+	if InTransaction(ctx) {
+		return repo.Repository.LoadOption(ctx, optionName)
+	}
+
+	var option *model.Option
+	err := repo.cache.Fetch(ctx, cache.Key(ctx, "option", optionName), &option, func(ctx context.Context) (interface{}, error) {
+		return repo.Repository.LoadOption(ctx, optionName)
+	})
+	return option, err
+}
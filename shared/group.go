@@ -0,0 +1,22 @@
+package shared
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// MaxConcurrentQueries is the max number of independent queries that a request runs at once
+var MaxConcurrentQueries = 4
+
+// NewQueryGroup returns group that runs independent queries of a request concurrently and context that is canceled on
+// the first error, queries inside transaction run one by one because connection of the transaction can't run concurrent queries
+func NewQueryGroup(ctx context.Context) (*errgroup.Group, context.Context) {
+	g, ctx := errgroup.WithContext(ctx)
+	if InTransaction(ctx) || MaxConcurrentQueries < 1 {
+		g.SetLimit(1)
+	} else {
+		g.SetLimit(MaxConcurrentQueries)
+	}
+	return g, ctx
+}
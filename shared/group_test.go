@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQueryGroup(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// maxRunning returns the max number of queries of the group that run at once
+	maxRunning := func(ctx context.Context) int32 {
+		var running, max int32
+		g, _ := NewQueryGroup(ctx)
+		for i := 0; i < 8; i++ {
+			g.Go(func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}
+		assert.NoError(t, g.Wait())
+		return max
+	}
+
+	assert.Equal(t, int32(MaxConcurrentQueries), maxRunning(context.Background()))
+	assert.NoError(t, WithTransaction(context.Background(), db, func(ctx context.Context) error {
+		assert.Equal(t, int32(1), maxRunning(ctx))
+		return nil
+	}))
+
+	// the first error cancels context of the other queries
+	g, ctx := NewQueryGroup(context.Background())
+	g.Go(func() error { return errors.New("failed") })
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.EqualError(t, g.Wait(), "failed")
+}
@@ -64,3 +64,59 @@ func (mr *MockRepositoryMockRecorder) PostMetasByPostIDs(ctx, idList interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMetasByPostIDs", reflect.TypeOf((*MockRepository)(nil).PostMetasByPostIDs), ctx, idList)
 }
+
+// UpdatePostMetas mocks base method
+func (m *MockRepository) UpdatePostMetas(ctx context.Context, postID uint64, metas map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePostMetas", ctx, postID, metas)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePostMetas indicates an expected call of UpdatePostMetas
+func (mr *MockRepositoryMockRecorder) UpdatePostMetas(ctx, postID, metas interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePostMetas", reflect.TypeOf((*MockRepository)(nil).UpdatePostMetas), ctx, postID, metas)
+}
+
+// DeletePostMetas mocks base method
+func (m *MockRepository) DeletePostMetas(ctx context.Context, postID uint64, metaKeys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePostMetas", ctx, postID, metaKeys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePostMetas indicates an expected call of DeletePostMetas
+func (mr *MockRepositoryMockRecorder) DeletePostMetas(ctx, postID, metaKeys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePostMetas", reflect.TypeOf((*MockRepository)(nil).DeletePostMetas), ctx, postID, metaKeys)
+}
+
+// WithTransaction mocks base method
+func (m *MockRepository) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTransaction indicates an expected call of WithTransaction
+func (mr *MockRepositoryMockRecorder) WithTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransaction", reflect.TypeOf((*MockRepository)(nil).WithTransaction), ctx, fn)
+}
+
+// WithSavepoint mocks base method
+func (m *MockRepository) WithSavepoint(ctx context.Context, name string, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithSavepoint", ctx, name, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithSavepoint indicates an expected call of WithSavepoint
+func (mr *MockRepositoryMockRecorder) WithSavepoint(ctx, name, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithSavepoint", reflect.TypeOf((*MockRepository)(nil).WithSavepoint), ctx, name, fn)
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
 
+	"github.com/qreasio/restlr/dialect"
 	"github.com/qreasio/restlr/model"
-	"github.com/qreasio/restlr/toolbox"
+	"github.com/qreasio/restlr/sqlbuilder"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -15,14 +15,18 @@ import (
 type Repository interface {
 	LoadOption(ctx context.Context, optionName string) (*model.Option, error)
 	PostMetasByPostIDs(ctx context.Context, idList []uint64) (map[uint64]map[string]string, error)
+	UpdatePostMetas(ctx context.Context, postID uint64, metas map[string]string) error
+	DeletePostMetas(ctx context.Context, postID uint64, metaKeys []string) error
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	WithSavepoint(ctx context.Context, name string, fn func(ctx context.Context) error) error
 }
 
 type repository struct {
-	db *sql.DB
+	db *dialect.DB
 }
 
 // NewRepository is function to create new repository struct instance that implements Repository interface
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db *dialect.DB) Repository {
 	return &repository{
 		db: db,
 	}
@@ -31,26 +35,22 @@ func NewRepository(db *sql.DB) Repository {
 // LoadOptions is function get Option list from list of option name
 func (repo *repository) LoadOptions(ctx context.Context, autoload string, optionName []string) ([]*model.Option, error) {
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := apiConfig.TablePrefix + "options"
-	var err error
-
-	var sqlQuery = `SELECT ` +
-		`option_id, option_name, option_value, autoload ` +
-		`FROM ` + tableName +
-		` WHERE autoload = ? `
-
-	var optionWithQuote = make([]string, 0)
-	for _, option := range optionName {
-		optionWithQuote = append(optionWithQuote, fmt.Sprintf("'%s'", option))
+	tableName := apiConfig.Tables().Options
+	query := sqlbuilder.New(`SELECT `+
+		`option_id, option_name, option_value, autoload `+
+		`FROM `).Ident(tableName).
+		SQL(` WHERE autoload = ?`, autoload)
+
+	if len(optionName) > 0 {
+		query.SQL(` OR `).In("option_name", sqlbuilder.Strings(optionName))
 	}
 
-	nameString := strings.Join(optionWithQuote, ",")
-
-	if nameString != "" {
-		sqlQuery += fmt.Sprintf("OR option_name IN (%s)", nameString)
+	sqlQuery, args, err := query.Build()
+	if err != nil {
+		return nil, err
 	}
 
-	q, err := repo.db.Query(sqlQuery, autoload)
+	q, err := Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": fmt.Sprintf("%s, %s, %v", sqlQuery, autoload, optionName),
@@ -82,7 +82,7 @@ func (repo *repository) LoadOptions(ctx context.Context, autoload string, option
 // LoadOptions is function get an Option from specific option name
 func (repo *repository) LoadOption(ctx context.Context, optionName string) (*model.Option, error) {
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := apiConfig.TablePrefix + "options"
+	tableName := apiConfig.Tables().Options
 
 	var sqlQuery = `SELECT ` +
 		`option_id, option_name, option_value, autoload ` +
@@ -90,7 +90,7 @@ func (repo *repository) LoadOption(ctx context.Context, optionName string) (*mod
 		` WHERE option_name = ? `
 
 	wo := &model.Option{}
-	row := repo.db.QueryRow(sqlQuery, optionName)
+	row := Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, optionName)
 
 	err := row.Scan(&wo.OptionID, &wo.OptionName, &wo.OptionValue, &wo.AutoLoad)
 
@@ -107,14 +107,17 @@ func (repo *repository) LoadOption(ctx context.Context, optionName string) (*mod
 
 // PostMetasByPostIDs is function get Post Meta from list of integer post id
 func (repo *repository) PostMetasByPostIDs(ctx context.Context, idList []uint64) (map[uint64]map[string]string, error) {
-	var err error
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := apiConfig.TablePrefix + "postmeta"
-	idParameters := toolbox.UInt64SliceToCSV(idList)
+	tableName := apiConfig.Tables().PostMeta
 
-	sqlQuery := fmt.Sprintf(`SELECT meta_id, post_id, meta_key, meta_value FROM %s WHERE post_id IN (%s)`, tableName, idParameters)
+	sqlQuery, args, err := sqlbuilder.New(`SELECT meta_id, post_id, meta_key, meta_value FROM `).Ident(tableName).
+		SQL(` WHERE `).In("post_id", sqlbuilder.Uint64s(idList)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
 
-	q, err := repo.db.Query(sqlQuery)
+	q, err := Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": sqlQuery,
@@ -147,3 +150,82 @@ func (repo *repository) PostMetasByPostIDs(ctx context.Context, idList []uint64)
 
 	return res, nil
 }
+
+// UpdatePostMetas is function to update post metas of a post, it inserts meta key that doesn't exist yet
+func (repo *repository) UpdatePostMetas(ctx context.Context, postID uint64, metas map[string]string) error {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := apiConfig.Tables().PostMeta
+	conn := Conn(ctx, repo.db)
+
+	for key, value := range metas {
+		selectSQL, args, err := sqlbuilder.New(`SELECT meta_id FROM `).Ident(tableName).
+			SQL(` WHERE post_id = ? AND meta_key = ? LIMIT 1`, postID, key).Build()
+		if err != nil {
+			return err
+		}
+		var metaID uint64
+		err = conn.QueryRowContext(ctx, selectSQL, args...).Scan(&metaID)
+
+		var query *sqlbuilder.Builder
+		switch {
+		case err == sql.ErrNoRows:
+			query = sqlbuilder.New(`INSERT INTO `).Ident(tableName).SQL(` (post_id, meta_key, meta_value) VALUES (?, ?, ?)`, postID, key, value)
+		case err == nil:
+			query = sqlbuilder.New(`UPDATE `).Ident(tableName).SQL(` SET meta_value = ? WHERE post_id = ? AND meta_key = ?`, value, postID, key)
+		}
+		if query != nil {
+			var sqlQuery string
+			if sqlQuery, args, err = query.Build(); err != nil {
+				return err
+			}
+			_, err = conn.ExecContext(ctx, sqlQuery, args...)
+		}
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("postID: %d, key: %s", postID, key),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to update post meta: %s", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeletePostMetas is function to delete post metas of a post by meta keys, it deletes all metas of the post if meta keys is empty
+func (repo *repository) DeletePostMetas(ctx context.Context, postID uint64, metaKeys []string) error {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	tableName := apiConfig.Tables().PostMeta
+
+	query := sqlbuilder.New(`DELETE FROM `).Ident(tableName).SQL(` WHERE post_id = ?`, postID)
+	if len(metaKeys) > 0 {
+		query.AndIn("meta_key", sqlbuilder.Strings(metaKeys))
+	}
+
+	sqlQuery, args, err := query.Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = Conn(ctx, repo.db).ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": fmt.Sprintf("postID: %d, keys: %v", postID, metaKeys),
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to delete post metas: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// WithTransaction is function to run fn inside a db transaction, see WithTransaction function
+func (repo *repository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return WithTransaction(ctx, repo.db, fn)
+}
+
+// WithSavepoint is function to run fn inside a savepoint of running transaction, see WithSavepoint function
+func (repo *repository) WithSavepoint(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return WithSavepoint(ctx, repo.db, name, fn)
+}
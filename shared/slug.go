@@ -0,0 +1,15 @@
+package shared
+
+import "strings"
+
+// TruncateSlug truncates slug to the length without leaving trailing dash or broken percent encoded character
+func TruncateSlug(slug string, length int) string {
+	if len(slug) <= length {
+		return slug
+	}
+	slug = slug[:length]
+	if idx := strings.LastIndex(slug, "%"); idx >= len(slug)-2 && idx >= 0 {
+		slug = slug[:idx]
+	}
+	return strings.TrimRight(slug, "-")
+}
@@ -0,0 +1,167 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// QueryTimeout is the max duration of every query, queries have no timeout of their own if it is zero
+	QueryTimeout = 10 * time.Second
+	// SlowQueryThreshold is duration from which query is logged with its sql and duration, slow queries are not logged if it is zero
+	SlowQueryThreshold = time.Second
+	// QueryHooks observe every query that runs with Conn, they are set on start
+	QueryHooks []QueryHook
+)
+
+// QueryHook is called before query runs, method is the repository method that runs the query like 'post.QueryPosts'
+// and d is dialect of the database. The returned function is called with error of the query when the query function returns
+type QueryHook func(ctx context.Context, d dialect.Dialect, method string, query string) func(err error)
+
+// receiverPattern matches receiver type and closure suffix of function name that are not part of method name
+var receiverPattern = regexp.MustCompile(`\(\*?\w+\)\.|\.func\d+(\.\d+)*$`)
+
+// sqlQuerier is interface implemented by both *sql.DB and *sql.Tx
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// timedQuerier is Querier that runs every query of db or transaction with QueryTimeout and logs slow queries, placeholders
+// of queries are rebound to placeholder style of the dialect
+type timedQuerier struct {
+	conn    sqlQuerier
+	dialect dialect.Dialect
+}
+
+// Rows are rows of query that runs with QueryTimeout, the timeout also covers reading the rows and it is released
+// when the rows are closed
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+// Close closes the rows and releases timeout of the query
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
+
+// timedRow is row of query that runs with QueryTimeout, the timeout is released when the row is scanned
+type timedRow struct {
+	*sql.Row
+	cancel context.CancelFunc
+}
+
+// Scan copies columns of the row into dest and releases timeout of the query
+func (r timedRow) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+// ExecContext runs statement with QueryTimeout
+func (q timedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query = q.dialect.Rebind(query)
+	done := beforeQuery(ctx, q.dialect, query)
+	start := time.Now()
+	res, err := q.conn.ExecContext(ctx, query, args...)
+	logSlowQuery(query, time.Since(start))
+	done(err)
+	return res, err
+}
+
+// QueryContext runs query with QueryTimeout, the timeout also covers reading the rows until they are closed
+func (q timedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	query = q.dialect.Rebind(query)
+	done := beforeQuery(ctx, q.dialect, query)
+	queryCtx, cancel := queryContext(ctx)
+	start := time.Now()
+	rows, err := q.conn.QueryContext(queryCtx, query, args...)
+	logSlowQuery(query, time.Since(start))
+	done(err)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRowContext runs query with QueryTimeout, the timeout also covers scanning the row
+func (q timedQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) dialect.Row {
+	query = q.dialect.Rebind(query)
+	done := beforeQuery(ctx, q.dialect, query)
+	queryCtx, cancel := queryContext(ctx)
+	start := time.Now()
+	row := q.conn.QueryRowContext(queryCtx, query, args...)
+	logSlowQuery(query, time.Since(start))
+	// error of the row is known only when it is scanned
+	done(nil)
+	return timedRow{Row: row, cancel: cancel}
+}
+
+// queryContext returns context that is canceled after QueryTimeout and function that releases the timeout,
+// the context is returned as it is if QueryTimeout is zero
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, QueryTimeout)
+}
+
+func logSlowQuery(query string, duration time.Duration) {
+	if SlowQueryThreshold > 0 && duration >= SlowQueryThreshold {
+		log.WithFields(log.Fields{
+			"sql":      query,
+			"duration": duration.String(),
+		}).Warn("Slow query")
+	}
+}
+
+// beforeQuery calls QueryHooks with the repository method that runs the query, and returns function that ends them
+func beforeQuery(ctx context.Context, d dialect.Dialect, query string) func(err error) {
+	if len(QueryHooks) == 0 {
+		return func(error) {}
+	}
+
+	method := repositoryMethod()
+	dones := make([]func(error), len(QueryHooks))
+	for i, hook := range QueryHooks {
+		dones[i] = hook(ctx, d, method, query)
+	}
+	return func(err error) {
+		for _, done := range dones {
+			done(err)
+		}
+	}
+}
+
+// repositoryMethod returns package and name of the function that runs query with Conn, like 'post.QueryPosts'. Functions of
+// this file and of dialect package that run queries for the repository are skipped
+func repositoryMethod() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		if !strings.HasPrefix(name, "github.com/qreasio/restlr/dialect.") &&
+			!strings.HasPrefix(name, "github.com/qreasio/restlr/shared.timedQuerier.") &&
+			!strings.HasPrefix(name, "github.com/qreasio/restlr/shared.beforeQuery") {
+			return receiverPattern.ReplaceAllString(name[strings.LastIndex(name, "/")+1:], "")
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+// contextRecorder records context of the last query that runs on the db
+type contextRecorder struct {
+	sqlQuerier
+	ctx context.Context
+}
+
+func (r *contextRecorder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	r.ctx = ctx
+	return r.sqlQuerier.QueryContext(ctx, query, args...)
+}
+
+func (r *contextRecorder) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	r.ctx = ctx
+	return r.sqlQuerier.QueryRowContext(ctx, query, args...)
+}
+
+func TestTimedQuerier_ReleasesTimeout(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	defer func(timeout time.Duration) { QueryTimeout = timeout }(QueryTimeout)
+	QueryTimeout = time.Minute
+	recorder := &contextRecorder{sqlQuerier: db.DB}
+	q := timedQuerier{conn: recorder, dialect: db.Dialect}
+	ctx := context.Background()
+
+	rows, err := q.QueryContext(ctx, "SELECT 1 UNION SELECT 2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	deadline, ok := recorder.ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	// the timeout covers reading the rows
+	for rows.Next() {
+		assert.NoError(t, recorder.ctx.Err())
+	}
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, context.Canceled, recorder.ctx.Err())
+
+	var n int
+	row := q.QueryRowContext(ctx, "SELECT 1")
+	assert.NoError(t, recorder.ctx.Err())
+	assert.NoError(t, row.Scan(&n))
+	assert.Equal(t, 1, n)
+	assert.Equal(t, context.Canceled, recorder.ctx.Err())
+
+	// timeout of failed query is released at once
+	_, err = q.QueryContext(ctx, "SELECT FROM")
+	assert.Error(t, err)
+	assert.Equal(t, context.Canceled, recorder.ctx.Err())
+
+	QueryTimeout = 0
+	row = q.QueryRowContext(ctx, "SELECT 1")
+	assert.Equal(t, ctx, recorder.ctx)
+	assert.NoError(t, row.Scan(&n))
+}
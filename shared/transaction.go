@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/qreasio/restlr/dialect"
+	log "github.com/sirupsen/logrus"
+)
+
+// txKey is context key to store running transaction
+type txKey struct{}
+
+// Querier runs queries of db or of transaction, so repository function can run inside or outside transaction
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) dialect.Row
+}
+
+// Conn returns transaction that is stored in context if exists, otherwise it returns db.
+// Queries of the returned Querier are rebound to the dialect of db, run with QueryTimeout and slow queries are logged
+func Conn(ctx context.Context, db *dialect.DB) Querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return timedQuerier{conn: tx, dialect: db.Dialect}
+	}
+	return timedQuerier{conn: db.DB, dialect: db.Dialect}
+}
+
+// WithTransaction runs fn inside a db transaction that is stored in context passed to fn.
+// The transaction is committed if fn returns nil error and rolled back otherwise.
+// If context already has running transaction, fn joins it and the outer caller decides to commit or roll back
+func WithTransaction(ctx context.Context, db *dialect.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"func": "db.BeginTx",
+		}).Errorf("Failed to begin transaction: %s", err)
+		return err
+	}
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithFields(log.Fields{
+			"func": "tx.Commit",
+		}).Errorf("Failed to commit transaction: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// WithSavepoint runs fn inside a savepoint of the transaction that is stored in context, changes of fn are rolled back
+// to the savepoint if fn returns error while the transaction keeps running. Without running transaction it is the same as WithTransaction
+func WithSavepoint(ctx context.Context, db *dialect.DB, name string, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	if !ok {
+		return WithTransaction(ctx, db, fn)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		log.WithFields(log.Fields{
+			"params": name,
+			"func":   "tx.ExecContext",
+		}).Errorf("Failed to create savepoint: %s", err)
+		return err
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+			log.WithFields(log.Fields{
+				"params": name,
+				"func":   "tx.ExecContext",
+			}).Errorf("Failed to roll back to savepoint: %s", rollbackErr)
+			return rollbackErr
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		log.WithFields(log.Fields{
+			"params": name,
+			"func":   "tx.ExecContext",
+		}).Errorf("Failed to release savepoint: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// InTransaction returns true if context has running transaction
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return ok
+}
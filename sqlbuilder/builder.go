@@ -0,0 +1,179 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/qreasio/restlr/dialect"
+)
+
+var (
+	// ErrInvalidIdentifier is error of table or column name that isn't plain sql identifier
+	ErrInvalidIdentifier = errors.New("invalid sql identifier")
+	// ErrInvalidDirection is error of sort direction that isn't ASC or DESC
+	ErrInvalidDirection = errors.New("invalid sql sort direction")
+)
+
+// identPattern matches unquoted identifier of mysql with optional qualifier, like wp_posts or wpp.ID
+var identPattern = regexp.MustCompile(`^[A-Za-z0-9_$]+(\.[A-Za-z0-9_$]+)?$`)
+
+// Builder builds sql query from sql fragments of the code, identifiers that are checked against identifier pattern
+// and values that are always bound as arguments, so values from request can't change structure of the query.
+// The first error is kept and returned by Build, so calls can be chained without checking error of each call
+type Builder struct {
+	sql  strings.Builder
+	args []interface{}
+	err  error
+}
+
+// New returns Builder that starts with the sql fragment and its arguments
+func New(fragment string, args ...interface{}) *Builder {
+	return new(Builder).SQL(fragment, args...)
+}
+
+// ValidIdent returns true if the name is plain identifier that is safe to be written into query
+func ValidIdent(name string) bool {
+	return identPattern.MatchString(name)
+}
+
+// SQL appends sql fragment with ? placeholder of every argument. The fragment must be constant sql of the code,
+// never value from request, and it must not contain ? other than placeholders
+func (b *Builder) SQL(fragment string, args ...interface{}) *Builder {
+	if count := strings.Count(fragment, "?"); count != len(args) {
+		b.fail(fmt.Errorf("sql fragment %q has %d placeholders for %d arguments", fragment, count, len(args)))
+		return b
+	}
+	b.sql.WriteString(fragment)
+	b.args = append(b.args, args...)
+	return b
+}
+
+// Ident appends table or column name, it fails with ErrInvalidIdentifier if the name isn't plain identifier
+func (b *Builder) Ident(name string) *Builder {
+	if !ValidIdent(name) {
+		b.fail(ErrInvalidIdentifier)
+		return b
+	}
+	b.sql.WriteString(name)
+	return b
+}
+
+// And appends condition with AND, the condition is sql fragment like the fragment of SQL
+func (b *Builder) And(condition string, args ...interface{}) *Builder {
+	return b.SQL(" AND "+condition, args...)
+}
+
+// In appends 'column IN (?, ...)' condition with placeholder of every value, empty values appends condition that matches nothing
+func (b *Builder) In(column string, values []interface{}) *Builder {
+	if len(values) == 0 {
+		return b.SQL("1=0")
+	}
+	return b.Ident(column).SQL(" IN ("+Placeholders(len(values))+")", values...)
+}
+
+// NotIn appends 'column NOT IN (?, ...)' condition with placeholder of every value, empty values appends condition that matches everything
+func (b *Builder) NotIn(column string, values []interface{}) *Builder {
+	if len(values) == 0 {
+		return b.SQL("1=1")
+	}
+	return b.Ident(column).SQL(" NOT IN ("+Placeholders(len(values))+")", values...)
+}
+
+// AndIn appends In condition with AND
+func (b *Builder) AndIn(column string, values []interface{}) *Builder {
+	return b.SQL(" AND ").In(column, values)
+}
+
+// AndNotIn appends NotIn condition with AND
+func (b *Builder) AndNotIn(column string, values []interface{}) *Builder {
+	return b.SQL(" AND ").NotIn(column, values)
+}
+
+// OrderBy appends ORDER BY clause of the column and direction, direction is ASC or DESC in any case and it can be empty
+func (b *Builder) OrderBy(column string, direction string) *Builder {
+	b.SQL(" ORDER BY ").Ident(column)
+	return b.direction(direction)
+}
+
+// OrderByField appends ORDER BY clause that sorts rows in the order of the values in the column like FIELD function does,
+// the expression is written in the dialect of the database
+func (b *Builder) OrderByField(d dialect.Dialect, column string, values []interface{}) *Builder {
+	if len(values) == 0 {
+		b.fail(errors.New("order by field requires values"))
+		return b
+	}
+	if !ValidIdent(column) {
+		b.fail(ErrInvalidIdentifier)
+		return b
+	}
+	return b.SQL(" ORDER BY "+d.Field(column, len(values)), values...)
+}
+
+// Limit appends LIMIT clause of the offset and row count, it is written as LIMIT ... OFFSET that every dialect supports
+func (b *Builder) Limit(offset int, count int) *Builder {
+	return b.SQL(" LIMIT ? OFFSET ?", count, offset)
+}
+
+// Append appends sql and arguments of other builder, so query can be composed from builders of its parts
+func (b *Builder) Append(other *Builder) *Builder {
+	if other.err != nil {
+		b.fail(other.err)
+		return b
+	}
+	b.sql.WriteString(other.sql.String())
+	b.args = append(b.args, other.args...)
+	return b
+}
+
+// Build returns the sql query and its arguments, or the first error of the building
+func (b *Builder) Build() (string, []interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.sql.String(), b.args, nil
+}
+
+// Placeholders returns comma separated list of n placeholders
+func Placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return "?" + strings.Repeat(", ?", n-1)
+}
+
+// Uint64s returns the values as arguments of In and NotIn
+func Uint64s(values []uint64) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// Strings returns the values as arguments of In and NotIn
+func Strings(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func (b *Builder) direction(direction string) *Builder {
+	switch strings.ToUpper(direction) {
+	case "":
+	case "ASC", "DESC":
+		b.sql.WriteString(" " + strings.ToUpper(direction))
+	default:
+		b.fail(ErrInvalidDirection)
+	}
+	return b
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,112 @@
+package sqlbuilder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var hostileInputs = []string{
+	"x' OR '1'='1",
+	"1); DROP TABLE wp_posts; --",
+	"\\' OR 1=1 #",
+	"?",
+	"`ID`",
+	"ID /* comment */",
+	"ID, (SELECT user_pass FROM wp_users)",
+	"wp_posts WHERE 1=1",
+	"\x00",
+	"",
+}
+
+func TestBuild(t *testing.T) {
+	sqlQuery, args, err := New("SELECT ID FROM ").Ident("wp_posts").
+		SQL(" WHERE post_status = ?", "publish").
+		AndIn("ID", Uint64s([]uint64{1, 2})).
+		AndNotIn("post_name", Strings([]string{"a"})).
+		OrderBy("post_date", "desc").
+		Limit(10, 5).
+		Build()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID FROM wp_posts WHERE post_status = ? AND ID IN (?, ?) AND post_name NOT IN (?) ORDER BY post_date DESC LIMIT ? OFFSET ?", sqlQuery)
+	assert.Equal(t, []interface{}{"publish", uint64(1), uint64(2), "a", 5, 10}, args)
+}
+
+func TestBuildEmptyList(t *testing.T) {
+	sqlQuery, args, err := New("SELECT ID FROM wp_posts WHERE ").In("ID", nil).AndNotIn("ID", nil).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID FROM wp_posts WHERE 1=0 AND 1=1", sqlQuery)
+	assert.Empty(t, args)
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, _, err := New("SELECT ID FROM wp_posts WHERE ID = ?").Build()
+	assert.Error(t, err)
+
+	_, _, err = New("SELECT ID FROM wp_posts").OrderBy("ID", "DESC; DROP TABLE wp_posts").Build()
+	assert.Equal(t, ErrInvalidDirection, err)
+
+	// the first error is returned even if later calls succeed
+	_, _, err = New("SELECT ID FROM ").Ident("wp_posts; --").SQL(" WHERE ID = ?", 1).Build()
+	assert.Equal(t, ErrInvalidIdentifier, err)
+
+	_, _, err = New("SELECT ID FROM wp_posts").Append(New("").Ident("")).Build()
+	assert.Equal(t, ErrInvalidIdentifier, err)
+}
+
+// build returns query that has the value in every kind of bound value
+func build(value string) (string, []interface{}, error) {
+	return New("SELECT ID FROM wp_posts WHERE post_status = ?", value).
+		AndIn("post_name", Strings([]string{value, value})).
+		AndNotIn("post_type", Strings([]string{value})).
+		And("(post_title LIKE ? OR post_content LIKE ?)", "%"+value+"%", value).
+		Build()
+}
+
+func FuzzBuild(f *testing.F) {
+	for _, value := range hostileInputs {
+		f.Add(value)
+	}
+	benignSQL, _, err := build("hello")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	// any value is bound as argument, so it never changes the query
+	f.Fuzz(func(t *testing.T, value string) {
+		sqlQuery, args, err := build(value)
+		assert.NoError(t, err)
+		assert.Equal(t, benignSQL, sqlQuery)
+		assert.Equal(t, []interface{}{value, value, value, value, "%" + value + "%", value}, args)
+	})
+}
+
+func FuzzIdent(f *testing.F) {
+	for _, value := range hostileInputs {
+		f.Add(value)
+	}
+	for _, value := range []string{"wp_posts", "wpp.ID", "wp_2_posts", "site$posts", "wpp.", ".ID", "a.b.c"} {
+		f.Add(value)
+	}
+
+	// any accepted identifier is plain name that can't contain sql other than the name
+	f.Fuzz(func(t *testing.T, value string) {
+		sqlQuery, _, err := New("SELECT ID FROM ").Ident(value).OrderBy(value, "").Build()
+		if err != nil {
+			assert.Equal(t, ErrInvalidIdentifier, err)
+			return
+		}
+		assert.Empty(t, strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_$."), value)
+		assert.LessOrEqual(t, strings.Count(value, "."), 1, value)
+		assert.Equal(t, "SELECT ID FROM "+value+" ORDER BY "+value, sqlQuery)
+	})
+}
+
+func TestIdentRejectsHostileNames(t *testing.T) {
+	for _, value := range hostileInputs {
+		_, _, err := New("SELECT ID FROM wp_posts").OrderBy(value, "").Build()
+		assert.Equal(t, ErrInvalidIdentifier, err, value)
+	}
+}
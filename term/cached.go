@@ -0,0 +1,75 @@
+package term
+
+import (
+	"context"
+	"strings"
+
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+)
+
+// cachedRepository is Repository that caches terms of posts and terms by id, reads inside transaction skip the cache
+// so values that may be rolled back are never cached
+type cachedRepository struct {
+	Repository
+	cache *cache.Cache
+}
+
+// postTaxonomyAndFormat is cached result of GetPostTaxonomyAndFormat
+type postTaxonomyAndFormat struct {
+	Terms      map[uint64][]*model.TermWithPostTaxonomy
+	Taxonomies map[uint64]map[string][]uint64
+	Formats    map[uint64]string
+}
+
+// NewCachedRepository returns Repository that caches results of repo in c
+func NewCachedRepository(repo Repository, c *cache.Cache) Repository {
+	return &cachedRepository{
+		Repository: repo,
+		cache:      c,
+	}
+}
+
+// PostTermTaxonomyByIDs returns cached terms of the posts
+func (repo *cachedRepository) PostTermTaxonomyByIDs(ctx context.Context, idList []string) (map[uint64][]*model.TermWithPostTaxonomy, error) {
+	if shared.InTransaction(ctx) {
+		return repo.Repository.PostTermTaxonomyByIDs(ctx, idList)
+	}
+
+	var terms map[uint64][]*model.TermWithPostTaxonomy
+	err := repo.cache.Fetch(ctx, cache.Key(ctx, "post_terms", strings.Join(idList, ",")), &terms, func(ctx context.Context) (interface{}, error) {
+		return repo.Repository.PostTermTaxonomyByIDs(ctx, idList)
+	})
+	return terms, err
+}
+
+// GetPostTaxonomyAndFormat returns cached terms, term ids by taxonomy and format of the posts
+func (repo *cachedRepository) GetPostTaxonomyAndFormat(ctx context.Context, idList []string) (map[uint64][]*model.TermWithPostTaxonomy, map[uint64]map[string][]uint64, map[uint64]string, error) {
+	if shared.InTransaction(ctx) {
+		return repo.Repository.GetPostTaxonomyAndFormat(ctx, idList)
+	}
+
+	var res postTaxonomyAndFormat
+	err := repo.cache.Fetch(ctx, cache.Key(ctx, "post_taxonomy_format", strings.Join(idList, ",")), &res, func(ctx context.Context) (interface{}, error) {
+		terms, taxonomies, formats, err := repo.Repository.GetPostTaxonomyAndFormat(ctx, idList)
+		return postTaxonomyAndFormat{Terms: terms, Taxonomies: taxonomies, Formats: formats}, err
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return res.Terms, res.Taxonomies, res.Formats, nil
+}
+
+// TermByID returns cached term of the taxonomy
+func (repo *cachedRepository) TermByID(ctx context.Context, termID uint64, taxonomy string) (*model.TermTaxonomyJoin, error) {
+	if shared.InTransaction(ctx) {
+		return repo.Repository.TermByID(ctx, termID, taxonomy)
+	}
+
+	var term *model.TermTaxonomyJoin
+	err := repo.cache.Fetch(ctx, cache.Key(ctx, "term", termID, taxonomy), &term, func(ctx context.Context) (interface{}, error) {
+		return repo.Repository.TermByID(ctx, termID, taxonomy)
+	})
+	return term, err
+}
@@ -0,0 +1,93 @@
+package term
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+)
+
+func makeGetTermEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.GetTermRequest)
+		res, err := s.GetTerm(ctx, req)
+		if err == model.ErrInvalidTermID {
+			return http.NewErrorResponse(http.RestTermInvalidCode, http.RestTermInvalidMessage, nethttp.StatusNotFound), nil
+		}
+		return res, err
+	}
+	return endpoint
+}
+
+func makeListTermsEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.ListTermsRequest)
+		res, err := s.ListTerms(ctx, req)
+		if paramErr, ok := err.(*model.ParamError); ok {
+			return http.NewInvalidParam(paramErr.Param, paramErr.Message), nil
+		}
+		return res, err
+	}
+	return endpoint
+}
+
+func makeCreateTermEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.WriteTermRequest)
+		res, err := s.CreateTerm(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotCreateCode, "The term cannot be created."), nil
+		}
+		return http.CreatedResponse{Item: res, Location: itemLocation(ctx, res)}, nil
+	}
+	return endpoint
+}
+
+func makeUpdateTermEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.WriteTermRequest)
+		res, err := s.UpdateTerm(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotUpdateCode, "The term cannot be updated."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
+
+func makeMergeTermEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.MergeTermRequest)
+		res, err := s.MergeTerm(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotUpdateCode, "The term cannot be merged."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
+
+func makeDeleteTermEndpoint(s Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(model.DeleteTermRequest)
+		res, err := s.DeleteTerm(ctx, req)
+		if err != nil {
+			return http.NewWriteErrorResponse(err, http.RestCannotDeleteCode, "The term cannot be deleted."), nil
+		}
+		return res, nil
+	}
+	return endpoint
+}
+
+// itemLocation returns url of created term for Location header
+func itemLocation(ctx context.Context, item interface{}) string {
+	t, ok := item.(*model.TermTaxonomyJoin)
+	if !ok {
+		return ""
+	}
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	return fmt.Sprintf("%s/%s/%d", apiConfig.APIBaseURL, model.Plural(t.Taxonomy), t.TermID)
+}
@@ -68,16 +68,266 @@ func (mr *MockRepositoryMockRecorder) GetPostTaxonomyAndFormat(ctx, idStringArr
 }
 
 // TermTaxonomyByTermIDListTaxonomy mocks base method
-func (m *MockRepository) TermTaxonomyByTermIDListTaxonomy(termIDList []uint64, taxonomy string) ([]*model.TermTaxonomy, error) {
+func (m *MockRepository) TermTaxonomyByTermIDListTaxonomy(ctx context.Context, termIDList []uint64, taxonomy string) ([]*model.TermTaxonomy, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TermTaxonomyByTermIDListTaxonomy", termIDList, taxonomy)
+	ret := m.ctrl.Call(m, "TermTaxonomyByTermIDListTaxonomy", ctx, termIDList, taxonomy)
 	ret0, _ := ret[0].([]*model.TermTaxonomy)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // TermTaxonomyByTermIDListTaxonomy indicates an expected call of TermTaxonomyByTermIDListTaxonomy
-func (mr *MockRepositoryMockRecorder) TermTaxonomyByTermIDListTaxonomy(termIDList, taxonomy interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) TermTaxonomyByTermIDListTaxonomy(ctx, termIDList, taxonomy interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TermTaxonomyByTermIDListTaxonomy", reflect.TypeOf((*MockRepository)(nil).TermTaxonomyByTermIDListTaxonomy), termIDList, taxonomy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TermTaxonomyByTermIDListTaxonomy", reflect.TypeOf((*MockRepository)(nil).TermTaxonomyByTermIDListTaxonomy), ctx, termIDList, taxonomy)
+}
+
+// ObjectTermTaxonomyIDs mocks base method
+func (m *MockRepository) ObjectTermTaxonomyIDs(ctx context.Context, objectID uint64) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObjectTermTaxonomyIDs", ctx, objectID)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ObjectTermTaxonomyIDs indicates an expected call of ObjectTermTaxonomyIDs
+func (mr *MockRepositoryMockRecorder) ObjectTermTaxonomyIDs(ctx, objectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObjectTermTaxonomyIDs", reflect.TypeOf((*MockRepository)(nil).ObjectTermTaxonomyIDs), ctx, objectID)
+}
+
+// SetObjectTerms mocks base method
+func (m *MockRepository) SetObjectTerms(ctx context.Context, objectID uint64, termIDList []uint64, taxonomy string) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetObjectTerms", ctx, objectID, termIDList, taxonomy)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetObjectTerms indicates an expected call of SetObjectTerms
+func (mr *MockRepositoryMockRecorder) SetObjectTerms(ctx, objectID, termIDList, taxonomy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetObjectTerms", reflect.TypeOf((*MockRepository)(nil).SetObjectTerms), ctx, objectID, termIDList, taxonomy)
+}
+
+// DeleteObjectTerms mocks base method
+func (m *MockRepository) DeleteObjectTerms(ctx context.Context, objectID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObjectTerms", ctx, objectID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObjectTerms indicates an expected call of DeleteObjectTerms
+func (mr *MockRepositoryMockRecorder) DeleteObjectTerms(ctx, objectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObjectTerms", reflect.TypeOf((*MockRepository)(nil).DeleteObjectTerms), ctx, objectID)
+}
+
+// UpdateTermCount mocks base method
+func (m *MockRepository) UpdateTermCount(ctx context.Context, termTaxonomyIDList []uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTermCount", ctx, termTaxonomyIDList)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTermCount indicates an expected call of UpdateTermCount
+func (mr *MockRepositoryMockRecorder) UpdateTermCount(ctx, termTaxonomyIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTermCount", reflect.TypeOf((*MockRepository)(nil).UpdateTermCount), ctx, termTaxonomyIDList)
+}
+
+// TermByID mocks base method
+func (m *MockRepository) TermByID(ctx context.Context, termID uint64, taxonomy string) (*model.TermTaxonomyJoin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TermByID", ctx, termID, taxonomy)
+	ret0, _ := ret[0].(*model.TermTaxonomyJoin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TermByID indicates an expected call of TermByID
+func (mr *MockRepositoryMockRecorder) TermByID(ctx, termID, taxonomy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TermByID", reflect.TypeOf((*MockRepository)(nil).TermByID), ctx, termID, taxonomy)
+}
+
+// ListTerms mocks base method
+func (m *MockRepository) ListTerms(ctx context.Context, req model.ListTermsRequest) ([]*model.TermTaxonomyJoin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTerms", ctx, req)
+	ret0, _ := ret[0].([]*model.TermTaxonomyJoin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTerms indicates an expected call of ListTerms
+func (mr *MockRepositoryMockRecorder) ListTerms(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTerms", reflect.TypeOf((*MockRepository)(nil).ListTerms), ctx, req)
+}
+
+// SearchTerms mocks base method
+func (m *MockRepository) SearchTerms(ctx context.Context, filter model.SearchFilter) ([]*model.TermTaxonomyJoin, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTerms", ctx, filter)
+	ret0, _ := ret[0].([]*model.TermTaxonomyJoin)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchTerms indicates an expected call of SearchTerms
+func (mr *MockRepositoryMockRecorder) SearchTerms(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTerms", reflect.TypeOf((*MockRepository)(nil).SearchTerms), ctx, filter)
+}
+
+// TermNameExists mocks base method
+func (m *MockRepository) TermNameExists(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TermNameExists", ctx, term)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TermNameExists indicates an expected call of TermNameExists
+func (mr *MockRepositoryMockRecorder) TermNameExists(ctx, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TermNameExists", reflect.TypeOf((*MockRepository)(nil).TermNameExists), ctx, term)
+}
+
+// TermSlugExists mocks base method
+func (m *MockRepository) TermSlugExists(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TermSlugExists", ctx, term)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TermSlugExists indicates an expected call of TermSlugExists
+func (mr *MockRepositoryMockRecorder) TermSlugExists(ctx, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TermSlugExists", reflect.TypeOf((*MockRepository)(nil).TermSlugExists), ctx, term)
+}
+
+// UniqueTermSlug mocks base method
+func (m *MockRepository) UniqueTermSlug(ctx context.Context, slug string, term *model.TermTaxonomyJoin) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UniqueTermSlug", ctx, slug, term)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UniqueTermSlug indicates an expected call of UniqueTermSlug
+func (mr *MockRepositoryMockRecorder) UniqueTermSlug(ctx, slug, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UniqueTermSlug", reflect.TypeOf((*MockRepository)(nil).UniqueTermSlug), ctx, slug, term)
+}
+
+// InsertTerm mocks base method
+func (m *MockRepository) InsertTerm(ctx context.Context, term *model.TermTaxonomyJoin) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTerm", ctx, term)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTerm indicates an expected call of InsertTerm
+func (mr *MockRepositoryMockRecorder) InsertTerm(ctx, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTerm", reflect.TypeOf((*MockRepository)(nil).InsertTerm), ctx, term)
+}
+
+// UpdateTerm mocks base method
+func (m *MockRepository) UpdateTerm(ctx context.Context, term *model.TermTaxonomyJoin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTerm", ctx, term)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTerm indicates an expected call of UpdateTerm
+func (mr *MockRepositoryMockRecorder) UpdateTerm(ctx, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTerm", reflect.TypeOf((*MockRepository)(nil).UpdateTerm), ctx, term)
+}
+
+// DeleteTerm mocks base method
+func (m *MockRepository) DeleteTerm(ctx context.Context, term *model.TermTaxonomyJoin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTerm", ctx, term)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTerm indicates an expected call of DeleteTerm
+func (mr *MockRepositoryMockRecorder) DeleteTerm(ctx, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTerm", reflect.TypeOf((*MockRepository)(nil).DeleteTerm), ctx, term)
+}
+
+// ReparentTerms mocks base method
+func (m *MockRepository) ReparentTerms(ctx context.Context, taxonomy string, oldParent, newParent uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReparentTerms", ctx, taxonomy, oldParent, newParent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReparentTerms indicates an expected call of ReparentTerms
+func (mr *MockRepositoryMockRecorder) ReparentTerms(ctx, taxonomy, oldParent, newParent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReparentTerms", reflect.TypeOf((*MockRepository)(nil).ReparentTerms), ctx, taxonomy, oldParent, newParent)
+}
+
+// TermObjectIDs mocks base method
+func (m *MockRepository) TermObjectIDs(ctx context.Context, termTaxonomyID uint64) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TermObjectIDs", ctx, termTaxonomyID)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TermObjectIDs indicates an expected call of TermObjectIDs
+func (mr *MockRepositoryMockRecorder) TermObjectIDs(ctx, termTaxonomyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TermObjectIDs", reflect.TypeOf((*MockRepository)(nil).TermObjectIDs), ctx, termTaxonomyID)
+}
+
+// SoleTermObjectIDs mocks base method
+func (m *MockRepository) SoleTermObjectIDs(ctx context.Context, termTaxonomyID uint64, taxonomy string) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoleTermObjectIDs", ctx, termTaxonomyID, taxonomy)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoleTermObjectIDs indicates an expected call of SoleTermObjectIDs
+func (mr *MockRepositoryMockRecorder) SoleTermObjectIDs(ctx, termTaxonomyID, taxonomy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoleTermObjectIDs", reflect.TypeOf((*MockRepository)(nil).SoleTermObjectIDs), ctx, termTaxonomyID, taxonomy)
+}
+
+// AddObjectsTerm mocks base method
+func (m *MockRepository) AddObjectsTerm(ctx context.Context, objectIDList []uint64, termTaxonomyID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddObjectsTerm", ctx, objectIDList, termTaxonomyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddObjectsTerm indicates an expected call of AddObjectsTerm
+func (mr *MockRepositoryMockRecorder) AddObjectsTerm(ctx, objectIDList, termTaxonomyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddObjectsTerm", reflect.TypeOf((*MockRepository)(nil).AddObjectsTerm), ctx, objectIDList, termTaxonomyID)
 }
@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/qreasio/restlr/dialect"
 	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/sqlbuilder"
 	"github.com/qreasio/restlr/toolbox"
 	log "github.com/sirupsen/logrus"
 )
@@ -15,15 +19,35 @@ import (
 type Repository interface {
 	PostTermTaxonomyByIDs(ctx context.Context, idStringArray []string) (map[uint64][]*model.TermWithPostTaxonomy, error)
 	GetPostTaxonomyAndFormat(ctx context.Context, idStringArr []string) (map[uint64][]*model.TermWithPostTaxonomy, map[uint64]map[string][]uint64, map[uint64]string, error)
-	TermTaxonomyByTermIDListTaxonomy(termIDList []uint64, taxonomy string) ([]*model.TermTaxonomy, error)
+	TermTaxonomyByTermIDListTaxonomy(ctx context.Context, termIDList []uint64, taxonomy string) ([]*model.TermTaxonomy, error)
+	ObjectTermTaxonomyIDs(ctx context.Context, objectID uint64) ([]uint64, error)
+	SetObjectTerms(ctx context.Context, objectID uint64, termIDList []uint64, taxonomy string) ([]uint64, error)
+	DeleteObjectTerms(ctx context.Context, objectID uint64) error
+	UpdateTermCount(ctx context.Context, termTaxonomyIDList []uint64) error
+	TermByID(ctx context.Context, termID uint64, taxonomy string) (*model.TermTaxonomyJoin, error)
+	ListTerms(ctx context.Context, req model.ListTermsRequest) ([]*model.TermTaxonomyJoin, error)
+	SearchTerms(ctx context.Context, filter model.SearchFilter) ([]*model.TermTaxonomyJoin, int, error)
+	TermNameExists(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error)
+	TermSlugExists(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error)
+	UniqueTermSlug(ctx context.Context, slug string, term *model.TermTaxonomyJoin) (string, error)
+	InsertTerm(ctx context.Context, term *model.TermTaxonomyJoin) (uint64, error)
+	UpdateTerm(ctx context.Context, term *model.TermTaxonomyJoin) error
+	DeleteTerm(ctx context.Context, term *model.TermTaxonomyJoin) error
+	ReparentTerms(ctx context.Context, taxonomy string, oldParent uint64, newParent uint64) error
+	TermObjectIDs(ctx context.Context, termTaxonomyID uint64) ([]uint64, error)
+	SoleTermObjectIDs(ctx context.Context, termTaxonomyID uint64, taxonomy string) ([]uint64, error)
+	AddObjectsTerm(ctx context.Context, objectIDList []uint64, termTaxonomyID uint64) error
 }
 
+// maxTermSlugLength is length of slug column of terms table
+const maxTermSlugLength = 200
+
 type repository struct {
-	db *sql.DB
+	db *dialect.DB
 }
 
 // NewRepository is function to create new repository struct instance that implements Repository interface
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db *dialect.DB) Repository {
 	return &repository{
 		db: db,
 	}
@@ -32,20 +56,23 @@ func NewRepository(db *sql.DB) Repository {
 // PostTermTaxonomyByIDs is function get get post term taxonomy from list of post ids
 func (repo *repository) PostTermTaxonomyByIDs(ctx context.Context, idList []string) (map[uint64][]*model.TermWithPostTaxonomy, error) {
 	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	termsTableName := config.TablePrefix + "terms"
-	termTaxonomyTableName := config.TablePrefix + "term_taxonomy"
-	termRelationsTableName := config.TablePrefix + "term_relationships"
-	sqlQuery := fmt.Sprintf(`SELECT t.*, tt.term_taxonomy_id, tt.taxonomy, tt.description, tt.parent, tt.count, tr.object_id FROM %s AS t  `+
-		`INNER JOIN %s AS tt ON t.term_id = tt.term_id `+
-		`INNER JOIN %s AS tr ON tr.term_taxonomy_id = tt.term_taxonomy_id `+
-		`WHERE tt.taxonomy IN ('category', 'post_tag', 'post_format') AND tr.object_id IN (%s) `+
-		`ORDER BY t.name ASC`,
-		termsTableName,
-		termTaxonomyTableName,
-		termRelationsTableName,
-		strings.Join(idList, ","))
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	termRelationsTableName := config.Tables().TermRelationships
+	taxonomies := append(model.TermTaxonomies(), model.PostFormatType)
+	sqlQuery, args, err := sqlbuilder.New(`SELECT t.*, tt.term_taxonomy_id, tt.taxonomy, tt.description, tt.parent, tt.count, tr.object_id FROM `).
+		Ident(termsTableName).SQL(` AS t INNER JOIN `).
+		Ident(termTaxonomyTableName).SQL(` AS tt ON t.term_id = tt.term_id INNER JOIN `).
+		Ident(termRelationsTableName).SQL(` AS tr ON tr.term_taxonomy_id = tt.term_taxonomy_id WHERE `).
+		In("tt.taxonomy", sqlbuilder.Strings(taxonomies)).
+		AndIn("tr.object_id", sqlbuilder.Strings(idList)).
+		OrderBy("t.name", "ASC").
+		Build()
+	if err != nil {
+		return nil, err
+	}
 
-	q, err := repo.db.Query(sqlQuery)
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -99,7 +126,7 @@ func (repo *repository) GetPostTaxonomyAndFormat(ctx context.Context, idList []s
 
 	for _, terms := range termsMap {
 		for _, t := range terms {
-			if t.Taxonomy == "post_tag" || t.Taxonomy == "category" {
+			if t.Taxonomy != model.PostFormatType {
 				_, exists := taxonomies[t.ObjectID]
 				if !exists {
 					taxonomies[t.ObjectID] = make(map[string][]uint64)
@@ -111,7 +138,7 @@ func (repo *repository) GetPostTaxonomyAndFormat(ctx context.Context, idList []s
 				taxonomies[t.ObjectID][t.Taxonomy] = append(taxonomies[t.ObjectID][t.Taxonomy], t.TermID)
 			}
 
-			if t.Taxonomy == "post_format" {
+			if t.Taxonomy == model.PostFormatType {
 				formatMap[t.ObjectID] = t.Name
 			}
 		}
@@ -121,17 +148,24 @@ func (repo *repository) GetPostTaxonomyAndFormat(ctx context.Context, idList []s
 }
 
 // TermTaxonomyByTermIDListTaxonomy get array of TermTaxonomy from specified term ID slices
-func (repo *repository) TermTaxonomyByTermIDListTaxonomy(termIDList []uint64, taxonomy string) ([]*model.TermTaxonomy, error) {
+func (repo *repository) TermTaxonomyByTermIDListTaxonomy(ctx context.Context, termIDList []uint64, taxonomy string) ([]*model.TermTaxonomy, error) {
 	if len(termIDList) == 0 {
 		return []*model.TermTaxonomy{}, nil
 	}
 
-	sqlQuery := `SELECT ` +
-		`term_taxonomy_id, term_id, taxonomy, description, parent, count ` +
-		`FROM wp_term_taxonomy ` +
-		`WHERE term_id IN ( ` + toolbox.UInt64SliceToCSV(termIDList) + `)  AND taxonomy = ?`
+	termTaxonomyTableName := model.TablesFromContext(ctx).TermTaxonomy
 
-	q, err := repo.db.Query(sqlQuery, taxonomy)
+	sqlQuery, args, err := sqlbuilder.New(`SELECT `+
+		`term_taxonomy_id, term_id, taxonomy, description, parent, count `+
+		`FROM `).Ident(termTaxonomyTableName).SQL(` `+
+		`WHERE `).In("term_id", sqlbuilder.Uint64s(termIDList)).
+		And(`taxonomy = ?`, taxonomy).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": fmt.Sprintf("%s, %v, %s", sqlQuery, termIDList, taxonomy),
@@ -161,3 +195,670 @@ func (repo *repository) TermTaxonomyByTermIDListTaxonomy(termIDList []uint64, ta
 
 	return res, nil
 }
+
+// ObjectTermTaxonomyIDs get term taxonomy ids of all terms that are related with an object (post)
+func (repo *repository) ObjectTermTaxonomyIDs(ctx context.Context, objectID uint64) ([]uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termRelationsTableName := config.Tables().TermRelationships
+
+	sqlQuery := `SELECT term_taxonomy_id FROM ` + termRelationsTableName + ` WHERE object_id = ?`
+
+	return scanIDs(ctx, shared.Conn(ctx, repo.db), sqlQuery, objectID)
+}
+
+// SetObjectTerms replaces terms of specific taxonomy that are related with an object (post) like wp_set_object_terms does.
+// Term ID that doesn't exist in the taxonomy is skipped. It returns term taxonomy ids that are added or removed so their count can be updated
+func (repo *repository) SetObjectTerms(ctx context.Context, objectID uint64, termIDList []uint64, taxonomy string) ([]uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	termRelationsTableName := config.Tables().TermRelationships
+	conn := shared.Conn(ctx, repo.db)
+
+	// current term taxonomy ids of the object in the taxonomy
+	sqlQuery := `SELECT tr.term_taxonomy_id FROM ` + termRelationsTableName + ` AS tr ` +
+		`INNER JOIN ` + termTaxonomyTableName + ` AS tt ON tt.term_taxonomy_id = tr.term_taxonomy_id ` +
+		`WHERE tr.object_id = ? AND tt.taxonomy = ?`
+	oldIDs, err := scanIDs(ctx, conn, sqlQuery, objectID, taxonomy)
+	if err != nil {
+		return nil, err
+	}
+
+	// term taxonomy ids of the new terms
+	newIDs := make([]uint64, 0)
+	if len(termIDList) > 0 {
+		var args []interface{}
+		sqlQuery, args, err = sqlbuilder.New(`SELECT term_taxonomy_id FROM `).Ident(termTaxonomyTableName).
+			SQL(` WHERE taxonomy = ?`, taxonomy).AndIn("term_id", sqlbuilder.Uint64s(termIDList)).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		newIDs, err = scanIDs(ctx, conn, sqlQuery, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var affected []uint64
+
+	for _, id := range oldIDs {
+		if toolbox.UInt64InSlice(id, newIDs) {
+			continue
+		}
+		var args []interface{}
+		sqlQuery, args, err = sqlbuilder.New(`DELETE FROM `).Ident(termRelationsTableName).
+			SQL(` WHERE object_id = ? AND term_taxonomy_id = ?`, objectID, id).Build()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("objectID: %d, termTaxonomyID: %d", objectID, id),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to delete term relationship: %s", err)
+			return nil, err
+		}
+		affected = append(affected, id)
+	}
+
+	for _, id := range newIDs {
+		if toolbox.UInt64InSlice(id, oldIDs) {
+			continue
+		}
+		var args []interface{}
+		sqlQuery, args, err = sqlbuilder.New(`INSERT INTO `).Ident(termRelationsTableName).
+			SQL(` (object_id, term_taxonomy_id, term_order) VALUES (?, ?, 0)`, objectID, id).Build()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("objectID: %d, termTaxonomyID: %d", objectID, id),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to insert term relationship: %s", err)
+			return nil, err
+		}
+		affected = append(affected, id)
+	}
+
+	return affected, nil
+}
+
+// DeleteObjectTerms deletes all term relationships of an object (post)
+func (repo *repository) DeleteObjectTerms(ctx context.Context, objectID uint64) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termRelationsTableName := config.Tables().TermRelationships
+
+	sqlQuery, args, err := sqlbuilder.New(`DELETE FROM `).Ident(termRelationsTableName).SQL(` WHERE object_id = ?`, objectID).Build()
+	if err != nil {
+		return err
+	}
+
+	if _, err = shared.Conn(ctx, repo.db).ExecContext(ctx, sqlQuery, args...); err != nil {
+		log.WithFields(log.Fields{
+			"params": objectID,
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to delete term relationships: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTermCount recalculates count of term taxonomies like wp_update_term_count_now does. Taxonomies of post types are
+// counted like _update_post_term_count, published objects of the post types and attachments that are published or inherit
+// status of their published parent. Taxonomies that have other object types are counted like _update_generic_term_count,
+// every object of the term is counted
+func (repo *repository) UpdateTermCount(ctx context.Context, termTaxonomyIDList []uint64) error {
+	if len(termTaxonomyIDList) == 0 {
+		return nil
+	}
+
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	postsTableName := config.Tables().Posts
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	termRelationsTableName := config.Tables().TermRelationships
+	conn := shared.Conn(ctx, repo.db)
+
+	for _, taxonomy := range model.TermTaxonomies() {
+		objectTypes := model.TaxonomyObjectTypes(taxonomy)
+		if len(objectTypes) == 0 {
+			continue
+		}
+
+		b := sqlbuilder.New(`UPDATE `).Ident(termTaxonomyTableName).SQL(` AS tt SET count = (SELECT COUNT(*) FROM `).
+			Ident(termRelationsTableName).SQL(` AS tr `)
+		if postTypes, countAttachments, ok := countedPostTypes(objectTypes); ok {
+			b = b.SQL(`INNER JOIN `).Ident(postsTableName).SQL(` AS p ON p.ID = tr.object_id ` +
+				`WHERE tr.term_taxonomy_id = tt.term_taxonomy_id AND (`)
+			or := ""
+			if len(postTypes) > 0 {
+				b = b.SQL(`(p.post_status = ?`, model.PublishStatus).AndIn("p.post_type", sqlbuilder.Strings(postTypes)).SQL(`)`)
+				or = " OR "
+			}
+			if countAttachments {
+				b = b.SQL(or+`(p.post_type = ? AND (p.post_status = ? OR (p.post_status = ? AND p.post_parent > 0 AND (SELECT parent.post_status FROM `,
+					model.AttachmentType, model.PublishStatus, model.InheritStatus).
+					Ident(postsTableName).SQL(` AS parent WHERE parent.ID = p.post_parent) = ?)))`, model.PublishStatus)
+			}
+			b = b.SQL(`)`)
+		} else {
+			b = b.SQL(`WHERE tr.term_taxonomy_id = tt.term_taxonomy_id`)
+		}
+		sqlQuery, args, err := b.SQL(`) WHERE tt.taxonomy = ?`, taxonomy).
+			AndIn("tt.term_taxonomy_id", sqlbuilder.Uint64s(termTaxonomyIDList)).
+			Build()
+		if err != nil {
+			return err
+		}
+
+		if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("taxonomy: %s, termTaxonomyIDs: %v", taxonomy, termTaxonomyIDList),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to update term count: %s", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countedPostTypes returns object types of taxonomy without attachment and whether attachments are counted, object type
+// like 'attachment:image' is attachment. It returns false if an object type is not a post type, so objects are not posts
+func countedPostTypes(objectTypes []string) ([]string, bool, bool) {
+	var postTypes []string
+	countAttachments := false
+	for _, objectType := range objectTypes {
+		objectType = strings.SplitN(objectType, ":", 2)[0]
+		if _, ok := model.PostTypeBySlug(objectType); !ok {
+			return nil, false, false
+		}
+		if objectType == model.AttachmentType {
+			countAttachments = true
+			continue
+		}
+		postTypes = append(postTypes, objectType)
+	}
+	return postTypes, countAttachments, true
+}
+
+// TermByID retrieves term of the taxonomy from prefix+'_terms' and prefix+'_term_taxonomy' tables, it returns sql.ErrNoRows if not found
+func (repo *repository) TermByID(ctx context.Context, termID uint64, taxonomy string) (*model.TermTaxonomyJoin, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+
+	sqlQuery := `SELECT t.term_id, t.name, t.slug, t.term_group, tt.term_taxonomy_id, tt.taxonomy, tt.description, tt.parent, tt.count ` +
+		`FROM ` + termsTableName + ` AS t INNER JOIN ` + termTaxonomyTableName + ` AS tt ON t.term_id = tt.term_id ` +
+		`WHERE t.term_id = ? AND tt.taxonomy = ?`
+
+	t := &model.TermTaxonomyJoin{}
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, termID, taxonomy).
+		Scan(&t.TermID, &t.Name, &t.Slug, &t.TermGroup, &t.TermTaxonomyID, &t.Taxonomy, &t.Description, &t.Parent, &t.Count)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": fmt.Sprintf("termID: %d, taxonomy: %s", termID, taxonomy),
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to get term by id: %s", err)
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// termOrderColumns maps orderby parameter of list terms request to column
+var termOrderColumns = map[string]string{
+	"id":    "t.term_id",
+	"name":  "t.name",
+	"slug":  "t.slug",
+	"count": "tt.count",
+}
+
+// ListTerms retrieves terms of the taxonomy that match the list request, order and orderby must be validated before
+func (repo *repository) ListTerms(ctx context.Context, req model.ListTermsRequest) ([]*model.TermTaxonomyJoin, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	termRelationsTableName := config.Tables().TermRelationships
+
+	query := sqlbuilder.New(`SELECT t.term_id, t.name, t.slug, t.term_group, tt.term_taxonomy_id, tt.taxonomy, tt.description, tt.parent, tt.count ` +
+		`FROM `).Ident(termsTableName).SQL(` AS t INNER JOIN `).Ident(termTaxonomyTableName).SQL(` AS tt ON t.term_id = tt.term_id `)
+
+	if req.Post != nil {
+		query.SQL(`INNER JOIN `).Ident(termRelationsTableName).SQL(` AS tr ON tr.term_taxonomy_id = tt.term_taxonomy_id AND tr.object_id = ? `, *req.Post)
+	}
+
+	query.SQL(`WHERE tt.taxonomy = ?`, req.Taxonomy)
+
+	if len(req.Include) > 0 {
+		query.AndIn("t.term_id", sqlbuilder.Uint64s(req.Include))
+	}
+	if len(req.Exclude) > 0 {
+		query.AndNotIn("t.term_id", sqlbuilder.Uint64s(req.Exclude))
+	}
+	if req.Parent != nil {
+		query.And(`tt.parent = ?`, *req.Parent)
+	}
+	if len(req.Slug) > 0 {
+		query.AndIn("t.slug", sqlbuilder.Strings(req.Slug))
+	}
+	if req.Search != nil {
+		keyword := "%" + dialect.EscapeLike(*req.Search) + "%"
+		escape := repo.db.Dialect.LikeEscape()
+		query.And(`(t.name LIKE ?`+escape+` OR t.slug LIKE ?`+escape+`)`, keyword, keyword)
+	}
+	if req.HideEmpty {
+		query.And(`tt.count > 0`)
+	}
+
+	sqlQuery, args, err := query.OrderBy(termOrderColumns[req.OrderBy], req.Order).
+		Limit((req.Page-1)*req.PerPage, req.PerPage).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryContext",
+		}).Errorf("Failed to list terms: %s", err)
+		return nil, err
+	}
+	defer q.Close()
+
+	terms := make([]*model.TermTaxonomyJoin, 0)
+	for q.Next() {
+		t := &model.TermTaxonomyJoin{}
+		if err = q.Scan(&t.TermID, &t.Name, &t.Slug, &t.TermGroup, &t.TermTaxonomyID, &t.Taxonomy, &t.Description, &t.Parent, &t.Count); err != nil {
+			log.WithFields(log.Fields{
+				"params": args,
+				"func":   "q.Scan",
+			}).Errorf("Failed to scan term: %s", err)
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+
+	return terms, q.Err()
+}
+
+// SearchTerms returns terms of the taxonomies that match the search filter and total number of matching terms,
+// term with the same name as the keyword comes first followed by term whose name starts with the keyword
+func (repo *repository) SearchTerms(ctx context.Context, filter model.SearchFilter) ([]*model.TermTaxonomyJoin, int, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+
+	fromSQL := sqlbuilder.New(` FROM `).Ident(termsTableName).SQL(` AS t INNER JOIN `).Ident(termTaxonomyTableName).
+		SQL(` AS tt ON t.term_id = tt.term_id WHERE `).In("tt.taxonomy", sqlbuilder.Strings(filter.Subtypes))
+
+	if filter.Search != "" {
+		keyword := "%" + dialect.EscapeLike(filter.Search) + "%"
+		escape := repo.db.Dialect.LikeEscape()
+		fromSQL.And(`(t.name LIKE ?`+escape+` OR t.slug LIKE ?`+escape+`)`, keyword, keyword)
+	}
+	if len(filter.Include) > 0 {
+		fromSQL.AndIn("t.term_id", sqlbuilder.Uint64s(filter.Include))
+	}
+	if len(filter.Exclude) > 0 {
+		fromSQL.AndNotIn("t.term_id", sqlbuilder.Uint64s(filter.Exclude))
+	}
+
+	countSQL, args, err := sqlbuilder.New(`SELECT COUNT(*)`).Append(fromSQL).Build()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err = shared.Conn(ctx, repo.db).QueryRowContext(ctx, countSQL, args...).Scan(&total); err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to count search terms: %s", err)
+		return nil, 0, err
+	}
+
+	query := sqlbuilder.New(`SELECT t.term_id, t.name, t.slug, t.term_group, tt.term_taxonomy_id, tt.taxonomy, tt.description, tt.parent, tt.count`).
+		Append(fromSQL).SQL(` ORDER BY `)
+	if filter.Search != "" {
+		query.SQL(`CASE WHEN t.name = ? THEN 2 WHEN t.name LIKE ?`+repo.db.Dialect.LikeEscape()+` THEN 1 ELSE 0 END DESC, `,
+			filter.Search, dialect.EscapeLike(filter.Search)+"%")
+	}
+	sqlQuery, args, err := query.SQL(`t.name ASC`).Limit((filter.Page-1)*filter.PerPage, filter.PerPage).Build()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": args,
+			"func":   "conn.QueryContext",
+		}).Errorf("Failed to search terms: %s", err)
+		return nil, 0, err
+	}
+	defer q.Close()
+
+	terms := make([]*model.TermTaxonomyJoin, 0)
+	for q.Next() {
+		t := &model.TermTaxonomyJoin{}
+		if err = q.Scan(&t.TermID, &t.Name, &t.Slug, &t.TermGroup, &t.TermTaxonomyID, &t.Taxonomy, &t.Description, &t.Parent, &t.Count); err != nil {
+			log.WithFields(log.Fields{
+				"params": args,
+				"func":   "q.Scan",
+			}).Errorf("Failed to scan term: %s", err)
+			return nil, 0, err
+		}
+		terms = append(terms, t)
+	}
+
+	return terms, total, q.Err()
+}
+
+// termExists runs query that selects term id with the condition and returns true if any other term than the given term matches
+func (repo *repository) termExists(ctx context.Context, term *model.TermTaxonomyJoin, condition string, args ...interface{}) (bool, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+
+	sqlQuery := `SELECT t.term_id FROM ` + termsTableName + ` AS t INNER JOIN ` + termTaxonomyTableName + ` AS tt ON t.term_id = tt.term_id ` +
+		`WHERE tt.taxonomy = ? AND t.term_id != ? AND ` + condition + ` LIMIT 1`
+	args = append([]interface{}{term.Taxonomy, term.TermID}, args...)
+
+	var id uint64
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sqlQuery, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": fmt.Sprintf("%s, %v", sqlQuery, args),
+			"func":   "conn.QueryRowContext.Scan",
+		}).Errorf("Failed to check term: %s", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// TermNameExists checks whether another term of the taxonomy has the same name like term_exists does,
+// name of hierarchical term only needs to be unique under the same parent
+func (repo *repository) TermNameExists(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error) {
+	if model.IsHierarchicalTaxonomy(term.Taxonomy) {
+		return repo.termExists(ctx, term, `t.name = ? AND tt.parent = ?`, term.Name, term.Parent)
+	}
+	return repo.termExists(ctx, term, `t.name = ?`, term.Name)
+}
+
+// TermSlugExists checks whether another term of the taxonomy has the same slug
+func (repo *repository) TermSlugExists(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error) {
+	return repo.termExists(ctx, term, `t.slug = ?`, term.Slug)
+}
+
+// UniqueTermSlug returns slug that is unique in the taxonomy like wp_unique_term_slug does.
+// Slug of hierarchical term with parent gets slug of its parent as suffix first, then numeric suffix is added if the slug is still used
+func (repo *repository) UniqueTermSlug(ctx context.Context, slug string, term *model.TermTaxonomyJoin) (string, error) {
+	candidate := &model.TermTaxonomyJoin{Term: model.Term{TermID: term.TermID, Slug: slug, Taxonomy: term.Taxonomy}}
+	used, err := repo.TermSlugExists(ctx, candidate)
+	if err != nil || !used {
+		return slug, err
+	}
+
+	if model.IsHierarchicalTaxonomy(term.Taxonomy) && term.Parent != 0 {
+		parent, err := repo.TermByID(ctx, term.Parent, term.Taxonomy)
+		if err != nil && err != sql.ErrNoRows {
+			return "", err
+		}
+		if err == nil {
+			slug = shared.TruncateSlug(slug, maxTermSlugLength-len(parent.Slug)-1) + "-" + parent.Slug
+			candidate.Slug = slug
+			used, err = repo.TermSlugExists(ctx, candidate)
+			if err != nil || !used {
+				return slug, err
+			}
+		}
+	}
+
+	for suffix := 2; ; suffix++ {
+		suffixString := "-" + strconv.Itoa(suffix)
+		candidate.Slug = shared.TruncateSlug(slug, maxTermSlugLength-len(suffixString)) + suffixString
+		used, err = repo.TermSlugExists(ctx, candidate)
+		if err != nil || !used {
+			return candidate.Slug, err
+		}
+	}
+}
+
+// InsertTerm inserts term as new rows of prefix+'_terms' and prefix+'_term_taxonomy' tables and returns the new term ID
+func (repo *repository) InsertTerm(ctx context.Context, term *model.TermTaxonomyJoin) (uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	conn := shared.Conn(ctx, repo.db)
+
+	sqlQuery, args, err := sqlbuilder.New(`INSERT INTO `).Ident(termsTableName).
+		SQL(` (name, slug, term_group) VALUES (?, ?, ?)`, term.Name, term.Slug, term.TermGroup).Build()
+	if err != nil {
+		return 0, err
+	}
+
+	termID, err := repo.db.Dialect.Insert(ctx, conn, sqlQuery, "term_id", args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": term,
+			"func":   "Dialect.Insert",
+		}).Errorf("Failed to insert term: %s", err)
+		return 0, err
+	}
+	term.TermID = uint64(termID)
+
+	sqlQuery, args, err = sqlbuilder.New(`INSERT INTO `).Ident(termTaxonomyTableName).
+		SQL(` (term_id, taxonomy, description, parent, count) VALUES (?, ?, ?, ?, 0)`, term.TermID, term.Taxonomy, term.Description, term.Parent).
+		Build()
+	if err != nil {
+		return 0, err
+	}
+
+	termTaxonomyID, err := repo.db.Dialect.Insert(ctx, conn, sqlQuery, "term_taxonomy_id", args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": term,
+			"func":   "Dialect.Insert",
+		}).Errorf("Failed to insert term taxonomy: %s", err)
+		return 0, err
+	}
+	term.TermTaxonomyID = uint64(termTaxonomyID)
+	term.Count = 0
+
+	return term.TermID, nil
+}
+
+// UpdateTerm updates name and slug in prefix+'_terms' table and description and parent in prefix+'_term_taxonomy' table
+func (repo *repository) UpdateTerm(ctx context.Context, term *model.TermTaxonomyJoin) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	conn := shared.Conn(ctx, repo.db)
+
+	sqlQuery, args, err := sqlbuilder.New(`UPDATE `).Ident(termsTableName).
+		SQL(` SET name = ?, slug = ? WHERE term_id = ?`, term.Name, term.Slug, term.TermID).Build()
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+		log.WithFields(log.Fields{
+			"params": term,
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to update term: %s", err)
+		return err
+	}
+
+	sqlQuery, args, err = sqlbuilder.New(`UPDATE `).Ident(termTaxonomyTableName).
+		SQL(` SET description = ?, parent = ? WHERE term_taxonomy_id = ?`, term.Description, term.Parent, term.TermTaxonomyID).Build()
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+		log.WithFields(log.Fields{
+			"params": term,
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to update term taxonomy: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTerm deletes term taxonomy with its relationships, and deletes the term with its metas if no other taxonomy uses it
+func (repo *repository) DeleteTerm(ctx context.Context, term *model.TermTaxonomyJoin) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termsTableName := config.Tables().Terms
+	termMetaTableName := config.Tables().TermMeta
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	termRelationsTableName := config.Tables().TermRelationships
+	conn := shared.Conn(ctx, repo.db)
+
+	queries := []*sqlbuilder.Builder{
+		sqlbuilder.New(`DELETE FROM `).Ident(termRelationsTableName).SQL(` WHERE term_taxonomy_id = ?`, term.TermTaxonomyID),
+		sqlbuilder.New(`DELETE FROM `).Ident(termTaxonomyTableName).SQL(` WHERE term_taxonomy_id = ?`, term.TermTaxonomyID),
+		sqlbuilder.New(`DELETE FROM `).Ident(termMetaTableName).SQL(` WHERE term_id = ? AND NOT EXISTS (SELECT term_taxonomy_id FROM `, term.TermID).
+			Ident(termTaxonomyTableName).SQL(` WHERE term_id = ?)`, term.TermID),
+		sqlbuilder.New(`DELETE FROM `).Ident(termsTableName).SQL(` WHERE term_id = ? AND NOT EXISTS (SELECT term_taxonomy_id FROM `, term.TermID).
+			Ident(termTaxonomyTableName).SQL(` WHERE term_id = ?)`, term.TermID),
+	}
+
+	for _, query := range queries {
+		sqlQuery, args, err := query.Build()
+		if err != nil {
+			return err
+		}
+		if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("%s, %v", sqlQuery, args),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to delete term: %s", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReparentTerms moves child terms of the old parent to the new parent in the taxonomy
+func (repo *repository) ReparentTerms(ctx context.Context, taxonomy string, oldParent uint64, newParent uint64) error {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+
+	sqlQuery, args, err := sqlbuilder.New(`UPDATE `).Ident(termTaxonomyTableName).
+		SQL(` SET parent = ? WHERE parent = ? AND taxonomy = ?`, newParent, oldParent, taxonomy).Build()
+	if err != nil {
+		return err
+	}
+
+	if _, err = shared.Conn(ctx, repo.db).ExecContext(ctx, sqlQuery, args...); err != nil {
+		log.WithFields(log.Fields{
+			"params": fmt.Sprintf("taxonomy: %s, oldParent: %d, newParent: %d", taxonomy, oldParent, newParent),
+			"func":   "conn.ExecContext",
+		}).Errorf("Failed to update parent of child terms: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// TermObjectIDs get ids of objects (posts) that are related with the term taxonomy
+func (repo *repository) TermObjectIDs(ctx context.Context, termTaxonomyID uint64) ([]uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termRelationsTableName := config.Tables().TermRelationships
+
+	sqlQuery := `SELECT object_id FROM ` + termRelationsTableName + ` WHERE term_taxonomy_id = ?`
+
+	return scanIDs(ctx, shared.Conn(ctx, repo.db), sqlQuery, termTaxonomyID)
+}
+
+// SoleTermObjectIDs get ids of objects (posts) that have the term taxonomy as their only term in the taxonomy
+func (repo *repository) SoleTermObjectIDs(ctx context.Context, termTaxonomyID uint64, taxonomy string) ([]uint64, error) {
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termTaxonomyTableName := config.Tables().TermTaxonomy
+	termRelationsTableName := config.Tables().TermRelationships
+
+	sqlQuery := `SELECT tr.object_id FROM ` + termRelationsTableName + ` AS tr ` +
+		`INNER JOIN ` + termTaxonomyTableName + ` AS tt ON tt.term_taxonomy_id = tr.term_taxonomy_id ` +
+		`WHERE tt.taxonomy = ? AND tr.object_id IN (SELECT object_id FROM ` + termRelationsTableName + ` WHERE term_taxonomy_id = ?) ` +
+		`GROUP BY tr.object_id HAVING COUNT(*) = 1`
+
+	return scanIDs(ctx, shared.Conn(ctx, repo.db), sqlQuery, taxonomy, termTaxonomyID)
+}
+
+// AddObjectsTerm relates objects (posts) with the term taxonomy, objects that are already related are skipped
+func (repo *repository) AddObjectsTerm(ctx context.Context, objectIDList []uint64, termTaxonomyID uint64) error {
+	if len(objectIDList) == 0 {
+		return nil
+	}
+
+	existing, err := repo.TermObjectIDs(ctx, termTaxonomyID)
+	if err != nil {
+		return err
+	}
+
+	config := ctx.Value(model.APIConfigKey).(model.APIConfig)
+	termRelationsTableName := config.Tables().TermRelationships
+	conn := shared.Conn(ctx, repo.db)
+
+	for _, objectID := range objectIDList {
+		if toolbox.UInt64InSlice(objectID, existing) {
+			continue
+		}
+		sqlQuery, args, err := sqlbuilder.New(`INSERT INTO `).Ident(termRelationsTableName).
+			SQL(` (object_id, term_taxonomy_id, term_order) VALUES (?, ?, 0)`, objectID, termTaxonomyID).Build()
+		if err != nil {
+			return err
+		}
+		if _, err = conn.ExecContext(ctx, sqlQuery, args...); err != nil {
+			log.WithFields(log.Fields{
+				"params": fmt.Sprintf("objectID: %d, termTaxonomyID: %d", objectID, termTaxonomyID),
+				"func":   "conn.ExecContext",
+			}).Errorf("Failed to insert term relationship: %s", err)
+			return err
+		}
+		existing = append(existing, objectID)
+	}
+
+	return nil
+}
+
+// scanIDs runs query that selects single uint64 column and returns the values
+func scanIDs(ctx context.Context, conn shared.Querier, sqlQuery string, args ...interface{}) ([]uint64, error) {
+	q, err := conn.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": fmt.Sprintf("%s, %v", sqlQuery, args),
+			"func":   "conn.QueryContext",
+		}).Errorf("Failed to run db query: %s", err)
+		return nil, err
+	}
+	defer q.Close()
+
+	var res = make([]uint64, 0)
+	for q.Next() {
+		var id uint64
+		if err = q.Scan(&id); err != nil {
+			log.WithFields(log.Fields{
+				"params": sqlQuery,
+				"func":   "q.Scan",
+			}).Errorf("Failed to run query scan: %s", err)
+			return nil, err
+		}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package term
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_UpdateTermCount(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// in memory database is dropped when its connection is closed
+	db.SetMaxOpenConns(1)
+
+	// registering twice fails when the test runs more than once, the first registration is kept
+	_ = model.RegisterTaxonomy(model.TaxonomyConfig{Slug: "collection", RestBase: "collections",
+		ObjectTypes: []string{model.PostType, model.AttachmentType}})
+	_ = model.RegisterTaxonomy(model.TaxonomyConfig{Slug: "audience", RestBase: "audiences", ObjectTypes: []string{"user"}})
+
+	for _, statement := range []string{
+		"CREATE TABLE wp_posts (ID INTEGER PRIMARY KEY, post_type TEXT, post_status TEXT, post_parent INTEGER)",
+		"CREATE TABLE wp_term_taxonomy (term_taxonomy_id INTEGER PRIMARY KEY, taxonomy TEXT, count INTEGER)",
+		"CREATE TABLE wp_term_relationships (object_id INTEGER, term_taxonomy_id INTEGER)",
+		"INSERT INTO wp_posts VALUES (10, 'post', 'publish', 0), (13, 'post', 'draft', 0), (30, 'attachment', 'inherit', 10), " +
+			"(31, 'attachment', 'inherit', 0), (32, 'page', 'publish', 0)",
+		"INSERT INTO wp_term_taxonomy VALUES (4, 'post_tag', 0), (50, 'collection', 0), (51, 'audience', 0)",
+		// tag of published post, draft and attachment
+		"INSERT INTO wp_term_relationships VALUES (10, 4), (13, 4), (30, 4)",
+		// published post, draft, attachment of published parent, unattached attachment and page of the collection
+		"INSERT INTO wp_term_relationships VALUES (10, 50), (13, 50), (30, 50), (31, 50), (32, 50)",
+		// users of the generic taxonomy are not posts
+		"INSERT INTO wp_term_relationships VALUES (1, 51), (2, 51)",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{TablePrefix: "wp_"})
+	repo := NewRepository(db)
+	count := func(termTaxonomyID uint64) int {
+		var n int
+		if err := db.QueryRow("SELECT count FROM wp_term_taxonomy WHERE term_taxonomy_id = ?", termTaxonomyID).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	assert.NoError(t, repo.UpdateTermCount(ctx, []uint64{4, 50, 51}))
+	assert.Equal(t, 1, count(4))
+	assert.Equal(t, 2, count(50))
+	assert.Equal(t, 2, count(51))
+
+	// attachment of draft parent is not counted
+	_, err = db.Exec("UPDATE wp_posts SET post_status = 'draft' WHERE ID = 10")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.UpdateTermCount(ctx, []uint64{50}))
+	assert.Equal(t, 0, count(50))
+}
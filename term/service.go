@@ -0,0 +1,439 @@
+package term
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/toolbox"
+	log "github.com/sirupsen/logrus"
+)
+
+// Service is interface for term functions
+type Service interface {
+	GetTerm(ctx context.Context, req model.GetTermRequest) (interface{}, error)
+	ListTerms(ctx context.Context, req model.ListTermsRequest) (interface{}, error)
+	CreateTerm(ctx context.Context, req model.WriteTermRequest) (interface{}, error)
+	UpdateTerm(ctx context.Context, req model.WriteTermRequest) (interface{}, error)
+	MergeTerm(ctx context.Context, req model.MergeTermRequest) (interface{}, error)
+	DeleteTerm(ctx context.Context, req model.DeleteTermRequest) (interface{}, error)
+}
+
+// maxTermsPerPage is maximum number of terms that are returned by list terms request
+const maxTermsPerPage = 100
+
+// service is struct that will implement Service interface and store related repositories
+type service struct {
+	term   Repository
+	shared shared.Repository
+}
+
+// NewService is a simple helper function to create a service instance
+func NewService(termRepo Repository, sharedRepo shared.Repository) Service {
+	return &service{
+		term:   termRepo,
+		shared: sharedRepo,
+	}
+}
+
+// termByID returns term of the taxonomy, it returns ErrInvalidTermID if the term doesn't exist
+func (s *service) termByID(ctx context.Context, termID uint64, taxonomy string) (*model.TermTaxonomyJoin, error) {
+	t, err := s.term.TermByID(ctx, termID, taxonomy)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrInvalidTermID
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": termID,
+			"func":   "s.term.TermByID",
+		}).Errorf("Failed to get term by id: %s", err)
+		return nil, err
+	}
+	return t, nil
+}
+
+// ancestorIDs returns ids of ancestors of the term from its parent up to the root, it stops on existing loop in parent
+func (s *service) ancestorIDs(ctx context.Context, t *model.TermTaxonomyJoin) ([]uint64, error) {
+	var ids []uint64
+	visited := map[uint64]bool{t.TermID: true}
+
+	for parentID := t.Parent; parentID != 0 && !visited[parentID]; {
+		visited[parentID] = true
+		ids = append(ids, parentID)
+
+		parent, err := s.term.TermByID(ctx, parentID, t.Taxonomy)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parentID = parent.Parent
+	}
+
+	return ids, nil
+}
+
+// validateParent checks parent of the term exists in the taxonomy and the term is not its own ancestor,
+// only hierarchical taxonomy can have parent
+func (s *service) validateParent(ctx context.Context, t *model.TermTaxonomyJoin) error {
+	if t.Parent == 0 {
+		return nil
+	}
+	if !model.IsHierarchicalTaxonomy(t.Taxonomy) {
+		return model.NewParamError("parent", "Cannot set parent term, taxonomy is not hierarchical.")
+	}
+
+	parent, err := s.term.TermByID(ctx, t.Parent, t.Taxonomy)
+	if err == sql.ErrNoRows {
+		return model.NewParamError("parent", "Parent term does not exist.")
+	}
+	if err != nil {
+		return err
+	}
+	if t.TermID == 0 {
+		return nil
+	}
+
+	ancestors, err := s.ancestorIDs(ctx, parent)
+	if err != nil {
+		return err
+	}
+	if parent.TermID == t.TermID || toolbox.UInt64InSlice(t.TermID, ancestors) {
+		return model.NewParamError("parent", "Term cannot be a child of itself or its descendants.")
+	}
+
+	return nil
+}
+
+// newTermResponse sets link and _links of the term for json response
+func newTermResponse(ctx context.Context, t *model.TermTaxonomyJoin) *model.TermTaxonomyJoin {
+	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
+
+	t.Link = model.TaxonomyTermLink(apiConfig.SiteURL, t.Taxonomy, t.Slug)
+	t.Links = model.GetTermLinks(t.Taxonomy, apiConfig.APIBaseURL, strconv.FormatUint(t.TermID, 10))
+
+	return t
+}
+
+// GetTerm returns term of the taxonomy by its id
+func (s *service) GetTerm(ctx context.Context, req model.GetTermRequest) (interface{}, error) {
+	t, err := s.termByID(ctx, *req.ID, req.Taxonomy)
+	if err != nil {
+		return nil, err
+	}
+	return newTermResponse(ctx, t), nil
+}
+
+// ListTerms returns terms of the taxonomy that match the request, terms are ordered by name by default like WP Rest API does
+func (s *service) ListTerms(ctx context.Context, req model.ListTermsRequest) (interface{}, error) {
+	if req.Page < 1 {
+		return nil, model.NewParamError("page", "page must be greater than or equal to 1")
+	}
+	if req.PerPage < 1 || req.PerPage > maxTermsPerPage {
+		return nil, model.NewParamError("per_page", fmt.Sprintf("per_page must be between 1 (inclusive) and %d (inclusive)", maxTermsPerPage))
+	}
+	if req.Order != "asc" && req.Order != "desc" {
+		return nil, model.NewParamError("order", "order is not one of asc, desc.")
+	}
+	if _, ok := termOrderColumns[req.OrderBy]; !ok {
+		return nil, model.NewParamError("orderby", "orderby is not one of id, name, slug, count.")
+	}
+	if req.Parent != nil && !model.IsHierarchicalTaxonomy(req.Taxonomy) {
+		return nil, model.NewParamError("parent", "Cannot filter by parent, taxonomy is not hierarchical.")
+	}
+
+	terms, err := s.term.ListTerms(ctx, req)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": req,
+			"func":   "s.term.ListTerms",
+		}).Errorf("Failed to list terms: %s", err)
+		return nil, err
+	}
+
+	for _, t := range terms {
+		newTermResponse(ctx, t)
+	}
+	return terms, nil
+}
+
+// CreateTerm inserts new term of the taxonomy with unique slug like wp_insert_term does
+func (s *service) CreateTerm(ctx context.Context, req model.WriteTermRequest) (interface{}, error) {
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		return nil, model.ErrEmptyTermName
+	}
+
+	t := &model.TermTaxonomyJoin{Term: model.Term{Name: strings.TrimSpace(*req.Name), Taxonomy: req.Taxonomy}}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.Parent != nil {
+		t.Parent = *req.Parent
+	}
+
+	err := s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.validateParent(ctx, t); err != nil {
+			return err
+		}
+
+		exists, err := s.term.TermNameExists(ctx, t)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return model.ErrTermExists
+		}
+
+		t.Slug = model.SanitizeTitle(t.Name)
+		if req.Slug != nil && model.SanitizeTitle(*req.Slug) != "" {
+			// slug that is sent in request is not changed to make it unique
+			t.Slug = model.SanitizeTitle(*req.Slug)
+			exists, err = s.term.TermSlugExists(ctx, t)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return model.ErrTermExists
+			}
+		} else if t.Slug, err = s.term.UniqueTermSlug(ctx, t.Slug, t); err != nil {
+			return err
+		}
+
+		_, err = s.term.InsertTerm(ctx, t)
+		return err
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": req,
+			"func":   "s.shared.WithTransaction",
+		}).Errorf("Failed to create term: %s", err)
+		return nil, err
+	}
+
+	return newTermResponse(ctx, t), nil
+}
+
+// UpdateTerm updates name, slug, description and parent of the term like wp_update_term does,
+// renaming the term keeps its slug unless new slug is sent
+func (s *service) UpdateTerm(ctx context.Context, req model.WriteTermRequest) (interface{}, error) {
+	var t *model.TermTaxonomyJoin
+
+	err := s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if t, err = s.termByID(ctx, *req.ID, req.Taxonomy); err != nil {
+			return err
+		}
+
+		// renamed or moved term must not take name that is already used under its parent, like create checks with term_exists
+		moved := false
+		if req.Name != nil {
+			if strings.TrimSpace(*req.Name) == "" {
+				return model.ErrEmptyTermName
+			}
+			moved = strings.TrimSpace(*req.Name) != t.Name
+			t.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			t.Description = *req.Description
+		}
+		if req.Parent != nil {
+			moved = moved || *req.Parent != t.Parent
+			t.Parent = *req.Parent
+			if err = s.validateParent(ctx, t); err != nil {
+				return err
+			}
+		}
+		if moved {
+			exists, err := s.term.TermNameExists(ctx, t)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return model.ErrTermExists
+			}
+		}
+
+		if req.Slug != nil {
+			t.Slug = model.SanitizeTitle(*req.Slug)
+			if t.Slug == "" {
+				t.Slug = model.SanitizeTitle(t.Name)
+			}
+			exists, err := s.term.TermSlugExists(ctx, t)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return model.ErrDuplicateTermSlug
+			}
+		}
+
+		return s.term.UpdateTerm(ctx, t)
+	})
+	if err != nil {
+		if err != model.ErrInvalidTermID {
+			log.WithFields(log.Fields{
+				"params": req,
+				"func":   "s.shared.WithTransaction",
+			}).Errorf("Failed to update term: %s", err)
+		}
+		return nil, err
+	}
+
+	return newTermResponse(ctx, t), nil
+}
+
+// MergeTerm moves posts and child terms of the term to the target term, then deletes the term and recalculates count of the target term
+func (s *service) MergeTerm(ctx context.Context, req model.MergeTermRequest) (interface{}, error) {
+	if req.Into == nil || *req.Into == *req.ID {
+		return nil, model.NewParamError("into", "Term cannot be merged into itself.")
+	}
+
+	var target *model.TermTaxonomyJoin
+
+	err := s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		source, err := s.termByID(ctx, *req.ID, req.Taxonomy)
+		if err != nil {
+			return err
+		}
+		target, err = s.term.TermByID(ctx, *req.Into, req.Taxonomy)
+		if err == sql.ErrNoRows {
+			return model.NewParamError("into", "Term does not exist.")
+		}
+		if err != nil {
+			return err
+		}
+
+		objectIDs, err := s.term.TermObjectIDs(ctx, source.TermTaxonomyID)
+		if err != nil {
+			return err
+		}
+		if err = s.term.AddObjectsTerm(ctx, objectIDs, target.TermTaxonomyID); err != nil {
+			return err
+		}
+
+		if model.IsHierarchicalTaxonomy(req.Taxonomy) {
+			ancestors, err := s.ancestorIDs(ctx, target)
+			if err != nil {
+				return err
+			}
+			if err = s.term.ReparentTerms(ctx, req.Taxonomy, source.TermID, target.TermID); err != nil {
+				return err
+			}
+			// target that is descendant of the term takes place of the term to avoid loop in parent
+			if toolbox.UInt64InSlice(source.TermID, ancestors) {
+				target.Parent = source.Parent
+				if err = s.term.UpdateTerm(ctx, target); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err = s.term.DeleteTerm(ctx, source); err != nil {
+			return err
+		}
+		if err = s.term.UpdateTermCount(ctx, []uint64{target.TermTaxonomyID}); err != nil {
+			return err
+		}
+
+		target, err = s.termByID(ctx, target.TermID, req.Taxonomy)
+		return err
+	})
+	if err != nil {
+		if err != model.ErrInvalidTermID {
+			log.WithFields(log.Fields{
+				"params": req,
+				"func":   "s.shared.WithTransaction",
+			}).Errorf("Failed to merge term: %s", err)
+		}
+		return nil, err
+	}
+
+	return newTermResponse(ctx, target), nil
+}
+
+// DeleteTerm deletes the term like wp_delete_term does, child terms are moved to parent of the term and
+// posts that only have the deleted category are assigned to default category
+func (s *service) DeleteTerm(ctx context.Context, req model.DeleteTermRequest) (interface{}, error) {
+	if !req.Force {
+		return nil, model.ErrTrashNotSupported
+	}
+
+	var previous *model.TermTaxonomyJoin
+
+	err := s.shared.WithTransaction(ctx, func(ctx context.Context) error {
+		t, err := s.termByID(ctx, *req.ID, req.Taxonomy)
+		if err != nil {
+			return err
+		}
+		previous = newTermResponse(ctx, t)
+
+		if req.Taxonomy == model.CategoryType {
+			if err = s.assignDefaultCategory(ctx, t); err != nil {
+				return err
+			}
+		}
+
+		if model.IsHierarchicalTaxonomy(req.Taxonomy) {
+			if err = s.term.ReparentTerms(ctx, req.Taxonomy, t.TermID, t.Parent); err != nil {
+				return err
+			}
+		}
+
+		return s.term.DeleteTerm(ctx, t)
+	})
+	if err != nil {
+		if err != model.ErrInvalidTermID && err != model.ErrCannotDeleteDefaultTerm {
+			log.WithFields(log.Fields{
+				"params": req,
+				"func":   "s.shared.WithTransaction",
+			}).Errorf("Failed to delete term: %s", err)
+		}
+		return nil, err
+	}
+
+	return model.DeletedItem{Deleted: true, Previous: previous}, nil
+}
+
+// assignDefaultCategory assigns default_category option to posts that only have the category,
+// it returns ErrCannotDeleteDefaultTerm if the category is the default category
+func (s *service) assignDefaultCategory(ctx context.Context, t *model.TermTaxonomyJoin) error {
+	defaultCategory := "1"
+	option, err := s.shared.LoadOption(ctx, "default_category")
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil {
+		defaultCategory = option.OptionValue
+	}
+
+	defaultID, err := strconv.ParseUint(defaultCategory, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if defaultID == t.TermID {
+		return model.ErrCannotDeleteDefaultTerm
+	}
+
+	defaultTerm, err := s.term.TermByID(ctx, defaultID, model.CategoryType)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	objectIDs, err := s.term.SoleTermObjectIDs(ctx, t.TermTaxonomyID, model.CategoryType)
+	if err != nil {
+		return err
+	}
+	if err = s.term.AddObjectsTerm(ctx, objectIDs, defaultTerm.TermTaxonomyID); err != nil {
+		return err
+	}
+
+	return s.term.UpdateTermCount(ctx, []uint64{defaultTerm.TermTaxonomyID})
+}
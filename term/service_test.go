@@ -0,0 +1,250 @@
+package term
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/model"
+	mockshared "github.com/qreasio/restlr/shared/mock"
+	mockterm "github.com/qreasio/restlr/term/mock"
+	"github.com/qreasio/restlr/toolbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTerm(id uint64, name string, slug string, taxonomy string, parent uint64) *model.TermTaxonomyJoin {
+	t := &model.TermTaxonomyJoin{Term: model.Term{TermID: id, Name: name, Slug: slug, Taxonomy: taxonomy}, Parent: parent}
+	t.TermTaxonomyID = id + 100
+	return t
+}
+
+// newTermTestService returns service with mocked repositories, terms are returned by TermByID from the map
+func newTermTestService(t *testing.T, ctx context.Context, terms map[uint64]*model.TermTaxonomyJoin, options map[string]string) (Service, *mockterm.MockRepository) {
+	ctrl := gomock.NewController(t)
+	termRepoMock := mockterm.NewMockRepository(ctrl)
+	termRepoMock.EXPECT().TermByID(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id uint64, taxonomy string) (*model.TermTaxonomyJoin, error) {
+		if term, ok := terms[id]; ok && term.Taxonomy == taxonomy {
+			copied := *term
+			return &copied, nil
+		}
+		return nil, sql.ErrNoRows
+	}).AnyTimes()
+
+	sharedRepoMock := mockshared.NewMockRepository(ctrl)
+	sharedRepoMock.EXPECT().LoadOption(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (*model.Option, error) {
+		if value, ok := options[name]; ok {
+			return &model.Option{OptionName: name, OptionValue: value}, nil
+		}
+		return nil, sql.ErrNoRows
+	}).AnyTimes()
+	sharedRepoMock.EXPECT().WithTransaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return NewService(termRepoMock, sharedRepoMock), termRepoMock
+}
+
+func TestService_CreateTerm(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	terms := map[uint64]*model.TermTaxonomyJoin{
+		1: newTestTerm(1, "News", "news", model.CategoryType, 0),
+	}
+	s, termRepoMock := newTermTestService(t, ctx, terms, nil)
+
+	_, err := s.CreateTerm(ctx, model.WriteTermRequest{Taxonomy: model.CategoryType, Name: toolbox.StringPointer("  ")})
+	assert.Equal(t, model.ErrEmptyTermName, err)
+
+	parent := uint64(1)
+	_, err = s.CreateTerm(ctx, model.WriteTermRequest{Taxonomy: model.TagType, Name: toolbox.StringPointer("Go"), Parent: &parent})
+	assert.Equal(t, model.NewParamError("parent", "Cannot set parent term, taxonomy is not hierarchical."), err)
+
+	missingParent := uint64(9)
+	_, err = s.CreateTerm(ctx, model.WriteTermRequest{Taxonomy: model.CategoryType, Name: toolbox.StringPointer("Go"), Parent: &missingParent})
+	assert.Equal(t, model.NewParamError("parent", "Parent term does not exist."), err)
+
+	termRepoMock.EXPECT().TermNameExists(ctx, gomock.Any()).Return(true, nil)
+	_, err = s.CreateTerm(ctx, model.WriteTermRequest{Taxonomy: model.CategoryType, Name: toolbox.StringPointer("News")})
+	assert.Equal(t, model.ErrTermExists, err)
+
+	termRepoMock.EXPECT().TermNameExists(ctx, gomock.Any()).Return(false, nil)
+	termRepoMock.EXPECT().UniqueTermSlug(ctx, "local-news", gomock.Any()).Return("local-news-news", nil)
+	termRepoMock.EXPECT().InsertTerm(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, term *model.TermTaxonomyJoin) (uint64, error) {
+		assert.Equal(t, uint64(1), term.Parent)
+		term.TermID, term.TermTaxonomyID = 2, 102
+		return term.TermID, nil
+	})
+	res, err := s.CreateTerm(ctx, model.WriteTermRequest{Taxonomy: model.CategoryType, Name: toolbox.StringPointer("Local News"), Description: toolbox.StringPointer("City"), Parent: &parent})
+	assert.Nil(t, err)
+	term := res.(*model.TermTaxonomyJoin)
+	assert.Equal(t, uint64(2), term.TermID)
+	assert.Equal(t, "local-news-news", term.Slug)
+	assert.Equal(t, "City", term.Description)
+	assert.Equal(t, "http://example.com/category/local-news-news", term.Link)
+	assert.Equal(t, "http://example.com/wp-json/wp/v2/categories/2", term.Links.SelfLink[0]["href"])
+
+	// slug from request must not be used by another term
+	termRepoMock.EXPECT().TermNameExists(ctx, gomock.Any()).Return(false, nil)
+	termRepoMock.EXPECT().TermSlugExists(ctx, gomock.Any()).Return(true, nil)
+	_, err = s.CreateTerm(ctx, model.WriteTermRequest{Taxonomy: model.TagType, Name: toolbox.StringPointer("Golang"), Slug: toolbox.StringPointer("go")})
+	assert.Equal(t, model.ErrTermExists, err)
+}
+
+func TestService_UpdateTerm(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	terms := map[uint64]*model.TermTaxonomyJoin{
+		1: newTestTerm(1, "News", "news", model.CategoryType, 0),
+		2: newTestTerm(2, "Local", "local", model.CategoryType, 1),
+		3: newTestTerm(3, "City", "city", model.CategoryType, 2),
+	}
+	s, termRepoMock := newTermTestService(t, ctx, terms, nil)
+
+	id := uint64(9)
+	_, err := s.UpdateTerm(ctx, model.WriteTermRequest{ID: &id, Taxonomy: model.CategoryType, Name: toolbox.StringPointer("Other")})
+	assert.Equal(t, model.ErrInvalidTermID, err)
+
+	// renaming term keeps its slug
+	id = 2
+	termRepoMock.EXPECT().TermNameExists(ctx, gomock.Any()).Return(false, nil)
+	termRepoMock.EXPECT().UpdateTerm(ctx, gomock.Any()).Return(nil)
+	res, err := s.UpdateTerm(ctx, model.WriteTermRequest{ID: &id, Taxonomy: model.CategoryType, Name: toolbox.StringPointer("Local News")})
+	assert.Nil(t, err)
+	assert.Equal(t, "Local News", res.(*model.TermTaxonomyJoin).Name)
+	assert.Equal(t, "local", res.(*model.TermTaxonomyJoin).Slug)
+
+	termRepoMock.EXPECT().TermSlugExists(ctx, gomock.Any()).Return(true, nil)
+	_, err = s.UpdateTerm(ctx, model.WriteTermRequest{ID: &id, Taxonomy: model.CategoryType, Slug: toolbox.StringPointer("news")})
+	assert.Equal(t, model.ErrDuplicateTermSlug, err)
+
+	// term can't be renamed or moved to name that is already used under the parent
+	termRepoMock.EXPECT().TermNameExists(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, term *model.TermTaxonomyJoin) (bool, error) {
+		assert.Equal(t, "City", term.Name)
+		assert.Equal(t, uint64(1), term.Parent)
+		return true, nil
+	})
+	_, err = s.UpdateTerm(ctx, model.WriteTermRequest{ID: &id, Taxonomy: model.CategoryType, Name: toolbox.StringPointer("City")})
+	assert.Equal(t, model.ErrTermExists, err)
+
+	id = 3
+	parent := uint64(1)
+	termRepoMock.EXPECT().TermNameExists(ctx, gomock.Any()).Return(true, nil)
+	_, err = s.UpdateTerm(ctx, model.WriteTermRequest{ID: &id, Taxonomy: model.CategoryType, Parent: &parent})
+	assert.Equal(t, model.ErrTermExists, err)
+
+	// term can't be moved under its descendant
+	id = 1
+	parent = 3
+	_, err = s.UpdateTerm(ctx, model.WriteTermRequest{ID: &id, Taxonomy: model.CategoryType, Parent: &parent})
+	assert.Equal(t, model.NewParamError("parent", "Term cannot be a child of itself or its descendants."), err)
+}
+
+func TestService_MergeTerm(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	terms := map[uint64]*model.TermTaxonomyJoin{
+		1: newTestTerm(1, "News", "news", model.CategoryType, 0),
+		2: newTestTerm(2, "Local", "local", model.CategoryType, 1),
+	}
+	s, termRepoMock := newTermTestService(t, ctx, terms, nil)
+
+	id, into := uint64(1), uint64(1)
+	_, err := s.MergeTerm(ctx, model.MergeTermRequest{ID: &id, Into: &into, Taxonomy: model.CategoryType})
+	assert.Equal(t, model.NewParamError("into", "Term cannot be merged into itself."), err)
+
+	// merge term into its child, the child takes place of the term
+	into = 2
+	gomock.InOrder(
+		termRepoMock.EXPECT().TermObjectIDs(ctx, uint64(101)).Return([]uint64{10, 11}, nil),
+		termRepoMock.EXPECT().AddObjectsTerm(ctx, []uint64{10, 11}, uint64(102)).Return(nil),
+		termRepoMock.EXPECT().ReparentTerms(ctx, model.CategoryType, uint64(1), uint64(2)).Return(nil),
+		termRepoMock.EXPECT().UpdateTerm(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, term *model.TermTaxonomyJoin) error {
+			assert.Equal(t, uint64(2), term.TermID)
+			assert.Equal(t, uint64(0), term.Parent)
+			return nil
+		}),
+		termRepoMock.EXPECT().DeleteTerm(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, term *model.TermTaxonomyJoin) error {
+			assert.Equal(t, uint64(1), term.TermID)
+			return nil
+		}),
+		termRepoMock.EXPECT().UpdateTermCount(ctx, []uint64{102}).Return(nil),
+	)
+	res, err := s.MergeTerm(ctx, model.MergeTermRequest{ID: &id, Into: &into, Taxonomy: model.CategoryType})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), res.(*model.TermTaxonomyJoin).TermID)
+}
+
+func TestService_DeleteTerm(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	terms := map[uint64]*model.TermTaxonomyJoin{
+		1: newTestTerm(1, "Uncategorized", "uncategorized", model.CategoryType, 0),
+		2: newTestTerm(2, "News", "news", model.CategoryType, 0),
+		3: newTestTerm(3, "Local", "local", model.CategoryType, 2),
+		4: newTestTerm(4, "Go", "go", model.TagType, 0),
+	}
+	s, termRepoMock := newTermTestService(t, ctx, terms, map[string]string{"default_category": "1"})
+
+	id := uint64(2)
+	_, err := s.DeleteTerm(ctx, model.DeleteTermRequest{DeleteItemRequest: model.DeleteItemRequest{ID: &id}, Taxonomy: model.CategoryType})
+	assert.Equal(t, model.ErrTrashNotSupported, err)
+
+	id = 1
+	_, err = s.DeleteTerm(ctx, model.DeleteTermRequest{DeleteItemRequest: model.DeleteItemRequest{ID: &id, Force: true}, Taxonomy: model.CategoryType})
+	assert.Equal(t, model.ErrCannotDeleteDefaultTerm, err)
+
+	// posts that only have the category get default category and child category is moved to its parent
+	id = 3
+	gomock.InOrder(
+		termRepoMock.EXPECT().SoleTermObjectIDs(ctx, uint64(103), model.CategoryType).Return([]uint64{10}, nil),
+		termRepoMock.EXPECT().AddObjectsTerm(ctx, []uint64{10}, uint64(101)).Return(nil),
+		termRepoMock.EXPECT().UpdateTermCount(ctx, []uint64{101}).Return(nil),
+		termRepoMock.EXPECT().ReparentTerms(ctx, model.CategoryType, uint64(3), uint64(2)).Return(nil),
+		termRepoMock.EXPECT().DeleteTerm(ctx, gomock.Any()).Return(nil),
+	)
+	res, err := s.DeleteTerm(ctx, model.DeleteTermRequest{DeleteItemRequest: model.DeleteItemRequest{ID: &id, Force: true}, Taxonomy: model.CategoryType})
+	assert.Nil(t, err)
+	deleted := res.(model.DeletedItem)
+	assert.True(t, deleted.Deleted)
+	assert.Equal(t, "local", deleted.Previous.(*model.TermTaxonomyJoin).Slug)
+
+	// tag has no default term
+	id = 4
+	termRepoMock.EXPECT().DeleteTerm(ctx, gomock.Any()).Return(nil)
+	_, err = s.DeleteTerm(ctx, model.DeleteTermRequest{DeleteItemRequest: model.DeleteItemRequest{ID: &id, Force: true}, Taxonomy: model.TagType})
+	assert.Nil(t, err)
+}
+
+func TestService_ListTerms(t *testing.T) {
+	ctx := context.WithValue(context.Background(), model.APIConfigKey, model.APIConfig{SiteURL: "http://example.com", APIBaseURL: "http://example.com/wp-json/wp/v2"})
+	// registering twice fails when the test runs more than once, the first registration is kept
+	_ = model.RegisterTaxonomy(model.TaxonomyConfig{Slug: "region", RestBase: "regions", Hierarchical: true, ObjectTypes: []string{model.PostType}})
+	s, termRepoMock := newTermTestService(t, ctx, nil, nil)
+
+	req := model.ListTermsRequest{Taxonomy: model.TagType, Page: 1, PerPage: 10, Order: "asc", OrderBy: "name"}
+
+	invalid := req
+	invalid.PerPage = 101
+	_, err := s.ListTerms(ctx, invalid)
+	assert.Equal(t, model.NewParamError("per_page", "per_page must be between 1 (inclusive) and 100 (inclusive)"), err)
+
+	invalid = req
+	invalid.OrderBy = "term_group"
+	_, err = s.ListTerms(ctx, invalid)
+	assert.Equal(t, model.NewParamError("orderby", "orderby is not one of id, name, slug, count."), err)
+
+	parent := uint64(1)
+	invalid = req
+	invalid.Parent = &parent
+	_, err = s.ListTerms(ctx, invalid)
+	assert.Equal(t, model.NewParamError("parent", "Cannot filter by parent, taxonomy is not hierarchical."), err)
+
+	// registered taxonomy can be hierarchical and its terms link to archive named by the taxonomy
+	req.Taxonomy = "region"
+	req.Parent = &parent
+	termRepoMock.EXPECT().ListTerms(ctx, req).Return([]*model.TermTaxonomyJoin{newTestTerm(7, "North", "north", "region", 1)}, nil)
+	res, err := s.ListTerms(ctx, req)
+	assert.Nil(t, err)
+	terms := res.([]*model.TermTaxonomyJoin)
+	assert.Len(t, terms, 1)
+	assert.Equal(t, "http://example.com/region/north", terms[0].Link)
+	assert.Equal(t, "http://example.com/wp-json/wp/v2/regions/7", terms[0].Links.SelfLink[0]["href"])
+	assert.Equal(t, "http://example.com/wp-json/wp/v2/taxonomies/region", terms[0].Links.About[0]["href"])
+}
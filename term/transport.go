@@ -0,0 +1,176 @@
+package term
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/go-playground/form"
+	resthttp "github.com/qreasio/restlr/http"
+	"github.com/qreasio/restlr/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// MakeHTTPHandler returns http handler that makes a set of endpoints of the taxonomy available on predefined paths
+func MakeHTTPHandler(s Service, taxonomy string, middlewares ...resthttp.EndpointMiddleware) http.Handler {
+	r := chi.NewRouter()
+	instrument := resthttp.Instrument(middlewares)
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(resthttp.EncodeError),
+	}
+
+	ListTermsHandler := kithttp.NewServer(
+		instrument("term.ListTerms", makeListTermsEndpoint(s)),
+		makeListTermsRequestDecoder(taxonomy),
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/", ListTermsHandler)
+
+	GetTermHandler := kithttp.NewServer(
+		instrument("term.GetTerm", makeGetTermEndpoint(s)),
+		makeGetTermRequestDecoder(taxonomy),
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.Method(http.MethodGet, "/{id}", GetTermHandler)
+
+	CreateTermHandler := kithttp.NewServer(
+		instrument("term.CreateTerm", makeCreateTermEndpoint(s)),
+		makeWriteTermRequestDecoder(taxonomy, false),
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/", CreateTermHandler)
+
+	UpdateTermHandler := kithttp.NewServer(
+		instrument("term.UpdateTerm", makeUpdateTermEndpoint(s)),
+		makeWriteTermRequestDecoder(taxonomy, true),
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/{id}", UpdateTermHandler)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPut, "/{id}", UpdateTermHandler)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPatch, "/{id}", UpdateTermHandler)
+
+	MergeTermHandler := kithttp.NewServer(
+		instrument("term.MergeTerm", makeMergeTermEndpoint(s)),
+		makeMergeTermRequestDecoder(taxonomy),
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodPost, "/{id}/merge", MergeTermHandler)
+
+	DeleteTermHandler := kithttp.NewServer(
+		instrument("term.DeleteTerm", makeDeleteTermEndpoint(s)),
+		makeDeleteTermRequestDecoder(taxonomy),
+		resthttp.EncodeJSONResponse,
+		options...,
+	)
+	r.With(resthttp.RequireWriteAccess).Method(http.MethodDelete, "/{id}", DeleteTermHandler)
+
+	return r
+}
+
+// parseIDParam returns id parameter of the route, it returns ErrInvalidRoute if id is not a number
+func parseIDParam(r *http.Request) (*uint64, error) {
+	id := chi.URLParam(r, "id")
+	termID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"params": id,
+			"func":   "strconv.ParseUint",
+		}).Errorf("Failed to parse uint from string: %s", err)
+		return nil, model.ErrInvalidRoute
+	}
+	return &termID, nil
+}
+
+func makeGetTermRequestDecoder(taxonomy string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			return nil, err
+		}
+		return model.GetTermRequest{ID: id, Taxonomy: taxonomy}, nil
+	}
+}
+
+func makeListTermsRequestDecoder(taxonomy string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		listRequest := model.ListTermsRequest{Taxonomy: taxonomy, Page: 1, PerPage: 10, Order: "asc", OrderBy: "name"}
+		r.ParseForm()
+		if err := form.NewDecoder().Decode(&listRequest, r.Form); err != nil {
+			log.WithFields(log.Fields{
+				"params": r.Form,
+				"func":   "decoder.Decode",
+			}).Errorf("Failed to decode request: %s", err)
+			return nil, model.ErrInvalidParameter
+		}
+		listRequest.Taxonomy = taxonomy
+		return listRequest, nil
+	}
+}
+
+func makeWriteTermRequestDecoder(taxonomy string, withID bool) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		writeRequest := model.WriteTermRequest{Taxonomy: taxonomy}
+		if withID {
+			id, err := parseIDParam(r)
+			if err != nil {
+				return nil, err
+			}
+			writeRequest.ID = id
+		}
+		if err := resthttp.DecodeBody(r, &writeRequest); err != nil {
+			log.WithFields(log.Fields{
+				"params": r.Form,
+				"func":   "resthttp.DecodeBody",
+			}).Errorf("Failed to decode request: %s", err)
+			return nil, err
+		}
+		return writeRequest, nil
+	}
+}
+
+func makeMergeTermRequestDecoder(taxonomy string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			return nil, err
+		}
+		mergeRequest := model.MergeTermRequest{Taxonomy: taxonomy}
+		if err = resthttp.DecodeBody(r, &mergeRequest); err != nil {
+			log.WithFields(log.Fields{
+				"params": r.Form,
+				"func":   "resthttp.DecodeBody",
+			}).Errorf("Failed to decode request: %s", err)
+			return nil, err
+		}
+		mergeRequest.ID = id
+		return mergeRequest, nil
+	}
+}
+
+func makeDeleteTermRequestDecoder(taxonomy string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			return nil, err
+		}
+		deleteRequest := model.DeleteTermRequest{Taxonomy: taxonomy}
+		r.ParseForm()
+		if err = form.NewDecoder().Decode(&deleteRequest.DeleteItemRequest, r.Form); err != nil {
+			log.WithFields(log.Fields{
+				"params": r.Form,
+				"func":   "decoder.Decode",
+			}).Errorf("Failed to decode request: %s", err)
+			return nil, err
+		}
+		deleteRequest.ID = id
+		return deleteRequest, nil
+	}
+}
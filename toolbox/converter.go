@@ -35,3 +35,30 @@ func UInt64SliceToCSV(numbers []uint64) string {
 
 	return strings.Join(stringVars, ",")
 }
+
+// UInt64InSlice is function to check whether uint64 value exists in uint64 slice
+func UInt64InSlice(val uint64, numbers []uint64) bool {
+	for _, number := range numbers {
+		if number == val {
+			return true
+		}
+	}
+	return false
+}
+
+// CSVToUInt64Slice is function to convert string with comma separated values (csv) format to uint64 slice,
+// it returns error if any value is not unsigned integer
+func CSVToUInt64Slice(csv string) ([]uint64, error) {
+	var numbers []uint64
+	for _, val := range strings.Split(csv, ",") {
+		if val = strings.TrimSpace(val); val == "" {
+			continue
+		}
+		number, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/shared"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts server span of every request as child of W3C traceparent header of the request, the span is named
+// by method and route template like 'GET /wp-json/wp/v2/posts/{id}' after the request is routed
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path)))
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		if rctx, ok := r.Context().Value(chi.RouteCtxKey).(*chi.Context); ok && rctx.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(semconv.HTTPRoute(rctx.RoutePattern()))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(sw.status))
+		}
+	})
+}
+
+// Endpoint returns middleware that starts span of the named endpoint, it is EndpointMiddleware of the API handlers
+func Endpoint(name string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			ctx, span := Start(ctx, name)
+			defer func() { End(span, err) }()
+			return next(ctx, request)
+		}
+	}
+}
+
+// QueryHook starts client span of every query that is named by repository method like 'post.QueryPosts', the statement
+// is sanitized so values that are inlined in sql are not exported. It is added to shared.QueryHooks
+func QueryHook(ctx context.Context, d dialect.Dialect, method string, query string) func(err error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String(d.Name()),
+			semconv.DBStatement(SanitizeQuery(query)),
+			semconv.CodeFunction(method),
+		))
+	return func(err error) {
+		End(span, err)
+	}
+}
+
+var _ shared.QueryHook = QueryHook
+
+var (
+	// literalPattern matches string literals and numbers of sql, numbered placeholders like $1 are matched to be kept
+	literalPattern = regexp.MustCompile(`'(?:[^']|'')*'|\$?\b\d+(?:\.\d+)?\b`)
+	// spacePattern matches whitespace of sql
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// SanitizeQuery returns the query with string and number literals replaced by '?' and whitespace collapsed
+func SanitizeQuery(query string) string {
+	query = literalPattern.ReplaceAllStringFunc(query, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+	return strings.TrimSpace(spacePattern.ReplaceAllString(query, " "))
+}
+
+// statusWriter records status code of response that is written to the wrapped ResponseWriter
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DefaultOTLPEndpoint is base URL of OTLP/HTTP receiver of local OpenTelemetry collector
+const DefaultOTLPEndpoint = "http://localhost:4318"
+
+// otlpTracesPath is path of traces of OTLP/HTTP receiver
+const otlpTracesPath = "/v1/traces"
+
+// NewOTLPExporter returns OTLP/HTTP exporter of OpenTelemetry that posts spans to /v1/traces of the endpoint like
+// 'http://localhost:4318', spans are posted without TLS if the scheme of the endpoint is http
+func NewOTLPExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + otlpTracesPath),
+	}
+	if u.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(context.Background(), options...)
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPExporter_BatchSpanProcessor(t *testing.T) {
+	var mu sync.Mutex
+	var received []*coltracepb.ExportTraceServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/otel/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := &coltracepb.ExportTraceServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(body, req))
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(server.URL + "/otel/")
+	if !assert.NoError(t, err) {
+		return
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "restlr"))),
+	)
+	tracer := provider.Tracer(tracerName)
+	ctx, parent := tracer.Start(context.Background(), "post.ListPosts")
+	_, child := tracer.Start(ctx, "post.QueryPosts", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("rows", 10)))
+	End(child, errors.New("timeout"))
+	parent.End()
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !assert.Len(t, received, 1) || !assert.Len(t, received[0].ResourceSpans, 1) {
+		return
+	}
+	rs := received[0].ResourceSpans[0]
+	assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	if !assert.Len(t, rs.ScopeSpans, 1) || !assert.Len(t, rs.ScopeSpans[0].Spans, 2) {
+		return
+	}
+	assert.Equal(t, tracerName, rs.ScopeSpans[0].Scope.Name)
+	spans := rs.ScopeSpans[0].Spans
+	assert.Equal(t, "post.QueryPosts", spans[0].Name)
+	assert.Equal(t, spans[1].SpanId, spans[0].ParentSpanId)
+	assert.Equal(t, "timeout", spans[0].Status.Message)
+	assert.Equal(t, int64(10), spans[0].Attributes[0].Value.GetIntValue())
+}
+
+func TestOTLPExporter_ExportSpansError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	_, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(tracerName).Start(context.Background(), "span")
+	span.End()
+
+	exporter, err := NewOTLPExporter(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Error(t, exporter.ExportSpans(context.Background(), recorder.Ended()))
+	assert.NoError(t, exporter.Shutdown(context.Background()))
+
+	_, err = NewOTLPExporter("localhost:4318")
+	assert.Error(t, err)
+}
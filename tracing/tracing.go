@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// names of span exporters
+const (
+	// OTLPExporterName exports spans to OpenTelemetry collector with OTLP/HTTP
+	OTLPExporterName = "otlp"
+	// StdoutExporterName writes spans as json to stdout
+	StdoutExporterName = "stdout"
+	// NoopExporterName drops spans, spans are still created and trace context is still propagated
+	NoopExporterName = "none"
+)
+
+// tracerName is instrumentation name of spans of Restlr
+const tracerName = "github.com/qreasio/restlr"
+
+// NewExporter returns span exporter of the name, endpoint is base URL of OTLP/HTTP collector like 'http://localhost:4318'
+func NewExporter(name string, endpoint string) (sdktrace.SpanExporter, error) {
+	switch name {
+	case OTLPExporterName:
+		return NewOTLPExporter(endpoint)
+	case StdoutExporterName:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case NoopExporterName:
+		return noopExporter{}, nil
+	}
+	return nil, fmt.Errorf("unknown exporter %q", name)
+}
+
+// Setup sets global tracer provider that exports spans of the service with batch span processor of the exporter and W3C
+// trace context propagator, spans are sampled if their parent is sampled or if they are root spans. The returned function
+// flushes and stops the exporter
+func Setup(exporter sdktrace.SpanExporter, serviceName string) func(ctx context.Context) error {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown
+}
+
+// Start starts span of the name as child of span of ctx, it is no-op span until Setup is called
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error to the span if it is not nil and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// noopExporter drops spans
+type noopExporter struct{}
+
+func (noopExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	return nil
+}
+
+func (noopExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
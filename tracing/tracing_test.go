@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans sets global tracer provider that records ended spans until the test ends
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+// attributeOf returns value of the attribute of the span
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+	getPost := Endpoint("post.GetPost")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		QueryHook(ctx, dialect.MySQL, "post.PostByID", "SELECT * FROM wp_posts WHERE ID = ?  AND post_status = 'publish'")(nil)
+		return nil, errors.New("post is not found")
+	})
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/wp-json/wp/v2/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		getPost(r.Context(), nil)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/wp-json/wp/v2/posts/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	query, endpoint, server := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "GET /wp-json/wp/v2/posts/{id}", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, "404", attributeOf(server, "http.response.status_code"))
+	assert.Equal(t, codes.Unset, server.Status().Code)
+
+	assert.Equal(t, "post.GetPost", endpoint.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), endpoint.Parent().SpanID())
+	assert.Equal(t, codes.Error, endpoint.Status().Code)
+
+	assert.Equal(t, "post.PostByID", query.Name())
+	assert.Equal(t, endpoint.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, "SELECT * FROM wp_posts WHERE ID = ? AND post_status = ?", attributeOf(query, "db.statement"))
+}
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT ID FROM wp_2_posts WHERE ID IN (12, 10) LIMIT 10", "SELECT ID FROM wp_2_posts WHERE ID IN (?, ?) LIMIT ?"},
+		{"SELECT * FROM wp_options WHERE option_name = 'it''s' AND autoload = $1", "SELECT * FROM wp_options WHERE option_name = ? AND autoload = $1"},
+		{"SELECT 1.5,\n\t\"post_title\" FROM wp_posts", "SELECT ?, \"post_title\" FROM wp_posts"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, SanitizeQuery(test.query))
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	for _, name := range []string{OTLPExporterName, StdoutExporterName, NoopExporterName} {
+		exporter, err := NewExporter(name, "")
+		assert.NoError(t, err, name)
+		assert.NotNil(t, exporter, name)
+	}
+	_, err := NewExporter("zipkin", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"context"
+
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+)
+
+// cachedRepository is Repository that caches users, reads inside transaction skip the cache so values that may be
+// rolled back are never cached
+type cachedRepository struct {
+	Repository
+	cache *cache.Cache
+}
+
+// NewCachedRepository returns Repository that caches results of repo in c
+func NewCachedRepository(repo Repository, c *cache.Cache) Repository {
+	return &cachedRepository{
+		Repository: repo,
+		cache:      c,
+	}
+}
+
+// GetUserByID returns cached user of the id
+func (repo *cachedRepository) GetUserByID(ctx context.Context, id uint64) (*model.UserDetail, error) {
+	if shared.InTransaction(ctx) {
+		return repo.Repository.GetUserByID(ctx, id)
+	}
+
+	var user *model.UserDetail
+	err := repo.cache.Fetch(ctx, cache.Key(ctx, "user", id), &user, func(ctx context.Context) (interface{}, error) {
+		return repo.Repository.GetUserByID(ctx, id)
+	})
+	return user, err
+}
+
+// GetUserByIDList returns cached users of the id list
+func (repo *cachedRepository) GetUserByIDList(ctx context.Context, idList []uint64) (map[uint64]*model.UserDetail, error) {
+	if shared.InTransaction(ctx) {
+		return repo.Repository.GetUserByIDList(ctx, idList)
+	}
+
+	var users map[uint64]*model.UserDetail
+	err := repo.cache.Fetch(ctx, cache.Key(ctx, "users", idList), &users, func(ctx context.Context) (interface{}, error) {
+		return repo.Repository.GetUserByIDList(ctx, idList)
+	})
+	return users, err
+}
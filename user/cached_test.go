@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qreasio/restlr/cache"
+	"github.com/qreasio/restlr/dialect"
+	"github.com/qreasio/restlr/model"
+	"github.com/qreasio/restlr/shared"
+	mockuser "github.com/qreasio/restlr/user/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedRepository_InTransaction(t *testing.T) {
+	db, err := dialect.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepoMock := mockuser.NewMockRepository(ctrl)
+	repo := NewCachedRepository(userRepoMock, cache.New(cache.NewLRUStore(1<<20), 0))
+	user := &model.UserDetail{}
+	user.ID = 1
+
+	// reads inside transaction always reach the repository and aren't cached
+	err = shared.WithTransaction(context.Background(), db, func(ctx context.Context) error {
+		userRepoMock.EXPECT().GetUserByID(ctx, uint64(1)).Return(user, nil).Times(2)
+		userRepoMock.EXPECT().GetUserByIDList(ctx, []uint64{1}).Return(map[uint64]*model.UserDetail{1: user}, nil).Times(2)
+		for i := 0; i < 2; i++ {
+			if _, err := repo.GetUserByID(ctx, 1); err != nil {
+				return err
+			}
+			if _, err := repo.GetUserByIDList(ctx, []uint64{1}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	// the cached read loads with context that is detached from ctx
+	userRepoMock.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil).Times(1)
+	for i := 0; i < 2; i++ {
+		cached, err := repo.GetUserByID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), cached.ID)
+	}
+}
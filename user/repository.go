@@ -2,10 +2,11 @@ package user
 
 import (
 	"context"
-	"database/sql"
 
+	"github.com/qreasio/restlr/dialect"
 	"github.com/qreasio/restlr/model"
-	"github.com/qreasio/restlr/toolbox"
+	"github.com/qreasio/restlr/shared"
+	"github.com/qreasio/restlr/sqlbuilder"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -16,11 +17,11 @@ type Repository interface {
 }
 
 type repository struct {
-	db *sql.DB
+	db *dialect.DB
 }
 
 // NewRepository is function to create new repository struct instance that implements Repository interface
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db *dialect.DB) Repository {
 	return &repository{
 		db: db,
 	}
@@ -29,8 +30,8 @@ func NewRepository(db *sql.DB) Repository {
 // GetUserByID is function to get UserDetail from specific id parameter
 func (repo *repository) GetUserByID(ctx context.Context, id uint64) (*model.UserDetail, error) {
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := apiConfig.TablePrefix + "users"
-	metaTableName := apiConfig.TablePrefix + "usermeta"
+	tableName := apiConfig.Tables().Users
+	metaTableName := apiConfig.Tables().UserMeta
 
 	var sql = `SELECT ` +
 		`u.ID, u.user_login, u.user_pass, u.user_nicename, u.user_email, u.user_url, u.user_registered, u.user_activation_key, u.user_status, u.display_name, m.meta_value ` +
@@ -39,7 +40,7 @@ func (repo *repository) GetUserByID(ctx context.Context, id uint64) (*model.User
 
 	wu := &model.UserDetail{}
 
-	err := repo.db.QueryRow(sql, id).Scan(&wu.ID, &wu.Login, &wu.Pass, &wu.NiceName, &wu.Email, &wu.URL, &wu.Registered, &wu.ActivationKey, &wu.Status, &wu.DisplayName, &wu.Description)
+	err := shared.Conn(ctx, repo.db).QueryRowContext(ctx, sql, id).Scan(&wu.ID, &wu.Login, &wu.Pass, &wu.NiceName, &wu.Email, &wu.URL, &wu.Registered, &wu.ActivationKey, &wu.Status, &wu.DisplayName, &wu.Description)
 
 	return wu, err
 }
@@ -47,15 +48,19 @@ func (repo *repository) GetUserByID(ctx context.Context, id uint64) (*model.User
 // GetUserByIDList is function to get list of UserDetail from idList parameter
 func (repo *repository) GetUserByIDList(ctx context.Context, idList []uint64) (map[uint64]*model.UserDetail, error) {
 	apiConfig := ctx.Value(model.APIConfigKey).(model.APIConfig)
-	tableName := apiConfig.TablePrefix + "users"
-	metaTableName := apiConfig.TablePrefix + "usermeta"
-
-	var sqlQuery = `SELECT ` +
-		`u.ID, u.user_login, u.user_pass, u.user_nicename, u.user_email, u.user_url, u.user_registered, u.user_activation_key, u.user_status, u.display_name, m.meta_value ` +
-		`FROM ` + tableName + ` u LEFT JOIN ` + metaTableName + ` m ON m.user_id = u.ID ` +
-		`WHERE m.meta_key = 'description' AND u.ID IN (` + toolbox.UInt64SliceToCSV(idList) + `)`
+	tableName := apiConfig.Tables().Users
+	metaTableName := apiConfig.Tables().UserMeta
+
+	sqlQuery, args, err := sqlbuilder.New(`SELECT `+
+		`u.ID, u.user_login, u.user_pass, u.user_nicename, u.user_email, u.user_url, u.user_registered, u.user_activation_key, u.user_status, u.display_name, m.meta_value `+
+		`FROM `).Ident(tableName).SQL(` u LEFT JOIN `).Ident(metaTableName).SQL(` m ON m.user_id = u.ID `+
+		`WHERE m.meta_key = 'description'`).AndIn("u.ID", sqlbuilder.Uint64s(idList)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
 
-	q, err := repo.db.Query(sqlQuery)
+	q, err := shared.Conn(ctx, repo.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"params": sqlQuery,